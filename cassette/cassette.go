@@ -0,0 +1,333 @@
+// Package cassette provides an http.RoundTripper that records RPC exchanges
+// to a fixture file and replays them later, so tests against wallet.Client
+// or daemon.Client can run without a live monero-wallet-rpc or monerod.
+//
+//	tr, err := cassette.New(cassette.Config{Path: "testdata/transfer.json", Mode: cassette.ModeAuto})
+//	cl := wallet.New(wallet.Config{Address: addr, Transport: tr})
+//	...
+//	err = tr.Save()
+//
+// Requests are matched by URL path, JSON-RPC method and params, ignoring the
+// JSON-RPC id and the order of object keys. Identical requests are replayed
+// in the order they were recorded, so a flow that polls get_balance before
+// and after a transfer sees both answers. Values of sensitive fields such as
+// passwords, seeds and private keys are redacted before they are written;
+// matching uses a hash of the request as sent, so requests that differ only
+// in redacted values are still told apart.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// Mode selects whether a Transport records or replays.
+type Mode int
+
+const (
+	// ModeAuto replays if the cassette file exists and records otherwise.
+	ModeAuto Mode = iota
+	// ModeRecord forwards requests to the real server and records them.
+	ModeRecord
+	// ModeReplay serves recorded responses and never touches the network.
+	ModeReplay
+)
+
+// Redacted replaces the value of redacted fields.
+const Redacted = "REDACTED"
+
+// DefaultRedactKeys are the JSON fields whose values are redacted in all
+// requests and responses. String values are redacted, as are the strings of
+// an array, such as tx_key_list.
+var DefaultRedactKeys = []string{
+	"password", "old_password", "new_password", "wallet_password", "background_cache_password",
+	"seed", "seed_offset", "spendkey", "viewkey", "spend_key", "view_key",
+	"mnemonic", "tx_key", "tx_key_list", "multisig_txset",
+}
+
+// DefaultRedactMethodKeys are the JSON fields redacted only in the exchanges
+// of one method. Generic names such as "key" are public data elsewhere: an
+// output key of get_outs or an attribute name of get_attribute.
+var DefaultRedactMethodKeys = map[string][]string{
+	"query_key": {"key"},
+}
+
+// ErrNoInteraction is returned in replay mode for requests that are not
+// in the cassette.
+var ErrNoInteraction = errors.New("cassette: no recorded interaction")
+
+// Config configures a Transport.
+type Config struct {
+	// Path of the cassette file.
+	Path string
+	Mode Mode
+	// Transport used to reach the real server while recording. Defaults to
+	// http.DefaultTransport.
+	Transport http.RoundTripper
+	// RedactKeys replaces DefaultRedactKeys if not nil.
+	RedactKeys []string
+	// RedactMethodKeys replaces DefaultRedactMethodKeys if not nil. It is
+	// keyed by JSON-RPC method, or by URL path for the daemon's other
+	// endpoints.
+	RedactMethodKeys map[string][]string
+}
+
+// Interaction is a recorded request and its response. Bodies are stored as
+// JSON when they are valid JSON and as base64 otherwise (the .bin
+// endpoints). RequestHash identifies the request before redaction;
+// cassettes recorded without it are matched on the redacted request.
+type Interaction struct {
+	Path        string          `json:"path"`
+	Method      string          `json:"method,omitempty"`
+	RequestHash string          `json:"request_hash,omitempty"`
+	Request     json.RawMessage `json:"request,omitempty"`
+	RequestBin  []byte          `json:"request_bin,omitempty"`
+	StatusCode  int             `json:"status_code"`
+	Response    json.RawMessage `json:"response,omitempty"`
+	ResponseBin []byte          `json:"response_bin,omitempty"`
+	key         string
+	used        bool
+}
+
+type file struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Transport records or replays HTTP exchanges.
+type Transport struct {
+	path         string
+	mode         Mode
+	next         http.RoundTripper
+	redact       map[string]bool
+	redactMethod map[string][]string
+
+	mu           sync.Mutex
+	interactions []*Interaction
+}
+
+// New returns a Transport for cfg. In replay mode, or in auto mode when the
+// file exists, the cassette is loaded immediately.
+func New(cfg Config) (*Transport, error) {
+	t := &Transport{
+		path:         cfg.Path,
+		mode:         cfg.Mode,
+		next:         cfg.Transport,
+		redact:       make(map[string]bool),
+		redactMethod: cfg.RedactMethodKeys,
+	}
+	if t.next == nil {
+		t.next = http.DefaultTransport
+	}
+	keys := cfg.RedactKeys
+	if keys == nil {
+		keys = DefaultRedactKeys
+	}
+	for _, k := range keys {
+		t.redact[k] = true
+	}
+	if t.redactMethod == nil {
+		t.redactMethod = DefaultRedactMethodKeys
+	}
+
+	if t.mode == ModeAuto {
+		t.mode = ModeRecord
+		if _, err := os.Stat(cfg.Path); err == nil {
+			t.mode = ModeReplay
+		}
+	}
+	if t.mode == ModeReplay {
+		data, err := os.ReadFile(cfg.Path)
+		if err != nil {
+			return nil, fmt.Errorf("cassette: %w", err)
+		}
+		var f file
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("cassette: %s: %w", cfg.Path, err)
+		}
+		for _, in := range f.Interactions {
+			in.key = in.RequestHash
+			if in.key == "" {
+				body := []byte(in.Request)
+				if in.RequestBin != nil {
+					body = in.RequestBin
+				}
+				in.key = matchKey(in.Path, body)
+			}
+		}
+		t.interactions = f.Interactions
+	}
+	return t, nil
+}
+
+// Mode returns ModeRecord or ModeReplay.
+func (t *Transport) Mode() Mode {
+	return t.mode
+}
+
+// Interactions returns the interactions recorded or loaded so far.
+func (t *Transport) Interactions() []Interaction {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]Interaction, len(t.interactions))
+	for i, in := range t.interactions {
+		out[i] = *in
+	}
+	return out
+}
+
+// Save writes the recorded interactions to the cassette file. It does
+// nothing in replay mode.
+func (t *Transport) Save() error {
+	if t.mode != ModeRecord {
+		return nil
+	}
+	t.mu.Lock()
+	data, err := json.MarshalIndent(file{Interactions: t.interactions}, "", "  ")
+	t.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(t.path, append(data, '\n'), 0o644)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var raw []byte
+	if req.Body != nil {
+		var err error
+		raw, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	keys := t.redactKeys(req.URL.Path, raw)
+	body := redactJSON(raw, keys)
+	if t.mode == ModeReplay {
+		return t.replay(req, raw, body)
+	}
+	return t.record(req, raw, body, keys)
+}
+
+// redactKeys returns the fields to redact in an exchange with path and
+// request body raw.
+func (t *Transport) redactKeys(path string, raw []byte) map[string]bool {
+	var extra []string
+	for _, method := range append(rpcMethods(raw), path) {
+		extra = append(extra, t.redactMethod[method]...)
+	}
+	if len(extra) == 0 {
+		return t.redact
+	}
+	keys := make(map[string]bool, len(t.redact)+len(extra))
+	for k := range t.redact {
+		keys[k] = true
+	}
+	for _, k := range extra {
+		keys[k] = true
+	}
+	return keys
+}
+
+// record sends the original request and stores it with the redacted body.
+func (t *Transport) record(req *http.Request, raw, body []byte, keys map[string]bool) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	if req.Body != nil {
+		out.Body = io.NopCloser(bytes.NewReader(raw))
+	}
+	resp, err := t.next.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	// Digest challenges are not recorded, so a cassette replays without
+	// credentials.
+	if resp.StatusCode == http.StatusUnauthorized {
+		return resp, nil
+	}
+	in := &Interaction{
+		Path:        req.URL.Path,
+		Method:      rpcMethod(body),
+		RequestHash: requestHash(req.URL.Path, raw),
+		StatusCode:  resp.StatusCode,
+	}
+	in.key = in.RequestHash
+	if json.Valid(body) {
+		in.Request = stripID(body)
+	} else if len(body) > 0 {
+		in.RequestBin = body
+	}
+	if json.Valid(respBody) {
+		in.Response = redactJSON(respBody, keys)
+	} else if len(respBody) > 0 {
+		in.ResponseBin = respBody
+	}
+	t.mu.Lock()
+	t.interactions = append(t.interactions, in)
+	t.mu.Unlock()
+	return resp, nil
+}
+
+func (t *Transport) replay(req *http.Request, raw, body []byte) (*http.Response, error) {
+	hash, key := requestHash(req.URL.Path, raw), matchKey(req.URL.Path, body)
+
+	t.mu.Lock()
+	var match *Interaction
+	for _, in := range t.interactions {
+		want := key
+		if in.RequestHash != "" {
+			want = hash
+		}
+		if in.key != want {
+			continue
+		}
+		match = in
+		if !in.used {
+			break
+		}
+	}
+	if match != nil {
+		match.used = true
+	}
+	t.mu.Unlock()
+
+	if match == nil {
+		if m := rpcMethod(body); m != "" {
+			return nil, fmt.Errorf("%w for %s %s", ErrNoInteraction, req.URL.Path, m)
+		}
+		return nil, fmt.Errorf("%w for %s", ErrNoInteraction, req.URL.Path)
+	}
+
+	respBody := []byte(match.Response)
+	contentType := "application/json"
+	if match.ResponseBin != nil {
+		respBody = match.ResponseBin
+		contentType = "application/octet-stream"
+	} else if id := requestID(body); id != nil {
+		respBody = setID(respBody, id)
+	}
+	return &http.Response{
+		Status:        strconv.Itoa(match.StatusCode) + " " + http.StatusText(match.StatusCode),
+		StatusCode:    match.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {contentType}},
+		Body:          io.NopCloser(bytes.NewReader(respBody)),
+		ContentLength: int64(len(respBody)),
+		Request:       req,
+	}, nil
+}
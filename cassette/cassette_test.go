@@ -0,0 +1,178 @@
+package cassette
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/boomhut/go-monero-rpc-client/daemon"
+	"github.com/boomhut/go-monero-rpc-client/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+func newWalletServer(t *testing.T) *httptest.Server {
+	balance := 0
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req struct {
+			Method string                 `json:"method"`
+			Params map[string]interface{} `json:"params"`
+			ID     json.RawMessage        `json:"id"`
+		}
+		assert.NoError(t, json.Unmarshal(body, &req))
+		var result string
+		switch req.Method {
+		case "open_wallet":
+			assert.Equal(t, "hunter2", req.Params["password"])
+			result = `{}`
+		case "get_balance":
+			balance += 100
+			result = fmt.Sprintf(`{"balance":%d}`, balance)
+		case "query_key":
+			result = `{"key":"abandon abandon abandon"}`
+		case "get_attribute":
+			result = fmt.Sprintf(`{"value":"value of %s"}`, req.Params["key"])
+		case "transfer":
+			result = `{"amount":1152921504606846977,"fee":18446744073709551615,"tx_key":"secret tx key"}`
+		case "transfer_split":
+			result = `{"amount_list":[9007199254740993],"tx_key_list":["secret one","secret two"]}`
+		}
+		w.Write([]byte(`{"id":` + string(req.ID) + `,"jsonrpc":"2.0","result":` + result + `}`))
+	}))
+}
+
+func TestRecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wallet.json")
+	srv := newWalletServer(t)
+
+	rec, err := New(Config{Path: path})
+	assert.NoError(t, err)
+	assert.Equal(t, ModeRecord, rec.Mode())
+	cl := wallet.New(wallet.Config{Address: srv.URL + "/json_rpc", Transport: rec})
+	assert.NoError(t, cl.OpenWallet(&wallet.RequestOpenWallet{Filename: "w", Password: "hunter2"}))
+	b1, err := cl.GetBalance(&wallet.RequestGetBalance{})
+	assert.NoError(t, err)
+	b2, err := cl.GetBalance(&wallet.RequestGetBalance{})
+	assert.NoError(t, err)
+	key, err := cl.QueryKey(&wallet.RequestQueryKey{KeyType: "mnemonic"})
+	assert.NoError(t, err)
+	assert.Equal(t, "abandon abandon abandon", key.Key)
+	assert.NoError(t, rec.Save())
+	srv.Close()
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "hunter2")
+	assert.NotContains(t, string(data), "abandon")
+
+	rep, err := New(Config{Path: path})
+	assert.NoError(t, err)
+	assert.Equal(t, ModeReplay, rep.Mode())
+	cl = wallet.New(wallet.Config{Address: srv.URL + "/json_rpc", Transport: rep})
+	err = cl.OpenWallet(&wallet.RequestOpenWallet{Filename: "w", Password: "other"})
+	assert.True(t, errors.Is(err, ErrNoInteraction), "got %v", err)
+	assert.NoError(t, cl.OpenWallet(&wallet.RequestOpenWallet{Filename: "w", Password: "hunter2"}))
+	r1, err := cl.GetBalance(&wallet.RequestGetBalance{})
+	assert.NoError(t, err)
+	r2, err := cl.GetBalance(&wallet.RequestGetBalance{})
+	assert.NoError(t, err)
+	assert.Equal(t, b1, r1)
+	assert.Equal(t, b2, r2)
+	assert.NotEqual(t, r1.Balance, r2.Balance)
+	key, err = cl.QueryKey(&wallet.RequestQueryKey{KeyType: "mnemonic"})
+	assert.NoError(t, err)
+	assert.Equal(t, Redacted, key.Key)
+
+	// the last matching interaction is served again once all are used
+	r3, err := cl.GetBalance(&wallet.RequestGetBalance{})
+	assert.NoError(t, err)
+	assert.Equal(t, b2, r3)
+
+	_, err = cl.GetHeight()
+	assert.True(t, errors.Is(err, ErrNoInteraction), "got %v", err)
+}
+
+func TestReplayDaemonEndpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daemon.json")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"hash":"aa","height":7,"status":"OK"}`))
+	}))
+
+	rec, err := New(Config{Path: path, Mode: ModeRecord})
+	assert.NoError(t, err)
+	_, err = daemon.New(daemon.Config{Address: srv.URL, Transport: rec}).GetHeight()
+	assert.NoError(t, err)
+	assert.NoError(t, rec.Save())
+	srv.Close()
+
+	rep, err := New(Config{Path: path, Mode: ModeReplay})
+	assert.NoError(t, err)
+	height, err := daemon.New(daemon.Config{Address: srv.URL, Transport: rep}).GetHeight()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), height.Height)
+	assert.Len(t, rep.Interactions(), 1)
+	assert.Equal(t, "/get_height", rep.Interactions()[0].Path)
+}
+
+func TestRedaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "redact.json")
+	srv := newWalletServer(t)
+	outs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"outs":[{"key":"aa11","mask":"bb22"}],"status":"OK"}`))
+	}))
+
+	rec, err := New(Config{Path: path})
+	assert.NoError(t, err)
+	cl := wallet.New(wallet.Config{Address: srv.URL + "/json_rpc", Transport: rec})
+	for _, key := range []string{"a", "b"} {
+		_, err := cl.GetAttribute(&wallet.RequestGetAttribute{Key: key})
+		assert.NoError(t, err)
+	}
+	_, err = cl.Transfer(&wallet.RequestTransfer{GetTxKey: true})
+	assert.NoError(t, err)
+	_, err = cl.TransferSplit(&wallet.RequestTransferSplit{GetxKeys: true})
+	assert.NoError(t, err)
+	_, err = daemon.New(daemon.Config{Address: outs.URL, Transport: rec}).GetOuts(nil, false)
+	assert.NoError(t, err)
+	assert.NoError(t, rec.Save())
+	srv.Close()
+	outs.Close()
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "secret")
+
+	rep, err := New(Config{Path: path})
+	assert.NoError(t, err)
+	cl = wallet.New(wallet.Config{Address: srv.URL + "/json_rpc", Transport: rep})
+
+	// Attribute names are not redacted and still select their recording.
+	b, err := cl.GetAttribute(&wallet.RequestGetAttribute{Key: "b"})
+	assert.NoError(t, err)
+	assert.Equal(t, "value of b", b.Value)
+	a, err := cl.GetAttribute(&wallet.RequestGetAttribute{Key: "a"})
+	assert.NoError(t, err)
+	assert.Equal(t, "value of a", a.Value)
+
+	// Amounts above 2^53 come back exactly next to redacted fields.
+	transfer, err := cl.Transfer(&wallet.RequestTransfer{GetTxKey: true})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1152921504606846977), transfer.Amount)
+	assert.Equal(t, uint64(18446744073709551615), transfer.Fee)
+	assert.Equal(t, Redacted, transfer.TxKey)
+	split, err := cl.TransferSplit(&wallet.RequestTransferSplit{GetxKeys: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{9007199254740993}, split.AmountList)
+	assert.Equal(t, []string{Redacted, Redacted}, split.TxKeyList)
+
+	// Output keys are public and replay as recorded.
+	res, err := daemon.New(daemon.Config{Address: outs.URL, Transport: rep}).GetOuts(nil, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "aa11", res.Outs[0].Key)
+}
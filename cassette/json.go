@@ -0,0 +1,171 @@
+package cassette
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// decodeJSON decodes data keeping numbers as json.Number, so amounts
+// above 2^53 survive being marshalled again.
+func decodeJSON(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// redactJSON replaces the values of the redacted keys anywhere in data.
+// Data that is not JSON is returned unchanged.
+func redactJSON(data []byte, keys map[string]bool) []byte {
+	if len(keys) == 0 || !json.Valid(data) {
+		return data
+	}
+	v, err := decodeJSON(data)
+	if err != nil {
+		return data
+	}
+	if !redactValue(v, keys) {
+		return data
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return data
+	}
+	return out
+}
+
+func redactValue(v interface{}, keys map[string]bool) bool {
+	changed := false
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			if keys[k] {
+				if redacted, ok := redactString(val); ok {
+					v[k] = redacted
+					changed = true
+					continue
+				}
+				if list, ok := val.([]interface{}); ok {
+					for i, item := range list {
+						if redacted, ok := redactString(item); ok {
+							list[i] = redacted
+							changed = true
+						}
+					}
+				}
+				continue
+			}
+			if redactValue(val, keys) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, val := range v {
+			if redactValue(val, keys) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// redactString returns Redacted for a non-empty string that is not
+// redacted yet.
+func redactString(v interface{}) (interface{}, bool) {
+	if s, ok := v.(string); ok && s != "" && s != Redacted {
+		return Redacted, true
+	}
+	return v, false
+}
+
+// matchKey identifies a request independently of its JSON-RPC id and key
+// order.
+func matchKey(path string, body []byte) string {
+	v, err := decodeJSON(body)
+	if err != nil {
+		return path + " " + string(body)
+	}
+	if m, ok := v.(map[string]interface{}); ok {
+		delete(m, "id")
+	}
+	// encoding/json sorts map keys, giving a canonical form.
+	canonical, _ := json.Marshal(v)
+	return path + " " + string(canonical)
+}
+
+// requestHash is the SHA-256 of the match key of an unredacted request. It
+// tells apart requests that differ only in redacted values.
+func requestHash(path string, body []byte) string {
+	sum := sha256.Sum256([]byte(matchKey(path, body)))
+	return hex.EncodeToString(sum[:])
+}
+
+// stripID removes the JSON-RPC id of a single request, which is random.
+func stripID(body []byte) json.RawMessage {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(body, &m); err != nil {
+		return body
+	}
+	if _, ok := m["id"]; !ok {
+		return body
+	}
+	delete(m, "id")
+	out, err := json.Marshal(m)
+	if err != nil {
+		return body
+	}
+	return out
+}
+
+func rpcMethod(body []byte) string {
+	var req struct {
+		Method string `json:"method"`
+	}
+	json.Unmarshal(body, &req)
+	return req.Method
+}
+
+// rpcMethods returns the JSON-RPC methods of a single or batch request.
+func rpcMethods(body []byte) []string {
+	var batch []struct {
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(body, &batch); err != nil {
+		return []string{rpcMethod(body)}
+	}
+	methods := make([]string, len(batch))
+	for i, req := range batch {
+		methods[i] = req.Method
+	}
+	return methods
+}
+
+func requestID(body []byte) json.RawMessage {
+	var req struct {
+		ID json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil
+	}
+	return req.ID
+}
+
+// setID gives a replayed JSON-RPC response the id of the request it
+// answers.
+func setID(body []byte, id json.RawMessage) []byte {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(body, &m); err != nil {
+		return body
+	}
+	m["id"] = id
+	out, err := json.Marshal(m)
+	if err != nil {
+		return body
+	}
+	return out
+}
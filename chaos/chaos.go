@@ -0,0 +1,277 @@
+// Package chaos provides an http.RoundTripper that injects faults into
+// wallet and daemon RPC calls, to test how services cope with flaky nodes.
+// It works with both wallet.Config.Transport and daemon.Config.Transport:
+//
+//	tr := chaos.New(chaos.Config{})
+//	tr.Set("transfer", chaos.Fault{ErrorCode: int(wallet.ErrDaemonIsBusy), Probability: 0.5})
+//	tr.Set("get_info", chaos.Fault{Busy: true, Times: 2})
+//	tr.Set("/get_transactions", chaos.Fault{Latency: 2 * time.Second})
+//	tr.Set(chaos.Any, chaos.Fault{StatusCode: http.StatusInternalServerError, Probability: 0.1})
+//	cl := wallet.New(wallet.Config{Address: addr, Transport: tr})
+//
+// Faults are looked up by JSON-RPC method first, then by URL path (which
+// also covers JSON-RPC batches on "/json_rpc" and the daemon's other
+// endpoints), then Any, and the first one found decides. Faults that
+// replace the response (Drop, StatusCode, Busy and ErrorCode) do not
+// forward the request, so the node never sees it; Latency, DropResponse
+// and Truncate apply to a request that does reach the node.
+package chaos
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/boomhut/go-monero-rpc-client/epee"
+)
+
+// Any is the key of a fault that applies to every call without a more
+// specific fault.
+const Any = "*"
+
+// ErrDropped is returned for calls whose connection a fault dropped.
+var ErrDropped = errors.New("chaos: connection dropped")
+
+// Fault describes what happens to a call. Several effects can be combined,
+// they are applied in the order of the fields.
+type Fault struct {
+	// Probability that a call is affected, between 0 and 1. Zero means
+	// every call.
+	Probability float64
+	// Times limits how many calls are affected, zero means no limit.
+	Times int
+
+	// Latency delays the call, or fails it earlier if its context is done.
+	Latency time.Duration
+	// Drop fails the call with ErrDropped before it is sent.
+	Drop bool
+	// DropResponse sends the call but fails it with ErrDropped, as if the
+	// connection broke before the response arrived. The node still acts
+	// on the request.
+	DropResponse bool
+	// StatusCode answers with this HTTP status, e.g. 500 or 401.
+	StatusCode int
+	// Busy answers with status "BUSY", like monerod while syncing. The
+	// daemon's .bin endpoints get it epee-encoded.
+	Busy bool
+	// ErrorCode answers JSON-RPC calls with an error object with this code,
+	// e.g. int(wallet.ErrNotEnoughMoney) or int(daemon.ErrCoreBusy). Calls
+	// to the daemon's other endpoints are not affected.
+	ErrorCode int
+	// ErrorMessage is the message sent with ErrorCode.
+	ErrorMessage string
+	// Truncate cuts the response body in half.
+	Truncate bool
+}
+
+// Config configures a Transport.
+type Config struct {
+	// Transport used to reach the node. Defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+	// Faults by JSON-RPC method, URL path or Any. See Set.
+	Faults map[string]Fault
+	// Seed of the random source deciding which calls a Probability
+	// affects. The same seed affects the same calls.
+	Seed int64
+}
+
+// Transport injects faults into the calls it forwards. It is safe for
+// concurrent use.
+type Transport struct {
+	next http.RoundTripper
+
+	mu     sync.Mutex
+	rand   *rand.Rand
+	faults map[string]*state
+	counts map[string]int
+}
+
+type state struct {
+	Fault
+	left int
+}
+
+// New returns a Transport for cfg.
+func New(cfg Config) *Transport {
+	t := &Transport{
+		next:   cfg.Transport,
+		rand:   rand.New(rand.NewSource(cfg.Seed)),
+		faults: make(map[string]*state),
+		counts: make(map[string]int),
+	}
+	if t.next == nil {
+		t.next = http.DefaultTransport
+	}
+	for key, f := range cfg.Faults {
+		t.Set(key, f)
+	}
+	return t
+}
+
+// Set makes f the fault for key: a JSON-RPC method such as "transfer", a
+// URL path such as "/get_transactions" or "/json_rpc", or Any. It
+// replaces any fault set for key before and resets its Times.
+func (t *Transport) Set(key string, f Fault) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.faults[key] = &state{Fault: f, left: f.Times}
+}
+
+// Remove removes the fault for key.
+func (t *Transport) Remove(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.faults, key)
+}
+
+// Clear removes all faults and resets the counts.
+func (t *Transport) Clear() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.faults = make(map[string]*state)
+	t.counts = make(map[string]int)
+}
+
+// Count returns how many calls the fault for key affected.
+func (t *Transport) Count(key string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.counts[key]
+}
+
+// pick returns the fault affecting a call, if any.
+func (t *Transport) pick(method, path string) (Fault, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, key := range []string{method, path, Any} {
+		s, ok := t.faults[key]
+		if key == "" || !ok {
+			continue
+		}
+		if s.Times > 0 && s.left == 0 {
+			return Fault{}, false
+		}
+		if s.Probability > 0 && t.rand.Float64() >= s.Probability {
+			return Fault{}, false
+		}
+		if s.Times > 0 {
+			s.left--
+		}
+		t.counts[key]++
+		return s.Fault, true
+	}
+	return Fault{}, false
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	calls, batch := parseCalls(body)
+	method := ""
+	if len(calls) > 0 && !batch {
+		method = calls[0].Method
+	}
+	f, ok := t.pick(method, req.URL.Path)
+	if !ok {
+		return t.forward(req, body)
+	}
+
+	if f.Latency > 0 {
+		timer := time.NewTimer(f.Latency)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+	if f.Drop {
+		return nil, ErrDropped
+	}
+
+	var resp *http.Response
+	switch {
+	case f.StatusCode != 0:
+		resp = response(req, f.StatusCode, []byte(http.StatusText(f.StatusCode)+"\n"))
+		resp.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	case f.Busy && strings.HasSuffix(req.URL.Path, ".bin"):
+		data, err := epee.Marshal(binaryStatus{Status: "BUSY"})
+		if err != nil {
+			return nil, err
+		}
+		resp = response(req, http.StatusOK, data)
+		resp.Header.Set("Content-Type", "application/octet-stream")
+	case f.Busy:
+		resp = response(req, http.StatusOK, replies(calls, batch, json.RawMessage(`{"status":"BUSY"}`), nil))
+	case f.ErrorCode != 0 && len(calls) > 0:
+		msg := f.ErrorMessage
+		if msg == "" {
+			msg = "chaos: injected error " + strconv.Itoa(f.ErrorCode)
+		}
+		resp = response(req, http.StatusOK, replies(calls, batch, nil, &rpcError{Code: f.ErrorCode, Message: msg}))
+	default:
+		var err error
+		if resp, err = t.forward(req, body); err != nil {
+			return nil, err
+		}
+		if f.DropResponse {
+			resp.Body.Close()
+			return nil, ErrDropped
+		}
+	}
+
+	if f.Truncate {
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		data = data[:len(data)/2]
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+		resp.ContentLength = int64(len(data))
+		resp.Header.Del("Content-Length")
+	}
+	return resp, nil
+}
+
+// binaryStatus is the response of a .bin endpoint that only sets a status.
+type binaryStatus struct {
+	Status string `epee:"status"`
+}
+
+func (t *Transport) forward(req *http.Request, body []byte) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	if req.Body != nil {
+		out.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return t.next.RoundTrip(out)
+}
+
+func response(req *http.Request, code int, body []byte) *http.Response {
+	return &http.Response{
+		Status:        strconv.Itoa(code) + " " + http.StatusText(code),
+		StatusCode:    code,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
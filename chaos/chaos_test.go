@@ -0,0 +1,124 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/boomhut/go-monero-rpc-client/daemon"
+	"github.com/boomhut/go-monero-rpc-client/daemon/daemontest"
+	"github.com/boomhut/go-monero-rpc-client/wallet"
+	"github.com/boomhut/go-monero-rpc-client/wallet/wallettest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDaemon(t *testing.T, tr *Transport) daemon.Client {
+	srv := daemontest.NewServer(nil)
+	t.Cleanup(srv.Close)
+	return daemon.New(daemon.Config{Address: srv.Address(), Transport: tr})
+}
+
+func TestWalletErrorCode(t *testing.T) {
+	w := wallettest.NewWallet()
+	w.Receive(wallettest.Payment{Amount: 5e12, Height: 1})
+	srv := wallettest.NewServer(w)
+	defer srv.Close()
+	tr := New(Config{})
+	tr.Set("get_balance", Fault{ErrorCode: int(wallet.ErrDaemonIsBusy), Times: 1})
+	cl := wallet.New(wallet.Config{Address: srv.Address(), Transport: tr})
+
+	_, err := cl.GetBalance(&wallet.RequestGetBalance{})
+	assert.True(t, errors.Is(err, wallet.ErrDaemonIsBusy), "got %v", err)
+	res, err := cl.GetBalance(&wallet.RequestGetBalance{})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5e12), res.Balance)
+	assert.Equal(t, 1, tr.Count("get_balance"))
+
+	// A dropped response loses the answer, not the transfer.
+	tr.Set("transfer", Fault{DropResponse: true})
+	addr, err := cl.GetAddress(&wallet.RequestGetAddress{})
+	assert.NoError(t, err)
+	_, err = cl.Transfer(&wallet.RequestTransfer{Destinations: []*wallet.Destination{{Address: addr.Address, Amount: 1e12}}})
+	assert.True(t, errors.Is(err, wallet.ErrTransport), "got %v", err)
+	transfers, err := cl.GetTransfers(&wallet.RequestGetTransfers{Pending: true})
+	assert.NoError(t, err)
+	assert.Len(t, transfers.Pending, 1)
+}
+
+func TestDaemonFaults(t *testing.T) {
+	tr := New(Config{})
+	cl := newDaemon(t, tr)
+
+	tr.Set("get_info", Fault{Busy: true})
+	_, err := cl.GetInfo()
+	assert.True(t, errors.Is(err, daemon.ErrBusy), "got %v", err)
+
+	tr.Set("/get_height", Fault{Busy: true})
+	_, err = cl.GetHeight()
+	assert.True(t, errors.Is(err, daemon.ErrBusy), "got %v", err)
+
+	tr.Set("/get_blocks.bin", Fault{Busy: true})
+	_, err = cl.GetBlocksBin(&daemon.RequestGetBlocksBin{})
+	assert.True(t, errors.Is(err, daemon.ErrBusy), "got %v", err)
+
+	tr.Set("get_block_count", Fault{StatusCode: http.StatusInternalServerError})
+	_, err = cl.GetBlockCount()
+	assert.True(t, errors.Is(err, daemon.ErrHTTPStatus), "got %v", err)
+
+	tr.Set("get_block_count", Fault{StatusCode: http.StatusUnauthorized})
+	_, err = cl.GetBlockCount()
+	var herr *daemon.HTTPStatusError
+	assert.True(t, errors.As(err, &herr), "got %v", err)
+
+	tr.Set("get_last_block_header", Fault{Drop: true})
+	_, err = cl.GetLastBlockHeader()
+	assert.True(t, errors.Is(err, daemon.ErrTransport), "got %v", err)
+
+	tr.Set("get_version", Fault{Truncate: true})
+	_, err = cl.GetVersion()
+	assert.Error(t, err)
+
+	tr.Set("get_fee_estimate", Fault{ErrorCode: int(daemon.ErrCoreBusy)})
+	_, err = cl.GetFeeEstimate(10)
+	assert.True(t, errors.Is(err, daemon.ErrBusy), "got %v", err)
+
+	tr.Set(Any, Fault{Latency: time.Second})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = cl.GetTransactionPoolHashesContext(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "got %v", err)
+
+	tr.Clear()
+	_, err = cl.GetInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, tr.Count("get_info"))
+}
+
+func TestBatch(t *testing.T) {
+	tr := New(Config{Faults: map[string]Fault{"/json_rpc": {Busy: true}}})
+	cl := newDaemon(t, tr)
+	calls := []daemon.BatchCall{
+		{Method: "get_block_count", Result: &daemon.ResponseGetBlockCount{}},
+		{Method: "get_info", Result: &daemon.ResponseGetInfo{}},
+	}
+	assert.NoError(t, cl.CallBatch(context.Background(), calls))
+	for _, c := range calls {
+		assert.True(t, errors.Is(c.Error, daemon.ErrBusy), "got %v", c.Error)
+	}
+}
+
+func TestProbability(t *testing.T) {
+	count := func(seed int64) int {
+		tr := New(Config{Seed: seed, Faults: map[string]Fault{Any: {Drop: true, Probability: 0.3}}})
+		cl := newDaemon(t, tr)
+		for i := 0; i < 100; i++ {
+			cl.GetBlockCount()
+		}
+		return tr.Count(Any)
+	}
+	n := count(7)
+	assert.Equal(t, n, count(7))
+	assert.InDelta(t, 30, n, 15)
+}
@@ -0,0 +1,58 @@
+package chaos
+
+import (
+	"encoding/json"
+)
+
+type call struct {
+	Method string          `json:"method"`
+	ID     json.RawMessage `json:"id"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type reply struct {
+	ID      json.RawMessage `json:"id"`
+	Version string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// parseCalls returns the JSON-RPC calls in body, and whether body is a
+// batch. Bodies of the daemon's other endpoints have no calls.
+func parseCalls(body []byte) ([]call, bool) {
+	var batch []call
+	if err := json.Unmarshal(body, &batch); err == nil {
+		return batch, true
+	}
+	var c call
+	if err := json.Unmarshal(body, &c); err != nil || c.Method == "" {
+		return nil, false
+	}
+	return []call{c}, false
+}
+
+// replies answers every call with result or err. Without calls, for the
+// daemon's other endpoints, the body is result itself.
+func replies(calls []call, batch bool, result json.RawMessage, err *rpcError) []byte {
+	if len(calls) == 0 {
+		return result
+	}
+	out := make([]reply, len(calls))
+	for i, c := range calls {
+		out[i] = reply{ID: c.ID, Version: "2.0", Result: result, Error: err}
+		if out[i].ID == nil {
+			out[i].ID = json.RawMessage("null")
+		}
+	}
+	var data []byte
+	if batch {
+		data, _ = json.Marshal(out)
+	} else {
+		data, _ = json.Marshal(out[0])
+	}
+	return data
+}
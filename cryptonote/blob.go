@@ -0,0 +1,586 @@
+package cryptonote
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Input and output tags of the binary serialization.
+const (
+	tagTxInGen        = 0xff
+	tagTxInKey        = 0x02
+	tagTxOutKey       = 0x02
+	tagTxOutTaggedKey = 0x03
+)
+
+// Sizes of the fixed-length fields of the binary serialization.
+const (
+	ringSigSize  = 64
+	boroSigSize  = 64*32*2 + 32
+	rangeCiSize  = 64 * 32
+	ecdhLongSize = 32
+)
+
+// decoder reads the binary serialization. The first error sticks and
+// later reads return zero values.
+type decoder struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (d *decoder) fail(format string, args ...interface{}) {
+	if d.err == nil {
+		d.err = fmt.Errorf("invalid blob at byte %d: %s", d.pos, fmt.Sprintf(format, args...))
+	}
+}
+
+func (d *decoder) varint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.data[d.pos:])
+	if n <= 0 {
+		d.fail("bad varint")
+		return 0
+	}
+	d.pos += n
+	return v
+}
+
+// count reads a varint length of elements of at least size bytes each.
+func (d *decoder) count(size int) int {
+	n := d.varint()
+	if d.err == nil && n > uint64((len(d.data)-d.pos)/size) {
+		d.fail("length %d exceeds the blob", n)
+		return 0
+	}
+	return int(n)
+}
+
+func (d *decoder) uint32() uint32 {
+	b := d.bytes(4)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(b)
+}
+
+func (d *decoder) byte() byte {
+	b := d.bytes(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (d *decoder) bytes(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n > len(d.data)-d.pos {
+		d.fail("unexpected end")
+		return nil
+	}
+	b := d.data[d.pos : d.pos+n : d.pos+n]
+	d.pos += n
+	return b
+}
+
+func (d *decoder) key() Key {
+	var k Key
+	copy(k[:], d.bytes(len(k)))
+	return k
+}
+
+func (d *decoder) keys(n int) []Key {
+	if d.err == nil && n > (len(d.data)-d.pos)/32 {
+		d.fail("%d keys exceed the blob", n)
+	}
+	if d.err != nil {
+		return nil
+	}
+	keys := make([]Key, n)
+	for i := range keys {
+		keys[i] = d.key()
+	}
+	return keys
+}
+
+// keyVector reads a varint length followed by that many keys.
+func (d *decoder) keyVector() []Key {
+	return d.keys(d.count(32))
+}
+
+// txLayout records where the prefix and the RingCT base of a
+// serialized transaction end.
+type txLayout struct {
+	prefixEnd int
+	baseEnd   int
+}
+
+// ParseTransaction decodes a transaction from its binary serialization,
+// as in TxInfo.AsHex or a wallet's tx_blob.
+func ParseTransaction(blob []byte) (*Transaction, error) {
+	tx, _, err := decodeTransaction(blob, false)
+	return tx, err
+}
+
+// ParsePrunedTransaction decodes a pruned transaction blob, as in
+// TxInfo.PrunedAsHex. The result has no RctPrunable and, for version 1,
+// no Signatures.
+func ParsePrunedTransaction(blob []byte) (*Transaction, error) {
+	tx, _, err := decodeTransaction(blob, true)
+	return tx, err
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler for full blobs.
+func (t *Transaction) UnmarshalBinary(blob []byte) error {
+	tx, err := ParseTransaction(blob)
+	if err != nil {
+		return err
+	}
+	*t = *tx
+	return nil
+}
+
+func decodeTransaction(blob []byte, pruned bool) (*Transaction, txLayout, error) {
+	d := &decoder{data: blob}
+	tx := decodePrefix(d)
+	var layout txLayout
+	layout.prefixEnd = d.pos
+	if d.err == nil {
+		if tx.Version == 1 {
+			if !pruned {
+				decodeRingSignatures(d, tx)
+			}
+			layout.baseEnd = d.pos
+		} else {
+			tx.RctSignatures = decodeRctBase(d, len(tx.Inputs), len(tx.Outputs))
+			layout.baseEnd = d.pos
+			if !pruned && d.err == nil && tx.RctSignatures.Type != RCTTypeNull {
+				tx.RctPrunable = decodeRctPrunable(d, tx.RctSignatures.Type, tx.Inputs, len(tx.Outputs))
+			}
+		}
+	}
+	if d.err == nil && d.pos != len(blob) {
+		d.fail("%d trailing bytes", len(blob)-d.pos)
+	}
+	if d.err != nil {
+		return nil, layout, d.err
+	}
+	return tx, layout, nil
+}
+
+func decodePrefix(d *decoder) *Transaction {
+	tx := &Transaction{}
+	tx.Version = d.varint()
+	if d.err == nil && (tx.Version == 0 || tx.Version > 2) {
+		d.fail("unsupported transaction version %d", tx.Version)
+	}
+	tx.UnlockTime = d.varint()
+	tx.Inputs = make([]TxIn, d.count(1))
+	for i := range tx.Inputs {
+		switch tag := d.byte(); tag {
+		case tagTxInGen:
+			tx.Inputs[i].Gen = &TxInGen{Height: d.varint()}
+		case tagTxInKey:
+			in := &TxInKey{Amount: d.varint()}
+			in.KeyOffsets = make([]uint64, d.count(1))
+			for j := range in.KeyOffsets {
+				in.KeyOffsets[j] = d.varint()
+			}
+			in.KeyImage = d.key()
+			tx.Inputs[i].Key = in
+		default:
+			d.fail("unsupported input type %#x", tag)
+		}
+	}
+	tx.Outputs = make([]TxOut, d.count(1))
+	for i := range tx.Outputs {
+		out := &tx.Outputs[i]
+		out.Amount = d.varint()
+		switch tag := d.byte(); tag {
+		case tagTxOutKey:
+			k := d.key()
+			out.Target.Key = &k
+		case tagTxOutTaggedKey:
+			out.Target.TaggedKey = &TaggedKey{Key: d.key(), ViewTag: ViewTag(d.byte())}
+		default:
+			d.fail("unsupported output type %#x", tag)
+		}
+	}
+	tx.Extra = Extra(d.bytes(d.count(1)))
+	return tx
+}
+
+// ringSize returns the number of ring members of an input, 0 for a
+// coinbase input.
+func ringSize(in TxIn) int {
+	if in.Key == nil {
+		return 0
+	}
+	return len(in.Key.KeyOffsets)
+}
+
+func decodeRingSignatures(d *decoder, tx *Transaction) {
+	total := 0
+	for _, in := range tx.Inputs {
+		total += ringSize(in)
+	}
+	if total == 0 {
+		return
+	}
+	tx.Signatures = make([]HexBytes, len(tx.Inputs))
+	for i, in := range tx.Inputs {
+		tx.Signatures[i] = HexBytes(d.bytes(ringSize(in) * ringSigSize))
+	}
+}
+
+// compactEcdh reports whether rctType stores 8-byte amounts without masks.
+func compactEcdh(rctType uint8) bool {
+	return rctType >= RCTTypeBulletproof2
+}
+
+func decodeRctBase(d *decoder, inputs, outputs int) *RctSignatures {
+	rct := &RctSignatures{Type: d.byte()}
+	if d.err != nil || rct.Type == RCTTypeNull {
+		return rct
+	}
+	if rct.Type > RCTTypeBulletproofPlus {
+		d.fail("unsupported RingCT type %d", rct.Type)
+		return rct
+	}
+	rct.TxnFee = d.varint()
+	if rct.Type == RCTTypeSimple {
+		rct.PseudoOuts = d.keys(inputs)
+	}
+	rct.EcdhInfo = make([]EcdhTuple, outputs)
+	for i := range rct.EcdhInfo {
+		if compactEcdh(rct.Type) {
+			rct.EcdhInfo[i].Amount = HexBytes(d.bytes(8))
+		} else {
+			rct.EcdhInfo[i].Mask = HexBytes(d.bytes(ecdhLongSize))
+			rct.EcdhInfo[i].Amount = HexBytes(d.bytes(ecdhLongSize))
+		}
+	}
+	rct.OutPk = d.keys(outputs)
+	return rct
+}
+
+// mixin returns the ring size minus one, which RingCT signatures take
+// from the first input.
+func mixin(inputs []TxIn) (int, error) {
+	if len(inputs) == 0 || inputs[0].Key == nil || len(inputs[0].Key.KeyOffsets) == 0 {
+		return 0, errors.New("RingCT signatures without a ring")
+	}
+	return len(inputs[0].Key.KeyOffsets) - 1, nil
+}
+
+func decodeRctPrunable(d *decoder, rctType uint8, inputs []TxIn, outputs int) *RctPrunable {
+	p := &RctPrunable{}
+	m, err := mixin(inputs)
+	if err != nil {
+		d.fail("%v", err)
+		return p
+	}
+	switch rctType {
+	case RCTTypeBulletproofPlus:
+		p.NBP = d.varint()
+		if d.err == nil && p.NBP > uint64(len(d.data)-d.pos) {
+			d.fail("%d bulletproofs exceed the blob", p.NBP)
+		}
+		for i := uint64(0); i < p.NBP && d.err == nil; i++ {
+			p.BulletproofsPlus = append(p.BulletproofsPlus, BulletproofPlus{
+				A: d.key(), A1: d.key(), B: d.key(), R1: d.key(), S1: d.key(), D1: d.key(),
+				L: d.keyVector(), R: d.keyVector(),
+			})
+		}
+	case RCTTypeBulletproof, RCTTypeBulletproof2, RCTTypeCLSAG:
+		if rctType == RCTTypeBulletproof {
+			p.NBP = uint64(d.uint32())
+		} else {
+			p.NBP = d.varint()
+		}
+		if d.err == nil && p.NBP > uint64(len(d.data)-d.pos) {
+			d.fail("%d bulletproofs exceed the blob", p.NBP)
+		}
+		for i := uint64(0); i < p.NBP && d.err == nil; i++ {
+			p.Bulletproofs = append(p.Bulletproofs, Bulletproof{
+				A: d.key(), S: d.key(), T1: d.key(), T2: d.key(), Taux: d.key(), Mu: d.key(),
+				L: d.keyVector(), R: d.keyVector(),
+				LA: d.key(), LB: d.key(), T: d.key(),
+			})
+		}
+	default:
+		p.RangeSigs = make([]RangeSig, outputs)
+		for i := range p.RangeSigs {
+			p.RangeSigs[i] = RangeSig{Asig: HexBytes(d.bytes(boroSigSize)), Ci: HexBytes(d.bytes(rangeCiSize))}
+		}
+	}
+
+	if rctType == RCTTypeCLSAG || rctType == RCTTypeBulletproofPlus {
+		p.CLSAGs = make([]Clsag, len(inputs))
+		for i := range p.CLSAGs {
+			p.CLSAGs[i] = Clsag{S: d.keys(m + 1), C1: d.key(), D: d.key()}
+		}
+	} else {
+		mgs, cols := len(inputs), 2
+		if rctType == RCTTypeFull {
+			mgs, cols = 1, len(inputs)+1
+		}
+		p.MGs = make([]MgSig, mgs)
+		for i := range p.MGs {
+			p.MGs[i].SS = make([][]Key, m+1)
+			for j := range p.MGs[i].SS {
+				p.MGs[i].SS[j] = d.keys(cols)
+			}
+			p.MGs[i].CC = d.key()
+		}
+	}
+	if rctType >= RCTTypeBulletproof {
+		p.PseudoOuts = d.keys(len(inputs))
+	}
+	return p
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. It serializes t as
+// monerod does; pruned transactions cannot be serialized in full, use
+// MarshalPrunedBinary.
+func (t *Transaction) MarshalBinary() ([]byte, error) {
+	blob, _, err := t.encode(false)
+	return blob, err
+}
+
+// MarshalPrunedBinary serializes t without its prunable part, as in
+// TxInfo.PrunedAsHex.
+func (t *Transaction) MarshalPrunedBinary() ([]byte, error) {
+	blob, _, err := t.encode(true)
+	return blob, err
+}
+
+func (t *Transaction) encode(pruned bool) ([]byte, txLayout, error) {
+	var layout txLayout
+	buf, err := t.encodePrefix(nil)
+	if err != nil {
+		return nil, layout, err
+	}
+	layout.prefixEnd = len(buf)
+	if t.Version == 1 {
+		if !pruned {
+			if buf, err = t.encodeRingSignatures(buf); err != nil {
+				return nil, layout, err
+			}
+		}
+		layout.baseEnd = len(buf)
+		return buf, layout, nil
+	}
+	if t.RctSignatures == nil {
+		return nil, layout, errors.New("version 2 transaction without rct_signatures")
+	}
+	if buf, err = t.encodeRctBase(buf); err != nil {
+		return nil, layout, err
+	}
+	layout.baseEnd = len(buf)
+	if pruned || t.RctSignatures.Type == RCTTypeNull {
+		return buf, layout, nil
+	}
+	if t.RctPrunable == nil {
+		return nil, layout, errors.New("pruned transaction has no rctsig_prunable to serialize")
+	}
+	if buf, err = t.encodeRctPrunable(buf); err != nil {
+		return nil, layout, err
+	}
+	return buf, layout, nil
+}
+
+func appendKeys(buf []byte, keys []Key) []byte {
+	for _, k := range keys {
+		buf = append(buf, k[:]...)
+	}
+	return buf
+}
+
+func appendKeyVector(buf []byte, keys []Key) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(keys)))
+	return appendKeys(buf, keys)
+}
+
+func (t *Transaction) encodePrefix(buf []byte) ([]byte, error) {
+	buf = binary.AppendUvarint(buf, t.Version)
+	buf = binary.AppendUvarint(buf, t.UnlockTime)
+	buf = binary.AppendUvarint(buf, uint64(len(t.Inputs)))
+	for i, in := range t.Inputs {
+		switch {
+		case in.Gen != nil:
+			buf = append(buf, tagTxInGen)
+			buf = binary.AppendUvarint(buf, in.Gen.Height)
+		case in.Key != nil:
+			buf = append(buf, tagTxInKey)
+			buf = binary.AppendUvarint(buf, in.Key.Amount)
+			buf = binary.AppendUvarint(buf, uint64(len(in.Key.KeyOffsets)))
+			for _, off := range in.Key.KeyOffsets {
+				buf = binary.AppendUvarint(buf, off)
+			}
+			buf = append(buf, in.Key.KeyImage[:]...)
+		default:
+			return nil, fmt.Errorf("input %d has no gen or key", i)
+		}
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(t.Outputs)))
+	for i, out := range t.Outputs {
+		buf = binary.AppendUvarint(buf, out.Amount)
+		switch {
+		case out.Target.TaggedKey != nil:
+			buf = append(buf, tagTxOutTaggedKey)
+			buf = append(buf, out.Target.TaggedKey.Key[:]...)
+			buf = append(buf, byte(out.Target.TaggedKey.ViewTag))
+		case out.Target.Key != nil:
+			buf = append(buf, tagTxOutKey)
+			buf = append(buf, out.Target.Key[:]...)
+		default:
+			return nil, fmt.Errorf("output %d has no key or tagged_key", i)
+		}
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(t.Extra)))
+	return append(buf, t.Extra...), nil
+}
+
+func (t *Transaction) encodeRingSignatures(buf []byte) ([]byte, error) {
+	if len(t.Signatures) == 0 {
+		for _, in := range t.Inputs {
+			if ringSize(in) != 0 {
+				return nil, errors.New("version 1 transaction without signatures")
+			}
+		}
+		return buf, nil
+	}
+	if len(t.Signatures) != len(t.Inputs) {
+		return nil, fmt.Errorf("%d signatures for %d inputs", len(t.Signatures), len(t.Inputs))
+	}
+	for i, in := range t.Inputs {
+		if len(t.Signatures[i]) != ringSize(in)*ringSigSize {
+			return nil, fmt.Errorf("signature %d has %d bytes, want %d", i, len(t.Signatures[i]), ringSize(in)*ringSigSize)
+		}
+		buf = append(buf, t.Signatures[i]...)
+	}
+	return buf, nil
+}
+
+func (t *Transaction) encodeRctBase(buf []byte) ([]byte, error) {
+	rct := t.RctSignatures
+	buf = append(buf, rct.Type)
+	if rct.Type == RCTTypeNull {
+		return buf, nil
+	}
+	if rct.Type > RCTTypeBulletproofPlus {
+		return nil, fmt.Errorf("unsupported RingCT type %d", rct.Type)
+	}
+	buf = binary.AppendUvarint(buf, rct.TxnFee)
+	if rct.Type == RCTTypeSimple {
+		if len(rct.PseudoOuts) != len(t.Inputs) {
+			return nil, fmt.Errorf("%d pseudoOuts for %d inputs", len(rct.PseudoOuts), len(t.Inputs))
+		}
+		buf = appendKeys(buf, rct.PseudoOuts)
+	}
+	if len(rct.EcdhInfo) != len(t.Outputs) || len(rct.OutPk) != len(t.Outputs) {
+		return nil, fmt.Errorf("%d ecdhInfo and %d outPk for %d outputs", len(rct.EcdhInfo), len(rct.OutPk), len(t.Outputs))
+	}
+	for i, e := range rct.EcdhInfo {
+		if compactEcdh(rct.Type) {
+			if len(e.Amount) != 8 {
+				return nil, fmt.Errorf("ecdhInfo %d: amount has %d bytes, want 8", i, len(e.Amount))
+			}
+			buf = append(buf, e.Amount...)
+			continue
+		}
+		if len(e.Mask) != ecdhLongSize || len(e.Amount) != ecdhLongSize {
+			return nil, fmt.Errorf("ecdhInfo %d: want a 32-byte mask and amount", i)
+		}
+		buf = append(buf, e.Mask...)
+		buf = append(buf, e.Amount...)
+	}
+	return appendKeys(buf, rct.OutPk), nil
+}
+
+func (t *Transaction) encodeRctPrunable(buf []byte) ([]byte, error) {
+	rctType := t.RctSignatures.Type
+	p := t.RctPrunable
+	m, err := mixin(t.Inputs)
+	if err != nil {
+		return nil, err
+	}
+	switch rctType {
+	case RCTTypeBulletproofPlus:
+		buf = binary.AppendUvarint(buf, uint64(len(p.BulletproofsPlus)))
+		for _, bp := range p.BulletproofsPlus {
+			buf = appendKeys(buf, []Key{bp.A, bp.A1, bp.B, bp.R1, bp.S1, bp.D1})
+			buf = appendKeyVector(buf, bp.L)
+			buf = appendKeyVector(buf, bp.R)
+		}
+	case RCTTypeBulletproof, RCTTypeBulletproof2, RCTTypeCLSAG:
+		if rctType == RCTTypeBulletproof {
+			buf = binary.LittleEndian.AppendUint32(buf, uint32(len(p.Bulletproofs)))
+		} else {
+			buf = binary.AppendUvarint(buf, uint64(len(p.Bulletproofs)))
+		}
+		for _, bp := range p.Bulletproofs {
+			buf = appendKeys(buf, []Key{bp.A, bp.S, bp.T1, bp.T2, bp.Taux, bp.Mu})
+			buf = appendKeyVector(buf, bp.L)
+			buf = appendKeyVector(buf, bp.R)
+			buf = appendKeys(buf, []Key{bp.LA, bp.LB, bp.T})
+		}
+	default:
+		if len(p.RangeSigs) != len(t.Outputs) {
+			return nil, fmt.Errorf("%d rangeSigs for %d outputs", len(p.RangeSigs), len(t.Outputs))
+		}
+		for i, rs := range p.RangeSigs {
+			if len(rs.Asig) != boroSigSize || len(rs.Ci) != rangeCiSize {
+				return nil, fmt.Errorf("rangeSig %d has the wrong size", i)
+			}
+			buf = append(buf, rs.Asig...)
+			buf = append(buf, rs.Ci...)
+		}
+	}
+
+	if rctType == RCTTypeCLSAG || rctType == RCTTypeBulletproofPlus {
+		if len(p.CLSAGs) != len(t.Inputs) {
+			return nil, fmt.Errorf("%d CLSAGs for %d inputs", len(p.CLSAGs), len(t.Inputs))
+		}
+		for i, c := range p.CLSAGs {
+			if len(c.S) != m+1 {
+				return nil, fmt.Errorf("CLSAG %d has %d scalars for a ring of %d", i, len(c.S), m+1)
+			}
+			buf = appendKeys(buf, c.S)
+			buf = appendKeys(buf, []Key{c.C1, c.D})
+		}
+	} else {
+		mgs, cols := len(t.Inputs), 2
+		if rctType == RCTTypeFull {
+			mgs, cols = 1, len(t.Inputs)+1
+		}
+		if len(p.MGs) != mgs {
+			return nil, fmt.Errorf("%d MGs, want %d", len(p.MGs), mgs)
+		}
+		for i, mg := range p.MGs {
+			if len(mg.SS) != m+1 {
+				return nil, fmt.Errorf("MG %d has %d rows for a ring of %d", i, len(mg.SS), m+1)
+			}
+			for _, row := range mg.SS {
+				if len(row) != cols {
+					return nil, fmt.Errorf("MG %d has a row of %d keys, want %d", i, len(row), cols)
+				}
+				buf = appendKeys(buf, row)
+			}
+			buf = append(buf, mg.CC[:]...)
+		}
+	}
+	if rctType >= RCTTypeBulletproof {
+		if len(p.PseudoOuts) != len(t.Inputs) {
+			return nil, fmt.Errorf("%d pseudoOuts for %d inputs", len(p.PseudoOuts), len(t.Inputs))
+		}
+		buf = appendKeys(buf, p.PseudoOuts)
+	}
+	return buf, nil
+}
@@ -0,0 +1,259 @@
+package cryptonote
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// genesisTx is the miner transaction of the mainnet genesis block,
+// GENESIS_TX in cryptonote_config.h.
+const genesisTx = "013c01ff0001ffffffffffff03029b2e4c0281c0b02e7c53291a94d1d0cbff8883f8024f5142ee494ffbbd08807121017767aafcde9be00dcfd098715ebcf7f410daebc582fda69d24a28e9d0bc890d1"
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	assert.NoError(t, err)
+	return b
+}
+
+func TestParseGenesisTransaction(t *testing.T) {
+	blob := mustDecodeHex(t, genesisTx)
+	tx, err := ParseTransaction(blob)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), tx.Version)
+	assert.Equal(t, uint64(60), tx.UnlockTime)
+	assert.True(t, tx.IsCoinbase())
+	assert.Equal(t, uint64(0), tx.Inputs[0].Gen.Height)
+	assert.Equal(t, uint64(17592186044415), tx.Outputs[0].Amount)
+	assert.Equal(t, "9b2e4c0281c0b02e7c53291a94d1d0cbff8883f8024f5142ee494ffbbd088071", tx.Outputs[0].Target.PublicKey().String())
+	assert.Len(t, tx.Extra, 33)
+	assert.Nil(t, tx.Signatures)
+
+	again, err := tx.MarshalBinary()
+	assert.NoError(t, err)
+	assert.Equal(t, blob, again)
+	id, err := TransactionHash(blob)
+	assert.NoError(t, err)
+	assert.Equal(t, Keccak256(blob), id)
+	fromTx, err := tx.Hash()
+	assert.NoError(t, err)
+	assert.Equal(t, id, fromTx)
+}
+
+// The JSON samples of transaction_test.go serialize to blobs that decode
+// back to the same transactions.
+func TestTransactionBinaryRoundTrip(t *testing.T) {
+	for name, sample := range map[string]string{"clsag": clsagTx, "v1": v1Tx, "coinbase": coinbaseTx} {
+		var tx Transaction
+		assert.NoError(t, json.Unmarshal([]byte(sample), &tx), name)
+		blob, err := tx.MarshalBinary()
+		assert.NoError(t, err, name)
+		parsed, err := ParseTransaction(blob)
+		assert.NoError(t, err, name)
+		out, err := json.Marshal(parsed)
+		assert.NoError(t, err, name)
+		assert.JSONEq(t, sample, string(out), name)
+	}
+}
+
+func TestOlderRingCTTypes(t *testing.T) {
+	var base Transaction
+	assert.NoError(t, json.Unmarshal([]byte(clsagTx), &base))
+	key := func(b byte) Key { return Key{b} }
+	long := func(b byte) HexBytes { return bytes.Repeat([]byte{b}, 32) }
+	ring := len(base.Inputs[0].Key.KeyOffsets)
+	mg := func(cols int) MgSig {
+		ss := make([][]Key, ring)
+		for i := range ss {
+			ss[i] = make([]Key, cols)
+			ss[i][0] = key(byte(i))
+		}
+		return MgSig{SS: ss, CC: key(9)}
+	}
+	bp := Bulletproof{A: key(1), S: key(2), L: []Key{key(3)}, R: []Key{key(4)}, T: key(5)}
+
+	for rctType := uint8(RCTTypeFull); rctType <= RCTTypeCLSAG; rctType++ {
+		tx := base
+		rct := *base.RctSignatures
+		rct.Type = rctType
+		p := &RctPrunable{}
+		if compactEcdh(rctType) {
+			rct.EcdhInfo = []EcdhTuple{{Amount: HexBytes{1, 2, 3, 4, 5, 6, 7, 8}}, {Amount: HexBytes{8, 7, 6, 5, 4, 3, 2, 1}}}
+		} else {
+			rct.EcdhInfo = []EcdhTuple{{Mask: long(1), Amount: long(2)}, {Mask: long(3), Amount: long(4)}}
+		}
+		switch rctType {
+		case RCTTypeFull, RCTTypeSimple:
+			p.RangeSigs = []RangeSig{
+				{Asig: bytes.Repeat([]byte{5}, boroSigSize), Ci: bytes.Repeat([]byte{6}, rangeCiSize)},
+				{Asig: bytes.Repeat([]byte{7}, boroSigSize), Ci: bytes.Repeat([]byte{8}, rangeCiSize)},
+			}
+		default:
+			p.NBP = 1
+			p.Bulletproofs = []Bulletproof{bp}
+			p.PseudoOuts = []Key{key(7)}
+		}
+		if rctType == RCTTypeCLSAG {
+			p.CLSAGs = base.RctPrunable.CLSAGs
+		} else {
+			// One input: RCTTypeFull has inputs+1 columns too.
+			p.MGs = []MgSig{mg(2)}
+		}
+		if rctType == RCTTypeSimple {
+			rct.PseudoOuts = []Key{key(7)}
+		}
+		tx.RctSignatures, tx.RctPrunable = &rct, p
+
+		blob, err := tx.MarshalBinary()
+		assert.NoError(t, err, rctType)
+		parsed, err := ParseTransaction(blob)
+		assert.NoError(t, err, rctType)
+		assert.Equal(t, &tx, parsed, rctType)
+	}
+}
+
+func TestTransactionHashes(t *testing.T) {
+	var tx Transaction
+	assert.NoError(t, json.Unmarshal([]byte(clsagTx), &tx))
+	full, err := tx.MarshalBinary()
+	assert.NoError(t, err)
+	pruned, err := tx.MarshalPrunedBinary()
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(full, pruned))
+
+	prefix, err := tx.encodePrefix(nil)
+	assert.NoError(t, err)
+	prefixHash, err := tx.PrefixHash()
+	assert.NoError(t, err)
+	assert.Equal(t, Keccak256(prefix), prefixHash)
+
+	prunableHash, err := TransactionPrunableHash(full)
+	assert.NoError(t, err)
+	assert.Equal(t, Keccak256(full[len(pruned):]), prunableHash)
+	base := Keccak256(pruned[len(prefix):])
+	want := Keccak256(prefixHash[:], base[:], prunableHash[:])
+
+	id, err := TransactionHash(full)
+	assert.NoError(t, err)
+	assert.Equal(t, want, id)
+	id, err = PrunedTransactionHash(pruned, prunableHash)
+	assert.NoError(t, err)
+	assert.Equal(t, want, id)
+	id, err = tx.PrunedHash(prunableHash)
+	assert.NoError(t, err)
+	assert.Equal(t, want, id)
+
+	parsed, err := ParsePrunedTransaction(pruned)
+	assert.NoError(t, err)
+	assert.Nil(t, parsed.RctPrunable)
+	_, err = parsed.Hash()
+	assert.Error(t, err)
+
+	// A coinbase transaction has no prunable part.
+	var coinbase Transaction
+	assert.NoError(t, json.Unmarshal([]byte(coinbaseTx), &coinbase))
+	blob, err := coinbase.MarshalBinary()
+	assert.NoError(t, err)
+	prefix, _ = coinbase.encodePrefix(nil)
+	prefixHash = Keccak256(prefix)
+	base = Keccak256([]byte{RCTTypeNull})
+	id, err = TransactionHash(blob)
+	assert.NoError(t, err)
+	assert.Equal(t, Keccak256(prefixHash[:], base[:], make([]byte, 32)), id)
+	prunedID, err := PrunedTransactionHash(blob, Hash{1})
+	assert.NoError(t, err)
+	assert.Equal(t, id, prunedID)
+
+	_, err = PrunedTransactionHash(mustDecodeHex(t, genesisTx), Hash{})
+	assert.Error(t, err)
+}
+
+// mainnetTx is a transaction as returned by get_transactions.
+type mainnetTx struct {
+	TxHash       string `json:"tx_hash"`
+	AsHex        string `json:"as_hex"`
+	PrunedAsHex  string `json:"pruned_as_hex"`
+	PrunableHash string `json:"prunable_hash"`
+}
+
+// testdata/mainnet_rct_simple.json is transaction be9d2cf9... of mainnet
+// block 1302238, an RCTTypeSimple transaction with Borromean range
+// signatures.
+func TestMainnetTransaction(t *testing.T) {
+	data, err := os.ReadFile("testdata/mainnet_rct_simple.json")
+	assert.NoError(t, err)
+	var sample mainnetTx
+	assert.NoError(t, json.Unmarshal(data, &sample))
+	full := mustDecodeHex(t, sample.AsHex)
+	pruned := mustDecodeHex(t, sample.PrunedAsHex)
+
+	tx, err := ParseTransaction(full)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), tx.Version)
+	assert.Equal(t, uint8(RCTTypeSimple), tx.RctSignatures.Type)
+	assert.Len(t, tx.Inputs, 2)
+	assert.Len(t, tx.Outputs, 3)
+	assert.Equal(t, "3006bd371b1f0896e8288565a6ee338cc1bf8d49377c4ee6fb4edec941b469ff", tx.Inputs[0].Key.KeyImage.String())
+	assert.Len(t, tx.RctPrunable.RangeSigs, 3)
+	prefixHash, err := tx.PrefixHash()
+	assert.NoError(t, err)
+	assert.Equal(t, "1bbfda600fa6affc80dae05b1124bf05ed0e20890aa42601441dbe0f6fa81f4b", prefixHash.String())
+
+	again, err := tx.MarshalBinary()
+	assert.NoError(t, err)
+	assert.Equal(t, full, again)
+	again, err = tx.MarshalPrunedBinary()
+	assert.NoError(t, err)
+	assert.Equal(t, pruned, again)
+
+	id, err := TransactionHash(full)
+	assert.NoError(t, err)
+	assert.Equal(t, sample.TxHash, id.String())
+	prunableHash, err := TransactionPrunableHash(full)
+	assert.NoError(t, err)
+	assert.Equal(t, sample.PrunableHash, prunableHash.String())
+	id, err = PrunedTransactionHash(pruned, prunableHash)
+	assert.NoError(t, err)
+	assert.Equal(t, sample.TxHash, id.String())
+
+	parsed, err := ParsePrunedTransaction(pruned)
+	assert.NoError(t, err)
+	assert.Nil(t, parsed.RctPrunable)
+	assert.Equal(t, tx.RctSignatures, parsed.RctSignatures)
+}
+
+func TestParseTransactionErrors(t *testing.T) {
+	var tx Transaction
+	assert.NoError(t, json.Unmarshal([]byte(clsagTx), &tx))
+	blob, err := tx.MarshalBinary()
+	assert.NoError(t, err)
+	for i := 0; i < len(blob); i += 97 {
+		_, err := ParseTransaction(blob[:i])
+		assert.Error(t, err, i)
+	}
+	_, err = ParseTransaction(append(blob, 0))
+	assert.Error(t, err)
+
+	for _, s := range []string{
+		"03",                         // version
+		"0200" + "01" + "00",         // txin_to_script
+		"020001ff00" + "01" + "0001", // txout_to_script
+		"0200ffffffffffff",           // input count beyond the blob
+	} {
+		_, err := ParseTransaction(mustDecodeHex(t, s))
+		assert.Error(t, err, s)
+	}
+
+	tx.RctPrunable = nil
+	_, err = tx.MarshalBinary()
+	assert.Error(t, err)
+	tx.Inputs[0].Key = nil
+	_, err = tx.MarshalPrunedBinary()
+	assert.True(t, err != nil && strings.Contains(err.Error(), "input 0"), "got %v", err)
+}
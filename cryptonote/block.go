@@ -0,0 +1,180 @@
+package cryptonote
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// BlockHeader is the header of a block as it is serialized, without the
+// fields monerod derives such as height and reward.
+type BlockHeader struct {
+	MajorVersion uint64 `json:"major_version"`
+	MinorVersion uint64 `json:"minor_version"`
+	Timestamp    uint64 `json:"timestamp"`
+	PrevID       Hash   `json:"prev_id"`
+	Nonce        uint32 `json:"nonce"`
+}
+
+// Block is a block as in the "json" field of get_block: the header, the
+// miner transaction and the hashes of the other transactions.
+type Block struct {
+	BlockHeader
+	MinerTx  Transaction `json:"miner_tx"`
+	TxHashes []Hash      `json:"tx_hashes"`
+}
+
+// Height returns the height of b, which only its miner transaction
+// records.
+func (b *Block) Height() (uint64, bool) {
+	if !b.MinerTx.IsCoinbase() {
+		return 0, false
+	}
+	return b.MinerTx.Inputs[0].Gen.Height, true
+}
+
+// ParseBlock decodes a block from its binary serialization, as in the
+// "blob" field of get_block.
+func ParseBlock(blob []byte) (*Block, error) {
+	d := &decoder{data: blob}
+	b := &Block{BlockHeader: decodeBlockHeader(d)}
+	if d.err != nil {
+		return nil, d.err
+	}
+	tx, layout, err := decodeMinerTx(blob[d.pos:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid miner transaction: %w", err)
+	}
+	b.MinerTx = *tx
+	d.pos += layout
+	b.TxHashes = make([]Hash, d.count(32))
+	for i := range b.TxHashes {
+		b.TxHashes[i] = Hash(d.key())
+	}
+	if d.err == nil && d.pos != len(blob) {
+		d.fail("%d trailing bytes", len(blob)-d.pos)
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return b, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (b *Block) UnmarshalBinary(blob []byte) error {
+	parsed, err := ParseBlock(blob)
+	if err != nil {
+		return err
+	}
+	*b = *parsed
+	return nil
+}
+
+func decodeBlockHeader(d *decoder) BlockHeader {
+	var h BlockHeader
+	h.MajorVersion = d.varint()
+	h.MinorVersion = d.varint()
+	h.Timestamp = d.varint()
+	h.PrevID = Hash(d.key())
+	h.Nonce = d.uint32()
+	return h
+}
+
+// decodeMinerTx decodes the miner transaction at the start of data and
+// returns its length. Miner transactions have no prunable part, so their
+// end is where the RingCT base, or for version 1 the prefix, ends.
+func decodeMinerTx(data []byte) (*Transaction, int, error) {
+	d := &decoder{data: data}
+	tx := decodePrefix(d)
+	if d.err == nil && tx.Version > 1 {
+		tx.RctSignatures = decodeRctBase(d, len(tx.Inputs), len(tx.Outputs))
+		if d.err == nil && tx.RctSignatures.Type != RCTTypeNull {
+			d.fail("miner transaction with RingCT type %d", tx.RctSignatures.Type)
+		}
+	}
+	if d.err == nil && !tx.IsCoinbase() {
+		d.fail("miner transaction without a coinbase input")
+	}
+	if d.err != nil {
+		return nil, 0, d.err
+	}
+	return tx, d.pos, nil
+}
+
+func (h *BlockHeader) encode(buf []byte) []byte {
+	buf = binary.AppendUvarint(buf, h.MajorVersion)
+	buf = binary.AppendUvarint(buf, h.MinorVersion)
+	buf = binary.AppendUvarint(buf, h.Timestamp)
+	buf = append(buf, h.PrevID[:]...)
+	return binary.LittleEndian.AppendUint32(buf, h.Nonce)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (b *Block) MarshalBinary() ([]byte, error) {
+	buf := b.BlockHeader.encode(nil)
+	minerTx, err := b.MinerTx.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("miner transaction: %w", err)
+	}
+	buf = append(buf, minerTx...)
+	buf = binary.AppendUvarint(buf, uint64(len(b.TxHashes)))
+	for _, h := range b.TxHashes {
+		buf = append(buf, h[:]...)
+	}
+	return buf, nil
+}
+
+// HashingBlob returns the blob that miners hash: the header, the merkle
+// root of the transaction hashes, miner transaction first, and the number
+// of transactions.
+func (b *Block) HashingBlob() ([]byte, error) {
+	minerTxHash, err := b.MinerTx.Hash()
+	if err != nil {
+		return nil, fmt.Errorf("miner transaction: %w", err)
+	}
+	root := TreeHash(append([]Hash{minerTxHash}, b.TxHashes...))
+	buf := b.BlockHeader.encode(nil)
+	buf = append(buf, root[:]...)
+	return binary.AppendUvarint(buf, uint64(1+len(b.TxHashes))), nil
+}
+
+// Hash returns the block id, the hash of the length-prefixed hashing
+// blob. It matches monerod for every block but mainnet block 202612,
+// whose id monerod hard-codes.
+func (b *Block) Hash() (Hash, error) {
+	blob, err := b.HashingBlob()
+	if err != nil {
+		return Hash{}, err
+	}
+	return Keccak256(binary.AppendUvarint(nil, uint64(len(blob))), blob), nil
+}
+
+// TreeHash returns the merkle root of hashes as tree_hash computes it:
+// hashes beyond the largest power of two below len(hashes) are paired
+// first. It returns the zero hash for no hashes.
+func TreeHash(hashes []Hash) Hash {
+	switch len(hashes) {
+	case 0:
+		return Hash{}
+	case 1:
+		return hashes[0]
+	case 2:
+		return Keccak256(hashes[0][:], hashes[1][:])
+	}
+	cnt := 1
+	for cnt*2 < len(hashes) {
+		cnt *= 2
+	}
+	ints := make([]Hash, cnt)
+	direct := 2*cnt - len(hashes)
+	copy(ints, hashes[:direct])
+	for i, j := direct, direct; j < cnt; i, j = i+2, j+1 {
+		ints[j] = Keccak256(hashes[i][:], hashes[i+1][:])
+	}
+	for cnt > 2 {
+		cnt /= 2
+		for i, j := 0, 0; j < cnt; i, j = i+2, j+1 {
+			ints[j] = Keccak256(ints[i][:], ints[i+1][:])
+		}
+	}
+	return Keccak256(ints[0][:], ints[1][:])
+}
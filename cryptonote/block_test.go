@@ -0,0 +1,112 @@
+package cryptonote
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// genesisBlock is the mainnet genesis block: version 1, timestamp 0, no
+// previous block, nonce 10000 and the genesis miner transaction.
+var genesisBlock = "010000" + strings.Repeat("00", 32) + "10270000" + genesisTx + "00"
+
+const genesisID = "418015bb9ae982a1975da7d79277c2705727a56894ba0fb246adaabb1f4632e3"
+
+func TestGenesisBlock(t *testing.T) {
+	blob := mustDecodeHex(t, genesisBlock)
+	b, err := ParseBlock(blob)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), b.MajorVersion)
+	assert.Equal(t, uint32(10000), b.Nonce)
+	assert.Empty(t, b.TxHashes)
+	height, ok := b.Height()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(0), height)
+
+	id, err := b.Hash()
+	assert.NoError(t, err)
+	assert.Equal(t, genesisID, id.String())
+
+	hashing, err := b.HashingBlob()
+	assert.NoError(t, err)
+	assert.Len(t, hashing, 3+32+4+32+1)
+	assert.Equal(t, blob[:39], hashing[:39])
+
+	again, err := b.MarshalBinary()
+	assert.NoError(t, err)
+	assert.Equal(t, blob, again)
+}
+
+func TestBlockJSON(t *testing.T) {
+	sample := keys.Replace(`{
+  "major_version": 16,
+  "minor_version": 16,
+  "timestamp": 1700000000,
+  "prev_id": "K1",
+  "nonce": 4294967295,
+  "miner_tx": ` + coinbaseTx + `,
+  "tx_hashes": ["K2", "K3", "K4"]
+}`)
+	var b Block
+	assert.NoError(t, json.Unmarshal([]byte(sample), &b))
+	assert.Equal(t, uint64(16), b.MajorVersion)
+	assert.Equal(t, uint32(4294967295), b.Nonce)
+	assert.Len(t, b.TxHashes, 3)
+	height, _ := b.Height()
+	assert.Equal(t, uint64(3000000), height)
+	out, err := json.Marshal(&b)
+	assert.NoError(t, err)
+	assert.JSONEq(t, sample, string(out))
+
+	// The blob decodes to the same block, and both hash alike.
+	blob, err := b.MarshalBinary()
+	assert.NoError(t, err)
+	parsed, err := ParseBlock(blob)
+	assert.NoError(t, err)
+	assert.Equal(t, &b, parsed)
+	id, err := b.Hash()
+	assert.NoError(t, err)
+	minerTxHash, err := b.MinerTx.Hash()
+	assert.NoError(t, err)
+	hashing, err := b.HashingBlob()
+	assert.NoError(t, err)
+	root := TreeHash(append([]Hash{minerTxHash}, b.TxHashes...))
+	assert.Equal(t, root[:], hashing[len(hashing)-33:len(hashing)-1])
+	assert.Equal(t, byte(4), hashing[len(hashing)-1])
+	assert.Equal(t, Keccak256([]byte{byte(len(hashing))}, hashing), id)
+}
+
+func TestTreeHash(t *testing.T) {
+	h := make([]Hash, 5)
+	for i := range h {
+		h[i] = Hash{byte(i + 1)}
+	}
+	pair := func(a, b Hash) Hash { return Keccak256(a[:], b[:]) }
+	assert.Equal(t, Hash{}, TreeHash(nil))
+	assert.Equal(t, h[0], TreeHash(h[:1]))
+	assert.Equal(t, pair(h[0], h[1]), TreeHash(h[:2]))
+	assert.Equal(t, pair(h[0], pair(h[1], h[2])), TreeHash(h[:3]))
+	assert.Equal(t, pair(pair(h[0], h[1]), pair(h[2], h[3])), TreeHash(h[:4]))
+	assert.Equal(t, pair(pair(h[0], h[1]), pair(h[2], pair(h[3], h[4]))), TreeHash(h))
+}
+
+func TestParseBlockErrors(t *testing.T) {
+	blob := mustDecodeHex(t, genesisBlock)
+	for i := 0; i < len(blob); i += 7 {
+		_, err := ParseBlock(blob[:i])
+		assert.Error(t, err, i)
+	}
+	_, err := ParseBlock(append(blob, 0))
+	assert.Error(t, err)
+
+	// A miner transaction needs a coinbase input.
+	var tx Transaction
+	assert.NoError(t, json.Unmarshal([]byte(v1Tx), &tx))
+	b := Block{MinerTx: tx}
+	blob, err = b.MarshalBinary()
+	assert.NoError(t, err)
+	_, err = ParseBlock(blob)
+	assert.Error(t, err)
+}
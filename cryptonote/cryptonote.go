@@ -0,0 +1,102 @@
+// Package cryptonote holds Go types for the Monero data structures that
+// the daemon and wallet RPCs return as JSON or blobs: transactions, their
+// inputs, outputs and RingCT signatures, tx_extra fields and blocks.
+//
+// The types follow monerod's JSON (get_transactions "as_json"), so
+//
+//	var tx cryptonote.Transaction
+//	err := json.Unmarshal([]byte(info.AsJSON), &tx)
+//
+// decodes a transaction, and json.Marshal produces the same JSON. Hashes,
+// keys and other 32-byte values are arrays that read and write as hex.
+//
+// ParseTransaction and Transaction.MarshalBinary convert between the same
+// types and monerod's binary serialization, and TransactionHash computes
+// transaction ids from blobs. ParseBlock and Block.Hash do the same for
+// blocks.
+package cryptonote
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// Hash is a 32-byte hash, such as a transaction or block id.
+type Hash [32]byte
+
+// Key is a 32-byte key: a public key, a key image or a RingCT key.
+type Key [32]byte
+
+// ParseHash decodes a hash from 64 hex characters.
+func ParseHash(s string) (Hash, error) {
+	var h Hash
+	err := parseHex(h[:], s)
+	return h, err
+}
+
+// ParseKey decodes a key from 64 hex characters.
+func ParseKey(s string) (Key, error) {
+	var k Key
+	err := parseHex(k[:], s)
+	return k, err
+}
+
+func parseHex(dst []byte, s string) error {
+	if len(s) != 2*len(dst) {
+		return fmt.Errorf("invalid hash %q: want %d hex characters", s, 2*len(dst))
+	}
+	if _, err := hex.Decode(dst, []byte(s)); err != nil {
+		return fmt.Errorf("invalid hash %q: %w", s, err)
+	}
+	return nil
+}
+
+func (h Hash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (h Hash) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (h *Hash) UnmarshalText(text []byte) error {
+	return parseHex(h[:], string(text))
+}
+
+func (k Key) String() string {
+	return hex.EncodeToString(k[:])
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (k Key) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (k *Key) UnmarshalText(text []byte) error {
+	return parseHex(k[:], string(text))
+}
+
+// HexBytes are bytes of variable length that read and write as hex.
+type HexBytes []byte
+
+func (b HexBytes) String() string {
+	return hex.EncodeToString(b)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (b HexBytes) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (b *HexBytes) UnmarshalText(text []byte) error {
+	d, err := hex.DecodeString(string(text))
+	if err != nil {
+		return fmt.Errorf("invalid hex %.20q: %w", text, err)
+	}
+	*b = d
+	return nil
+}
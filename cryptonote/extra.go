@@ -0,0 +1,227 @@
+package cryptonote
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Tags of the tx_extra fields, from tx_extra.h.
+const (
+	ExtraTagPadding           = 0x00
+	ExtraTagPubKey            = 0x01
+	ExtraTagNonce             = 0x02
+	ExtraTagMergeMining       = 0x03
+	ExtraTagAdditionalPubKeys = 0x04
+	ExtraTagMinerGate         = 0xde
+)
+
+// Limits and prefixes of tx_extra fields.
+const (
+	// MaxExtraPadding is the most bytes of padding, the tag included.
+	MaxExtraPadding = 255
+	// MaxExtraNonce is the longest nonce.
+	MaxExtraNonce = 255
+
+	nonceTagPaymentID          = 0x00
+	nonceTagEncryptedPaymentID = 0x01
+)
+
+// ExtraField is a field of tx_extra. Tag says which of the other fields is
+// set.
+type ExtraField struct {
+	Tag byte
+	// PubKey is the transaction public key, for ExtraTagPubKey.
+	PubKey Key
+	// AdditionalPubKeys are the per-output public keys of transactions to
+	// subaddresses, for ExtraTagAdditionalPubKeys.
+	AdditionalPubKeys []Key
+	// Data is the nonce for ExtraTagNonce and the payload for
+	// ExtraTagMinerGate.
+	Data []byte
+	// MergeMining is set for ExtraTagMergeMining.
+	MergeMining *MergeMiningTag
+	// Padding is the number of zero bytes, the tag included, for
+	// ExtraTagPadding. Padding runs to the end of tx_extra.
+	Padding int
+	// Raw holds the rest of tx_extra, starting at the tag, for a tag this
+	// package does not know. Its length cannot be known, so it is always
+	// the last field.
+	Raw []byte
+}
+
+// MergeMiningTag commits to the merkle root of merge-mined chains.
+type MergeMiningTag struct {
+	Depth      uint64
+	MerkleRoot Hash
+}
+
+// ExtraFields are the fields of a tx_extra, in order.
+type ExtraFields []ExtraField
+
+// Fields parses e, see ParseExtra.
+func (e Extra) Fields() (ExtraFields, error) {
+	return ParseExtra(e)
+}
+
+// ParseExtra splits tx_extra into its fields. An unknown tag ends parsing
+// with a field holding the remaining bytes in Raw. A malformed field is an
+// error; the fields before it are returned with it, as monerod keeps them
+// too.
+func ParseExtra(extra []byte) (ExtraFields, error) {
+	var fields ExtraFields
+	d := &decoder{data: extra}
+	for d.pos < len(extra) {
+		start := d.pos
+		f := ExtraField{Tag: d.byte()}
+		switch f.Tag {
+		case ExtraTagPadding:
+			f.Padding = len(extra) - start
+			if f.Padding > MaxExtraPadding {
+				d.fail("padding of %d bytes", f.Padding)
+			}
+			for _, b := range d.bytes(f.Padding - 1) {
+				if b != 0 {
+					d.fail("non-zero padding")
+					break
+				}
+			}
+		case ExtraTagPubKey:
+			f.PubKey = d.key()
+		case ExtraTagNonce:
+			f.Data = d.bytes(d.count(1))
+			if len(f.Data) > MaxExtraNonce {
+				d.fail("nonce of %d bytes", len(f.Data))
+			}
+		case ExtraTagMinerGate:
+			f.Data = d.bytes(d.count(1))
+		case ExtraTagMergeMining:
+			inner := &decoder{data: d.bytes(d.count(1))}
+			f.MergeMining = &MergeMiningTag{Depth: inner.varint(), MerkleRoot: Hash(inner.key())}
+			if inner.err != nil || inner.pos != len(inner.data) {
+				d.fail("malformed merge mining tag")
+			}
+		case ExtraTagAdditionalPubKeys:
+			f.AdditionalPubKeys = d.keyVector()
+		default:
+			f.Raw = extra[start:]
+			d.pos = len(extra)
+		}
+		if d.err != nil {
+			return fields, fmt.Errorf("invalid tx_extra field %#x at byte %d: %w", f.Tag, start, d.err)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// Marshal serializes f as tx_extra.
+func (f ExtraFields) Marshal() (Extra, error) {
+	var buf []byte
+	for i, field := range f {
+		if (field.Tag == ExtraTagPadding || field.Raw != nil) && i != len(f)-1 {
+			return nil, fmt.Errorf("tx_extra field %d: padding and unknown fields must come last", i)
+		}
+		if field.Raw != nil {
+			buf = append(buf, field.Raw...)
+			continue
+		}
+		buf = append(buf, field.Tag)
+		switch field.Tag {
+		case ExtraTagPadding:
+			if field.Padding < 1 || field.Padding > MaxExtraPadding {
+				return nil, fmt.Errorf("tx_extra field %d: padding of %d bytes", i, field.Padding)
+			}
+			buf = append(buf, make([]byte, field.Padding-1)...)
+		case ExtraTagPubKey:
+			buf = append(buf, field.PubKey[:]...)
+		case ExtraTagNonce, ExtraTagMinerGate:
+			if field.Tag == ExtraTagNonce && len(field.Data) > MaxExtraNonce {
+				return nil, fmt.Errorf("tx_extra field %d: nonce of %d bytes", i, len(field.Data))
+			}
+			buf = binary.AppendUvarint(buf, uint64(len(field.Data)))
+			buf = append(buf, field.Data...)
+		case ExtraTagMergeMining:
+			if field.MergeMining == nil {
+				return nil, fmt.Errorf("tx_extra field %d: merge mining tag without MergeMining", i)
+			}
+			inner := binary.AppendUvarint(nil, field.MergeMining.Depth)
+			inner = append(inner, field.MergeMining.MerkleRoot[:]...)
+			buf = binary.AppendUvarint(buf, uint64(len(inner)))
+			buf = append(buf, inner...)
+		case ExtraTagAdditionalPubKeys:
+			buf = appendKeyVector(buf, field.AdditionalPubKeys)
+		default:
+			return nil, fmt.Errorf("tx_extra field %d: unknown tag %#x without Raw", i, field.Tag)
+		}
+	}
+	return Extra(buf), nil
+}
+
+func (f ExtraFields) find(tag byte) (ExtraField, bool) {
+	for _, field := range f {
+		if field.Tag == tag && field.Raw == nil {
+			return field, true
+		}
+	}
+	return ExtraField{}, false
+}
+
+// PubKey returns the first transaction public key. Like monerod, it
+// ignores any later ones.
+func (f ExtraFields) PubKey() (Key, bool) {
+	field, ok := f.find(ExtraTagPubKey)
+	return field.PubKey, ok
+}
+
+// AdditionalPubKeys returns the additional public keys, or nil.
+func (f ExtraFields) AdditionalPubKeys() []Key {
+	field, _ := f.find(ExtraTagAdditionalPubKeys)
+	return field.AdditionalPubKeys
+}
+
+// Nonce returns the extra nonce, which holds the payment id of a
+// transaction or the reserved space of a block template.
+func (f ExtraFields) Nonce() ([]byte, bool) {
+	field, ok := f.find(ExtraTagNonce)
+	return field.Data, ok
+}
+
+// PaymentID returns the unencrypted 32-byte payment id of the nonce.
+func (f ExtraFields) PaymentID() (Hash, bool) {
+	var id Hash
+	nonce, ok := f.Nonce()
+	if !ok || len(nonce) != 1+len(id) || nonce[0] != nonceTagPaymentID {
+		return id, false
+	}
+	copy(id[:], nonce[1:])
+	return id, true
+}
+
+// EncryptedPaymentID returns the 8-byte encrypted payment id of the
+// nonce, as used by integrated addresses.
+func (f ExtraFields) EncryptedPaymentID() ([8]byte, bool) {
+	var id [8]byte
+	nonce, ok := f.Nonce()
+	if !ok || len(nonce) != 1+len(id) || nonce[0] != nonceTagEncryptedPaymentID {
+		return id, false
+	}
+	copy(id[:], nonce[1:])
+	return id, true
+}
+
+// MergeMining returns the merge mining tag.
+func (f ExtraFields) MergeMining() (*MergeMiningTag, bool) {
+	field, ok := f.find(ExtraTagMergeMining)
+	return field.MergeMining, ok
+}
+
+// PaymentIDNonce returns the nonce that carries an unencrypted payment id.
+func PaymentIDNonce(id Hash) []byte {
+	return append([]byte{nonceTagPaymentID}, id[:]...)
+}
+
+// EncryptedPaymentIDNonce returns the nonce that carries an encrypted
+// payment id.
+func EncryptedPaymentIDNonce(id [8]byte) []byte {
+	return append([]byte{nonceTagEncryptedPaymentID}, id[:]...)
+}
@@ -0,0 +1,121 @@
+package cryptonote
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExtra(t *testing.T) {
+	genesis, err := ParseTransaction(mustDecodeHex(t, genesisTx))
+	assert.NoError(t, err)
+	fields, err := genesis.Extra.Fields()
+	assert.NoError(t, err)
+	pub, ok := fields.PubKey()
+	assert.True(t, ok)
+	assert.Equal(t, "7767aafcde9be00dcfd098715ebcf7f410daebc582fda69d24a28e9d0bc890d1", pub.String())
+	_, ok = fields.Nonce()
+	assert.False(t, ok)
+
+	// A transfer to an integrated address and a subaddress.
+	encrypted := [8]byte{1, 2, 3, 4, 5, 6, 7, 8}
+	extra := append([]byte{ExtraTagPubKey}, bytes.Repeat([]byte{0xaa}, 32)...)
+	extra = append(extra, ExtraTagNonce, 9)
+	extra = append(extra, EncryptedPaymentIDNonce(encrypted)...)
+	extra = append(extra, ExtraTagAdditionalPubKeys, 2)
+	extra = append(extra, bytes.Repeat([]byte{0xbb}, 32)...)
+	extra = append(extra, bytes.Repeat([]byte{0xcc}, 32)...)
+	fields, err = ParseExtra(extra)
+	assert.NoError(t, err)
+	assert.Len(t, fields, 3)
+	id, ok := fields.EncryptedPaymentID()
+	assert.True(t, ok)
+	assert.Equal(t, encrypted, id)
+	_, ok = fields.PaymentID()
+	assert.False(t, ok)
+	assert.Len(t, fields.AdditionalPubKeys(), 2)
+	assert.Equal(t, byte(0xcc), fields.AdditionalPubKeys()[1][31])
+	again, err := fields.Marshal()
+	assert.NoError(t, err)
+	assert.Equal(t, Extra(extra), again)
+}
+
+func TestExtraMarshal(t *testing.T) {
+	paymentID := Hash{9, 9, 9}
+	fields := ExtraFields{
+		{Tag: ExtraTagPubKey, PubKey: Key{1}},
+		{Tag: ExtraTagMergeMining, MergeMining: &MergeMiningTag{Depth: 300, MerkleRoot: Hash{2}}},
+		{Tag: ExtraTagNonce, Data: PaymentIDNonce(paymentID)},
+		{Tag: ExtraTagMinerGate, Data: []byte("pool")},
+		{Tag: ExtraTagPadding, Padding: 8},
+	}
+	extra, err := fields.Marshal()
+	assert.NoError(t, err)
+	assert.Equal(t, 1+32+1+1+2+32+1+1+33+1+1+4+8, len(extra))
+	assert.Equal(t, make([]byte, 8), []byte(extra[len(extra)-8:]))
+
+	parsed, err := extra.Fields()
+	assert.NoError(t, err)
+	assert.Equal(t, fields, parsed)
+	id, ok := parsed.PaymentID()
+	assert.True(t, ok)
+	assert.Equal(t, paymentID, id)
+	mm, ok := parsed.MergeMining()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(300), mm.Depth)
+
+	// Block templates reserve space in the nonce for pools.
+	template, err := ExtraFields{
+		{Tag: ExtraTagPubKey, PubKey: Key{1}},
+		{Tag: ExtraTagNonce, Data: make([]byte, MaxExtraNonce)},
+	}.Marshal()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{ExtraTagNonce, 0xff, 0x01}, []byte(template[33:36]))
+	parsed, err = template.Fields()
+	assert.NoError(t, err)
+	nonce, _ := parsed.Nonce()
+	assert.Len(t, nonce, MaxExtraNonce)
+
+	for _, bad := range []ExtraFields{
+		{{Tag: ExtraTagPadding, Padding: 2}, {Tag: ExtraTagPubKey}},
+		{{Tag: ExtraTagPadding, Padding: MaxExtraPadding + 1}},
+		{{Tag: ExtraTagNonce, Data: make([]byte, MaxExtraNonce+1)}},
+		{{Tag: ExtraTagMergeMining}},
+		{{Tag: 0x7f}},
+	} {
+		_, err := bad.Marshal()
+		assert.Error(t, err)
+	}
+}
+
+func TestParseExtraUnknownTag(t *testing.T) {
+	extra := append([]byte{ExtraTagPubKey}, make([]byte, 32)...)
+	extra = append(extra, 0x7f, 1, 2, 3)
+	fields, err := ParseExtra(extra)
+	assert.NoError(t, err)
+	assert.Len(t, fields, 2)
+	assert.Equal(t, []byte{0x7f, 1, 2, 3}, fields[1].Raw)
+	_, ok := fields.PubKey()
+	assert.True(t, ok)
+	again, err := fields.Marshal()
+	assert.NoError(t, err)
+	assert.Equal(t, Extra(extra), again)
+}
+
+func TestParseExtraErrors(t *testing.T) {
+	pubKey := append([]byte{ExtraTagPubKey}, make([]byte, 32)...)
+	for name, tail := range map[string][]byte{
+		"short key":        {ExtraTagPubKey, 1, 2},
+		"short nonce":      {ExtraTagNonce, 5, 1},
+		"long nonce":       append([]byte{ExtraTagNonce, 0x80, 0x02}, make([]byte, 256)...),
+		"non-zero padding": {ExtraTagPadding, 0, 1},
+		"long padding":     make([]byte, MaxExtraPadding+1),
+		"bad merge mining": {ExtraTagMergeMining, 2, 1, 2},
+		"short additional": {ExtraTagAdditionalPubKeys, 2, 1},
+	} {
+		fields, err := ParseExtra(append(append([]byte{}, pubKey...), tail...))
+		assert.Error(t, err, name)
+		assert.Len(t, fields, 1, name)
+	}
+}
@@ -0,0 +1,99 @@
+package cryptonote
+
+import (
+	"errors"
+
+	"github.com/boomhut/go-monero-rpc-client/internal/keccak"
+)
+
+// Keccak256 returns cn_fast_hash, the Keccak-256 hash of the concatenation
+// of data.
+func Keccak256(data ...[]byte) Hash {
+	return Hash(keccak.Sum256(data...))
+}
+
+// TransactionHash returns the id of a transaction from its full blob.
+func TransactionHash(blob []byte) (Hash, error) {
+	tx, layout, err := decodeTransaction(blob, false)
+	if err != nil {
+		return Hash{}, err
+	}
+	if tx.Version == 1 {
+		return Keccak256(blob), nil
+	}
+	return txHash(blob, layout, tx.RctSignatures.Type, Keccak256(blob[layout.baseEnd:])), nil
+}
+
+// PrunedTransactionHash returns the id of a version 2 transaction from its
+// pruned blob and the hash of its prunable part, as in TxInfo.PrunedAsHex
+// and TxInfo.PrunableHash. prunableHash is ignored for coinbase
+// transactions, which have no prunable part. Version 1 transactions cannot
+// be hashed without their signatures.
+func PrunedTransactionHash(blob []byte, prunableHash Hash) (Hash, error) {
+	tx, layout, err := decodeTransaction(blob, true)
+	if err != nil {
+		return Hash{}, err
+	}
+	if tx.Version == 1 {
+		return Hash{}, errors.New("pruned version 1 transactions cannot be hashed")
+	}
+	return txHash(blob, layout, tx.RctSignatures.Type, prunableHash), nil
+}
+
+// TransactionPrunableHash returns the hash of the prunable part of a full
+// version 2 transaction blob, as monerod reports in TxInfo.PrunableHash.
+// It is the zero hash for coinbase transactions.
+func TransactionPrunableHash(blob []byte) (Hash, error) {
+	tx, layout, err := decodeTransaction(blob, false)
+	if err != nil {
+		return Hash{}, err
+	}
+	if tx.Version == 1 {
+		return Hash{}, errors.New("version 1 transactions have no prunable hash")
+	}
+	if tx.RctSignatures.Type == RCTTypeNull {
+		return Hash{}, nil
+	}
+	return Keccak256(blob[layout.baseEnd:]), nil
+}
+
+// txHash combines the hashes of the prefix, the RingCT base and the
+// prunable part of a version 2 transaction.
+func txHash(blob []byte, layout txLayout, rctType uint8, prunableHash Hash) Hash {
+	if rctType == RCTTypeNull {
+		prunableHash = Hash{}
+	}
+	prefix := Keccak256(blob[:layout.prefixEnd])
+	base := Keccak256(blob[layout.prefixEnd:layout.baseEnd])
+	return Keccak256(prefix[:], base[:], prunableHash[:])
+}
+
+// Hash returns the id of t. Pruned version 2 transactions need their
+// prunable hash, use PrunedHash.
+func (t *Transaction) Hash() (Hash, error) {
+	blob, err := t.MarshalBinary()
+	if err != nil {
+		return Hash{}, err
+	}
+	return TransactionHash(blob)
+}
+
+// PrunedHash returns the id of a version 2 transaction whose prunable
+// part has the hash prunableHash.
+func (t *Transaction) PrunedHash(prunableHash Hash) (Hash, error) {
+	blob, err := t.MarshalPrunedBinary()
+	if err != nil {
+		return Hash{}, err
+	}
+	return PrunedTransactionHash(blob, prunableHash)
+}
+
+// PrefixHash returns the hash of the transaction prefix, the message that
+// the ring signatures sign.
+func (t *Transaction) PrefixHash() (Hash, error) {
+	prefix, err := t.encodePrefix(nil)
+	if err != nil {
+		return Hash{}, err
+	}
+	return Keccak256(prefix), nil
+}
@@ -0,0 +1,255 @@
+package cryptonote
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// RingCT signature types, rct::RCTType.
+const (
+	RCTTypeNull            = 0
+	RCTTypeFull            = 1
+	RCTTypeSimple          = 2
+	RCTTypeBulletproof     = 3
+	RCTTypeBulletproof2    = 4
+	RCTTypeCLSAG           = 5
+	RCTTypeBulletproofPlus = 6
+)
+
+// Transaction is a Monero transaction. Version 1 transactions carry ring
+// signatures in Signatures, version 2 transactions RingCT signatures.
+// RctPrunable is nil for pruned transactions and coinbase transactions.
+type Transaction struct {
+	Version    uint64  `json:"version"`
+	UnlockTime uint64  `json:"unlock_time"`
+	Inputs     []TxIn  `json:"vin"`
+	Outputs    []TxOut `json:"vout"`
+	Extra      Extra   `json:"extra"`
+	// Signatures are the ring signatures of a version 1 transaction, one
+	// hex string per input.
+	Signatures    []HexBytes     `json:"signatures,omitempty"`
+	RctSignatures *RctSignatures `json:"rct_signatures,omitempty"`
+	RctPrunable   *RctPrunable   `json:"rctsig_prunable,omitempty"`
+}
+
+// IsCoinbase reports whether t is a miner transaction.
+func (t *Transaction) IsCoinbase() bool {
+	return len(t.Inputs) == 1 && t.Inputs[0].Gen != nil
+}
+
+// KeyImages returns the key images of the inputs of t.
+func (t *Transaction) KeyImages() []Key {
+	var images []Key
+	for _, in := range t.Inputs {
+		if in.Key != nil {
+			images = append(images, in.Key.KeyImage)
+		}
+	}
+	return images
+}
+
+// Fee returns the fee of t: the RingCT fee, or for version 1 the inputs
+// minus the outputs. It returns 0 for coinbase transactions.
+func (t *Transaction) Fee() uint64 {
+	if t.IsCoinbase() {
+		return 0
+	}
+	if t.RctSignatures != nil && t.RctSignatures.Type != RCTTypeNull {
+		return t.RctSignatures.TxnFee
+	}
+	var in, out uint64
+	for _, i := range t.Inputs {
+		if i.Key != nil {
+			in += i.Key.Amount
+		}
+	}
+	for _, o := range t.Outputs {
+		out += o.Amount
+	}
+	if out > in {
+		return 0
+	}
+	return in - out
+}
+
+// TxIn is an input: Gen for the input of a coinbase transaction, Key
+// otherwise.
+type TxIn struct {
+	Gen *TxInGen `json:"gen,omitempty"`
+	Key *TxInKey `json:"key,omitempty"`
+}
+
+// TxInGen is the input of a coinbase transaction.
+type TxInGen struct {
+	Height uint64 `json:"height"`
+}
+
+// TxInKey spends one output of a ring. KeyOffsets are the global indices
+// of the ring members, each relative to the previous one.
+type TxInKey struct {
+	Amount     uint64   `json:"amount"`
+	KeyOffsets []uint64 `json:"key_offsets"`
+	KeyImage   Key      `json:"k_image"`
+}
+
+// RingIndices returns the absolute global indices of the ring members.
+func (in *TxInKey) RingIndices() []uint64 {
+	indices := make([]uint64, len(in.KeyOffsets))
+	var sum uint64
+	for i, off := range in.KeyOffsets {
+		sum += off
+		indices[i] = sum
+	}
+	return indices
+}
+
+// TxOut is an output of Amount (0 for RingCT outputs) to Target.
+type TxOut struct {
+	Amount uint64      `json:"amount"`
+	Target TxOutTarget `json:"target"`
+}
+
+// TxOutTarget is the destination of an output: Key before view tags,
+// TaggedKey since.
+type TxOutTarget struct {
+	Key       *Key       `json:"key,omitempty"`
+	TaggedKey *TaggedKey `json:"tagged_key,omitempty"`
+}
+
+// PublicKey returns the one-time public key of the output.
+func (t TxOutTarget) PublicKey() Key {
+	if t.TaggedKey != nil {
+		return t.TaggedKey.Key
+	}
+	if t.Key != nil {
+		return *t.Key
+	}
+	return Key{}
+}
+
+// TaggedKey is an output key with its view tag.
+type TaggedKey struct {
+	Key     Key     `json:"key"`
+	ViewTag ViewTag `json:"view_tag"`
+}
+
+// ViewTag is the one-byte view tag of an output, written as two hex
+// characters.
+type ViewTag byte
+
+// MarshalText implements encoding.TextMarshaler.
+func (v ViewTag) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString([]byte{byte(v)})), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (v *ViewTag) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(string(text))
+	if err != nil || len(b) != 1 {
+		return fmt.Errorf("invalid view tag %q", text)
+	}
+	*v = ViewTag(b[0])
+	return nil
+}
+
+// Extra is the tx_extra field, which monerod writes as an array of
+// numbers.
+type Extra []byte
+
+// MarshalJSON writes e as an array of numbers.
+func (e Extra) MarshalJSON() ([]byte, error) {
+	ints := make([]int, len(e))
+	for i, b := range e {
+		ints[i] = int(b)
+	}
+	return json.Marshal(ints)
+}
+
+// UnmarshalJSON reads an array of numbers.
+func (e *Extra) UnmarshalJSON(data []byte) error {
+	var ints []uint8
+	if err := json.Unmarshal(data, &ints); err != nil {
+		return fmt.Errorf("invalid extra: %w", err)
+	}
+	*e = Extra(ints)
+	return nil
+}
+
+// RctSignatures is the part of the RingCT signatures that is kept in
+// pruned transactions. Coinbase transactions only have Type.
+type RctSignatures struct {
+	Type   uint8  `json:"type"`
+	TxnFee uint64 `json:"txnFee,omitempty"`
+	// PseudoOuts are only here for RCTTypeSimple, later types keep them
+	// in RctPrunable.
+	PseudoOuts []Key       `json:"pseudoOuts,omitempty"`
+	EcdhInfo   []EcdhTuple `json:"ecdhInfo,omitempty"`
+	OutPk      []Key       `json:"outPk,omitempty"`
+}
+
+// EcdhTuple is the encrypted amount of an output. Since
+// RCTTypeBulletproof2 the amount has 8 bytes and there is no mask.
+type EcdhTuple struct {
+	Mask   HexBytes `json:"mask,omitempty"`
+	Amount HexBytes `json:"amount"`
+}
+
+// RctPrunable holds the range proofs and ring signatures, which pruned
+// transactions drop. Which fields are set depends on the RingCT type.
+type RctPrunable struct {
+	// NBP is the number of bulletproofs.
+	NBP              uint64            `json:"nbp,omitempty"`
+	RangeSigs        []RangeSig        `json:"rangeSigs,omitempty"`
+	Bulletproofs     []Bulletproof     `json:"bp,omitempty"`
+	BulletproofsPlus []BulletproofPlus `json:"bpp,omitempty"`
+	MGs              []MgSig           `json:"MGs,omitempty"`
+	CLSAGs           []Clsag           `json:"CLSAGs,omitempty"`
+	PseudoOuts       []Key             `json:"pseudoOuts,omitempty"`
+}
+
+// RangeSig is a Borromean range proof of the first RingCT types.
+type RangeSig struct {
+	Asig HexBytes `json:"asig"`
+	Ci   HexBytes `json:"Ci"`
+}
+
+// Bulletproof is a bulletproof range proof.
+type Bulletproof struct {
+	A    Key   `json:"A"`
+	S    Key   `json:"S"`
+	T1   Key   `json:"T1"`
+	T2   Key   `json:"T2"`
+	Taux Key   `json:"taux"`
+	Mu   Key   `json:"mu"`
+	L    []Key `json:"L"`
+	R    []Key `json:"R"`
+	LA   Key   `json:"a"`
+	LB   Key   `json:"b"`
+	T    Key   `json:"t"`
+}
+
+// BulletproofPlus is a bulletproof+ range proof.
+type BulletproofPlus struct {
+	A  Key   `json:"A"`
+	A1 Key   `json:"A1"`
+	B  Key   `json:"B"`
+	R1 Key   `json:"r1"`
+	S1 Key   `json:"s1"`
+	D1 Key   `json:"d1"`
+	L  []Key `json:"L"`
+	R  []Key `json:"R"`
+}
+
+// MgSig is an MLSAG ring signature.
+type MgSig struct {
+	SS [][]Key `json:"ss"`
+	CC Key     `json:"cc"`
+}
+
+// Clsag is a CLSAG ring signature.
+type Clsag struct {
+	S  []Key `json:"s"`
+	C1 Key   `json:"c1"`
+	D  Key   `json:"D"`
+}
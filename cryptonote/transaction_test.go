@@ -0,0 +1,125 @@
+package cryptonote
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// keys replaces the placeholders K0..K9 of a JSON sample with 64 hex
+// characters.
+var keys = strings.NewReplacer(
+	"K0", strings.Repeat("00", 32), "K1", strings.Repeat("11", 32),
+	"K2", strings.Repeat("22", 32), "K3", strings.Repeat("33", 32),
+	"K4", strings.Repeat("44", 32), "K5", strings.Repeat("55", 32),
+	"K6", strings.Repeat("66", 32), "K7", strings.Repeat("77", 32),
+	"K8", strings.Repeat("88", 32), "K9", strings.Repeat("99", 32),
+)
+
+// A version 2 transaction with bulletproofs+ and CLSAGs, as monerod
+// writes it with decode_as_json.
+var clsagTx = keys.Replace(`{
+  "version": 2,
+  "unlock_time": 0,
+  "vin": [{"key": {"amount": 0, "key_offsets": [5000, 120, 3], "k_image": "K1"}}],
+  "vout": [
+    {"amount": 0, "target": {"tagged_key": {"key": "K2", "view_tag": "a7"}}},
+    {"amount": 0, "target": {"tagged_key": {"key": "K3", "view_tag": "0c"}}}
+  ],
+  "extra": [1, 17, 17, 17, 17, 17, 17, 17, 17, 17, 17, 17, 17, 17, 17, 17, 17, 17, 17, 17, 17, 17, 17, 17, 17, 17, 17, 17, 17, 17, 17, 17, 17],
+  "rct_signatures": {
+    "type": 6,
+    "txnFee": 30660000,
+    "ecdhInfo": [{"amount": "0102030405060708"}, {"amount": "1112131415161718"}],
+    "outPk": ["K4", "K5"]
+  },
+  "rctsig_prunable": {
+    "nbp": 1,
+    "bpp": [{"A": "K6", "A1": "K7", "B": "K8", "r1": "K9", "s1": "K0", "d1": "K1", "L": ["K2", "K3"], "R": ["K4", "K5"]}],
+    "CLSAGs": [{"s": ["K6", "K7", "K8"], "c1": "K9", "D": "K0"}],
+    "pseudoOuts": ["K1"]
+  }
+}`)
+
+// A version 1 transaction with a ring signature and an output before view
+// tags.
+var v1Tx = keys.Replace(`{
+  "version": 1,
+  "unlock_time": 0,
+  "vin": [{"key": {"amount": 10000000000, "key_offsets": [7, 9], "k_image": "K1"}}],
+  "vout": [{"amount": 9000000000, "target": {"key": "K2"}}],
+  "extra": [],
+  "signatures": ["K3K4K5K6"]
+}`)
+
+// A coinbase transaction, which has no RingCT data besides the type.
+var coinbaseTx = keys.Replace(`{
+  "version": 2,
+  "unlock_time": 3000060,
+  "vin": [{"gen": {"height": 3000000}}],
+  "vout": [{"amount": 600000000000, "target": {"tagged_key": {"key": "K2", "view_tag": "ff"}}}],
+  "extra": [1, 0, 2],
+  "rct_signatures": {"type": 0}
+}`)
+
+func TestTransactionJSON(t *testing.T) {
+	for name, sample := range map[string]string{"clsag": clsagTx, "v1": v1Tx, "coinbase": coinbaseTx} {
+		var tx Transaction
+		assert.NoError(t, json.Unmarshal([]byte(sample), &tx), name)
+		out, err := json.Marshal(&tx)
+		assert.NoError(t, err, name)
+		assert.JSONEq(t, sample, string(out), name)
+	}
+}
+
+func TestTransactionFields(t *testing.T) {
+	var tx Transaction
+	assert.NoError(t, json.Unmarshal([]byte(clsagTx), &tx))
+	assert.False(t, tx.IsCoinbase())
+	assert.Equal(t, uint64(30660000), tx.Fee())
+	assert.Equal(t, []uint64{5000, 5120, 5123}, tx.Inputs[0].Key.RingIndices())
+	assert.Equal(t, strings.Repeat("11", 32), tx.KeyImages()[0].String())
+	assert.Equal(t, ViewTag(0xa7), tx.Outputs[0].Target.TaggedKey.ViewTag)
+	assert.Equal(t, strings.Repeat("22", 32), tx.Outputs[0].Target.PublicKey().String())
+	assert.Equal(t, byte(RCTTypeBulletproofPlus), tx.RctSignatures.Type)
+	assert.Len(t, tx.RctSignatures.EcdhInfo[1].Amount, 8)
+	assert.Len(t, tx.RctPrunable.CLSAGs[0].S, 3)
+	assert.Len(t, tx.Extra, 33)
+
+	tx = Transaction{}
+	assert.NoError(t, json.Unmarshal([]byte(v1Tx), &tx))
+	assert.Equal(t, uint64(1000000000), tx.Fee())
+	assert.Equal(t, *tx.Outputs[0].Target.Key, tx.Outputs[0].Target.PublicKey())
+	assert.Len(t, tx.Signatures[0], 128)
+
+	tx = Transaction{}
+	assert.NoError(t, json.Unmarshal([]byte(coinbaseTx), &tx))
+	assert.True(t, tx.IsCoinbase())
+	assert.Equal(t, uint64(0), tx.Fee())
+	assert.Empty(t, tx.KeyImages())
+	assert.Nil(t, tx.RctPrunable)
+}
+
+func TestInvalidTransactionJSON(t *testing.T) {
+	for _, sample := range []string{
+		`{"vin": [{"key": {"k_image": "abcd"}}]}`,
+		`{"vout": [{"target": {"tagged_key": {"view_tag": "abcd"}}}]}`,
+		`{"extra": [256]}`,
+		`{"signatures": ["xyz"]}`,
+	} {
+		var tx Transaction
+		assert.Error(t, json.Unmarshal([]byte(sample), &tx), sample)
+	}
+}
+
+func TestParseHash(t *testing.T) {
+	h, err := ParseHash(strings.Repeat("ab", 32))
+	assert.NoError(t, err)
+	assert.Equal(t, byte(0xab), h[31])
+	_, err = ParseHash("ab")
+	assert.Error(t, err)
+	_, err = ParseKey(strings.Repeat("zz", 32))
+	assert.Error(t, err)
+}
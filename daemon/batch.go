@@ -0,0 +1,133 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/boomhut/go-monero-rpc-client/internal/batch"
+	"github.com/boomhut/go-monero-rpc-client/internal/retry"
+)
+
+// BatchMethod is the method name interceptors see for a batch sent with
+// CallBatch. The params are the []BatchCall being sent.
+const BatchMethod = "batch"
+
+// BatchCall is a single JSON-RPC call in a batch. Result must be a pointer
+// to the value the reply is decoded into (for example *ResponseGetBlockHeader)
+// or nil to discard it. Error is set by CallBatch once the batch completes.
+type BatchCall struct {
+	Method string
+	Params interface{}
+	Result interface{}
+	Error  error
+}
+
+// CallBatch sends calls to /json_rpc as a single JSON-RPC 2.0 batch and
+// decodes each reply into its Result. Per-call failures (JSON-RPC errors,
+// non-OK status) are stored in the call's Error field; the returned error
+// reports a failure of the batch as a whole. If the daemon does not accept
+// batches the calls are made one by one instead. Only JSON-RPC methods can
+// be batched, not endpoints such as /get_height.
+func (c *client) CallBatch(ctx context.Context, calls []BatchCall) error {
+	if len(calls) == 0 {
+		return nil
+	}
+	for _, call := range calls {
+		if strings.HasPrefix(call.Method, "/") {
+			return fmt.Errorf("cannot batch %s: only JSON-RPC methods can be batched", call.Method)
+		}
+	}
+	if c.batch.Supported() {
+		err := c.invoke(ctx, BatchMethod, calls, nil)
+		if !errors.Is(err, batch.ErrUnsupported) {
+			return err
+		}
+		c.batch.Disable()
+	}
+	for i := range calls {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("request aborted: %w", err)
+		}
+		calls[i].Error = c.invoke(ctx, calls[i].Method, calls[i].Params, calls[i].Result)
+	}
+	return nil
+}
+
+// callBatch is the innermost Invoker for BatchMethod. The batch is retried,
+// or failed over by a Pool, only if every call in it may be retried.
+func (c *client) callBatch(ctx context.Context, calls []BatchCall) error {
+	reqs := make([]batch.Call, len(calls))
+	attempts, resend := 0, true
+	for i, call := range calls {
+		reqs[i] = batch.Call{Method: call.Method, Params: call.Params}
+		if n := c.config.Retry.attempts(call.Method); attempts == 0 || n < attempts {
+			attempts = n
+		}
+		resend = resend && c.config.Retry.repeatable(call.Method)
+	}
+	ctx = withResend(ctx, resend)
+	message, err := batch.Encode(reqs)
+	if err != nil {
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		err = c.callBatchOnce(ctx, message, calls)
+		if err == nil || attempt >= attempts || !c.config.Retry.retryable(err) {
+			return err
+		}
+		if err := retry.Sleep(ctx, c.config.Retry.backoff(attempt)); err != nil {
+			return fmt.Errorf("request aborted: %w", err)
+		}
+	}
+}
+
+func (c *client) callBatchOnce(ctx context.Context, message []byte, calls []BatchCall) error {
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.config.Address+"/json_rpc", bytes.NewReader(message))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	body, err := c.send(httpReq)
+	if err != nil {
+		var herr *HTTPStatusError
+		if errors.As(err, &herr) && batch.UnsupportedStatus(herr.StatusCode) {
+			return batch.ErrUnsupported
+		}
+		return err
+	}
+
+	replies, err := batch.Decode(body, len(calls))
+	if err != nil {
+		return err
+	}
+	for i, reply := range replies {
+		if reply == nil {
+			calls[i].Error = batch.NoReply(calls[i].Method)
+			continue
+		}
+		calls[i].Error = c.decodeBatchReply(calls[i], reply)
+	}
+	return nil
+}
+
+func (c *client) decodeBatchReply(call BatchCall, reply *batch.Reply) error {
+	if reply.Error != nil {
+		return &RPCError{Code: ErrorCode(reply.Error.Code), Message: reply.Error.Message}
+	}
+	if err := c.checkStatus(call.Method, reply.Result); err != nil {
+		return err
+	}
+	if call.Result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(reply.Result, call.Result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testRPCRequest struct {
+	Method string `json:"method"`
+	Params struct {
+		Height uint64 `json:"height"`
+	} `json:"params"`
+	ID uint64 `json:"id"`
+}
+
+func testReply(req testRPCRequest) map[string]interface{} {
+	reply := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+	switch {
+	case req.Params.Height == 99:
+		reply["error"] = map[string]interface{}{"code": -2, "message": "Requested block height: 99 greater than current top block height: 10"}
+	case req.Params.Height == 7:
+		reply["result"] = map[string]interface{}{"status": "BUSY"}
+	default:
+		reply["result"] = map[string]interface{}{
+			"block_header": map[string]interface{}{"height": req.Params.Height},
+			"status":       "OK",
+		}
+	}
+	return reply
+}
+
+func TestCallBatch(t *testing.T) {
+	for _, batches := range []bool{true, false} {
+		var posts int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&posts, 1)
+			body, _ := io.ReadAll(r.Body)
+			var reqs []testRPCRequest
+			if err := json.Unmarshal(body, &reqs); err == nil {
+				if !batches {
+					w.Write([]byte(`{"id":0,"jsonrpc":"2.0","error":{"code":-32700,"message":"Parse error"}}`))
+					return
+				}
+				var replies []interface{}
+				for i := len(reqs) - 1; i >= 0; i-- {
+					replies = append(replies, testReply(reqs[i]))
+				}
+				json.NewEncoder(w).Encode(replies)
+				return
+			}
+			var req testRPCRequest
+			assert.NoError(t, json.Unmarshal(body, &req))
+			json.NewEncoder(w).Encode(testReply(req))
+		}))
+
+		cl := New(Config{Address: srv.URL})
+		for round := 0; round < 2; round++ {
+			heights := []uint64{1, 2, 7, 99}
+			calls := make([]BatchCall, len(heights))
+			results := make([]ResponseGetBlockHeaderByHeight, len(heights))
+			for i, h := range heights {
+				calls[i] = BatchCall{
+					Method: "get_block_header_by_height",
+					Params: RequestGetBlockHeaderByHeight{Height: h},
+					Result: &results[i],
+				}
+			}
+			assert.NoError(t, cl.CallBatch(context.Background(), calls))
+			assert.NoError(t, calls[0].Error)
+			assert.Equal(t, uint64(1), results[0].BlockHeader.Height)
+			assert.NoError(t, calls[1].Error)
+			assert.Equal(t, uint64(2), results[1].BlockHeader.Height)
+			assert.True(t, errors.Is(calls[2].Error, ErrBusy), "got %v", calls[2].Error)
+			assert.True(t, errors.Is(calls[3].Error, ErrTooBigHeight), "got %v", calls[3].Error)
+		}
+		if batches {
+			assert.Equal(t, int32(2), posts)
+		} else {
+			assert.Equal(t, int32(1+4+4), posts)
+		}
+		srv.Close()
+	}
+}
+
+func TestCallBatchRejectsOtherEndpoints(t *testing.T) {
+	cl := New(Config{Address: "http://127.0.0.1:0"})
+	err := cl.CallBatch(context.Background(), []BatchCall{{Method: "/get_height"}})
+	assert.Error(t, err)
+}
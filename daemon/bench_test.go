@@ -0,0 +1,48 @@
+package daemon
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// The benchmarks compare the client's own transport with
+// http.DefaultTransport, which keeps only two idle connections per host.
+// Each op is a burst of concurrent calls, as a block scanner fetching a
+// range of heights would make. Run with:
+//
+//	go test -run '^$' -bench GetHeightBurst ./daemon
+const burst = 16
+
+func benchmarkGetHeightBurst(b *testing.B, transport http.RoundTripper) {
+	srv := heightServer()
+	conns := countConns(srv)
+	defer srv.Close()
+
+	cl := New(Config{Address: srv.URL, Transport: transport})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var wg sync.WaitGroup
+		for j := 0; j < burst; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := cl.GetHeight(); err != nil {
+					b.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+	b.ReportMetric(float64(atomic.LoadInt32(conns))/float64(b.N), "conns/op")
+}
+
+func BenchmarkGetHeightBurst(b *testing.B) {
+	benchmarkGetHeightBurst(b, nil)
+}
+
+func BenchmarkGetHeightBurstDefaultTransport(b *testing.B) {
+	benchmarkGetHeightBurst(b, http.DefaultTransport)
+}
@@ -0,0 +1,104 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/boomhut/go-monero-rpc-client/cryptonote"
+	"github.com/boomhut/go-monero-rpc-client/epee"
+)
+
+// Hash is a 32-byte hash or key in binary form, as the .bin endpoints send
+// them. It prints and marshals to JSON as hex, like the hashes of the JSON
+// endpoints.
+type Hash = cryptonote.Hash
+
+// ParseHash decodes a hash from 64 hex characters.
+func ParseHash(s string) (Hash, error) {
+	return cryptonote.ParseHash(s)
+}
+
+// callBinary sends req to a .bin endpoint encoded with epee, or an empty
+// section if req is nil, and decodes the epee response into res.
+func (c *client) callBinary(ctx context.Context, endpoint string, req, res interface{}) error {
+	if req == nil {
+		req = struct{}{}
+	}
+	data, err := epee.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.config.Address+endpoint, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/octet-stream")
+
+	body, err := c.send(httpReq)
+	if err != nil {
+		return err
+	}
+
+	// statusFields has json tags, which epee falls back to.
+	var fields statusFields
+	if err := epee.Unmarshal(body, &fields); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if err := c.checkFields(endpoint, fields, nil); err != nil {
+		return err
+	}
+
+	if err := epee.Unmarshal(body, res); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// MarshalEpee writes the transactions as blob strings unless the block is
+// pruned, like monerod.
+func (b BlockCompleteEntry) MarshalEpee() (interface{}, error) {
+	m := map[string]interface{}{"block": b.Block}
+	if b.Pruned {
+		m["pruned"] = true
+		m["block_weight"] = b.BlockWeight
+	}
+	if len(b.Txs) == 0 {
+		return m, nil
+	}
+	if b.Pruned {
+		m["txs"] = b.Txs
+		return m, nil
+	}
+	blobs := make([][]byte, len(b.Txs))
+	for i, tx := range b.Txs {
+		blobs[i] = tx.Blob
+	}
+	m["txs"] = blobs
+	return m, nil
+}
+
+// UnmarshalEpee reads transactions sent as blob strings or as sections.
+func (b *BlockCompleteEntry) UnmarshalEpee(v interface{}) error {
+	var entry struct {
+		Pruned      bool          `epee:"pruned"`
+		Block       []byte        `epee:"block"`
+		BlockWeight uint64        `epee:"block_weight"`
+		Txs         []interface{} `epee:"txs"`
+	}
+	if err := epee.Assign(&entry, v); err != nil {
+		return err
+	}
+	*b = BlockCompleteEntry{Pruned: entry.Pruned, Block: entry.Block, BlockWeight: entry.BlockWeight}
+	for _, tx := range entry.Txs {
+		var t TxBlobEntry
+		if s, ok := tx.(string); ok {
+			t.Blob = []byte(s)
+		} else if err := epee.Assign(&t, tx); err != nil {
+			return err
+		}
+		b.Txs = append(b.Txs, t)
+	}
+	return nil
+}
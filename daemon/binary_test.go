@@ -0,0 +1,96 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/boomhut/go-monero-rpc-client/epee"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHash(t *testing.T) {
+	s := "0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20"
+	h, err := ParseHash(s)
+	assert.NoError(t, err)
+	assert.Equal(t, byte(1), h[0])
+	assert.Equal(t, s, h.String())
+
+	text, err := h.MarshalText()
+	assert.NoError(t, err)
+	var back Hash
+	assert.NoError(t, back.UnmarshalText(text))
+	assert.Equal(t, h, back)
+
+	_, err = ParseHash(s[:62])
+	assert.Error(t, err)
+	_, err = ParseHash(s[:62] + "zz")
+	assert.Error(t, err)
+}
+
+func TestBinary(t *testing.T) {
+	var status string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/get_blocks_by_height.bin", r.URL.Path)
+		assert.Equal(t, "application/octet-stream", r.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(r.Body)
+		var req RequestGetBlocksByHeightBin
+		assert.NoError(t, epee.Unmarshal(body, &req))
+		assert.Equal(t, []uint64{7}, req.Heights)
+
+		res := ResponseGetBlocksByHeightBin{
+			Blocks: []BlockCompleteEntry{{Block: []byte{1, 2}, Txs: []TxBlobEntry{{Blob: []byte{3}}}}},
+			Status: status,
+		}
+		data, err := epee.Marshal(&res)
+		assert.NoError(t, err)
+		w.Write(data)
+	}))
+	defer srv.Close()
+	cl := New(Config{Address: srv.URL})
+
+	status = StatusOK
+	res, err := cl.GetBlocksByHeightBin([]uint64{7})
+	assert.NoError(t, err)
+	assert.Equal(t, []BlockCompleteEntry{{Block: []byte{1, 2}, Txs: []TxBlobEntry{{Blob: []byte{3}}}}}, res.Blocks)
+
+	status = StatusBusy
+	_, err = cl.GetBlocksByHeightBin([]uint64{7})
+	assert.True(t, errors.Is(err, ErrStatus), "got %v", err)
+
+	_, err = cl.CallOtherRaw(context.Background(), "/get_blocks_by_height.bin", nil)
+	assert.Error(t, err)
+}
+
+func TestBlockCompleteEntry(t *testing.T) {
+	for _, e := range []BlockCompleteEntry{
+		{Block: []byte{1}},
+		{Block: []byte{1}, Txs: []TxBlobEntry{{Blob: []byte{2}}, {Blob: []byte{3}}}},
+		{Pruned: true, Block: []byte{1}, BlockWeight: 300, Txs: []TxBlobEntry{{Blob: []byte{2}, PrunableHash: Hash{9}}}},
+	} {
+		data, err := epee.Marshal(map[string]interface{}{"blocks": []BlockCompleteEntry{e}})
+		assert.NoError(t, err)
+		var res ResponseGetBlocksByHeightBin
+		assert.NoError(t, epee.Unmarshal(data, &res))
+		assert.Equal(t, []BlockCompleteEntry{e}, res.Blocks)
+	}
+}
+
+func TestBinaryNoParams(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, append(append([]byte(nil), epee.Header...), 0), body)
+		data, err := epee.Marshal(&ResponseGetTransactionPoolHashesBin{TxHashes: []Hash{{1}, {2}}, Status: StatusOK})
+		assert.NoError(t, err)
+		w.Write(data)
+	}))
+	defer srv.Close()
+	cl := New(Config{Address: srv.URL})
+
+	res, err := cl.GetTransactionPoolHashesBin()
+	assert.NoError(t, err)
+	assert.Equal(t, []Hash{{1}, {2}}, res.TxHashes)
+}
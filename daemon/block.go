@@ -0,0 +1,51 @@
+package daemon
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/boomhut/go-monero-rpc-client/cryptonote"
+)
+
+// Block decodes the "json" field of the response.
+func (r *ResponseGetBlock) Block() (*cryptonote.Block, error) {
+	if r.JSON == "" {
+		return nil, fmt.Errorf("block %s has no JSON", r.BlockHeader.Hash)
+	}
+	var b cryptonote.Block
+	if err := json.Unmarshal([]byte(r.JSON), &b); err != nil {
+		return nil, fmt.Errorf("failed to decode block %s: %w", r.BlockHeader.Hash, err)
+	}
+	return &b, nil
+}
+
+// ParseBlob decodes the "blob" field of the response.
+func (r *ResponseGetBlock) ParseBlob() (*cryptonote.Block, error) {
+	blob, err := hex.DecodeString(r.Blob)
+	if err != nil {
+		return nil, fmt.Errorf("invalid blob of block %s: %w", r.BlockHeader.Hash, err)
+	}
+	b, err := cryptonote.ParseBlock(blob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse block %s: %w", r.BlockHeader.Hash, err)
+	}
+	return b, nil
+}
+
+// VerifyHash recomputes the block id from the blob and checks it against
+// the hash in the block header.
+func (r *ResponseGetBlock) VerifyHash() error {
+	b, err := r.ParseBlob()
+	if err != nil {
+		return err
+	}
+	id, err := b.Hash()
+	if err != nil {
+		return fmt.Errorf("failed to hash block %s: %w", r.BlockHeader.Hash, err)
+	}
+	if id.String() != r.BlockHeader.Hash {
+		return fmt.Errorf("%w: block %s hashes to %s", ErrBlockHashMismatch, r.BlockHeader.Hash, id)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The mainnet genesis block.
+var genesisBlob = "010000" + strings.Repeat("00", 32) + "10270000" +
+	"013c01ff0001ffffffffffff03029b2e4c0281c0b02e7c53291a94d1d0cbff8883f8024f5142ee494ffbbd08807121017767aafcde9be00dcfd098715ebcf7f410daebc582fda69d24a28e9d0bc890d1" +
+	"00"
+
+func TestResponseGetBlock(t *testing.T) {
+	res := ResponseGetBlock{
+		Blob:        genesisBlob,
+		BlockHeader: BlockHeader{Hash: "418015bb9ae982a1975da7d79277c2705727a56894ba0fb246adaabb1f4632e3"},
+	}
+	assert.NoError(t, res.VerifyHash())
+	parsed, err := res.ParseBlob()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(10000), parsed.Nonce)
+
+	raw, err := json.Marshal(parsed)
+	assert.NoError(t, err)
+	res.JSON = string(raw)
+	decoded, err := res.Block()
+	assert.NoError(t, err)
+	assert.Equal(t, parsed.MinerTx.Outputs, decoded.MinerTx.Outputs)
+
+	res.BlockHeader.Hash = strings.Repeat("00", 32)
+	assert.True(t, errors.Is(res.VerifyHash(), ErrBlockHashMismatch))
+	res.Blob = genesisBlob[:20]
+	assert.Error(t, res.VerifyHash())
+	res.JSON = ""
+	_, err = res.Block()
+	assert.Error(t, err)
+}
@@ -0,0 +1,73 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// CallRaw calls the JSON-RPC method with params and returns the undecoded
+// "result" member of the reply. It goes through the same interceptors,
+// retry policy, authentication, status check and error handling as the
+// typed methods, so it can be used for RPC methods (or fields) this package
+// does not support yet.
+func (c *client) CallRaw(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	if strings.HasPrefix(method, "/") {
+		return nil, fmt.Errorf("%s is not a JSON-RPC method, use CallOtherRaw", method)
+	}
+	var result json.RawMessage
+	if err := c.do(ctx, method, params, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CallOtherRaw is CallRaw for the endpoints outside /json_rpc, such as
+// /get_height. The request is a POST of params as JSON, or a GET if params
+// is nil, and the whole response body is returned.
+func (c *client) CallOtherRaw(ctx context.Context, endpoint string, params interface{}) (json.RawMessage, error) {
+	if !strings.HasPrefix(endpoint, "/") {
+		endpoint = "/" + endpoint
+	}
+	if strings.HasSuffix(endpoint, ".bin") {
+		return nil, fmt.Errorf("%s is a binary endpoint and cannot return JSON", endpoint)
+	}
+	var result json.RawMessage
+	if err := c.doOther(ctx, endpoint, params, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Call calls the JSON-RPC method on c with params and decodes the result
+// into a T.
+//
+//	res, err := daemon.Call[daemon.ResponseGetBlockCount](ctx, client, "get_block_count", nil)
+func Call[T any](ctx context.Context, c Client, method string, params interface{}) (T, error) {
+	var res T
+	raw, err := c.CallRaw(ctx, method, params)
+	if err != nil {
+		return res, err
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return res, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return res, nil
+}
+
+// CallOther calls endpoint on c with params and decodes the response into
+// a T.
+//
+//	res, err := daemon.CallOther[daemon.ResponseGetHeight](ctx, client, "/get_height", nil)
+func CallOther[T any](ctx context.Context, c Client, endpoint string, params interface{}) (T, error) {
+	var res T
+	raw, err := c.CallOtherRaw(ctx, endpoint, params)
+	if err != nil {
+		return res, err
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return res, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,64 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCall(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/json_rpc":
+			var req struct {
+				Method string `json:"method"`
+			}
+			assert.NoError(t, json.Unmarshal(body, &req))
+			switch req.Method {
+			case "get_block_count":
+				w.Write([]byte(`{"id":0,"jsonrpc":"2.0","result":{"count":9,"status":"OK"}}`))
+			case "flush_txpool":
+				w.Write([]byte(`{"id":0,"jsonrpc":"2.0","result":{"status":"Failed"}}`))
+			}
+		case "/get_height":
+			assert.Equal(t, http.MethodGet, r.Method)
+			w.Write([]byte(`{"hash":"aa","height":9,"status":"OK"}`))
+		case "/get_limit":
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.JSONEq(t, `{"x":1}`, string(body))
+			w.Write([]byte(`{"limit_down":8192,"limit_up":2048,"status":"OK"}`))
+		}
+	}))
+	defer srv.Close()
+	cl := New(Config{Address: srv.URL})
+	ctx := context.Background()
+
+	raw, err := cl.CallRaw(ctx, "get_block_count", nil)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"count":9,"status":"OK"}`, string(raw))
+
+	count, err := Call[ResponseGetBlockCount](ctx, cl, "get_block_count", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(9), count.Count)
+
+	_, err = cl.CallRaw(ctx, "flush_txpool", nil)
+	assert.True(t, errors.Is(err, ErrStatus), "got %v", err)
+
+	height, err := CallOther[ResponseGetHeight](ctx, cl, "get_height", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(9), height.Height)
+
+	raw, err = cl.CallOtherRaw(ctx, "/get_limit", map[string]int{"x": 1})
+	assert.NoError(t, err)
+	assert.Contains(t, string(raw), `"limit_down":8192`)
+
+	_, err = cl.CallRaw(ctx, "/get_height", nil)
+	assert.Error(t, err)
+}
@@ -2,11 +2,18 @@ package daemon
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/boomhut/go-monero-rpc-client/internal/batch"
+	"github.com/boomhut/go-monero-rpc-client/internal/digest"
+	"github.com/boomhut/go-monero-rpc-client/internal/retry"
 	"github.com/gorilla/rpc/v2/json2"
 )
 
@@ -14,125 +21,280 @@ import (
 type Client interface {
 	// JSON-RPC Methods
 	GetBlockCount() (*ResponseGetBlockCount, error)
+	GetBlockCountContext(ctx context.Context) (*ResponseGetBlockCount, error)
 	OnGetBlockHash(height uint64) (string, error)
+	OnGetBlockHashContext(ctx context.Context, height uint64) (string, error)
 	GetBlockTemplate(walletAddress string, reserveSize uint64) (*ResponseGetBlockTemplate, error)
+	GetBlockTemplateContext(ctx context.Context, walletAddress string, reserveSize uint64) (*ResponseGetBlockTemplate, error)
 	SubmitBlock(blockBlob string) (*ResponseSubmitBlock, error)
+	SubmitBlockContext(ctx context.Context, blockBlob string) (*ResponseSubmitBlock, error)
 	GenerateBlocks(amountOfBlocks uint64, walletAddress string) (*ResponseGenerateBlocks, error)
+	GenerateBlocksContext(ctx context.Context, amountOfBlocks uint64, walletAddress string) (*ResponseGenerateBlocks, error)
 	GetLastBlockHeader() (*ResponseGetLastBlockHeader, error)
+	GetLastBlockHeaderContext(ctx context.Context) (*ResponseGetLastBlockHeader, error)
 	GetBlockHeaderByHash(hash string, fillPowHash bool) (*ResponseGetBlockHeaderByHash, error)
+	GetBlockHeaderByHashContext(ctx context.Context, hash string, fillPowHash bool) (*ResponseGetBlockHeaderByHash, error)
 	GetBlockHeaderByHeight(height uint64, fillPowHash bool) (*ResponseGetBlockHeaderByHeight, error)
+	GetBlockHeaderByHeightContext(ctx context.Context, height uint64, fillPowHash bool) (*ResponseGetBlockHeaderByHeight, error)
 	GetBlockHeadersRange(startHeight, endHeight uint64, fillPowHash bool) (*ResponseGetBlockHeadersRange, error)
+	GetBlockHeadersRangeContext(ctx context.Context, startHeight, endHeight uint64, fillPowHash bool) (*ResponseGetBlockHeadersRange, error)
 	GetBlock(hashOrHeight interface{}, fillPowHash bool) (*ResponseGetBlock, error)
+	GetBlockContext(ctx context.Context, hashOrHeight interface{}, fillPowHash bool) (*ResponseGetBlock, error)
 	GetConnections() (*ResponseGetConnections, error)
+	GetConnectionsContext(ctx context.Context) (*ResponseGetConnections, error)
 	GetInfo() (*ResponseGetInfo, error)
+	GetInfoContext(ctx context.Context) (*ResponseGetInfo, error)
 	HardForkInfo() (*ResponseHardForkInfo, error)
+	HardForkInfoContext(ctx context.Context) (*ResponseHardForkInfo, error)
 	SetBans(bans []BanRequest) (*ResponseSetBans, error)
+	SetBansContext(ctx context.Context, bans []BanRequest) (*ResponseSetBans, error)
 	GetBans() (*ResponseGetBans, error)
+	GetBansContext(ctx context.Context) (*ResponseGetBans, error)
 	Banned(address string) (*ResponseBanned, error)
+	BannedContext(ctx context.Context, address string) (*ResponseBanned, error)
 	FlushTxpool(txIDs []string) (*ResponseFlushTxpool, error)
+	FlushTxpoolContext(ctx context.Context, txIDs []string) (*ResponseFlushTxpool, error)
 	GetOutputHistogram(amounts []uint64, minCount, maxCount uint64, unlocked bool, recentCutoff uint64) (*ResponseGetOutputHistogram, error)
+	GetOutputHistogramContext(ctx context.Context, amounts []uint64, minCount, maxCount uint64, unlocked bool, recentCutoff uint64) (*ResponseGetOutputHistogram, error)
 	GetCoinbaseTxSum(height, count uint64) (*ResponseGetCoinbaseTxSum, error)
+	GetCoinbaseTxSumContext(ctx context.Context, height, count uint64) (*ResponseGetCoinbaseTxSum, error)
 	GetVersion() (*ResponseGetVersion, error)
+	GetVersionContext(ctx context.Context) (*ResponseGetVersion, error)
 	GetFeeEstimate(graceBlocks uint64) (*ResponseGetFeeEstimate, error)
+	GetFeeEstimateContext(ctx context.Context, graceBlocks uint64) (*ResponseGetFeeEstimate, error)
 	GetAlternateChains() (*ResponseGetAlternateChains, error)
+	GetAlternateChainsContext(ctx context.Context) (*ResponseGetAlternateChains, error)
 	RelayTx(txIDs []string) (*ResponseRelayTx, error)
+	RelayTxContext(ctx context.Context, txIDs []string) (*ResponseRelayTx, error)
 	SyncInfo() (*ResponseSyncInfo, error)
+	SyncInfoContext(ctx context.Context) (*ResponseSyncInfo, error)
 	GetTxpoolBacklog() (*ResponseGetTxpoolBacklog, error)
+	GetTxpoolBacklogContext(ctx context.Context) (*ResponseGetTxpoolBacklog, error)
 	GetOutputDistribution(amounts []uint64, cumulative bool, fromHeight, toHeight uint64) (*ResponseGetOutputDistribution, error)
+	GetOutputDistributionContext(ctx context.Context, amounts []uint64, cumulative bool, fromHeight, toHeight uint64) (*ResponseGetOutputDistribution, error)
 	GetMinerData() (*ResponseGetMinerData, error)
+	GetMinerDataContext(ctx context.Context) (*ResponseGetMinerData, error)
 	PruneBlockchain(check bool) (*ResponsePruneBlockchain, error)
+	PruneBlockchainContext(ctx context.Context, check bool) (*ResponsePruneBlockchain, error)
 	CalcPow(majorVersion, height uint64, blockBlob, seedHash string) (*ResponseCalcPow, error)
+	CalcPowContext(ctx context.Context, majorVersion, height uint64, blockBlob, seedHash string) (*ResponseCalcPow, error)
 	FlushCache(badTxs, badBlocks bool) (*ResponseFlushCache, error)
+	FlushCacheContext(ctx context.Context, badTxs, badBlocks bool) (*ResponseFlushCache, error)
 	AddAuxPow(blocktemplateBlob string, auxPow []AuxPow) (*ResponseAddAuxPow, error)
+	AddAuxPowContext(ctx context.Context, blocktemplateBlob string, auxPow []AuxPow) (*ResponseAddAuxPow, error)
 
 	// Other RPC Methods
 	GetHeight() (*ResponseGetHeight, error)
+	GetHeightContext(ctx context.Context) (*ResponseGetHeight, error)
 	GetTransactions(txHashes []string, decodeAsJSON, prune, split bool) (*ResponseGetTransactions, error)
+	GetTransactionsContext(ctx context.Context, txHashes []string, decodeAsJSON, prune, split bool) (*ResponseGetTransactions, error)
 	GetAltBlocksHashes() (*ResponseGetAltBlocksHashes, error)
+	GetAltBlocksHashesContext(ctx context.Context) (*ResponseGetAltBlocksHashes, error)
 	IsKeyImageSpent(keyImages []string) (*ResponseIsKeyImageSpent, error)
+	IsKeyImageSpentContext(ctx context.Context, keyImages []string) (*ResponseIsKeyImageSpent, error)
 	SendRawTransaction(txAsHex string, doNotRelay bool) (*ResponseSendRawTransaction, error)
+	SendRawTransactionContext(ctx context.Context, txAsHex string, doNotRelay bool) (*ResponseSendRawTransaction, error)
 	StartMining(minerAddress string, threadsCount uint64, doBackgroundMining, ignoreBattery bool) (*ResponseStartMining, error)
+	StartMiningContext(ctx context.Context, minerAddress string, threadsCount uint64, doBackgroundMining, ignoreBattery bool) (*ResponseStartMining, error)
 	StopMining() (*ResponseStopMining, error)
+	StopMiningContext(ctx context.Context) (*ResponseStopMining, error)
 	MiningStatus() (*ResponseMiningStatus, error)
+	MiningStatusContext(ctx context.Context) (*ResponseMiningStatus, error)
 	SaveBC() (*ResponseSaveBC, error)
+	SaveBCContext(ctx context.Context) (*ResponseSaveBC, error)
 	GetPeerList() (*ResponseGetPeerList, error)
+	GetPeerListContext(ctx context.Context) (*ResponseGetPeerList, error)
 	GetPublicNodes(gray, white, includeBlocked bool) (*ResponseGetPublicNodes, error)
+	GetPublicNodesContext(ctx context.Context, gray, white, includeBlocked bool) (*ResponseGetPublicNodes, error)
 	SetLogHashRate(visible bool) (*ResponseSetLogHashRate, error)
+	SetLogHashRateContext(ctx context.Context, visible bool) (*ResponseSetLogHashRate, error)
 	SetLogLevel(level uint64) (*ResponseSetLogLevel, error)
+	SetLogLevelContext(ctx context.Context, level uint64) (*ResponseSetLogLevel, error)
 	SetLogCategories(categories string) (*ResponseSetLogCategories, error)
+	SetLogCategoriesContext(ctx context.Context, categories string) (*ResponseSetLogCategories, error)
 	SetBootstrapDaemon(address, username, password, proxy string) (*ResponseSetBootstrapDaemon, error)
+	SetBootstrapDaemonContext(ctx context.Context, address, username, password, proxy string) (*ResponseSetBootstrapDaemon, error)
 	GetTransactionPool() (*ResponseGetTransactionPool, error)
+	GetTransactionPoolContext(ctx context.Context) (*ResponseGetTransactionPool, error)
 	GetTransactionPoolHashes() (*ResponseGetTransactionPoolHashes, error)
+	GetTransactionPoolHashesContext(ctx context.Context) (*ResponseGetTransactionPoolHashes, error)
 	GetTransactionPoolStats() (*ResponseGetTransactionPoolStats, error)
+	GetTransactionPoolStatsContext(ctx context.Context) (*ResponseGetTransactionPoolStats, error)
 	StopDaemon() (*ResponseStopDaemon, error)
+	StopDaemonContext(ctx context.Context) (*ResponseStopDaemon, error)
 	GetLimit() (*ResponseGetLimit, error)
+	GetLimitContext(ctx context.Context) (*ResponseGetLimit, error)
 	SetLimit(limitDown, limitUp int64) (*ResponseSetLimit, error)
+	SetLimitContext(ctx context.Context, limitDown, limitUp int64) (*ResponseSetLimit, error)
 	OutPeers(outPeers uint64) (*ResponseOutPeers, error)
+	OutPeersContext(ctx context.Context, outPeers uint64) (*ResponseOutPeers, error)
 	InPeers(inPeers uint64) (*ResponseInPeers, error)
+	InPeersContext(ctx context.Context, inPeers uint64) (*ResponseInPeers, error)
 	GetNetStats() (*ResponseGetNetStats, error)
+	GetNetStatsContext(ctx context.Context) (*ResponseGetNetStats, error)
 	GetOuts(outputs []OutputIndex, getTxID bool) (*ResponseGetOuts, error)
+	GetOutsContext(ctx context.Context, outputs []OutputIndex, getTxID bool) (*ResponseGetOuts, error)
 	Update(command, path string) (*ResponseUpdate, error)
+	UpdateContext(ctx context.Context, command, path string) (*ResponseUpdate, error)
 	PopBlocks(nBlocks uint64) (*ResponsePopBlocks, error)
+	PopBlocksContext(ctx context.Context, nBlocks uint64) (*ResponsePopBlocks, error)
+
+	// Binary Endpoints
+	GetBlocksBin(req *RequestGetBlocksBin) (*ResponseGetBlocksBin, error)
+	GetBlocksBinContext(ctx context.Context, req *RequestGetBlocksBin) (*ResponseGetBlocksBin, error)
+	GetBlocksByHeightBin(heights []uint64) (*ResponseGetBlocksByHeightBin, error)
+	GetBlocksByHeightBinContext(ctx context.Context, heights []uint64) (*ResponseGetBlocksByHeightBin, error)
+	GetOutsBin(outputs []OutputIndex, getTxID bool) (*ResponseGetOutsBin, error)
+	GetOutsBinContext(ctx context.Context, outputs []OutputIndex, getTxID bool) (*ResponseGetOutsBin, error)
+	GetOIndexesBin(txid Hash) (*ResponseGetOIndexesBin, error)
+	GetOIndexesBinContext(ctx context.Context, txid Hash) (*ResponseGetOIndexesBin, error)
+	GetHashesBin(blockIDs []Hash, startHeight uint64) (*ResponseGetHashesBin, error)
+	GetHashesBinContext(ctx context.Context, blockIDs []Hash, startHeight uint64) (*ResponseGetHashesBin, error)
+	GetTransactionPoolHashesBin() (*ResponseGetTransactionPoolHashesBin, error)
+	GetTransactionPoolHashesBinContext(ctx context.Context) (*ResponseGetTransactionPoolHashesBin, error)
+	GetOutputDistributionBin(amounts []uint64, cumulative bool, fromHeight, toHeight uint64, compress bool) (*ResponseGetOutputDistribution, error)
+	GetOutputDistributionBinContext(ctx context.Context, amounts []uint64, cumulative bool, fromHeight, toHeight uint64, compress bool) (*ResponseGetOutputDistribution, error)
+
+	// Batch Requests
+	CallBatch(ctx context.Context, calls []BatchCall) error
+
+	// Raw Calls
+	CallRaw(ctx context.Context, method string, params interface{}) (json.RawMessage, error)
+	CallOtherRaw(ctx context.Context, endpoint string, params interface{}) (json.RawMessage, error)
 }
 
 type client struct {
-	config Config
+	config     Config
+	transport  http.RoundTripper
+	httpClient *http.Client
+	invoke     Invoker
+	batch      batch.State
 }
 
 // New creates a new Monero daemon RPC client
 func New(config Config) Client {
-	return &client{
-		config: config,
+	transport := config.Transport
+	if transport == nil {
+		transport = newTransport(config)
+	}
+	if config.Username != "" {
+		transport = digest.NewTransport(config.Username, config.Password, transport)
+	}
+	return newClient(config, transport)
+}
+
+func newClient(config Config, transport http.RoundTripper) *client {
+	c := &client{
+		config:    config,
+		transport: transport,
+		httpClient: &http.Client{
+			Transport: transport,
+			Timeout:   config.Timeout,
+		},
+	}
+	interceptors := config.Interceptors
+	if config.Tracer != nil || config.Metrics != nil {
+		interceptors = append(interceptors[:len(interceptors):len(interceptors)], instrumentInterceptor(config))
+	}
+	c.invoke = chainInterceptors(interceptors, c.call)
+	return c
+}
+
+// newTransport returns a keep-alive transport sized for many concurrent
+// calls to the same daemon. http.DefaultTransport keeps only two idle
+// connections per host, so parallel scanners would keep reconnecting.
+func newTransport(config Config) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConns = 100
+	t.MaxIdleConnsPerHost = 32
+	t.IdleConnTimeout = 90 * time.Second
+	if config.MaxIdleConns > 0 {
+		t.MaxIdleConns = config.MaxIdleConns
+	}
+	if config.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = config.MaxIdleConnsPerHost
 	}
+	if config.IdleConnTimeout > 0 {
+		t.IdleConnTimeout = config.IdleConnTimeout
+	}
+	return t
 }
 
 // Helper method for JSON-RPC calls
-func (c *client) do(method string, req, res interface{}) error {
+func (c *client) do(ctx context.Context, method string, req, res interface{}) error {
+	return c.invoke(ctx, method, req, res)
+}
+
+// Helper method for non JSON-RPC calls
+func (c *client) doOther(ctx context.Context, endpoint string, req, res interface{}) error {
+	return c.invoke(ctx, endpoint, req, res)
+}
+
+// call is the innermost Invoker. Methods starting with a slash are sent to
+// that endpoint as plain JSON, or as epee if they end in ".bin", anything
+// else goes through /json_rpc.
+// Failed attempts are retried according to Config.Retry.
+func (c *client) call(ctx context.Context, method string, req, res interface{}) error {
+	if calls, ok := req.([]BatchCall); ok && method == BatchMethod {
+		return c.callBatch(ctx, calls)
+	}
+	ctx = withResend(ctx, c.config.Retry.repeatable(method))
+	attempts := c.config.Retry.attempts(method)
+	for attempt := 1; ; attempt++ {
+		var err error
+		if strings.HasPrefix(method, "/") && strings.HasSuffix(method, ".bin") {
+			err = c.callBinary(ctx, method, req, res)
+		} else if strings.HasPrefix(method, "/") {
+			err = c.callOther(ctx, method, req, res)
+		} else {
+			err = c.callJSONRPC(ctx, method, req, res)
+		}
+		if err == nil || attempt >= attempts || !c.config.Retry.retryable(err) {
+			return err
+		}
+		if err := retry.Sleep(ctx, c.config.Retry.backoff(attempt)); err != nil {
+			return fmt.Errorf("request aborted: %w", err)
+		}
+	}
+}
+
+func (c *client) callJSONRPC(ctx context.Context, method string, req, res interface{}) error {
 	message, err := json2.EncodeClientRequest(method, req)
 	if err != nil {
 		return fmt.Errorf("failed to encode request: %w", err)
 	}
 
-	httpReq, err := http.NewRequest("POST", c.config.Address+"/json_rpc", bytes.NewReader(message))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.config.Address+"/json_rpc", bytes.NewReader(message))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	httpReq.Header.Set("Content-Type", "application/json")
-	for key, value := range c.config.CustomHeaders {
-		httpReq.Header.Set(key, value)
-	}
-
-	transport := c.config.Transport
-	if transport == nil {
-		transport = http.DefaultTransport
-	}
-
-	httpClient := &http.Client{Transport: transport}
-	httpRes, err := httpClient.Do(httpReq)
+	body, err := c.send(httpReq)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return err
 	}
-	defer httpRes.Body.Close()
 
-	body, err := io.ReadAll(httpRes.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response: %w", err)
+	var result json.RawMessage
+	if err := json2.DecodeClientResponse(bytes.NewReader(body), &result); err != nil {
+		var jerr *json2.Error
+		if errors.As(err, &jerr) {
+			return &RPCError{Code: ErrorCode(jerr.Code), Message: jerr.Message}
+		}
+		return fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	if httpRes.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d, body: %s", httpRes.StatusCode, string(body))
+	if err := c.checkStatus(method, result); err != nil {
+		return err
 	}
 
-	if err := json2.DecodeClientResponse(bytes.NewReader(body), res); err != nil {
+	if err := json.Unmarshal(result, res); err != nil {
 		return fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	return nil
 }
 
-// Helper method for non JSON-RPC calls
-func (c *client) doOther(endpoint string, req, res interface{}) error {
+func (c *client) callOther(ctx context.Context, endpoint string, req, res interface{}) error {
 	var httpReq *http.Request
 	var err error
 
@@ -141,146 +303,245 @@ func (c *client) doOther(endpoint string, req, res interface{}) error {
 		if marshalErr != nil {
 			return fmt.Errorf("failed to marshal request: %w", marshalErr)
 		}
-		httpReq, err = http.NewRequest("POST", c.config.Address+endpoint, bytes.NewReader(data))
+		httpReq, err = http.NewRequestWithContext(ctx, "POST", c.config.Address+endpoint, bytes.NewReader(data))
 	} else {
-		httpReq, err = http.NewRequest("GET", c.config.Address+endpoint, nil)
+		httpReq, err = http.NewRequestWithContext(ctx, "GET", c.config.Address+endpoint, nil)
 	}
 
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	httpReq.Header.Set("Content-Type", "application/json")
-	for key, value := range c.config.CustomHeaders {
-		httpReq.Header.Set(key, value)
+	body, err := c.send(httpReq)
+	if err != nil {
+		return err
 	}
 
-	transport := c.config.Transport
-	if transport == nil {
-		transport = http.DefaultTransport
+	if err := c.checkStatus(endpoint, body); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, res); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// statusFields are the fields monerod includes in most responses.
+type statusFields struct {
+	Status    string `json:"status"`
+	Untrusted bool   `json:"untrusted"`
+	Reason    string `json:"reason"`
+}
+
+// checkStatus turns a "status" other than "OK" into a *StatusError and,
+// if Config.RejectUntrusted is set, an untrusted response into
+// ErrUntrusted. Results that are not JSON objects or carry no status
+// (e.g. on_get_block_hash, calc_pow) are accepted as-is.
+func (c *client) checkStatus(method string, raw []byte) error {
+	var fields statusFields
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil
+	}
+	return c.checkFields(method, fields, raw)
+}
+
+// checkFields is checkStatus for already decoded fields. raw is kept in
+// the StatusError and is nil for binary responses.
+func (c *client) checkFields(method string, fields statusFields, raw []byte) error {
+	if fields.Status != "" && fields.Status != StatusOK {
+		return &StatusError{
+			Method:   method,
+			Status:   fields.Status,
+			Reason:   fields.Reason,
+			Response: raw,
+		}
+	}
+	if fields.Untrusted && c.config.RejectUntrusted {
+		return fmt.Errorf("%s: %w", method, ErrUntrusted)
+	}
+	return nil
+}
+
+// send performs the HTTP round trip shared by callJSONRPC, callOther and
+// callBinary and returns the raw response body. If the request context is
+// canceled or its deadline expires, the returned error wraps ctx.Err() so
+// callers can test for it with errors.Is(err, context.Canceled) or
+// errors.Is(err, context.DeadlineExceeded).
+func (c *client) send(httpReq *http.Request) ([]byte, error) {
+	if httpReq.Header.Get("Content-Type") == "" {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	for key, value := range c.config.CustomHeaders {
+		httpReq.Header.Set(key, value)
 	}
 
-	httpClient := &http.Client{Transport: transport}
-	httpRes, err := httpClient.Do(httpReq)
+	httpRes, err := c.httpClient.Do(httpReq)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		if ctxErr := httpReq.Context().Err(); ctxErr != nil {
+			return nil, fmt.Errorf("request aborted: %w", ctxErr)
+		}
+		return nil, &TransportError{Err: err}
 	}
 	defer httpRes.Body.Close()
 
 	body, err := io.ReadAll(httpRes.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read response: %w", err)
+		if ctxErr := httpReq.Context().Err(); ctxErr != nil {
+			return nil, fmt.Errorf("request aborted: %w", ctxErr)
+		}
+		return nil, &TransportError{Err: fmt.Errorf("failed to read response: %w", err)}
 	}
 
 	if httpRes.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d, body: %s", httpRes.StatusCode, string(body))
-	}
-
-	if err := json.Unmarshal(body, res); err != nil {
-		return fmt.Errorf("failed to decode response: %w", err)
+		if len(body) > 64<<10 {
+			body = body[:64<<10]
+		}
+		return nil, &HTTPStatusError{StatusCode: httpRes.StatusCode, Body: body}
 	}
 
-	return nil
+	return body, nil
 }
 
 // JSON-RPC Method Implementations
 
 func (c *client) GetBlockCount() (*ResponseGetBlockCount, error) {
+	return c.GetBlockCountContext(context.Background())
+}
+
+func (c *client) GetBlockCountContext(ctx context.Context) (*ResponseGetBlockCount, error) {
 	var res ResponseGetBlockCount
-	if err := c.do("get_block_count", nil, &res); err != nil {
+	if err := c.do(ctx, "get_block_count", nil, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) OnGetBlockHash(height uint64) (string, error) {
+	return c.OnGetBlockHashContext(context.Background(), height)
+}
+
+func (c *client) OnGetBlockHashContext(ctx context.Context, height uint64) (string, error) {
 	req := RequestOnGetBlockHash{height}
 	var res ResponseOnGetBlockHash
-	if err := c.do("on_get_block_hash", req, &res); err != nil {
+	if err := c.do(ctx, "on_get_block_hash", req, &res); err != nil {
 		return "", err
 	}
 	return string(res), nil
 }
 
 func (c *client) GetBlockTemplate(walletAddress string, reserveSize uint64) (*ResponseGetBlockTemplate, error) {
+	return c.GetBlockTemplateContext(context.Background(), walletAddress, reserveSize)
+}
+
+func (c *client) GetBlockTemplateContext(ctx context.Context, walletAddress string, reserveSize uint64) (*ResponseGetBlockTemplate, error) {
 	req := &RequestGetBlockTemplate{
 		WalletAddress: walletAddress,
 		ReserveSize:   reserveSize,
 	}
 	var res ResponseGetBlockTemplate
-	if err := c.do("get_block_template", req, &res); err != nil {
+	if err := c.do(ctx, "get_block_template", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) SubmitBlock(blockBlob string) (*ResponseSubmitBlock, error) {
+	return c.SubmitBlockContext(context.Background(), blockBlob)
+}
+
+func (c *client) SubmitBlockContext(ctx context.Context, blockBlob string) (*ResponseSubmitBlock, error) {
 	req := RequestSubmitBlock{blockBlob}
 	var res ResponseSubmitBlock
-	if err := c.do("submit_block", req, &res); err != nil {
+	if err := c.do(ctx, "submit_block", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) GenerateBlocks(amountOfBlocks uint64, walletAddress string) (*ResponseGenerateBlocks, error) {
+	return c.GenerateBlocksContext(context.Background(), amountOfBlocks, walletAddress)
+}
+
+func (c *client) GenerateBlocksContext(ctx context.Context, amountOfBlocks uint64, walletAddress string) (*ResponseGenerateBlocks, error) {
 	req := &RequestGenerateBlocks{
 		AmountOfBlocks: amountOfBlocks,
 		WalletAddress:  walletAddress,
 	}
 	var res ResponseGenerateBlocks
-	if err := c.do("generateblocks", req, &res); err != nil {
+	if err := c.do(ctx, "generateblocks", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) GetLastBlockHeader() (*ResponseGetLastBlockHeader, error) {
+	return c.GetLastBlockHeaderContext(context.Background())
+}
+
+func (c *client) GetLastBlockHeaderContext(ctx context.Context) (*ResponseGetLastBlockHeader, error) {
 	var res ResponseGetLastBlockHeader
-	if err := c.do("get_last_block_header", nil, &res); err != nil {
+	if err := c.do(ctx, "get_last_block_header", nil, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) GetBlockHeaderByHash(hash string, fillPowHash bool) (*ResponseGetBlockHeaderByHash, error) {
+	return c.GetBlockHeaderByHashContext(context.Background(), hash, fillPowHash)
+}
+
+func (c *client) GetBlockHeaderByHashContext(ctx context.Context, hash string, fillPowHash bool) (*ResponseGetBlockHeaderByHash, error) {
 	req := &RequestGetBlockHeaderByHash{
 		Hash:        hash,
 		FillPowHash: fillPowHash,
 	}
 	var res ResponseGetBlockHeaderByHash
-	if err := c.do("get_block_header_by_hash", req, &res); err != nil {
+	if err := c.do(ctx, "get_block_header_by_hash", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) GetBlockHeaderByHeight(height uint64, fillPowHash bool) (*ResponseGetBlockHeaderByHeight, error) {
+	return c.GetBlockHeaderByHeightContext(context.Background(), height, fillPowHash)
+}
+
+func (c *client) GetBlockHeaderByHeightContext(ctx context.Context, height uint64, fillPowHash bool) (*ResponseGetBlockHeaderByHeight, error) {
 	req := &RequestGetBlockHeaderByHeight{
 		Height:      height,
 		FillPowHash: fillPowHash,
 	}
 	var res ResponseGetBlockHeaderByHeight
-	if err := c.do("get_block_header_by_height", req, &res); err != nil {
+	if err := c.do(ctx, "get_block_header_by_height", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) GetBlockHeadersRange(startHeight, endHeight uint64, fillPowHash bool) (*ResponseGetBlockHeadersRange, error) {
+	return c.GetBlockHeadersRangeContext(context.Background(), startHeight, endHeight, fillPowHash)
+}
+
+func (c *client) GetBlockHeadersRangeContext(ctx context.Context, startHeight, endHeight uint64, fillPowHash bool) (*ResponseGetBlockHeadersRange, error) {
 	req := &RequestGetBlockHeadersRange{
 		StartHeight: startHeight,
 		EndHeight:   endHeight,
 		FillPowHash: fillPowHash,
 	}
 	var res ResponseGetBlockHeadersRange
-	if err := c.do("get_block_headers_range", req, &res); err != nil {
+	if err := c.do(ctx, "get_block_headers_range", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) GetBlock(hashOrHeight interface{}, fillPowHash bool) (*ResponseGetBlock, error) {
+	return c.GetBlockContext(context.Background(), hashOrHeight, fillPowHash)
+}
+
+func (c *client) GetBlockContext(ctx context.Context, hashOrHeight interface{}, fillPowHash bool) (*ResponseGetBlock, error) {
 	req := &RequestGetBlock{
 		FillPowHash: fillPowHash,
 	}
@@ -297,72 +558,104 @@ func (c *client) GetBlock(hashOrHeight interface{}, fillPowHash bool) (*Response
 	}
 
 	var res ResponseGetBlock
-	if err := c.do("get_block", req, &res); err != nil {
+	if err := c.do(ctx, "get_block", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) GetConnections() (*ResponseGetConnections, error) {
+	return c.GetConnectionsContext(context.Background())
+}
+
+func (c *client) GetConnectionsContext(ctx context.Context) (*ResponseGetConnections, error) {
 	var res ResponseGetConnections
-	if err := c.do("get_connections", nil, &res); err != nil {
+	if err := c.do(ctx, "get_connections", nil, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) GetInfo() (*ResponseGetInfo, error) {
+	return c.GetInfoContext(context.Background())
+}
+
+func (c *client) GetInfoContext(ctx context.Context) (*ResponseGetInfo, error) {
 	var res ResponseGetInfo
-	if err := c.do("get_info", nil, &res); err != nil {
+	if err := c.do(ctx, "get_info", nil, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) HardForkInfo() (*ResponseHardForkInfo, error) {
+	return c.HardForkInfoContext(context.Background())
+}
+
+func (c *client) HardForkInfoContext(ctx context.Context) (*ResponseHardForkInfo, error) {
 	var res ResponseHardForkInfo
-	if err := c.do("hard_fork_info", nil, &res); err != nil {
+	if err := c.do(ctx, "hard_fork_info", nil, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) SetBans(bans []BanRequest) (*ResponseSetBans, error) {
+	return c.SetBansContext(context.Background(), bans)
+}
+
+func (c *client) SetBansContext(ctx context.Context, bans []BanRequest) (*ResponseSetBans, error) {
 	req := &RequestSetBans{Bans: bans}
 	var res ResponseSetBans
-	if err := c.do("set_bans", req, &res); err != nil {
+	if err := c.do(ctx, "set_bans", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) GetBans() (*ResponseGetBans, error) {
+	return c.GetBansContext(context.Background())
+}
+
+func (c *client) GetBansContext(ctx context.Context) (*ResponseGetBans, error) {
 	var res ResponseGetBans
-	if err := c.do("get_bans", nil, &res); err != nil {
+	if err := c.do(ctx, "get_bans", nil, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) Banned(address string) (*ResponseBanned, error) {
+	return c.BannedContext(context.Background(), address)
+}
+
+func (c *client) BannedContext(ctx context.Context, address string) (*ResponseBanned, error) {
 	req := &RequestBanned{Address: address}
 	var res ResponseBanned
-	if err := c.do("banned", req, &res); err != nil {
+	if err := c.do(ctx, "banned", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) FlushTxpool(txIDs []string) (*ResponseFlushTxpool, error) {
+	return c.FlushTxpoolContext(context.Background(), txIDs)
+}
+
+func (c *client) FlushTxpoolContext(ctx context.Context, txIDs []string) (*ResponseFlushTxpool, error) {
 	req := &RequestFlushTxpool{TxIDs: txIDs}
 	var res ResponseFlushTxpool
-	if err := c.do("flush_txpool", req, &res); err != nil {
+	if err := c.do(ctx, "flush_txpool", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) GetOutputHistogram(amounts []uint64, minCount, maxCount uint64, unlocked bool, recentCutoff uint64) (*ResponseGetOutputHistogram, error) {
+	return c.GetOutputHistogramContext(context.Background(), amounts, minCount, maxCount, unlocked, recentCutoff)
+}
+
+func (c *client) GetOutputHistogramContext(ctx context.Context, amounts []uint64, minCount, maxCount uint64, unlocked bool, recentCutoff uint64) (*ResponseGetOutputHistogram, error) {
 	req := &RequestGetOutputHistogram{
 		Amounts:      amounts,
 		MinCount:     minCount,
@@ -371,75 +664,107 @@ func (c *client) GetOutputHistogram(amounts []uint64, minCount, maxCount uint64,
 		RecentCutoff: recentCutoff,
 	}
 	var res ResponseGetOutputHistogram
-	if err := c.do("get_output_histogram", req, &res); err != nil {
+	if err := c.do(ctx, "get_output_histogram", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) GetCoinbaseTxSum(height, count uint64) (*ResponseGetCoinbaseTxSum, error) {
+	return c.GetCoinbaseTxSumContext(context.Background(), height, count)
+}
+
+func (c *client) GetCoinbaseTxSumContext(ctx context.Context, height, count uint64) (*ResponseGetCoinbaseTxSum, error) {
 	req := &RequestGetCoinbaseTxSum{
 		Height: height,
 		Count:  count,
 	}
 	var res ResponseGetCoinbaseTxSum
-	if err := c.do("get_coinbase_tx_sum", req, &res); err != nil {
+	if err := c.do(ctx, "get_coinbase_tx_sum", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) GetVersion() (*ResponseGetVersion, error) {
+	return c.GetVersionContext(context.Background())
+}
+
+func (c *client) GetVersionContext(ctx context.Context) (*ResponseGetVersion, error) {
 	var res ResponseGetVersion
-	if err := c.do("get_version", nil, &res); err != nil {
+	if err := c.do(ctx, "get_version", nil, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) GetFeeEstimate(graceBlocks uint64) (*ResponseGetFeeEstimate, error) {
+	return c.GetFeeEstimateContext(context.Background(), graceBlocks)
+}
+
+func (c *client) GetFeeEstimateContext(ctx context.Context, graceBlocks uint64) (*ResponseGetFeeEstimate, error) {
 	req := &RequestGetFeeEstimate{GraceBlocks: graceBlocks}
 	var res ResponseGetFeeEstimate
-	if err := c.do("get_fee_estimate", req, &res); err != nil {
+	if err := c.do(ctx, "get_fee_estimate", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) GetAlternateChains() (*ResponseGetAlternateChains, error) {
+	return c.GetAlternateChainsContext(context.Background())
+}
+
+func (c *client) GetAlternateChainsContext(ctx context.Context) (*ResponseGetAlternateChains, error) {
 	var res ResponseGetAlternateChains
-	if err := c.do("get_alternate_chains", nil, &res); err != nil {
+	if err := c.do(ctx, "get_alternate_chains", nil, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) RelayTx(txIDs []string) (*ResponseRelayTx, error) {
+	return c.RelayTxContext(context.Background(), txIDs)
+}
+
+func (c *client) RelayTxContext(ctx context.Context, txIDs []string) (*ResponseRelayTx, error) {
 	req := &RequestRelayTx{TxIDs: txIDs}
 	var res ResponseRelayTx
-	if err := c.do("relay_tx", req, &res); err != nil {
+	if err := c.do(ctx, "relay_tx", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) SyncInfo() (*ResponseSyncInfo, error) {
+	return c.SyncInfoContext(context.Background())
+}
+
+func (c *client) SyncInfoContext(ctx context.Context) (*ResponseSyncInfo, error) {
 	var res ResponseSyncInfo
-	if err := c.do("sync_info", nil, &res); err != nil {
+	if err := c.do(ctx, "sync_info", nil, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) GetTxpoolBacklog() (*ResponseGetTxpoolBacklog, error) {
+	return c.GetTxpoolBacklogContext(context.Background())
+}
+
+func (c *client) GetTxpoolBacklogContext(ctx context.Context) (*ResponseGetTxpoolBacklog, error) {
 	var res ResponseGetTxpoolBacklog
-	if err := c.do("get_txpool_backlog", nil, &res); err != nil {
+	if err := c.do(ctx, "get_txpool_backlog", nil, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) GetOutputDistribution(amounts []uint64, cumulative bool, fromHeight, toHeight uint64) (*ResponseGetOutputDistribution, error) {
+	return c.GetOutputDistributionContext(context.Background(), amounts, cumulative, fromHeight, toHeight)
+}
+
+func (c *client) GetOutputDistributionContext(ctx context.Context, amounts []uint64, cumulative bool, fromHeight, toHeight uint64) (*ResponseGetOutputDistribution, error) {
 	req := &RequestGetOutputDistribution{
 		Amounts:    amounts,
 		Cumulative: cumulative,
@@ -447,30 +772,45 @@ func (c *client) GetOutputDistribution(amounts []uint64, cumulative bool, fromHe
 		ToHeight:   toHeight,
 	}
 	var res ResponseGetOutputDistribution
-	if err := c.do("get_output_distribution", req, &res); err != nil {
+	if err := c.do(ctx, "get_output_distribution", req, &res); err != nil {
 		return nil, err
 	}
+	for i := range res.Distributions {
+		res.Distributions[i].Cumulative = cumulative
+	}
 	return &res, nil
 }
 
 func (c *client) GetMinerData() (*ResponseGetMinerData, error) {
+	return c.GetMinerDataContext(context.Background())
+}
+
+func (c *client) GetMinerDataContext(ctx context.Context) (*ResponseGetMinerData, error) {
 	var res ResponseGetMinerData
-	if err := c.do("get_miner_data", nil, &res); err != nil {
+	if err := c.do(ctx, "get_miner_data", nil, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) PruneBlockchain(check bool) (*ResponsePruneBlockchain, error) {
+	return c.PruneBlockchainContext(context.Background(), check)
+}
+
+func (c *client) PruneBlockchainContext(ctx context.Context, check bool) (*ResponsePruneBlockchain, error) {
 	req := &RequestPruneBlockchain{Check: check}
 	var res ResponsePruneBlockchain
-	if err := c.do("prune_blockchain", req, &res); err != nil {
+	if err := c.do(ctx, "prune_blockchain", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) CalcPow(majorVersion, height uint64, blockBlob, seedHash string) (*ResponseCalcPow, error) {
+	return c.CalcPowContext(context.Background(), majorVersion, height, blockBlob, seedHash)
+}
+
+func (c *client) CalcPowContext(ctx context.Context, majorVersion, height uint64, blockBlob, seedHash string) (*ResponseCalcPow, error) {
 	req := &RequestCalcPow{
 		MajorVersion: majorVersion,
 		Height:       height,
@@ -478,31 +818,39 @@ func (c *client) CalcPow(majorVersion, height uint64, blockBlob, seedHash string
 		SeedHash:     seedHash,
 	}
 	var res ResponseCalcPow
-	if err := c.do("calc_pow", req, &res); err != nil {
+	if err := c.do(ctx, "calc_pow", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) FlushCache(badTxs, badBlocks bool) (*ResponseFlushCache, error) {
+	return c.FlushCacheContext(context.Background(), badTxs, badBlocks)
+}
+
+func (c *client) FlushCacheContext(ctx context.Context, badTxs, badBlocks bool) (*ResponseFlushCache, error) {
 	req := &RequestFlushCache{
 		BadTxs:    badTxs,
 		BadBlocks: badBlocks,
 	}
 	var res ResponseFlushCache
-	if err := c.do("flush_cache", req, &res); err != nil {
+	if err := c.do(ctx, "flush_cache", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) AddAuxPow(blocktemplateBlob string, auxPow []AuxPow) (*ResponseAddAuxPow, error) {
+	return c.AddAuxPowContext(context.Background(), blocktemplateBlob, auxPow)
+}
+
+func (c *client) AddAuxPowContext(ctx context.Context, blocktemplateBlob string, auxPow []AuxPow) (*ResponseAddAuxPow, error) {
 	req := &RequestAddAuxPow{
 		BlocktemplateBlob: blocktemplateBlob,
 		AuxPow:            auxPow,
 	}
 	var res ResponseAddAuxPow
-	if err := c.do("add_aux_pow", req, &res); err != nil {
+	if err := c.do(ctx, "add_aux_pow", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
@@ -511,14 +859,22 @@ func (c *client) AddAuxPow(blocktemplateBlob string, auxPow []AuxPow) (*Response
 // Other RPC Method Implementations
 
 func (c *client) GetHeight() (*ResponseGetHeight, error) {
+	return c.GetHeightContext(context.Background())
+}
+
+func (c *client) GetHeightContext(ctx context.Context) (*ResponseGetHeight, error) {
 	var res ResponseGetHeight
-	if err := c.doOther("/get_height", nil, &res); err != nil {
+	if err := c.doOther(ctx, "/get_height", nil, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) GetTransactions(txHashes []string, decodeAsJSON, prune, split bool) (*ResponseGetTransactions, error) {
+	return c.GetTransactionsContext(context.Background(), txHashes, decodeAsJSON, prune, split)
+}
+
+func (c *client) GetTransactionsContext(ctx context.Context, txHashes []string, decodeAsJSON, prune, split bool) (*ResponseGetTransactions, error) {
 	req := &RequestGetTransactions{
 		TxHashes:     txHashes,
 		DecodeAsJSON: decodeAsJSON,
@@ -526,42 +882,58 @@ func (c *client) GetTransactions(txHashes []string, decodeAsJSON, prune, split b
 		Split:        split,
 	}
 	var res ResponseGetTransactions
-	if err := c.doOther("/get_transactions", req, &res); err != nil {
+	if err := c.doOther(ctx, "/get_transactions", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) GetAltBlocksHashes() (*ResponseGetAltBlocksHashes, error) {
+	return c.GetAltBlocksHashesContext(context.Background())
+}
+
+func (c *client) GetAltBlocksHashesContext(ctx context.Context) (*ResponseGetAltBlocksHashes, error) {
 	var res ResponseGetAltBlocksHashes
-	if err := c.doOther("/get_alt_blocks_hashes", nil, &res); err != nil {
+	if err := c.doOther(ctx, "/get_alt_blocks_hashes", nil, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) IsKeyImageSpent(keyImages []string) (*ResponseIsKeyImageSpent, error) {
+	return c.IsKeyImageSpentContext(context.Background(), keyImages)
+}
+
+func (c *client) IsKeyImageSpentContext(ctx context.Context, keyImages []string) (*ResponseIsKeyImageSpent, error) {
 	req := &RequestIsKeyImageSpent{KeyImages: keyImages}
 	var res ResponseIsKeyImageSpent
-	if err := c.doOther("/is_key_image_spent", req, &res); err != nil {
+	if err := c.doOther(ctx, "/is_key_image_spent", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) SendRawTransaction(txAsHex string, doNotRelay bool) (*ResponseSendRawTransaction, error) {
+	return c.SendRawTransactionContext(context.Background(), txAsHex, doNotRelay)
+}
+
+func (c *client) SendRawTransactionContext(ctx context.Context, txAsHex string, doNotRelay bool) (*ResponseSendRawTransaction, error) {
 	req := &RequestSendRawTransaction{
 		TxAsHex:    txAsHex,
 		DoNotRelay: doNotRelay,
 	}
 	var res ResponseSendRawTransaction
-	if err := c.doOther("/send_raw_transaction", req, &res); err != nil {
+	if err := c.doOther(ctx, "/send_raw_transaction", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) StartMining(minerAddress string, threadsCount uint64, doBackgroundMining, ignoreBattery bool) (*ResponseStartMining, error) {
+	return c.StartMiningContext(context.Background(), minerAddress, threadsCount, doBackgroundMining, ignoreBattery)
+}
+
+func (c *client) StartMiningContext(ctx context.Context, minerAddress string, threadsCount uint64, doBackgroundMining, ignoreBattery bool) (*ResponseStartMining, error) {
 	req := &RequestStartMining{
 		MinerAddress:       minerAddress,
 		ThreadsCount:       threadsCount,
@@ -569,85 +941,121 @@ func (c *client) StartMining(minerAddress string, threadsCount uint64, doBackgro
 		IgnoreBattery:      ignoreBattery,
 	}
 	var res ResponseStartMining
-	if err := c.doOther("/start_mining", req, &res); err != nil {
+	if err := c.doOther(ctx, "/start_mining", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) StopMining() (*ResponseStopMining, error) {
+	return c.StopMiningContext(context.Background())
+}
+
+func (c *client) StopMiningContext(ctx context.Context) (*ResponseStopMining, error) {
 	var res ResponseStopMining
-	if err := c.doOther("/stop_mining", nil, &res); err != nil {
+	if err := c.doOther(ctx, "/stop_mining", nil, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) MiningStatus() (*ResponseMiningStatus, error) {
+	return c.MiningStatusContext(context.Background())
+}
+
+func (c *client) MiningStatusContext(ctx context.Context) (*ResponseMiningStatus, error) {
 	var res ResponseMiningStatus
-	if err := c.doOther("/mining_status", nil, &res); err != nil {
+	if err := c.doOther(ctx, "/mining_status", nil, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) SaveBC() (*ResponseSaveBC, error) {
+	return c.SaveBCContext(context.Background())
+}
+
+func (c *client) SaveBCContext(ctx context.Context) (*ResponseSaveBC, error) {
 	var res ResponseSaveBC
-	if err := c.doOther("/save_bc", nil, &res); err != nil {
+	if err := c.doOther(ctx, "/save_bc", nil, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) GetPeerList() (*ResponseGetPeerList, error) {
+	return c.GetPeerListContext(context.Background())
+}
+
+func (c *client) GetPeerListContext(ctx context.Context) (*ResponseGetPeerList, error) {
 	var res ResponseGetPeerList
-	if err := c.doOther("/get_peer_list", nil, &res); err != nil {
+	if err := c.doOther(ctx, "/get_peer_list", nil, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) GetPublicNodes(gray, white, includeBlocked bool) (*ResponseGetPublicNodes, error) {
+	return c.GetPublicNodesContext(context.Background(), gray, white, includeBlocked)
+}
+
+func (c *client) GetPublicNodesContext(ctx context.Context, gray, white, includeBlocked bool) (*ResponseGetPublicNodes, error) {
 	req := &RequestGetPublicNodes{
 		Gray:           gray,
 		White:          white,
 		IncludeBlocked: includeBlocked,
 	}
 	var res ResponseGetPublicNodes
-	if err := c.doOther("/get_public_nodes", req, &res); err != nil {
+	if err := c.doOther(ctx, "/get_public_nodes", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) SetLogHashRate(visible bool) (*ResponseSetLogHashRate, error) {
+	return c.SetLogHashRateContext(context.Background(), visible)
+}
+
+func (c *client) SetLogHashRateContext(ctx context.Context, visible bool) (*ResponseSetLogHashRate, error) {
 	req := &RequestSetLogHashRate{Visible: visible}
 	var res ResponseSetLogHashRate
-	if err := c.doOther("/set_log_hash_rate", req, &res); err != nil {
+	if err := c.doOther(ctx, "/set_log_hash_rate", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) SetLogLevel(level uint64) (*ResponseSetLogLevel, error) {
+	return c.SetLogLevelContext(context.Background(), level)
+}
+
+func (c *client) SetLogLevelContext(ctx context.Context, level uint64) (*ResponseSetLogLevel, error) {
 	req := &RequestSetLogLevel{Level: level}
 	var res ResponseSetLogLevel
-	if err := c.doOther("/set_log_level", req, &res); err != nil {
+	if err := c.doOther(ctx, "/set_log_level", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) SetLogCategories(categories string) (*ResponseSetLogCategories, error) {
+	return c.SetLogCategoriesContext(context.Background(), categories)
+}
+
+func (c *client) SetLogCategoriesContext(ctx context.Context, categories string) (*ResponseSetLogCategories, error) {
 	req := &RequestSetLogCategories{Categories: categories}
 	var res ResponseSetLogCategories
-	if err := c.doOther("/set_log_categories", req, &res); err != nil {
+	if err := c.doOther(ctx, "/set_log_categories", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) SetBootstrapDaemon(address, username, password, proxy string) (*ResponseSetBootstrapDaemon, error) {
+	return c.SetBootstrapDaemonContext(context.Background(), address, username, password, proxy)
+}
+
+func (c *client) SetBootstrapDaemonContext(ctx context.Context, address, username, password, proxy string) (*ResponseSetBootstrapDaemon, error) {
 	req := &RequestSetBootstrapDaemon{
 		Address:  address,
 		Username: username,
@@ -655,119 +1063,280 @@ func (c *client) SetBootstrapDaemon(address, username, password, proxy string) (
 		Proxy:    proxy,
 	}
 	var res ResponseSetBootstrapDaemon
-	if err := c.doOther("/set_bootstrap_daemon", req, &res); err != nil {
+	if err := c.doOther(ctx, "/set_bootstrap_daemon", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) GetTransactionPool() (*ResponseGetTransactionPool, error) {
+	return c.GetTransactionPoolContext(context.Background())
+}
+
+func (c *client) GetTransactionPoolContext(ctx context.Context) (*ResponseGetTransactionPool, error) {
 	var res ResponseGetTransactionPool
-	if err := c.doOther("/get_transaction_pool", nil, &res); err != nil {
+	if err := c.doOther(ctx, "/get_transaction_pool", nil, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) GetTransactionPoolHashes() (*ResponseGetTransactionPoolHashes, error) {
+	return c.GetTransactionPoolHashesContext(context.Background())
+}
+
+func (c *client) GetTransactionPoolHashesContext(ctx context.Context) (*ResponseGetTransactionPoolHashes, error) {
 	var res ResponseGetTransactionPoolHashes
-	if err := c.doOther("/get_transaction_pool_hashes", nil, &res); err != nil {
+	if err := c.doOther(ctx, "/get_transaction_pool_hashes", nil, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) GetTransactionPoolStats() (*ResponseGetTransactionPoolStats, error) {
+	return c.GetTransactionPoolStatsContext(context.Background())
+}
+
+func (c *client) GetTransactionPoolStatsContext(ctx context.Context) (*ResponseGetTransactionPoolStats, error) {
 	var res ResponseGetTransactionPoolStats
-	if err := c.doOther("/get_transaction_pool_stats", nil, &res); err != nil {
+	if err := c.doOther(ctx, "/get_transaction_pool_stats", nil, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) StopDaemon() (*ResponseStopDaemon, error) {
+	return c.StopDaemonContext(context.Background())
+}
+
+func (c *client) StopDaemonContext(ctx context.Context) (*ResponseStopDaemon, error) {
 	var res ResponseStopDaemon
-	if err := c.doOther("/stop_daemon", nil, &res); err != nil {
+	if err := c.doOther(ctx, "/stop_daemon", nil, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) GetLimit() (*ResponseGetLimit, error) {
+	return c.GetLimitContext(context.Background())
+}
+
+func (c *client) GetLimitContext(ctx context.Context) (*ResponseGetLimit, error) {
 	var res ResponseGetLimit
-	if err := c.doOther("/get_limit", nil, &res); err != nil {
+	if err := c.doOther(ctx, "/get_limit", nil, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) SetLimit(limitDown, limitUp int64) (*ResponseSetLimit, error) {
+	return c.SetLimitContext(context.Background(), limitDown, limitUp)
+}
+
+func (c *client) SetLimitContext(ctx context.Context, limitDown, limitUp int64) (*ResponseSetLimit, error) {
 	req := &RequestSetLimit{
 		LimitDown: limitDown,
 		LimitUp:   limitUp,
 	}
 	var res ResponseSetLimit
-	if err := c.doOther("/set_limit", req, &res); err != nil {
+	if err := c.doOther(ctx, "/set_limit", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) OutPeers(outPeers uint64) (*ResponseOutPeers, error) {
+	return c.OutPeersContext(context.Background(), outPeers)
+}
+
+func (c *client) OutPeersContext(ctx context.Context, outPeers uint64) (*ResponseOutPeers, error) {
 	req := &RequestOutPeers{OutPeers: outPeers}
 	var res ResponseOutPeers
-	if err := c.doOther("/out_peers", req, &res); err != nil {
+	if err := c.doOther(ctx, "/out_peers", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) InPeers(inPeers uint64) (*ResponseInPeers, error) {
+	return c.InPeersContext(context.Background(), inPeers)
+}
+
+func (c *client) InPeersContext(ctx context.Context, inPeers uint64) (*ResponseInPeers, error) {
 	req := &RequestInPeers{InPeers: inPeers}
 	var res ResponseInPeers
-	if err := c.doOther("/in_peers", req, &res); err != nil {
+	if err := c.doOther(ctx, "/in_peers", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) GetNetStats() (*ResponseGetNetStats, error) {
+	return c.GetNetStatsContext(context.Background())
+}
+
+func (c *client) GetNetStatsContext(ctx context.Context) (*ResponseGetNetStats, error) {
 	var res ResponseGetNetStats
-	if err := c.doOther("/get_net_stats", nil, &res); err != nil {
+	if err := c.doOther(ctx, "/get_net_stats", nil, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) GetOuts(outputs []OutputIndex, getTxID bool) (*ResponseGetOuts, error) {
+	return c.GetOutsContext(context.Background(), outputs, getTxID)
+}
+
+func (c *client) GetOutsContext(ctx context.Context, outputs []OutputIndex, getTxID bool) (*ResponseGetOuts, error) {
 	req := &RequestGetOuts{
 		Outputs: outputs,
 		GetTxID: getTxID,
 	}
 	var res ResponseGetOuts
-	if err := c.doOther("/get_outs", req, &res); err != nil {
+	if err := c.doOther(ctx, "/get_outs", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) Update(command, path string) (*ResponseUpdate, error) {
+	return c.UpdateContext(context.Background(), command, path)
+}
+
+func (c *client) UpdateContext(ctx context.Context, command, path string) (*ResponseUpdate, error) {
 	req := &RequestUpdate{
 		Command: command,
 		Path:    path,
 	}
 	var res ResponseUpdate
-	if err := c.doOther("/update", req, &res); err != nil {
+	if err := c.doOther(ctx, "/update", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
 
 func (c *client) PopBlocks(nBlocks uint64) (*ResponsePopBlocks, error) {
+	return c.PopBlocksContext(context.Background(), nBlocks)
+}
+
+func (c *client) PopBlocksContext(ctx context.Context, nBlocks uint64) (*ResponsePopBlocks, error) {
 	req := &RequestPopBlocks{NBlocks: nBlocks}
 	var res ResponsePopBlocks
-	if err := c.doOther("/pop_blocks", req, &res); err != nil {
+	if err := c.doOther(ctx, "/pop_blocks", req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Binary Endpoint Implementations
+
+// GetBlocksBin calls /get_blocks.bin, which returns blocks with their
+// transaction blobs and output indices, and optionally pool changes, in
+// one call. It is how wallets sync.
+func (c *client) GetBlocksBin(req *RequestGetBlocksBin) (*ResponseGetBlocksBin, error) {
+	return c.GetBlocksBinContext(context.Background(), req)
+}
+
+func (c *client) GetBlocksBinContext(ctx context.Context, req *RequestGetBlocksBin) (*ResponseGetBlocksBin, error) {
+	var res ResponseGetBlocksBin
+	if err := c.doOther(ctx, "/get_blocks.bin", req, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
 }
+
+func (c *client) GetBlocksByHeightBin(heights []uint64) (*ResponseGetBlocksByHeightBin, error) {
+	return c.GetBlocksByHeightBinContext(context.Background(), heights)
+}
+
+func (c *client) GetBlocksByHeightBinContext(ctx context.Context, heights []uint64) (*ResponseGetBlocksByHeightBin, error) {
+	req := &RequestGetBlocksByHeightBin{Heights: heights}
+	var res ResponseGetBlocksByHeightBin
+	if err := c.doOther(ctx, "/get_blocks_by_height.bin", req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (c *client) GetOutsBin(outputs []OutputIndex, getTxID bool) (*ResponseGetOutsBin, error) {
+	return c.GetOutsBinContext(context.Background(), outputs, getTxID)
+}
+
+func (c *client) GetOutsBinContext(ctx context.Context, outputs []OutputIndex, getTxID bool) (*ResponseGetOutsBin, error) {
+	req := &RequestGetOutsBin{Outputs: outputs, GetTxID: getTxID}
+	var res ResponseGetOutsBin
+	if err := c.doOther(ctx, "/get_outs.bin", req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// GetOIndexesBin returns the global output indices of the outputs of a
+// mined transaction.
+func (c *client) GetOIndexesBin(txid Hash) (*ResponseGetOIndexesBin, error) {
+	return c.GetOIndexesBinContext(context.Background(), txid)
+}
+
+func (c *client) GetOIndexesBinContext(ctx context.Context, txid Hash) (*ResponseGetOIndexesBin, error) {
+	req := &RequestGetOIndexesBin{TxID: txid}
+	var res ResponseGetOIndexesBin
+	if err := c.doOther(ctx, "/get_o_indexes.bin", req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// GetHashesBin returns the ids of the main chain blocks from the first
+// block of blockIDs the daemon knows, or from startHeight if it is higher.
+// blockIDs must end with the genesis block id, see RequestGetBlocksBin.
+func (c *client) GetHashesBin(blockIDs []Hash, startHeight uint64) (*ResponseGetHashesBin, error) {
+	return c.GetHashesBinContext(context.Background(), blockIDs, startHeight)
+}
+
+func (c *client) GetHashesBinContext(ctx context.Context, blockIDs []Hash, startHeight uint64) (*ResponseGetHashesBin, error) {
+	req := &RequestGetHashesBin{BlockIDs: blockIDs, StartHeight: startHeight}
+	var res ResponseGetHashesBin
+	if err := c.doOther(ctx, "/get_hashes.bin", req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (c *client) GetTransactionPoolHashesBin() (*ResponseGetTransactionPoolHashesBin, error) {
+	return c.GetTransactionPoolHashesBinContext(context.Background())
+}
+
+func (c *client) GetTransactionPoolHashesBinContext(ctx context.Context) (*ResponseGetTransactionPoolHashesBin, error) {
+	var res ResponseGetTransactionPoolHashesBin
+	if err := c.doOther(ctx, "/get_transaction_pool_hashes.bin", nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// GetOutputDistributionBin is GetOutputDistribution on the binary
+// endpoint. With compress set, the daemon sends the counts as varints,
+// which is much smaller for per-block counts. The distributions are
+// decoded either way.
+func (c *client) GetOutputDistributionBin(amounts []uint64, cumulative bool, fromHeight, toHeight uint64, compress bool) (*ResponseGetOutputDistribution, error) {
+	return c.GetOutputDistributionBinContext(context.Background(), amounts, cumulative, fromHeight, toHeight, compress)
+}
+
+func (c *client) GetOutputDistributionBinContext(ctx context.Context, amounts []uint64, cumulative bool, fromHeight, toHeight uint64, compress bool) (*ResponseGetOutputDistribution, error) {
+	req := &RequestGetOutputDistribution{
+		Amounts:    amounts,
+		Cumulative: cumulative,
+		FromHeight: fromHeight,
+		ToHeight:   toHeight,
+		Binary:     true,
+		Compress:   compress,
+	}
+	var res ResponseGetOutputDistribution
+	if err := c.doOther(ctx, "/get_output_distribution.bin", req, &res); err != nil {
+		return nil, err
+	}
+	for i := range res.Distributions {
+		res.Distributions[i].Cumulative = cumulative
+	}
+	return &res, nil
+}
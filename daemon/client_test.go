@@ -0,0 +1,134 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/boomhut/go-monero-rpc-client/telemetry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientContextCanceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	cl := New(Config{Address: srv.URL})
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	_, err := cl.GetTransactionsContext(ctx, []string{"abcd"}, true, false, false)
+	assert.True(t, errors.Is(err, context.Canceled), "got %v", err)
+}
+
+func TestClientContextDeadline(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	cl := New(Config{Address: srv.URL})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := cl.GetInfoContext(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "got %v", err)
+}
+
+func TestClientTimeout(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		io.Copy(io.Discard, r.Body)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	metrics := telemetry.NewMetrics()
+	cl := New(Config{
+		Address: srv.URL,
+		Timeout: 20 * time.Millisecond,
+		Retry:   &RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+		Metrics: metrics,
+	})
+	_, err := cl.GetHeight()
+	assert.True(t, errors.Is(err, ErrTransport), "got %v", err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "got %v", err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Equal(t, uint64(1), metrics.Snapshot()[0].Errors[telemetry.ErrorTimeout])
+
+	// Timeouts below net/http, such as a dial timeout, match too.
+	err = &TransportError{Err: &net.OpError{Op: "dial", Net: "tcp", Err: os.ErrDeadlineExceeded}}
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.True(t, DefaultRetryable(err))
+	err = &TransportError{Err: io.ErrUnexpectedEOF}
+	assert.False(t, errors.Is(err, context.DeadlineExceeded))
+
+	// The deadline of the caller's context is not retried.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	cl = New(Config{Address: srv.URL, Retry: &RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}})
+	atomic.StoreInt32(&calls, 0)
+	_, err = cl.GetHeightContext(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "got %v", err)
+	assert.False(t, DefaultRetryable(err))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+// countConns starts srv and returns a counter of the TCP connections it
+// accepts.
+func countConns(srv *httptest.Server) *int32 {
+	var conns int32
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	srv.Start()
+	return &conns
+}
+
+func heightServer() *httptest.Server {
+	return httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"hash":"aa","height":100,"status":"OK"}`))
+	}))
+}
+
+func TestClientReusesConnections(t *testing.T) {
+	srv := heightServer()
+	conns := countConns(srv)
+	defer srv.Close()
+
+	cl := New(Config{Address: srv.URL})
+	for round := 0; round < 5; round++ {
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := cl.GetHeight()
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+	}
+	// 8 concurrent callers need at most 8 connections, which must all be
+	// kept alive between rounds.
+	assert.LessOrEqual(t, atomic.LoadInt32(conns), int32(8))
+}
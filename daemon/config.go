@@ -1,6 +1,11 @@
 package daemon
 
-import "net/http"
+import (
+	"net/http"
+	"time"
+
+	"github.com/boomhut/go-monero-rpc-client/telemetry"
+)
 
 // Config holds daemon client configuration
 type Config struct {
@@ -9,6 +14,35 @@ type Config struct {
 	Address string
 	// Custom headers to send with requests
 	CustomHeaders map[string]string
-	// Custom HTTP transport
+	// Custom HTTP transport. When nil, the client uses its own
+	// http.Transport tuned by the connection settings below.
 	Transport http.RoundTripper
+	// Timeout limits a single HTTP request, including reading the response
+	// body. Zero means no limit besides the context passed to the call.
+	Timeout time.Duration
+	// MaxIdleConns and MaxIdleConnsPerHost limit the keep-alive connections
+	// kept open for reuse, IdleConnTimeout is how long an idle connection
+	// is kept. They default to 100, 32 and 90s and are ignored when
+	// Transport is set.
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	// Username and Password for monerod --rpc-login.
+	// When Username is set the client answers HTTP Digest challenges itself.
+	Username string
+	Password string
+	// Retry enables retrying of idempotent methods. Nil means a single attempt.
+	Retry *RetryPolicy
+	// Interceptors wrap every call, the first one being the outermost.
+	Interceptors []Interceptor
+	// Tracer, if set, starts a span for every RPC call and Metrics, if set,
+	// records its outcome and latency. Params and results are never
+	// recorded.
+	Tracer  telemetry.Tracer
+	Metrics telemetry.Recorder
+	// RejectUntrusted makes calls fail with ErrUntrusted when monerod marks
+	// the response as untrusted, i.e. it was served by a bootstrap daemon
+	// while the node is still syncing. When false, such responses are
+	// returned and can be recognised by their Untrusted field.
+	RejectUntrusted bool
 }
@@ -0,0 +1,172 @@
+package daemontest
+
+import (
+	"fmt"
+
+	"github.com/boomhut/go-monero-rpc-client/daemon"
+)
+
+// The methods below implement the .bin endpoints. They are called by the
+// Server with c.mu held.
+
+const (
+	// maxBlockCount is the most blocks /get_blocks.bin returns, as
+	// COMMAND_RPC_GET_BLOCKS_FAST_MAX_BLOCK_COUNT.
+	maxBlockCount = 1000
+	// maxHashCount is the most ids /get_hashes.bin returns, as
+	// BLOCKS_IDS_SYNCHRONIZING_DEFAULT_COUNT.
+	maxHashCount = 10000
+)
+
+func binHash(s string) daemon.Hash {
+	var h daemon.Hash
+	copy(h[:], mustHex(s))
+	return h
+}
+
+// blockEntry returns b with the blobs of its transactions.
+func blockEntry(b *block, prune bool) daemon.BlockCompleteEntry {
+	e := daemon.BlockCompleteEntry{Pruned: prune, Block: b.blob}
+	weight := b.minerTx.weight
+	for _, t := range b.txs {
+		e.Txs = append(e.Txs, daemon.TxBlobEntry{Blob: t.blob})
+		weight += t.weight
+	}
+	if prune {
+		e.BlockWeight = weight
+	}
+	return e
+}
+
+// start returns the height /get_blocks.bin and /get_hashes.bin start
+// from, or false if the request matches no block, like
+// Blockchain::find_blockchain_supplement.
+func (c *Chain) start(startHeight uint64, ids []daemon.Hash) (uint64, bool) {
+	if len(ids) == 0 || ids[len(ids)-1] != binHash(c.main[0].hash) || startHeight >= uint64(len(c.main)) {
+		return 0, false
+	}
+	var split uint64
+	for _, id := range ids {
+		if b, ok := c.blocks[id.String()]; ok && !b.alt {
+			split = b.height
+			break
+		}
+	}
+	if startHeight > split {
+		return startHeight, true
+	}
+	return split, true
+}
+
+// getBlocksBin always reports the pool in full: PoolInfoSince is ignored.
+func (c *Chain) getBlocksBin(req *daemon.RequestGetBlocksBin) (*daemon.ResponseGetBlocksBin, error) {
+	resp := &daemon.ResponseGetBlocksBin{
+		CurrentHeight: uint64(len(c.main)),
+		DaemonTime:    c.now(),
+		TopBlockHash:  binHash(c.top().hash),
+		Status:        daemon.StatusOK,
+	}
+	if req.RequestedInfo != daemon.RequestedPool {
+		start, ok := c.start(req.StartHeight, req.BlockIDs)
+		if !ok {
+			return nil, &daemon.StatusError{Status: daemon.StatusFailed}
+		}
+		count := req.MaxBlockCount
+		if count == 0 || count > maxBlockCount {
+			count = maxBlockCount
+		}
+		resp.StartHeight = start
+		for h := start; h < uint64(len(c.main)) && h-start < count; h++ {
+			b := c.main[h]
+			resp.Blocks = append(resp.Blocks, blockEntry(b, req.Prune))
+			var indices daemon.BlockOutputIndices
+			if !req.NoMinerTx {
+				indices.Indices = append(indices.Indices, daemon.TxOutputIndices{Indices: b.minerTx.outputIndices})
+			}
+			for _, t := range b.txs {
+				indices.Indices = append(indices.Indices, daemon.TxOutputIndices{Indices: t.outputIndices})
+			}
+			resp.OutputIndices = append(resp.OutputIndices, indices)
+		}
+	}
+	if req.RequestedInfo != daemon.RequestedBlocks {
+		resp.PoolInfoExtent = daemon.PoolInfoFull
+		for _, p := range c.relayedPool() {
+			resp.AddedPoolTxs = append(resp.AddedPoolTxs, daemon.PoolTxInfo{TxHash: binHash(p.hash), TxBlob: p.blob})
+		}
+	}
+	return resp, nil
+}
+
+func (c *Chain) getBlocksByHeightBin(req *daemon.RequestGetBlocksByHeightBin) (*daemon.ResponseGetBlocksByHeightBin, error) {
+	resp := &daemon.ResponseGetBlocksByHeightBin{Status: daemon.StatusOK}
+	for _, h := range req.Heights {
+		if h >= uint64(len(c.main)) {
+			return nil, &daemon.StatusError{Status: fmt.Sprintf("Error retrieving block at height %d", h)}
+		}
+		resp.Blocks = append(resp.Blocks, blockEntry(c.main[h], false))
+	}
+	return resp, nil
+}
+
+func (c *Chain) getOutsBin(req *daemon.RequestGetOutsBin) (*daemon.ResponseGetOutsBin, error) {
+	outs, err := c.getOuts(&daemon.RequestGetOuts{Outputs: req.Outputs, GetTxID: req.GetTxID})
+	if err != nil {
+		return nil, err
+	}
+	resp := &daemon.ResponseGetOutsBin{Status: daemon.StatusOK}
+	for _, o := range outs.Outs {
+		out := daemon.OutKeyBin{Key: binHash(o.Key), Mask: binHash(o.Mask), Unlocked: o.Unlocked, Height: o.Height}
+		if o.Txid != "" {
+			out.Txid = binHash(o.Txid)
+		}
+		resp.Outs = append(resp.Outs, out)
+	}
+	return resp, nil
+}
+
+func (c *Chain) getOIndexesBin(req *daemon.RequestGetOIndexesBin) (*daemon.ResponseGetOIndexesBin, error) {
+	t, ok := c.txs[req.TxID.String()]
+	if !ok || t.block == nil {
+		return nil, &daemon.StatusError{Status: daemon.StatusFailed}
+	}
+	return &daemon.ResponseGetOIndexesBin{OIndexes: t.outputIndices, Status: daemon.StatusOK}, nil
+}
+
+func (c *Chain) getHashesBin(req *daemon.RequestGetHashesBin) (*daemon.ResponseGetHashesBin, error) {
+	start, ok := c.start(req.StartHeight, req.BlockIDs)
+	if !ok {
+		return nil, &daemon.StatusError{Status: daemon.StatusFailed}
+	}
+	resp := &daemon.ResponseGetHashesBin{
+		StartHeight:   start,
+		CurrentHeight: uint64(len(c.main)),
+		Status:        daemon.StatusOK,
+	}
+	for h := start; h < uint64(len(c.main)) && h-start < maxHashCount; h++ {
+		resp.BlockIDs = append(resp.BlockIDs, binHash(c.main[h].hash))
+	}
+	return resp, nil
+}
+
+func (c *Chain) getTransactionPoolHashesBin() (*daemon.ResponseGetTransactionPoolHashesBin, error) {
+	resp := &daemon.ResponseGetTransactionPoolHashesBin{Status: daemon.StatusOK}
+	for _, p := range c.pool {
+		resp.TxHashes = append(resp.TxHashes, binHash(p.hash))
+	}
+	return resp, nil
+}
+
+// getOutputDistributionBin sends the distributions in binary form, which
+// monerod always does on this endpoint.
+func (c *Chain) getOutputDistributionBin(req *daemon.RequestGetOutputDistribution) (*daemon.ResponseGetOutputDistribution, error) {
+	resp, err := c.getOutputDistribution(req)
+	if err != nil {
+		return nil, err
+	}
+	for i := range resp.Distributions {
+		resp.Distributions[i].Binary = true
+		resp.Distributions[i].Compress = req.Compress
+	}
+	return resp, nil
+}
@@ -0,0 +1,130 @@
+package daemontest
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/boomhut/go-monero-rpc-client/cryptonote"
+)
+
+// blockBlob serialises b like cryptonote::block: the header, the miner
+// transaction and the hashes of the other transactions.
+func blockBlob(b *block) []byte {
+	var buf []byte
+	buf = binary.AppendUvarint(buf, MajorVersion)
+	buf = binary.AppendUvarint(buf, MajorVersion)
+	buf = binary.AppendUvarint(buf, b.timestamp)
+	buf = append(buf, mustHex(b.prevHash)...)
+	buf = binary.LittleEndian.AppendUint32(buf, b.nonce)
+	buf = append(buf, b.minerTx.blob...)
+	buf = binary.AppendUvarint(buf, uint64(len(b.txs)))
+	for _, t := range b.txs {
+		buf = append(buf, mustHex(t.hash)...)
+	}
+	return buf
+}
+
+// minerTxBlob serialises t, a version 2 coinbase transaction with one
+// tagged key output and the transaction public key in tx_extra.
+func minerTxBlob(t *tx) []byte {
+	var buf []byte
+	buf = binary.AppendUvarint(buf, 2)
+	buf = binary.AppendUvarint(buf, t.unlockTime)
+	buf = binary.AppendUvarint(buf, 1)
+	buf = append(buf, 0xff)
+	buf = binary.AppendUvarint(buf, t.height)
+	buf = binary.AppendUvarint(buf, 1)
+	buf = binary.AppendUvarint(buf, t.amount)
+	buf = append(buf, 0x03)
+	key := mustHex(outputKey(t.seed, 0))
+	buf = append(buf, key...)
+	buf = append(buf, key[0])
+	extra := t.extra()
+	buf = binary.AppendUvarint(buf, uint64(len(extra)))
+	buf = append(buf, extra...)
+	buf = append(buf, 0)
+	return buf
+}
+
+// extra returns the tx_extra of t: the transaction public key and the
+// extra nonce for a coinbase transaction, nothing otherwise.
+func (t *tx) extra() []byte {
+	if !t.coinbase {
+		return nil
+	}
+	extra := append([]byte{0x01}, mustHex(t.seed)...)
+	if len(t.extraNonce) > 0 {
+		extra = append(extra, 0x02, byte(len(t.extraNonce)))
+		extra = append(extra, t.extraNonce...)
+	}
+	return extra
+}
+
+// outputKey returns the one-time key of output i of the transaction with
+// the given seed.
+func outputKey(seed string, i int) string {
+	return hashOf([]byte(seed), []byte("key"), []byte{byte(i)})
+}
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic("daemontest: bad hex " + s)
+	}
+	return b
+}
+
+// json returns t as monerod decodes it for get_transactions "as_json".
+func (t *tx) json() cryptonote.Transaction {
+	j := cryptonote.Transaction{
+		Version:       2,
+		UnlockTime:    t.unlockTime,
+		Inputs:        []cryptonote.TxIn{},
+		Outputs:       []cryptonote.TxOut{},
+		Extra:         t.extra(),
+		RctSignatures: &cryptonote.RctSignatures{},
+	}
+	if t.coinbase {
+		j.Inputs = append(j.Inputs, cryptonote.TxIn{Gen: &cryptonote.TxInGen{Height: t.height}})
+	} else {
+		j.RctSignatures = &cryptonote.RctSignatures{Type: cryptonote.RCTTypeBulletproofPlus, TxnFee: t.fee}
+	}
+	for _, ki := range t.keyImages {
+		j.Inputs = append(j.Inputs, cryptonote.TxIn{Key: &cryptonote.TxInKey{KeyOffsets: []uint64{}, KeyImage: cryptonote.Key(binHash(ki))}})
+	}
+	for i := 0; i < t.outputs; i++ {
+		key := cryptonote.Key(binHash(outputKey(t.seed, i)))
+		j.Outputs = append(j.Outputs, cryptonote.TxOut{
+			Amount: t.amount,
+			Target: cryptonote.TxOutTarget{TaggedKey: &cryptonote.TaggedKey{Key: key, ViewTag: cryptonote.ViewTag(key[0])}},
+		})
+	}
+	return j
+}
+
+// asJSON returns t as get_transactions reports it with decode_as_json.
+func (t *tx) asJSON() string {
+	raw, _ := json.MarshalIndent(t.json(), "", "  ")
+	return string(raw)
+}
+
+// asJSON returns b as get_block reports it in the "json" field.
+func (b *block) asJSON() string {
+	j := cryptonote.Block{
+		BlockHeader: cryptonote.BlockHeader{
+			MajorVersion: MajorVersion,
+			MinorVersion: MajorVersion,
+			Timestamp:    b.timestamp,
+			PrevID:       binHash(b.prevHash),
+			Nonce:        b.nonce,
+		},
+		MinerTx:  b.minerTx.json(),
+		TxHashes: []cryptonote.Hash{},
+	}
+	for _, t := range b.txs {
+		j.TxHashes = append(j.TxHashes, binHash(t.hash))
+	}
+	raw, _ := json.MarshalIndent(j, "", "  ")
+	return string(raw)
+}
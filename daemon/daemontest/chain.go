@@ -0,0 +1,647 @@
+// Package daemontest provides an in-memory monerod for tests.
+//
+// Chain is a scriptable synthetic blockchain with a transaction pool:
+// tests mine blocks, add pool transactions, fork the chain and reorganise
+// it. Server exposes a Chain over the monerod RPC protocol on an
+// httptest.Server, both the JSON-RPC methods on /json_rpc and the other
+// endpoints (/get_height, /get_transactions, /is_key_image_spent,
+// /send_raw_transaction, /get_transaction_pool, /get_blocks.bin, ...):
+//
+//	c := daemontest.NewChain()
+//	txid, _ := c.AddTx(daemontest.Tx{Fee: 30000000, KeyImages: []string{ki}})
+//	c.MineBlocks(1)
+//	srv := daemontest.NewServer(c)
+//	defer srv.Close()
+//
+//	client := daemon.New(daemon.Config{Address: srv.Address()})
+//
+// Block blobs have the layout of real blocks, but hashes are not Keccak
+// and there is no proof of work: every block has the same difficulty.
+// Transaction blobs are opaque; what the chain knows about a transaction
+// (fee, key images, outputs) comes from the Tx it was added with.
+package daemontest
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+const (
+	// DefaultHeight is the number of blocks of a new Chain, genesis
+	// included.
+	DefaultHeight = 100
+	// BlockTime is the simulated time between blocks in seconds.
+	BlockTime = 120
+	// GenesisTime is the timestamp of block 0.
+	GenesisTime = 1700000000
+	// Difficulty is the difficulty of every block.
+	Difficulty = 1000
+	// BlockReward is the coinbase reward of every block in atomic units,
+	// excluding fees. It is the tail emission of 0.6 XMR.
+	BlockReward = 600000000000
+	// DefaultFee is the per-byte fee estimate in atomic units.
+	DefaultFee = 20000
+	// SpendableAge is the number of blocks before an output is unlocked,
+	// as CRYPTONOTE_DEFAULT_TX_SPENDABLE_AGE.
+	SpendableAge = 10
+	// MinedMoneyUnlockWindow is the number of blocks before a coinbase
+	// output is unlocked, as CRYPTONOTE_MINED_MONEY_UNLOCK_WINDOW.
+	MinedMoneyUnlockWindow = 60
+	// MajorVersion is the hard fork version of every block.
+	MajorVersion = 16
+	// Version is the monerod version reported by get_info.
+	Version = "0.18.4.0-release"
+	// RPCVersion is the RPC version reported by get_version, 3.14.
+	RPCVersion = 3<<16 | 14
+)
+
+// ErrDoubleSpend is returned by AddTx for a transaction spending a key
+// image that is already spent in the chain or the pool.
+var ErrDoubleSpend = errors.New("daemontest: double spend")
+
+// Tx describes a transaction to add to the pool, see AddTx.
+type Tx struct {
+	// Blob is the transaction as sent on the wire. Nil means a fake,
+	// unique blob is generated. The transaction id is derived from it.
+	Blob []byte
+	// Fee in atomic units.
+	Fee uint64
+	// Weight in bytes. Zero means the length of Blob.
+	Weight uint64
+	// KeyImages spent by the transaction, 64 hex characters each.
+	KeyImages []string
+	// Outputs is the number of outputs. Zero means two.
+	Outputs int
+	// DoNotRelay keeps the transaction out of mined blocks until it is
+	// relayed with relay_tx.
+	DoNotRelay bool
+}
+
+// Chain is an in-memory blockchain and transaction pool. It is safe for
+// concurrent use. The zero value is not usable, use NewChain.
+type Chain struct {
+	mu sync.Mutex
+
+	counter uint64
+	// main holds the blocks of the main chain by height, blocks all known
+	// blocks (main and alternative) by hash.
+	main   []*block
+	blocks map[string]*block
+	// txs holds every transaction in the chain, in alternative blocks or
+	// in the pool by hash.
+	txs  map[string]*tx
+	pool []*tx
+	// outputs are the RingCT outputs of the main chain by global index.
+	outputs []*output
+	// spent maps the key images spent in the main chain to the spending
+	// transaction.
+	spent map[string]*tx
+	// templates are the block templates handed out by get_block_template,
+	// so that submit_block can accept them.
+	templates []*template
+	// decodeTx describes the transactions sent with /send_raw_transaction.
+	decodeTx func(blob []byte) (Tx, error)
+
+	failNext map[string][]error
+	fail     map[string]error
+}
+
+type block struct {
+	hash      string
+	prevHash  string
+	height    uint64
+	timestamp uint64
+	nonce     uint32
+	minerTx   *tx
+	txs       []*tx
+	reward    uint64
+	// cumulativeDifficulty includes the block itself.
+	cumulativeDifficulty uint64
+	alt                  bool
+	blob                 []byte
+}
+
+type tx struct {
+	hash       string
+	blob       []byte
+	fee        uint64
+	weight     uint64
+	keyImages  []string
+	outputs    int
+	unlockTime uint64
+	// seed derives the output keys: the tx public key of a coinbase
+	// transaction, the id otherwise.
+	seed string
+	// coinbase transactions have a height and a single output of amount.
+	coinbase bool
+	height   uint64
+	amount   uint64
+	// extraNonce is the space reserved in tx_extra of a block template.
+	extraNonce  []byte
+	doNotRelay  bool
+	keptByBlock bool
+	receiveTime uint64
+	// block is the main chain block containing the transaction, nil if
+	// it is in the pool or only in alternative blocks.
+	block *block
+	// outputIndices are the global indices of the outputs, valid if block
+	// is set.
+	outputIndices []uint64
+}
+
+type output struct {
+	key    string
+	mask   string
+	tx     *tx
+	height uint64
+}
+
+// NewChain returns a Chain of DefaultHeight blocks and an empty pool.
+func NewChain() *Chain {
+	c := &Chain{
+		blocks:   make(map[string]*block),
+		txs:      make(map[string]*tx),
+		spent:    make(map[string]*tx),
+		failNext: make(map[string][]error),
+		fail:     make(map[string]error),
+	}
+	for i := 0; i < DefaultHeight; i++ {
+		c.attach(c.newBlock(c.top(), nil))
+	}
+	return c
+}
+
+// Height returns the number of blocks in the main chain.
+func (c *Chain) Height() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return uint64(len(c.main))
+}
+
+// TopHash returns the hash of the last block of the main chain.
+func (c *Chain) TopHash() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.top().hash
+}
+
+// BlockHash returns the hash of the main chain block at height, or "" if
+// there is none.
+func (c *Chain) BlockHash(height uint64) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if height >= uint64(len(c.main)) {
+		return ""
+	}
+	return c.main[height].hash
+}
+
+// AddTx adds a transaction to the pool and returns its id. Adding a
+// transaction that is already known returns its id again.
+func (c *Chain) AddTx(t Tx) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, err := c.addTx(t)
+	if err != nil {
+		return "", err
+	}
+	return p.hash, nil
+}
+
+func (c *Chain) addTx(t Tx) (*tx, error) {
+	blob := t.Blob
+	if blob == nil {
+		blob = c.fakeTxBlob(t)
+	}
+	id := hashOf(blob)
+	if p, ok := c.txs[id]; ok {
+		return p, nil
+	}
+	seen := make(map[string]bool)
+	for _, ki := range t.KeyImages {
+		if b, err := hex.DecodeString(ki); err != nil || len(b) != 32 {
+			return nil, fmt.Errorf("invalid key image %q: want 64 hex characters", ki)
+		}
+		if seen[ki] || c.keyImageStatus(ki) != 0 {
+			return nil, fmt.Errorf("%w of key image %s", ErrDoubleSpend, ki)
+		}
+		seen[ki] = true
+	}
+	p := &tx{
+		hash:        id,
+		seed:        id,
+		blob:        blob,
+		fee:         t.Fee,
+		weight:      t.Weight,
+		keyImages:   append([]string(nil), t.KeyImages...),
+		outputs:     t.Outputs,
+		doNotRelay:  t.DoNotRelay,
+		receiveTime: c.now(),
+	}
+	if p.weight == 0 {
+		p.weight = uint64(len(blob))
+	}
+	if p.outputs == 0 {
+		p.outputs = 2
+	}
+	c.txs[id] = p
+	c.pool = append(c.pool, p)
+	return p, nil
+}
+
+// fakeTxBlob returns a unique blob for a transaction added without one.
+func (c *Chain) fakeTxBlob(t Tx) []byte {
+	c.counter++
+	var b []byte
+	b = binary.AppendUvarint(b, 2)
+	b = binary.AppendUvarint(b, c.counter)
+	b = binary.AppendUvarint(b, t.Fee)
+	for _, ki := range t.KeyImages {
+		b = append(b, ki...)
+	}
+	return b
+}
+
+// SetTxDecoder sets the function /send_raw_transaction uses to learn the
+// fee, key images and outputs of a transaction from its blob. Without one,
+// raw transactions have no fee and no key images, so they are never double
+// spends.
+func (c *Chain) SetTxDecoder(decode func(blob []byte) (Tx, error)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.decodeTx = decode
+}
+
+// RemoveTx removes transactions from the pool, like flush_txpool. No ids
+// means all of them.
+func (c *Chain) RemoveTx(ids ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.flushPool(ids)
+}
+
+func (c *Chain) flushPool(ids []string) {
+	remove := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		remove[id] = true
+	}
+	kept := c.pool[:0]
+	for _, p := range c.pool {
+		if len(ids) > 0 && !remove[p.hash] {
+			kept = append(kept, p)
+			continue
+		}
+		if !c.inAltBlock(p) {
+			delete(c.txs, p.hash)
+		}
+	}
+	c.pool = kept
+}
+
+// PoolHashes returns the ids of the transactions in the pool.
+func (c *Chain) PoolHashes() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	hashes := make([]string, len(c.pool))
+	for i, p := range c.pool {
+		hashes[i] = p.hash
+	}
+	return hashes
+}
+
+// MineBlocks appends n blocks to the main chain and returns their hashes.
+// The first block includes every relayed pool transaction.
+func (c *Chain) MineBlocks(n uint64) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.mine(n)
+}
+
+func (c *Chain) mine(n uint64) []string {
+	hashes := make([]string, 0, n)
+	for i := uint64(0); i < n; i++ {
+		b := c.newBlock(c.top(), c.relayedPool())
+		c.attach(b)
+		hashes = append(hashes, b.hash)
+	}
+	return hashes
+}
+
+// relayedPool returns the pool transactions a miner would include.
+func (c *Chain) relayedPool() []*tx {
+	var txs []*tx
+	for _, p := range c.pool {
+		if !p.doNotRelay {
+			txs = append(txs, p)
+		}
+	}
+	return txs
+}
+
+// PopBlocks removes the last n blocks from the main chain, like
+// /pop_blocks. Their transactions go back to the pool.
+func (c *Chain) PopBlocks(n uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pop(n)
+}
+
+func (c *Chain) pop(n uint64) {
+	if n >= uint64(len(c.main)) {
+		n = uint64(len(c.main)) - 1
+	}
+	for ; n > 0; n-- {
+		b := c.detach()
+		delete(c.blocks, b.hash)
+		delete(c.txs, b.minerTx.hash)
+	}
+}
+
+// Fork returns a Fork starting on top of the main chain block at height.
+// It panics if there is no such block.
+func (c *Chain) Fork(height uint64) *Fork {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if height >= uint64(len(c.main)) {
+		panic("daemontest: no block at height " + strconv.FormatUint(height, 10))
+	}
+	return &Fork{c: c, tip: c.main[height].hash}
+}
+
+// Reorg replaces the last depth blocks of the main chain by length new,
+// empty blocks and returns the hashes of the new blocks. The replaced
+// blocks remain known as an alternative chain and their transactions go
+// back to the pool. length must be greater than depth.
+func (c *Chain) Reorg(depth, length uint64) []string {
+	if length <= depth {
+		panic("daemontest: a reorg needs more new blocks than it replaces")
+	}
+	c.mu.Lock()
+	height := uint64(len(c.main)) - 1 - depth
+	c.mu.Unlock()
+	return c.Fork(height).MineBlocks(length)
+}
+
+// Fork is a branch of the chain. Blocks mined on a Fork are alternative
+// blocks until the branch is longer than the main chain, at which point
+// the chain reorganises to it.
+type Fork struct {
+	c   *Chain
+	tip string
+}
+
+// MineBlocks appends n empty blocks to the fork and returns their hashes.
+// If this makes the fork longer than the main chain it becomes the main
+// chain: the blocks it replaces become alternative blocks and their
+// transactions go back to the pool.
+func (f *Fork) MineBlocks(n uint64) []string {
+	f.c.mu.Lock()
+	defer f.c.mu.Unlock()
+	return f.c.mineFork(f, n)
+}
+
+func (c *Chain) mineFork(f *Fork, n uint64) []string {
+	hashes := make([]string, 0, n)
+	for i := uint64(0); i < n; i++ {
+		parent := c.blocks[f.tip]
+		b := c.newBlock(parent, nil)
+		b.alt = true
+		c.blocks[b.hash] = b
+		c.txs[b.minerTx.hash] = b.minerTx
+		f.tip = b.hash
+		hashes = append(hashes, b.hash)
+	}
+	if tip := c.blocks[f.tip]; tip.cumulativeDifficulty > c.top().cumulativeDifficulty {
+		c.switchTo(tip)
+	}
+	return hashes
+}
+
+// switchTo makes the alternative block tip the top of the main chain.
+func (c *Chain) switchTo(tip *block) {
+	var branch []*block
+	for b := tip; b.alt; b = c.blocks[b.prevHash] {
+		branch = append(branch, b)
+	}
+	fork := c.blocks[branch[len(branch)-1].prevHash]
+	for c.top() != fork {
+		c.detach().alt = true
+	}
+	for i := len(branch) - 1; i >= 0; i-- {
+		b := branch[i]
+		b.alt = false
+		c.attach(b)
+	}
+}
+
+// attach appends b to the main chain, moving its transactions out of the
+// pool.
+func (c *Chain) attach(b *block) {
+	c.main = append(c.main, b)
+	c.blocks[b.hash] = b
+	mined := make(map[*tx]bool, len(b.txs))
+	for _, t := range append([]*tx{b.minerTx}, b.txs...) {
+		c.txs[t.hash] = t
+		t.block = b
+		t.outputIndices = t.outputIndices[:0]
+		for i := 0; i < t.outputs; i++ {
+			t.outputIndices = append(t.outputIndices, uint64(len(c.outputs)))
+			c.outputs = append(c.outputs, &output{
+				key:    outputKey(t.seed, i),
+				mask:   hashOf([]byte(t.seed), []byte("mask"), []byte{byte(i)}),
+				tx:     t,
+				height: b.height,
+			})
+		}
+		for _, ki := range t.keyImages {
+			c.spent[ki] = t
+		}
+		mined[t] = true
+	}
+	kept := c.pool[:0]
+	for _, p := range c.pool {
+		if !mined[p] {
+			kept = append(kept, p)
+		}
+	}
+	c.pool = kept
+}
+
+// detach removes the top block from the main chain and returns it. Its
+// transactions go back to the pool.
+func (c *Chain) detach() *block {
+	b := c.top()
+	c.main = c.main[:len(c.main)-1]
+	for _, t := range append([]*tx{b.minerTx}, b.txs...) {
+		c.outputs = c.outputs[:len(c.outputs)-t.outputs]
+		t.block = nil
+		t.outputIndices = nil
+		for _, ki := range t.keyImages {
+			delete(c.spent, ki)
+		}
+	}
+	restored := make([]*tx, 0, len(b.txs)+len(c.pool))
+	for _, t := range b.txs {
+		t.keptByBlock = true
+		restored = append(restored, t)
+	}
+	c.pool = append(restored, c.pool...)
+	return b
+}
+
+func (c *Chain) top() *block {
+	if len(c.main) == 0 {
+		return nil
+	}
+	return c.main[len(c.main)-1]
+}
+
+// newBlock builds a block on top of parent (nil for genesis) containing
+// txs. It is not added to the chain.
+func (c *Chain) newBlock(parent *block, txs []*tx) *block {
+	b := &block{
+		timestamp:            GenesisTime,
+		txs:                  append([]*tx(nil), txs...),
+		reward:               BlockReward,
+		cumulativeDifficulty: Difficulty,
+	}
+	if parent != nil {
+		b.prevHash = parent.hash
+		b.height = parent.height + 1
+		b.timestamp = timestamp(b.height)
+		b.cumulativeDifficulty = parent.cumulativeDifficulty + Difficulty
+	} else {
+		b.prevHash = zeroHash
+	}
+	for _, t := range txs {
+		b.reward += t.fee
+	}
+	c.counter++
+	b.nonce = uint32(c.counter)
+	b.minerTx = &tx{
+		seed:       hashOf([]byte("miner"), []byte(strconv.FormatUint(c.counter, 10))),
+		outputs:    1,
+		unlockTime: b.height + MinedMoneyUnlockWindow,
+		coinbase:   true,
+		height:     b.height,
+		amount:     b.reward,
+	}
+	b.seal()
+	return b
+}
+
+// seal computes the blobs and hashes of b and its miner transaction.
+func (b *block) seal() {
+	t := b.minerTx
+	t.blob = minerTxBlob(t)
+	t.hash = hashOf(t.blob)
+	t.weight = uint64(len(t.blob))
+	b.blob = blockBlob(b)
+	b.hash = hashOf(b.blob)
+}
+
+// keyImageStatus returns 0 for an unspent key image, 1 if it is spent in
+// the main chain and 2 if it is spent in the pool, as /is_key_image_spent.
+func (c *Chain) keyImageStatus(ki string) uint64 {
+	if _, ok := c.spent[ki]; ok {
+		return 1
+	}
+	for _, p := range c.pool {
+		for _, k := range p.keyImages {
+			if k == ki {
+				return 2
+			}
+		}
+	}
+	return 0
+}
+
+func (c *Chain) inAltBlock(t *tx) bool {
+	for _, b := range c.blocks {
+		if !b.alt {
+			continue
+		}
+		for _, bt := range b.txs {
+			if bt == t {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// now is the simulated current time: one block time after the top block.
+func (c *Chain) now() uint64 {
+	if top := c.top(); top != nil {
+		return top.timestamp + BlockTime
+	}
+	return GenesisTime
+}
+
+func (c *Chain) unlocked(o *output) bool {
+	height := uint64(len(c.main))
+	return height >= o.height+SpendableAge && height >= o.tx.unlockTime
+}
+
+// FailNext makes the next call of method fail with err. method is a
+// JSON-RPC method ("get_info") or an endpoint path ("/get_height"). A
+// daemon.ErrorCode or *daemon.RPCError is sent as a JSON-RPC error object
+// (as status "Failed" on the other endpoints) and a *daemon.StatusError
+// as the "status" and "reason" fields. Calls queue up, so FailNext twice
+// fails two calls.
+func (c *Chain) FailNext(method string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failNext[method] = append(c.failNext[method], err)
+}
+
+// Fail makes every call of method fail with err until ClearErrors is
+// called. See FailNext.
+func (c *Chain) Fail(method string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fail[method] = err
+}
+
+// ClearErrors removes all errors set up by FailNext and Fail.
+func (c *Chain) ClearErrors() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failNext = make(map[string][]error)
+	c.fail = make(map[string]error)
+}
+
+// injected returns the error method must fail with, if any. c.mu must be
+// held.
+func (c *Chain) injected(method string) error {
+	if errs := c.failNext[method]; len(errs) > 0 {
+		c.failNext[method] = errs[1:]
+		return errs[0]
+	}
+	return c.fail[method]
+}
+
+const zeroHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+func hashOf(parts ...[]byte) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write(p)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func timestamp(height uint64) uint64 {
+	return GenesisTime + height*BlockTime
+}
+
+// grow appends n zero elements to s. It lets the methods fill response
+// slices of anonymous struct types without repeating the type.
+func grow[S ~[]E, E any](s S, n int) S {
+	return append(s, make(S, n)...)
+}
@@ -0,0 +1,479 @@
+package daemontest
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/boomhut/go-monero-rpc-client/daemon"
+	"github.com/stretchr/testify/assert"
+)
+
+func newClient(t *testing.T, c *Chain) daemon.Client {
+	t.Helper()
+	srv := NewServer(c)
+	t.Cleanup(srv.Close)
+	return daemon.New(daemon.Config{Address: srv.Address()})
+}
+
+func keyImage(n byte) string {
+	return strings.Repeat(hex.EncodeToString([]byte{n}), 32)
+}
+
+func TestChainQueries(t *testing.T) {
+	c := NewChain()
+	cl := newClient(t, c)
+
+	height, err := cl.GetHeight()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(DefaultHeight), height.Height)
+	assert.Equal(t, c.TopHash(), height.Hash)
+
+	info, err := cl.GetInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(DefaultHeight), info.Height)
+	assert.Equal(t, c.TopHash(), info.TopBlockHash)
+	assert.Equal(t, uint64(DefaultHeight*Difficulty), info.Cumulativeifficulty)
+
+	hash, err := cl.OnGetBlockHash(10)
+	assert.NoError(t, err)
+	assert.Equal(t, c.BlockHash(10), hash)
+
+	header, err := cl.GetBlockHeaderByHeight(10, false)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, header.BlockHeader.Hash)
+	assert.Equal(t, c.BlockHash(9), header.BlockHeader.PrevHash)
+	assert.Equal(t, uint64(GenesisTime+10*BlockTime), header.BlockHeader.Timestamp)
+	assert.Equal(t, uint64(DefaultHeight-11), header.BlockHeader.Depth)
+
+	headers, err := cl.GetBlockHeadersRange(5, 7, false)
+	assert.NoError(t, err)
+	assert.Len(t, headers.Headers, 3)
+
+	_, err = cl.GetBlockHeaderByHeight(DefaultHeight, false)
+	assert.True(t, errors.Is(err, daemon.ErrTooBigHeight), "got %v", err)
+	_, err = cl.GetBlockHeaderByHash(zeroHash, false)
+	assert.True(t, errors.Is(err, daemon.ErrInternalError), "got %v", err)
+
+	block, err := cl.GetBlock(uint64(10), false)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, block.BlockHeader.Hash)
+	blob, err := hex.DecodeString(block.Blob)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, hashOf(blob))
+	decoded, err := block.Block()
+	assert.NoError(t, err)
+	assert.Equal(t, c.BlockHash(9), decoded.PrevID.String())
+	parsed, err := block.ParseBlob()
+	assert.NoError(t, err)
+	assert.Equal(t, decoded, parsed)
+	assert.Equal(t, uint64(10), decoded.MinerTx.Inputs[0].Gen.Height)
+}
+
+func TestPoolAndMining(t *testing.T) {
+	c := NewChain()
+	cl := newClient(t, c)
+
+	txid, err := c.AddTx(Tx{Fee: 30000000, KeyImages: []string{keyImage(1)}})
+	assert.NoError(t, err)
+	_, err = c.AddTx(Tx{Fee: 30000000, KeyImages: []string{keyImage(1)}})
+	assert.True(t, errors.Is(err, ErrDoubleSpend), "got %v", err)
+	_, err = c.AddTx(Tx{Fee: 30000000, KeyImages: []string{"not hex"}})
+	assert.Error(t, err)
+
+	pool, err := cl.GetTransactionPool()
+	assert.NoError(t, err)
+	assert.Len(t, pool.Transactions, 1)
+	assert.Equal(t, txid, pool.Transactions[0].IDHash)
+	assert.Equal(t, uint64(30000000), pool.Transactions[0].Fee)
+	assert.Len(t, pool.SpentKeyImages, 1)
+
+	spent, err := cl.IsKeyImageSpent([]string{keyImage(1), keyImage(2)})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{2, 0}, spent.SpentStatus)
+
+	mined := c.MineBlocks(2)
+	assert.Len(t, mined, 2)
+	hashes, err := cl.GetTransactionPoolHashes()
+	assert.NoError(t, err)
+	assert.Empty(t, hashes.TxHashes)
+
+	txs, err := cl.GetTransactions([]string{txid, zeroHash}, true, false, false)
+	assert.NoError(t, err)
+	assert.Len(t, txs.Txs, 1)
+	assert.Equal(t, []string{zeroHash}, txs.MissedTx)
+	assert.False(t, txs.Txs[0].InPool)
+	assert.Equal(t, uint64(DefaultHeight), txs.Txs[0].BlockHeight)
+	assert.Len(t, txs.Txs[0].OutputIndices, 2)
+	assert.Contains(t, txs.Txs[0].AsJSON, keyImage(1))
+	decoded, err := txs.Transactions()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(30000000), decoded[0].Fee())
+	assert.Equal(t, keyImage(1), decoded[0].KeyImages()[0].String())
+	assert.Len(t, decoded[0].Outputs, 2)
+
+	_, err = daemon.GetDecodedTransactions(context.Background(), cl, []string{txid, zeroHash}, false)
+	assert.True(t, errors.Is(err, daemon.ErrTxNotFound), "got %v", err)
+
+	block, err := cl.GetBlock(mined[0], false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{txid}, block.TxHashes)
+	parsedBlock, err := block.ParseBlob()
+	assert.NoError(t, err)
+	assert.Equal(t, txid, parsedBlock.TxHashes[0].String())
+	assert.Equal(t, uint64(BlockReward+30000000), block.BlockHeader.Reward)
+	decoded, err = daemon.GetDecodedTransactions(context.Background(), cl, []string{block.MinerTxHash, txid}, true)
+	assert.NoError(t, err)
+	assert.True(t, decoded[0].IsCoinbase())
+	assert.Equal(t, block.BlockHeader.Height, decoded[0].Inputs[0].Gen.Height)
+	assert.Equal(t, uint64(BlockReward+30000000), decoded[0].Outputs[0].Amount)
+	assert.False(t, decoded[1].IsCoinbase())
+
+	spent, err = cl.IsKeyImageSpent([]string{keyImage(1)})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{1}, spent.SpentStatus)
+
+	outs, err := cl.GetOuts([]daemon.OutputIndex{{Index: txs.Txs[0].OutputIndices[0]}}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, txid, outs.Outs[0].Txid)
+	assert.False(t, outs.Outs[0].Unlocked)
+}
+
+func TestDoNotRelay(t *testing.T) {
+	c := NewChain()
+	cl := newClient(t, c)
+
+	txid, err := c.AddTx(Tx{Fee: 1, DoNotRelay: true})
+	assert.NoError(t, err)
+	c.MineBlocks(1)
+	assert.Equal(t, []string{txid}, c.PoolHashes())
+
+	_, err = cl.RelayTx([]string{txid})
+	assert.NoError(t, err)
+	_, err = cl.RelayTx([]string{zeroHash})
+	assert.True(t, errors.Is(err, daemon.ErrWrongParam), "got %v", err)
+	c.MineBlocks(1)
+	assert.Empty(t, c.PoolHashes())
+}
+
+func TestReorg(t *testing.T) {
+	c := NewChain()
+	cl := newClient(t, c)
+
+	txid, err := c.AddTx(Tx{Fee: 1, KeyImages: []string{keyImage(1)}})
+	assert.NoError(t, err)
+	old := c.MineBlocks(2)
+
+	fork := c.Fork(DefaultHeight - 1)
+	alt := fork.MineBlocks(2)
+	assert.Equal(t, old[1], c.TopHash(), "an equally long fork does not reorganise")
+	chains, err := cl.GetAlternateChains()
+	assert.NoError(t, err)
+	assert.Len(t, chains.Chains, 1)
+	assert.Equal(t, alt[1], chains.Chains[0].BlockHash)
+	assert.Equal(t, uint64(2), chains.Chains[0].Length)
+
+	alt = append(alt, fork.MineBlocks(1)...)
+	assert.Equal(t, alt[2], c.TopHash())
+	assert.Equal(t, []string{txid}, c.PoolHashes())
+	spent, err := cl.IsKeyImageSpent([]string{keyImage(1)})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{2}, spent.SpentStatus)
+
+	header, err := cl.GetBlockHeaderByHash(old[0], false)
+	assert.NoError(t, err)
+	assert.True(t, header.BlockHeader.OrphanStatus)
+	altHashes, err := cl.GetAltBlocksHashes()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, old, altHashes.BlkHashes)
+
+	pool, err := cl.GetTransactionPool()
+	assert.NoError(t, err)
+	assert.True(t, pool.Transactions[0].KeptByBlock)
+
+	replaced := c.Reorg(1, 2)
+	assert.Len(t, replaced, 2)
+	assert.Equal(t, uint64(DefaultHeight+4), c.Height())
+}
+
+func TestPopBlocks(t *testing.T) {
+	c := NewChain()
+	cl := newClient(t, c)
+
+	txid, err := c.AddTx(Tx{Fee: 1})
+	assert.NoError(t, err)
+	c.MineBlocks(3)
+
+	res, err := cl.PopBlocks(3)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(DefaultHeight), res.Height)
+	assert.Equal(t, []string{txid}, c.PoolHashes())
+	_, err = cl.GetBlockHeaderByHeight(DefaultHeight, false)
+	assert.True(t, errors.Is(err, daemon.ErrTooBigHeight), "got %v", err)
+}
+
+func TestBlockTemplate(t *testing.T) {
+	c := NewChain()
+	cl := newClient(t, c)
+	txid, err := c.AddTx(Tx{Fee: 5})
+	assert.NoError(t, err)
+
+	_, err = cl.GetBlockTemplate("", 0)
+	assert.True(t, errors.Is(err, daemon.ErrWrongWalletAddress), "got %v", err)
+
+	tmpl, err := cl.GetBlockTemplate("miner", 8)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(DefaultHeight), tmpl.Height)
+	assert.Equal(t, uint64(BlockReward+5), tmpl.ExpectedReward)
+	blob, err := hex.DecodeString(tmpl.BlocktemplateBlob)
+	assert.NoError(t, err)
+	copy(blob[tmpl.ReservedOffset:], "extranon")
+	blob[nonceOffset(c.main[0])] = 0x42
+
+	_, err = cl.SubmitBlock(hex.EncodeToString(blob[1:]))
+	assert.True(t, errors.Is(err, daemon.ErrBlockNotAccepted), "got %v", err)
+	_, err = cl.SubmitBlock(hex.EncodeToString(blob))
+	assert.NoError(t, err)
+	assert.Equal(t, hashOf(blob), c.TopHash())
+	assert.Empty(t, c.PoolHashes())
+
+	block, err := cl.GetBlock(c.TopHash(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0x42), block.BlockHeader.Nonce)
+	assert.Equal(t, []string{txid}, block.TxHashes)
+
+	gen, err := cl.GenerateBlocks(2, "miner")
+	assert.NoError(t, err)
+	assert.Len(t, gen.Blocks, 2)
+	assert.Equal(t, uint64(DefaultHeight+3), gen.Height)
+}
+
+func TestSendRawTransaction(t *testing.T) {
+	c := NewChain()
+	c.SetTxDecoder(func(blob []byte) (Tx, error) {
+		if blob[0] != 2 {
+			return Tx{}, errors.New("unsupported version")
+		}
+		return Tx{Fee: 10, KeyImages: []string{keyImage(blob[1])}}, nil
+	})
+	cl := newClient(t, c)
+
+	res, err := cl.SendRawTransaction("0201", false)
+	assert.NoError(t, err)
+	assert.False(t, res.NotRelayed)
+	assert.Len(t, c.PoolHashes(), 1)
+
+	_, err = cl.SendRawTransaction("0201ff", false)
+	var serr *daemon.StatusError
+	assert.True(t, errors.As(err, &serr), "got %v", err)
+	var flags daemon.ResponseSendRawTransaction
+	assert.NoError(t, json.Unmarshal(serr.Response, &flags))
+	assert.True(t, flags.DoubleSpend)
+
+	_, err = cl.SendRawTransaction("0102", false)
+	assert.True(t, errors.Is(err, daemon.ErrStatus), "got %v", err)
+	_, err = cl.SendRawTransaction("zz", false)
+	assert.True(t, errors.Is(err, daemon.ErrStatus), "got %v", err)
+}
+
+func TestFailNext(t *testing.T) {
+	c := NewChain()
+	cl := newClient(t, c)
+
+	c.FailNext("get_info", daemon.ErrCoreBusy)
+	_, err := cl.GetInfo()
+	assert.True(t, errors.Is(err, daemon.ErrBusy), "got %v", err)
+	_, err = cl.GetInfo()
+	assert.NoError(t, err)
+
+	c.Fail("/get_height", &daemon.StatusError{Status: daemon.StatusBusy})
+	_, err = cl.GetHeight()
+	assert.True(t, errors.Is(err, daemon.ErrBusy), "got %v", err)
+	c.ClearErrors()
+	_, err = cl.GetHeight()
+	assert.NoError(t, err)
+
+	_, err = cl.GetConnections()
+	assert.True(t, errors.Is(err, daemon.ErrorCode(codeMethodNotFound)), "got %v", err)
+	_, err = cl.GetLimit()
+	assert.True(t, errors.Is(err, daemon.ErrHTTPStatus), "got %v", err)
+
+	calls := []daemon.BatchCall{
+		{Method: "get_block_count", Result: &daemon.ResponseGetBlockCount{}},
+		{Method: "get_block_header_by_height", Params: daemon.RequestGetBlockHeaderByHeight{Height: 1e6}},
+	}
+	assert.NoError(t, cl.CallBatch(context.Background(), calls))
+	assert.NoError(t, calls[0].Error)
+	assert.Equal(t, uint64(DefaultHeight), calls[0].Result.(*daemon.ResponseGetBlockCount).Count)
+	assert.True(t, errors.Is(calls[1].Error, daemon.ErrTooBigHeight), "got %v", calls[1].Error)
+}
+
+func TestOutputDistribution(t *testing.T) {
+	c := NewChain()
+	cl := newClient(t, c)
+	_, err := c.AddTx(Tx{Outputs: 3})
+	assert.NoError(t, err)
+	c.MineBlocks(1)
+
+	dist, err := cl.GetOutputDistribution([]uint64{0}, true, DefaultHeight-1, 0)
+	assert.NoError(t, err)
+	d := dist.Distributions[0]
+	assert.Equal(t, uint64(DefaultHeight-1), d.Base)
+	assert.Equal(t, []uint64{DefaultHeight, DefaultHeight + 4}, d.Distribution)
+
+	dist, err = cl.GetOutputDistribution([]uint64{0}, false, DefaultHeight, DefaultHeight)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{4}, dist.Distributions[0].Distribution)
+
+	for _, compress := range []bool{false, true} {
+		bin, err := cl.GetOutputDistributionBin([]uint64{0}, false, 0, 0, compress)
+		assert.NoError(t, err)
+		d := bin.Distributions[0]
+		assert.Equal(t, compress, d.Compress)
+		assert.Len(t, d.Distribution, DefaultHeight+1)
+		assert.Equal(t, uint64(4), d.Distribution[DefaultHeight])
+		cumulative := d.CumulativeCounts()
+		assert.Equal(t, uint64(DefaultHeight+4), cumulative[DefaultHeight])
+		n, ok := d.OutputsUpTo(DefaultHeight - 1)
+		assert.True(t, ok)
+		assert.Equal(t, uint64(DefaultHeight), n)
+	}
+
+	n, err := daemon.RingCTOutputsUpTo(context.Background(), cl, DefaultHeight)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(DefaultHeight+4), n)
+	n, err = daemon.RingCTOutputsUpTo(context.Background(), cl, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), n)
+}
+
+func TestGetBlocksBin(t *testing.T) {
+	c := NewChain()
+	cl := newClient(t, c)
+
+	txid, err := c.AddTx(Tx{Fee: 1, Outputs: 3})
+	assert.NoError(t, err)
+	c.MineBlocks(1)
+	pending, err := c.AddTx(Tx{Fee: 2})
+	assert.NoError(t, err)
+
+	genesis, err := daemon.ParseHash(c.BlockHash(0))
+	assert.NoError(t, err)
+	known, err := daemon.ParseHash(c.BlockHash(DefaultHeight - 1))
+	assert.NoError(t, err)
+	res, err := cl.GetBlocksBin(&daemon.RequestGetBlocksBin{
+		RequestedInfo: daemon.RequestedBlocksAndPool,
+		BlockIDs:      []daemon.Hash{known, genesis},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(DefaultHeight-1), res.StartHeight)
+	assert.Equal(t, uint64(DefaultHeight+1), res.CurrentHeight)
+	assert.Equal(t, c.TopHash(), res.TopBlockHash.String())
+	assert.Len(t, res.Blocks, 2)
+	assert.Len(t, res.OutputIndices, 2)
+	assert.Len(t, res.Blocks[1].Txs, 1)
+	assert.Len(t, res.OutputIndices[1].Indices, 2, "miner tx and one tx")
+	assert.Len(t, res.OutputIndices[1].Indices[1].Indices, 3)
+	assert.Equal(t, daemon.PoolInfoFull, res.PoolInfoExtent)
+	assert.Len(t, res.AddedPoolTxs, 1)
+	assert.Equal(t, pending, res.AddedPoolTxs[0].TxHash.String())
+
+	txs, err := cl.GetTransactions([]string{txid}, false, false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, txs.Txs[0].AsHex, hex.EncodeToString(res.Blocks[1].Txs[0].Blob))
+	assert.Equal(t, txs.Txs[0].OutputIndices, res.OutputIndices[1].Indices[1].Indices)
+
+	res, err = cl.GetBlocksBin(&daemon.RequestGetBlocksBin{
+		BlockIDs:      []daemon.Hash{genesis},
+		StartHeight:   1,
+		Prune:         true,
+		NoMinerTx:     true,
+		MaxBlockCount: 10,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, res.Blocks, 10)
+	assert.True(t, res.Blocks[0].Pruned)
+	assert.NotZero(t, res.Blocks[0].BlockWeight)
+	assert.Empty(t, res.OutputIndices[0].Indices)
+	assert.Empty(t, res.AddedPoolTxs)
+
+	// The split height wins over a lower start height.
+	res, err = cl.GetBlocksBin(&daemon.RequestGetBlocksBin{BlockIDs: []daemon.Hash{known, genesis}, StartHeight: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(DefaultHeight-1), res.StartHeight)
+
+	_, err = cl.GetBlocksBin(&daemon.RequestGetBlocksBin{BlockIDs: []daemon.Hash{genesis}, StartHeight: DefaultHeight + 1})
+	var serr *daemon.StatusError
+	assert.True(t, errors.As(err, &serr), "got %v", err)
+	// A start height without a chain history is rejected.
+	_, err = cl.GetBlocksBin(&daemon.RequestGetBlocksBin{StartHeight: 1})
+	assert.True(t, errors.As(err, &serr), "got %v", err)
+	_, err = cl.GetBlocksBin(&daemon.RequestGetBlocksBin{BlockIDs: []daemon.Hash{known}})
+	assert.True(t, errors.As(err, &serr), "got %v", err)
+
+	byHeight, err := cl.GetBlocksByHeightBin([]uint64{0, DefaultHeight})
+	assert.NoError(t, err)
+	assert.Len(t, byHeight.Blocks, 2)
+	assert.Equal(t, txs.Txs[0].AsHex, hex.EncodeToString(byHeight.Blocks[1].Txs[0].Blob))
+	_, err = cl.GetBlocksByHeightBin([]uint64{DefaultHeight + 1})
+	assert.True(t, errors.As(err, &serr), "got %v", err)
+	assert.Equal(t, "Error retrieving block at height 101", serr.Status)
+}
+
+func TestBinaryOutputs(t *testing.T) {
+	c := NewChain()
+	cl := newClient(t, c)
+
+	txid, err := c.AddTx(Tx{Fee: 1})
+	assert.NoError(t, err)
+	id, err := daemon.ParseHash(txid)
+	assert.NoError(t, err)
+	pool, err := cl.GetTransactionPoolHashesBin()
+	assert.NoError(t, err)
+	assert.Equal(t, []daemon.Hash{id}, pool.TxHashes)
+	_, err = cl.GetOIndexesBin(id)
+	assert.True(t, errors.Is(err, daemon.ErrStatus), "got %v", err)
+
+	c.MineBlocks(1)
+	indexes, err := cl.GetOIndexesBin(id)
+	assert.NoError(t, err)
+	assert.Len(t, indexes.OIndexes, 2)
+
+	outs, err := cl.GetOuts([]daemon.OutputIndex{{Index: indexes.OIndexes[1]}}, true)
+	assert.NoError(t, err)
+	outsBin, err := cl.GetOutsBin([]daemon.OutputIndex{{Index: indexes.OIndexes[1]}}, true)
+	assert.NoError(t, err)
+	assert.Len(t, outsBin.Outs, 1)
+	assert.Equal(t, outs.Outs[0].Key, outsBin.Outs[0].Key.String())
+	assert.Equal(t, outs.Outs[0].Mask, outsBin.Outs[0].Mask.String())
+	assert.Equal(t, id, outsBin.Outs[0].Txid)
+	assert.Equal(t, uint64(DefaultHeight), outsBin.Outs[0].Height)
+	outsBin, err = cl.GetOutsBin([]daemon.OutputIndex{{Index: 0}}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, daemon.Hash{}, outsBin.Outs[0].Txid)
+	_, err = cl.GetOutsBin([]daemon.OutputIndex{{Index: 1 << 40}}, false)
+	assert.True(t, errors.Is(err, daemon.ErrStatus), "got %v", err)
+
+	pool, err = cl.GetTransactionPoolHashesBin()
+	assert.NoError(t, err)
+	assert.Empty(t, pool.TxHashes)
+
+	genesis, err := daemon.ParseHash(c.BlockHash(0))
+	assert.NoError(t, err)
+	known, err := daemon.ParseHash(c.BlockHash(DefaultHeight - 2))
+	assert.NoError(t, err)
+	hashes, err := cl.GetHashesBin([]daemon.Hash{{1}, known, genesis}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(DefaultHeight-2), hashes.StartHeight)
+	assert.Equal(t, uint64(DefaultHeight+1), hashes.CurrentHeight)
+	assert.Len(t, hashes.BlockIDs, 3)
+	assert.Equal(t, c.TopHash(), hashes.BlockIDs[2].String())
+	hashes, err = cl.GetHashesBin([]daemon.Hash{genesis}, 0)
+	assert.NoError(t, err)
+	assert.Len(t, hashes.BlockIDs, DefaultHeight+1)
+	_, err = cl.GetHashesBin([]daemon.Hash{known}, 0)
+	assert.True(t, errors.Is(err, daemon.ErrStatus), "got %v", err)
+}
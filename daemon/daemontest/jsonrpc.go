@@ -0,0 +1,523 @@
+package daemontest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/boomhut/go-monero-rpc-client/daemon"
+)
+
+// The methods below implement the JSON-RPC methods. They are called by the
+// Server with c.mu held.
+
+func (c *Chain) header(b *block, fillPowHash bool) daemon.BlockHeader {
+	size, weight := uint64(len(b.blob)), b.minerTx.weight
+	for _, t := range b.txs {
+		size += uint64(len(t.blob))
+		weight += t.weight
+	}
+	h := daemon.BlockHeader{
+		BlockSize:                size,
+		BlockWeight:              weight,
+		CumulativeDifficulty:     b.cumulativeDifficulty,
+		Difficulty:               Difficulty,
+		Hash:                     b.hash,
+		Height:                   b.height,
+		LongTermWeight:           weight,
+		MajorVersion:             MajorVersion,
+		MinerTxHash:              b.minerTx.hash,
+		MinorVersion:             MajorVersion,
+		Nonce:                    uint64(b.nonce),
+		NumTxes:                  uint64(len(b.txs)),
+		OrphanStatus:             b.alt,
+		PrevHash:                 b.prevHash,
+		Reward:                   b.reward,
+		Timestamp:                b.timestamp,
+		WideCumulativeDifficulty: wide(b.cumulativeDifficulty),
+		WideDifficulty:           wide(Difficulty),
+	}
+	if !b.alt {
+		h.Depth = c.top().height - b.height
+	}
+	if fillPowHash {
+		h.PowHash = hashOf([]byte("pow"), b.blob)
+	}
+	return h
+}
+
+func wide(n uint64) string {
+	return fmt.Sprintf("0x%x", n)
+}
+
+func (c *Chain) tooBigHeight(height uint64) error {
+	return rpcError(daemon.ErrTooBigHeight, "Requested block height: %d greater than current top block height: %d", height, c.top().height)
+}
+
+func (c *Chain) getBlockCount() (*daemon.ResponseGetBlockCount, error) {
+	return &daemon.ResponseGetBlockCount{Count: uint64(len(c.main)), Status: daemon.StatusOK}, nil
+}
+
+func (c *Chain) onGetBlockHash(req *daemon.RequestOnGetBlockHash) (*daemon.ResponseOnGetBlockHash, error) {
+	if len(*req) != 1 {
+		return nil, rpcError(daemon.ErrWrongParam, "Wrong parameters, expected height")
+	}
+	height := (*req)[0]
+	if height >= uint64(len(c.main)) {
+		return nil, c.tooBigHeight(height)
+	}
+	hash := daemon.ResponseOnGetBlockHash(c.main[height].hash)
+	return &hash, nil
+}
+
+// template is a block handed out by get_block_template.
+type template struct {
+	block *block
+	// nonceOffset and reservedOffset locate the bytes of the blob a miner
+	// may change.
+	nonceOffset    int
+	reservedOffset int
+	reserveSize    int
+}
+
+// matches reports whether blob is t with only the nonce and the reserved
+// bytes changed.
+func (t *template) matches(blob []byte) bool {
+	want := t.block.blob
+	if len(blob) != len(want) {
+		return false
+	}
+	masked := func(i int) bool {
+		return (i >= t.nonceOffset && i < t.nonceOffset+4) ||
+			(i >= t.reservedOffset && i < t.reservedOffset+t.reserveSize)
+	}
+	for i := range blob {
+		if blob[i] != want[i] && !masked(i) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Chain) getBlockTemplate(req *daemon.RequestGetBlockTemplate) (*daemon.ResponseGetBlockTemplate, error) {
+	if req.WalletAddress == "" {
+		return nil, rpcError(daemon.ErrWrongWalletAddress, "Failed to parse wallet address")
+	}
+	if req.ReserveSize > 255 {
+		return nil, rpcError(daemon.ErrTooBigReserveSize, "Too big reserved size, maximum 255")
+	}
+	parent := c.top()
+	if req.PrevBlock != "" {
+		if parent = c.blocks[req.PrevBlock]; parent == nil {
+			return nil, rpcError(daemon.ErrInternalError, "Internal error: failed to create block template")
+		}
+	}
+	b := c.newBlock(parent, c.relayedPool())
+	b.nonce = 0
+	b.minerTx.extraNonce = make([]byte, req.ReserveSize)
+	b.seal()
+
+	t := &template{block: b, nonceOffset: nonceOffset(b), reserveSize: int(req.ReserveSize)}
+	if t.reserveSize > 0 {
+		t.reservedOffset = bytes.Index(b.blob, b.minerTx.extra()) + len(b.minerTx.extra()) - t.reserveSize
+	}
+	c.templates = append(c.templates, t)
+
+	return &daemon.ResponseGetBlockTemplate{
+		Blockhashing_blob: hex.EncodeToString(hashingBlob(b)),
+		BlocktemplateBlob: hex.EncodeToString(b.blob),
+		Difficulty:        Difficulty,
+		ExpectedReward:    b.reward,
+		Height:            b.height,
+		PrevHash:          b.prevHash,
+		ReservedOffset:    uint64(t.reservedOffset),
+		SeedHash:          c.main[0].hash,
+		Status:            daemon.StatusOK,
+		WideDifficulty:    wide(Difficulty),
+	}, nil
+}
+
+// nonceOffset returns the offset of the nonce in the blob of b.
+func nonceOffset(b *block) int {
+	var buf []byte
+	buf = binary.AppendUvarint(buf, MajorVersion)
+	buf = binary.AppendUvarint(buf, MajorVersion)
+	buf = binary.AppendUvarint(buf, b.timestamp)
+	return len(buf) + 32
+}
+
+// hashingBlob returns the header of b followed by a root hash of its
+// transactions and their number, like get_block_hashing_blob.
+func hashingBlob(b *block) []byte {
+	n := nonceOffset(b) + 4
+	buf := append([]byte(nil), b.blob[:n]...)
+	ids := mustHex(b.minerTx.hash)
+	for _, t := range b.txs {
+		ids = append(ids, mustHex(t.hash)...)
+	}
+	buf = append(buf, mustHex(hashOf(ids))...)
+	return binary.AppendUvarint(buf, uint64(len(b.txs)+1))
+}
+
+func (c *Chain) submitBlock(req *daemon.RequestSubmitBlock) (*daemon.ResponseSubmitBlock, error) {
+	if len(*req) != 1 {
+		return nil, rpcError(daemon.ErrWrongParam, "Wrong param")
+	}
+	blob, err := hex.DecodeString((*req)[0])
+	if err != nil {
+		return nil, rpcError(daemon.ErrWrongBlockblob, "Wrong block blob")
+	}
+	for i, t := range c.templates {
+		if !t.matches(blob) {
+			continue
+		}
+		b := t.block
+		if b.prevHash != c.top().hash {
+			break
+		}
+		for _, p := range b.txs {
+			if p.block != nil {
+				return nil, rpcError(daemon.ErrBlockNotAccepted, "Block not accepted")
+			}
+		}
+		b.nonce = binary.LittleEndian.Uint32(blob[t.nonceOffset:])
+		copy(b.minerTx.extraNonce, blob[t.reservedOffset:])
+		b.seal()
+		c.templates = append(c.templates[:i], c.templates[i+1:]...)
+		c.attach(b)
+		return &daemon.ResponseSubmitBlock{Status: daemon.StatusOK}, nil
+	}
+	return nil, rpcError(daemon.ErrBlockNotAccepted, "Block not accepted")
+}
+
+func (c *Chain) generateBlocks(req *daemon.RequestGenerateBlocks) (*daemon.ResponseGenerateBlocks, error) {
+	if req.WalletAddress == "" {
+		return nil, rpcError(daemon.ErrWrongWalletAddress, "Failed to parse wallet address")
+	}
+	var hashes []string
+	if req.PrevBlock == "" || req.PrevBlock == c.top().hash {
+		hashes = c.mine(req.AmountOfBlocks)
+	} else {
+		if _, ok := c.blocks[req.PrevBlock]; !ok {
+			return nil, rpcError(daemon.ErrInternalError, "Internal error: failed to generate block")
+		}
+		hashes = c.mineFork(&Fork{c: c, tip: req.PrevBlock}, req.AmountOfBlocks)
+	}
+	return &daemon.ResponseGenerateBlocks{
+		Blocks: hashes,
+		Height: uint64(len(c.main)),
+		Status: daemon.StatusOK,
+	}, nil
+}
+
+func (c *Chain) getLastBlockHeader() (*daemon.ResponseGetLastBlockHeader, error) {
+	return &daemon.ResponseGetLastBlockHeader{
+		BlockHeader: c.header(c.top(), false),
+		Status:      daemon.StatusOK,
+	}, nil
+}
+
+func (c *Chain) getBlockHeaderByHash(req *daemon.RequestGetBlockHeaderByHash) (*daemon.ResponseGetBlockHeaderByHash, error) {
+	b, ok := c.blocks[req.Hash]
+	if !ok {
+		return nil, rpcError(daemon.ErrInternalError, "Internal error: can't get block by hash. Hash = %s.", req.Hash)
+	}
+	return &daemon.ResponseGetBlockHeaderByHash{
+		BlockHeader: c.header(b, req.FillPowHash),
+		Status:      daemon.StatusOK,
+	}, nil
+}
+
+func (c *Chain) getBlockHeaderByHeight(req *daemon.RequestGetBlockHeaderByHeight) (*daemon.ResponseGetBlockHeaderByHeight, error) {
+	if req.Height >= uint64(len(c.main)) {
+		return nil, c.tooBigHeight(req.Height)
+	}
+	return &daemon.ResponseGetBlockHeaderByHeight{
+		BlockHeader: c.header(c.main[req.Height], req.FillPowHash),
+		Status:      daemon.StatusOK,
+	}, nil
+}
+
+func (c *Chain) getBlockHeadersRange(req *daemon.RequestGetBlockHeadersRange) (*daemon.ResponseGetBlockHeadersRange, error) {
+	if req.StartHeight > req.EndHeight || req.EndHeight >= uint64(len(c.main)) {
+		return nil, rpcError(daemon.ErrTooBigHeight, "Invalid start/end heights.")
+	}
+	resp := &daemon.ResponseGetBlockHeadersRange{Status: daemon.StatusOK}
+	for _, b := range c.main[req.StartHeight : req.EndHeight+1] {
+		resp.Headers = append(resp.Headers, c.header(b, req.FillPowHash))
+	}
+	return resp, nil
+}
+
+func (c *Chain) getBlock(req *daemon.RequestGetBlock) (*daemon.ResponseGetBlock, error) {
+	var b *block
+	if req.Hash != "" {
+		if b = c.blocks[req.Hash]; b == nil {
+			return nil, rpcError(daemon.ErrInternalError, "Internal error: can't get block by hash. Hash = %s.", req.Hash)
+		}
+	} else {
+		if req.Height >= uint64(len(c.main)) {
+			return nil, c.tooBigHeight(req.Height)
+		}
+		b = c.main[req.Height]
+	}
+	resp := &daemon.ResponseGetBlock{
+		Blob:        hex.EncodeToString(b.blob),
+		BlockHeader: c.header(b, req.FillPowHash),
+		JSON:        b.asJSON(),
+		MinerTxHash: b.minerTx.hash,
+		Status:      daemon.StatusOK,
+	}
+	for _, t := range b.txs {
+		resp.TxHashes = append(resp.TxHashes, t.hash)
+	}
+	return resp, nil
+}
+
+func (c *Chain) altBlocks() []*block {
+	var alt []*block
+	for _, b := range c.blocks {
+		if b.alt {
+			alt = append(alt, b)
+		}
+	}
+	sort.Slice(alt, func(i, j int) bool {
+		if alt[i].height != alt[j].height {
+			return alt[i].height < alt[j].height
+		}
+		return alt[i].hash < alt[j].hash
+	})
+	return alt
+}
+
+func (c *Chain) getInfo() (*daemon.ResponseGetInfo, error) {
+	top := c.top()
+	var txCount uint64
+	for _, b := range c.main {
+		txCount += uint64(len(b.txs))
+	}
+	return &daemon.ResponseGetInfo{
+		AdjustedTime:             c.now(),
+		AltBlocksCount:           uint64(len(c.altBlocks())),
+		BlockSizeLimit:           600000,
+		BlockSizeMedian:          300000,
+		BlockWeightLimit:         600000,
+		BlockWeightMedian:        300000,
+		Cumulativeifficulty:      top.cumulativeDifficulty,
+		Difficulty:               Difficulty,
+		Height:                   uint64(len(c.main)),
+		HeightWithoutBootstrap:   uint64(len(c.main)),
+		Nettype:                  "fakechain",
+		Offline:                  true,
+		StartTime:                GenesisTime,
+		Status:                   daemon.StatusOK,
+		Synchronized:             true,
+		Target:                   BlockTime,
+		TargetHeight:             uint64(len(c.main)),
+		TopBlockHash:             top.hash,
+		TxCount:                  txCount,
+		TxPoolSize:               uint64(len(c.pool)),
+		Version:                  Version,
+		WideCumulativeDifficulty: wide(top.cumulativeDifficulty),
+		WideDifficulty:           wide(Difficulty),
+	}, nil
+}
+
+func (c *Chain) hardForkInfo() (*daemon.ResponseHardForkInfo, error) {
+	return &daemon.ResponseHardForkInfo{
+		Enabled: true,
+		Status:  daemon.StatusOK,
+		Version: MajorVersion,
+		Voting:  MajorVersion,
+		Window:  10080,
+	}, nil
+}
+
+func (c *Chain) getVersion() (*daemon.ResponseGetVersion, error) {
+	resp := &daemon.ResponseGetVersion{
+		CurrentHeight: uint64(len(c.main)),
+		Release:       true,
+		Status:        daemon.StatusOK,
+		Version:       RPCVersion,
+	}
+	resp.HardForks = grow(resp.HardForks, 1)
+	resp.HardForks[0].HFVersion = MajorVersion
+	return resp, nil
+}
+
+func (c *Chain) getFeeEstimate(*daemon.RequestGetFeeEstimate) (*daemon.ResponseGetFeeEstimate, error) {
+	return &daemon.ResponseGetFeeEstimate{
+		Fee:              DefaultFee,
+		Fees:             []uint64{DefaultFee, 5 * DefaultFee, 25 * DefaultFee, 1000 * DefaultFee},
+		QuantizationMask: 10000,
+		Status:           daemon.StatusOK,
+	}, nil
+}
+
+func (c *Chain) getAlternateChains() (*daemon.ResponseGetAlternateChains, error) {
+	alt := c.altBlocks()
+	parents := make(map[string]bool)
+	for _, b := range alt {
+		parents[b.prevHash] = true
+	}
+	resp := &daemon.ResponseGetAlternateChains{Chains: []daemon.ChainInfo{}, Status: daemon.StatusOK}
+	for _, tip := range alt {
+		if parents[tip.hash] {
+			continue
+		}
+		info := daemon.ChainInfo{BlockHash: tip.hash, Height: tip.height}
+		b := tip
+		for ; b.alt; b = c.blocks[b.prevHash] {
+			info.BlockHashes = append(info.BlockHashes, b.hash)
+			info.Difficulty += Difficulty
+			info.Length++
+		}
+		info.MainChainParentBlock = b.hash
+		info.WideDifficulty = wide(info.Difficulty)
+		resp.Chains = append(resp.Chains, info)
+	}
+	return resp, nil
+}
+
+func (c *Chain) poolTx(id string) *tx {
+	for _, p := range c.pool {
+		if p.hash == id {
+			return p
+		}
+	}
+	return nil
+}
+
+func (c *Chain) relayTx(req *daemon.RequestRelayTx) (*daemon.ResponseRelayTx, error) {
+	for _, id := range req.TxIDs {
+		if c.poolTx(id) == nil {
+			return nil, rpcError(daemon.ErrWrongParam, "Transaction not found in pool: %s", id)
+		}
+	}
+	for _, id := range req.TxIDs {
+		c.poolTx(id).doNotRelay = false
+	}
+	return &daemon.ResponseRelayTx{Status: daemon.StatusOK}, nil
+}
+
+func (c *Chain) flushTxpool(req *daemon.RequestFlushTxpool) (*daemon.ResponseFlushTxpool, error) {
+	c.flushPool(req.TxIDs)
+	return &daemon.ResponseFlushTxpool{Status: daemon.StatusOK}, nil
+}
+
+func (c *Chain) syncInfo() (*daemon.ResponseSyncInfo, error) {
+	return &daemon.ResponseSyncInfo{
+		Height:       uint64(len(c.main)),
+		Status:       daemon.StatusOK,
+		TargetHeight: uint64(len(c.main)),
+	}, nil
+}
+
+func (c *Chain) getTxpoolBacklog() (*daemon.ResponseGetTxpoolBacklog, error) {
+	resp := &daemon.ResponseGetTxpoolBacklog{Backlog: []daemon.TxPoolBacklogEntry{}, Status: daemon.StatusOK}
+	for _, p := range c.pool {
+		resp.Backlog = append(resp.Backlog, daemon.TxPoolBacklogEntry{
+			BlobSize:   uint64(len(p.blob)),
+			Fee:        p.fee,
+			TimeInPool: c.now() - p.receiveTime,
+		})
+	}
+	return resp, nil
+}
+
+func (c *Chain) getCoinbaseTxSum(req *daemon.RequestGetCoinbaseTxSum) (*daemon.ResponseGetCoinbaseTxSum, error) {
+	resp := &daemon.ResponseGetCoinbaseTxSum{Status: daemon.StatusOK}
+	for h := req.Height; h < req.Height+req.Count && h < uint64(len(c.main)); h++ {
+		b := c.main[h]
+		resp.EmissionAmount += BlockReward
+		resp.FeeAmount += b.reward - BlockReward
+	}
+	resp.WideEmissionAmount = wide(resp.EmissionAmount)
+	resp.WideFeeAmount = wide(resp.FeeAmount)
+	return resp, nil
+}
+
+func (c *Chain) getMinerData() (*daemon.ResponseGetMinerData, error) {
+	resp := &daemon.ResponseGetMinerData{
+		AlreadyGeneratedCoins: uint64(len(c.main)) * BlockReward,
+		Difficulty:            wide(Difficulty),
+		Height:                uint64(len(c.main)),
+		MajorVersion:          MajorVersion,
+		MedianWeight:          300000,
+		PrevID:                c.top().hash,
+		SeedHash:              c.main[0].hash,
+		Status:                daemon.StatusOK,
+	}
+	resp.TxBacklog = grow(resp.TxBacklog, len(c.pool))
+	for i, p := range c.pool {
+		resp.TxBacklog[i].Fee = p.fee
+		resp.TxBacklog[i].ID = p.hash
+		resp.TxBacklog[i].Weight = p.weight
+	}
+	return resp, nil
+}
+
+func (c *Chain) getOutputHistogram(req *daemon.RequestGetOutputHistogram) (*daemon.ResponseGetOutputHistogram, error) {
+	resp := &daemon.ResponseGetOutputHistogram{Histogram: []daemon.HistogramEntry{}, Status: daemon.StatusOK}
+	for _, amount := range req.Amounts {
+		e := daemon.HistogramEntry{Amount: amount}
+		if amount == 0 {
+			for _, o := range c.outputs {
+				e.TotalInstances++
+				if c.unlocked(o) {
+					e.UnlockedInstances++
+				}
+				if req.RecentCutoff > 0 && c.main[o.height].timestamp >= req.RecentCutoff {
+					e.RecentInstances++
+				}
+			}
+		}
+		count := e.TotalInstances
+		if req.Unlocked {
+			count = e.UnlockedInstances
+		}
+		if count < req.MinCount || (req.MaxCount > 0 && count > req.MaxCount) {
+			continue
+		}
+		resp.Histogram = append(resp.Histogram, e)
+	}
+	return resp, nil
+}
+
+func (c *Chain) getOutputDistribution(req *daemon.RequestGetOutputDistribution) (*daemon.ResponseGetOutputDistribution, error) {
+	to := req.ToHeight
+	if to == 0 || to >= uint64(len(c.main)) {
+		to = c.top().height
+	}
+	if req.FromHeight > to {
+		return nil, rpcError(daemon.ErrInternalError, "Failed to get output distribution")
+	}
+	perBlock := make([]uint64, len(c.main))
+	for _, o := range c.outputs {
+		perBlock[o.height]++
+	}
+	resp := &daemon.ResponseGetOutputDistribution{Status: daemon.StatusOK}
+	resp.Distributions = grow(resp.Distributions, len(req.Amounts))
+	for i, amount := range req.Amounts {
+		d := &resp.Distributions[i]
+		d.Amount = amount
+		d.StartHeight = req.FromHeight
+		d.Distribution = make([]uint64, to-req.FromHeight+1)
+		if amount != 0 {
+			continue
+		}
+		for h := uint64(0); h < req.FromHeight; h++ {
+			d.Base += perBlock[h]
+		}
+		copy(d.Distribution, perBlock[req.FromHeight:to+1])
+		if req.Cumulative {
+			d.Distribution[0] += d.Base
+			for j := 1; j < len(d.Distribution); j++ {
+				d.Distribution[j] += d.Distribution[j-1]
+			}
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,207 @@
+package daemontest
+
+import (
+	"encoding/hex"
+	"errors"
+	"sort"
+
+	"github.com/boomhut/go-monero-rpc-client/daemon"
+)
+
+// The methods below implement the endpoints other than /json_rpc. They are
+// called by the Server with c.mu held.
+
+func (c *Chain) getHeight() (*daemon.ResponseGetHeight, error) {
+	return &daemon.ResponseGetHeight{
+		Hash:   c.top().hash,
+		Height: uint64(len(c.main)),
+		Status: daemon.StatusOK,
+	}, nil
+}
+
+func validHash(s string) bool {
+	b, err := hex.DecodeString(s)
+	return err == nil && len(b) == 32
+}
+
+func (c *Chain) getTransactions(req *daemon.RequestGetTransactions) (*daemon.ResponseGetTransactions, error) {
+	for _, id := range req.TxHashes {
+		if !validHash(id) {
+			return nil, &daemon.StatusError{Status: "Failed to parse hex representation of transaction hash"}
+		}
+	}
+	resp := &daemon.ResponseGetTransactions{Txs: []daemon.TxInfo{}, Status: daemon.StatusOK}
+	for _, id := range req.TxHashes {
+		t, ok := c.txs[id]
+		if !ok || (t.block == nil && c.poolTx(id) == nil) {
+			resp.MissedTx = append(resp.MissedTx, id)
+			continue
+		}
+		blob := hex.EncodeToString(t.blob)
+		info := daemon.TxInfo{TxHash: t.hash, InPool: t.block == nil}
+		if req.Prune || req.Split {
+			info.PrunedAsHex = blob
+			info.PrunableHash = zeroHash
+		} else {
+			info.AsHex = blob
+		}
+		if req.DecodeAsJSON {
+			info.AsJSON = t.asJSON()
+			resp.TxsAsJSON = append(resp.TxsAsJSON, info.AsJSON)
+		}
+		if t.block != nil {
+			info.BlockHeight = t.block.height
+			info.BlockTimestamp = t.block.timestamp
+			info.OutputIndices = t.outputIndices
+		}
+		resp.Txs = append(resp.Txs, info)
+		resp.TxsAsHex = append(resp.TxsAsHex, blob)
+	}
+	return resp, nil
+}
+
+func (c *Chain) getAltBlocksHashes() (*daemon.ResponseGetAltBlocksHashes, error) {
+	resp := &daemon.ResponseGetAltBlocksHashes{BlkHashes: []string{}, Status: daemon.StatusOK}
+	for _, b := range c.altBlocks() {
+		resp.BlkHashes = append(resp.BlkHashes, b.hash)
+	}
+	return resp, nil
+}
+
+func (c *Chain) isKeyImageSpent(req *daemon.RequestIsKeyImageSpent) (*daemon.ResponseIsKeyImageSpent, error) {
+	resp := &daemon.ResponseIsKeyImageSpent{SpentStatus: []uint64{}, Status: daemon.StatusOK}
+	for _, ki := range req.KeyImages {
+		if !validHash(ki) {
+			return nil, &daemon.StatusError{Status: daemon.StatusFailed, Reason: "Failed to parse key image"}
+		}
+		resp.SpentStatus = append(resp.SpentStatus, c.keyImageStatus(ki))
+	}
+	return resp, nil
+}
+
+// sendRawTransaction returns a response with status "Failed" rather than
+// an error for rejected transactions, so that the flags reach the client.
+func (c *Chain) sendRawTransaction(req *daemon.RequestSendRawTransaction) (*daemon.ResponseSendRawTransaction, error) {
+	resp := &daemon.ResponseSendRawTransaction{Status: daemon.StatusOK, NotRelayed: req.DoNotRelay}
+	blob, err := hex.DecodeString(req.TxAsHex)
+	if err != nil || len(blob) == 0 {
+		resp.Status = daemon.StatusFailed
+		resp.Reason = "Failed to parse tx hex"
+		return resp, nil
+	}
+	t := Tx{Blob: blob}
+	if c.decodeTx != nil {
+		if t, err = c.decodeTx(blob); err != nil {
+			resp.Status = daemon.StatusFailed
+			resp.Reason = err.Error()
+			resp.SanityCheckFailed = true
+			return resp, nil
+		}
+		t.Blob = blob
+	}
+	t.DoNotRelay = req.DoNotRelay
+	if _, err := c.addTx(t); errors.Is(err, ErrDoubleSpend) {
+		resp.Status = daemon.StatusFailed
+		resp.Reason = "double spend"
+		resp.DoubleSpend = true
+	}
+	return resp, nil
+}
+
+func (c *Chain) getTransactionPool() (*daemon.ResponseGetTransactionPool, error) {
+	resp := &daemon.ResponseGetTransactionPool{Status: daemon.StatusOK}
+	resp.Transactions = grow(resp.Transactions, len(c.pool))
+	spenders := make(map[string][]string)
+	for i, p := range c.pool {
+		t := &resp.Transactions[i]
+		t.BlobSize = uint64(len(p.blob))
+		t.DoNotRelay = p.doNotRelay
+		t.Fee = p.fee
+		t.IDHash = p.hash
+		t.KeptByBlock = p.keptByBlock
+		t.MaxUsedBlockHeight = c.top().height
+		t.MaxUsedBlockIDHash = c.top().hash
+		t.ReceiveTime = p.receiveTime
+		t.TxBlob = hex.EncodeToString(p.blob)
+		t.TxJSON = p.asJSON()
+		t.Weight = p.weight
+		if !p.doNotRelay {
+			t.LastRelayedTime = p.receiveTime
+			t.RelayedCount = 1
+		}
+		for _, ki := range p.keyImages {
+			spenders[ki] = append(spenders[ki], p.hash)
+		}
+	}
+	keyImages := make([]string, 0, len(spenders))
+	for ki := range spenders {
+		keyImages = append(keyImages, ki)
+	}
+	sort.Strings(keyImages)
+	resp.SpentKeyImages = grow(resp.SpentKeyImages, len(keyImages))
+	for i, ki := range keyImages {
+		resp.SpentKeyImages[i].IDHash = ki
+		resp.SpentKeyImages[i].TxsHashes = spenders[ki]
+	}
+	return resp, nil
+}
+
+func (c *Chain) getTransactionPoolHashes() (*daemon.ResponseGetTransactionPoolHashes, error) {
+	resp := &daemon.ResponseGetTransactionPoolHashes{TxHashes: []string{}, Status: daemon.StatusOK}
+	for _, p := range c.pool {
+		resp.TxHashes = append(resp.TxHashes, p.hash)
+	}
+	return resp, nil
+}
+
+func (c *Chain) getTransactionPoolStats() (*daemon.ResponseGetTransactionPoolStats, error) {
+	resp := &daemon.ResponseGetTransactionPoolStats{Status: daemon.StatusOK}
+	s := &resp.PoolStats
+	sizes := make([]uint64, 0, len(c.pool))
+	for _, p := range c.pool {
+		size := uint64(len(p.blob))
+		sizes = append(sizes, size)
+		s.BytesTotal += size
+		s.FeeTotal += p.fee
+		if p.doNotRelay {
+			s.NumNotRelayed++
+		}
+		if s.Oldest == 0 || p.receiveTime < s.Oldest {
+			s.Oldest = p.receiveTime
+		}
+	}
+	s.TxsTotal = uint64(len(sizes))
+	if len(sizes) > 0 {
+		sort.Slice(sizes, func(i, j int) bool { return sizes[i] < sizes[j] })
+		s.BytesMin = sizes[0]
+		s.BytesMax = sizes[len(sizes)-1]
+		s.BytesMed = sizes[len(sizes)/2]
+	}
+	return resp, nil
+}
+
+func (c *Chain) popBlocks(req *daemon.RequestPopBlocks) (*daemon.ResponsePopBlocks, error) {
+	c.pop(req.NBlocks)
+	return &daemon.ResponsePopBlocks{Height: uint64(len(c.main)), Status: daemon.StatusOK}, nil
+}
+
+func (c *Chain) getOuts(req *daemon.RequestGetOuts) (*daemon.ResponseGetOuts, error) {
+	resp := &daemon.ResponseGetOuts{Outs: []daemon.OutKey{}, Status: daemon.StatusOK}
+	for _, in := range req.Outputs {
+		if in.Amount != 0 || in.Index >= uint64(len(c.outputs)) {
+			return nil, &daemon.StatusError{Status: daemon.StatusFailed, Reason: "Failed to get outputs"}
+		}
+		o := c.outputs[in.Index]
+		out := daemon.OutKey{
+			Height:   o.height,
+			Key:      o.key,
+			Mask:     o.mask,
+			Unlocked: c.unlocked(o),
+		}
+		if req.GetTxID {
+			out.Txid = o.tx.hash
+		}
+		resp.Outs = append(resp.Outs, out)
+	}
+	return resp, nil
+}
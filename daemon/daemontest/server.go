@@ -0,0 +1,318 @@
+package daemontest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/boomhut/go-monero-rpc-client/daemon"
+	"github.com/boomhut/go-monero-rpc-client/epee"
+)
+
+// JSON-RPC 2.0 error codes used for requests that never reach a method.
+const (
+	codeParseError     = -32700
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// Server serves a Chain over the monerod RPC protocol. Like monerod it
+// only accepts single requests on /json_rpc, JSON-RPC batches are
+// rejected with a parse error. Methods about peers, bans, mining and node
+// administration are not implemented: they fail with "Method not found"
+// on /json_rpc and 404 on the other endpoints.
+type Server struct {
+	*httptest.Server
+	chain *Chain
+}
+
+// NewServer starts a Server for c, or for a new Chain if c is nil. Call
+// Close when done.
+func NewServer(c *Chain) *Server {
+	if c == nil {
+		c = NewChain()
+	}
+	s := &Server{chain: c}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Address returns the URL to use as daemon.Config.Address.
+func (s *Server) Address() string {
+	return s.URL
+}
+
+// Chain returns the chain served by s.
+func (s *Server) Chain() *Chain {
+	return s.chain
+}
+
+type rpcRequest struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	ID     json.RawMessage `json:"id"`
+}
+
+type rpcErrorObject struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcResponse struct {
+	ID      json.RawMessage `json:"id"`
+	Version string          `json:"jsonrpc"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcErrorObject `json:"error,omitempty"`
+}
+
+// statusResult is the response of a call that failed with a status.
+type statusResult struct {
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/json_rpc" {
+		s.serveJSONRPC(w, r)
+		return
+	}
+	if h, ok := binaryHandlers[r.URL.Path]; ok {
+		s.serveBinary(w, r, h)
+		return
+	}
+	h, ok := otherHandlers[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	params, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	result, err := s.chain.handle(r.Context(), r.URL.Path, h, params)
+	if err != nil {
+		result = statusOf(err)
+	}
+	writeJSON(w, result)
+}
+
+func (s *Server) serveJSONRPC(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req rpcRequest
+	resp := rpcResponse{ID: json.RawMessage("0"), Version: "2.0"}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		resp.Error = &rpcErrorObject{Code: codeParseError, Message: "Parse error"}
+		writeJSON(w, resp)
+		return
+	}
+	if len(req.ID) > 0 {
+		resp.ID = req.ID
+	}
+	h, ok := jsonRPCHandlers[req.Method]
+	if !ok {
+		resp.Error = &rpcErrorObject{Code: codeMethodNotFound, Message: "Method not found"}
+		writeJSON(w, resp)
+		return
+	}
+	result, err := s.chain.handle(r.Context(), req.Method, h, req.Params)
+	var serr *daemon.StatusError
+	switch {
+	case errors.As(err, &serr):
+		resp.Result = statusOf(err)
+	case err != nil:
+		resp.Error = errorObject(err)
+	default:
+		resp.Result = result
+	}
+	writeJSON(w, resp)
+}
+
+// serveBinary serves a .bin endpoint, whose request and response are epee
+// documents.
+func (s *Server) serveBinary(w http.ResponseWriter, r *http.Request, h handler) {
+	params, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	result, err := s.chain.handle(r.Context(), r.URL.Path, h, params)
+	if err != nil {
+		result = statusOf(err)
+	}
+	data, err := epee.Marshal(result)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Write(data)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func errorObject(err error) *rpcErrorObject {
+	var rerr *daemon.RPCError
+	if errors.As(err, &rerr) {
+		return &rpcErrorObject{Code: int(rerr.Code), Message: rerr.Message}
+	}
+	var code daemon.ErrorCode
+	if errors.As(err, &code) {
+		return &rpcErrorObject{Code: int(code), Message: errorMessages[code]}
+	}
+	return &rpcErrorObject{Code: int(daemon.ErrInternalError), Message: err.Error()}
+}
+
+// statusOf returns the status fields the other endpoints report err with.
+func statusOf(err error) statusResult {
+	var serr *daemon.StatusError
+	if errors.As(err, &serr) {
+		return statusResult{Status: serr.Status, Reason: serr.Reason}
+	}
+	return statusResult{Status: daemon.StatusFailed, Reason: errorObject(err).Message}
+}
+
+// errorMessages are the messages monerod sends with some codes.
+var errorMessages = map[daemon.ErrorCode]string{
+	daemon.ErrWrongParam:    "Wrong parameters",
+	daemon.ErrTooBigHeight:  "Too big height",
+	daemon.ErrInternalError: "Internal error",
+	daemon.ErrCoreBusy:      "Core is busy",
+	daemon.ErrRestricted:    "Restricted RPC",
+}
+
+func rpcError(code daemon.ErrorCode, format string, args ...interface{}) error {
+	return &daemon.RPCError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// handle runs h for method with c.mu held, unless the context is done or
+// an error was injected with FailNext or Fail.
+func (c *Chain) handle(ctx context.Context, method string, h handler, params json.RawMessage) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := c.injected(method); err != nil {
+		return nil, err
+	}
+	return h(c, params)
+}
+
+type handler func(c *Chain, params json.RawMessage) (interface{}, error)
+
+func decodeParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return &daemon.RPCError{Code: codeInvalidParams, Message: "Invalid params"}
+	}
+	return nil
+}
+
+func call[Req, Resp any](fn func(*Chain, *Req) (*Resp, error)) handler {
+	return func(c *Chain, params json.RawMessage) (interface{}, error) {
+		req := new(Req)
+		if err := decodeParams(params, req); err != nil {
+			return nil, err
+		}
+		resp, err := fn(c, req)
+		if err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+}
+
+// callBinary is call for the .bin endpoints, whose params are epee.
+func callBinary[Req, Resp any](fn func(*Chain, *Req) (*Resp, error)) handler {
+	return func(c *Chain, params json.RawMessage) (interface{}, error) {
+		req := new(Req)
+		if err := epee.Unmarshal(params, req); err != nil {
+			return nil, &daemon.RPCError{Code: codeInvalidParams, Message: "Invalid params"}
+		}
+		resp, err := fn(c, req)
+		if err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+}
+
+// callBinaryNoParams is callBinary for endpoints without params.
+func callBinaryNoParams[Resp any](fn func(*Chain) (*Resp, error)) handler {
+	return callBinary(func(c *Chain, _ *struct{}) (*Resp, error) { return fn(c) })
+}
+
+func callNoParams[Resp any](fn func(*Chain) (*Resp, error)) handler {
+	return func(c *Chain, _ json.RawMessage) (interface{}, error) {
+		resp, err := fn(c)
+		if err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+}
+
+// jsonRPCHandlers maps the JSON-RPC methods to the Chain.
+var jsonRPCHandlers = map[string]handler{
+	"get_block_count":            callNoParams((*Chain).getBlockCount),
+	"on_get_block_hash":          call((*Chain).onGetBlockHash),
+	"get_block_template":         call((*Chain).getBlockTemplate),
+	"submit_block":               call((*Chain).submitBlock),
+	"generateblocks":             call((*Chain).generateBlocks),
+	"get_last_block_header":      callNoParams((*Chain).getLastBlockHeader),
+	"get_block_header_by_hash":   call((*Chain).getBlockHeaderByHash),
+	"get_block_header_by_height": call((*Chain).getBlockHeaderByHeight),
+	"get_block_headers_range":    call((*Chain).getBlockHeadersRange),
+	"get_block":                  call((*Chain).getBlock),
+	"get_info":                   callNoParams((*Chain).getInfo),
+	"hard_fork_info":             callNoParams((*Chain).hardForkInfo),
+	"get_version":                callNoParams((*Chain).getVersion),
+	"get_fee_estimate":           call((*Chain).getFeeEstimate),
+	"get_alternate_chains":       callNoParams((*Chain).getAlternateChains),
+	"relay_tx":                   call((*Chain).relayTx),
+	"flush_txpool":               call((*Chain).flushTxpool),
+	"sync_info":                  callNoParams((*Chain).syncInfo),
+	"get_txpool_backlog":         callNoParams((*Chain).getTxpoolBacklog),
+	"get_coinbase_tx_sum":        call((*Chain).getCoinbaseTxSum),
+	"get_miner_data":             callNoParams((*Chain).getMinerData),
+	"get_output_histogram":       call((*Chain).getOutputHistogram),
+	"get_output_distribution":    call((*Chain).getOutputDistribution),
+}
+
+// otherHandlers maps the other endpoints to the Chain.
+var otherHandlers = map[string]handler{
+	"/get_height":                  callNoParams((*Chain).getHeight),
+	"/get_transactions":            call((*Chain).getTransactions),
+	"/get_alt_blocks_hashes":       callNoParams((*Chain).getAltBlocksHashes),
+	"/is_key_image_spent":          call((*Chain).isKeyImageSpent),
+	"/send_raw_transaction":        call((*Chain).sendRawTransaction),
+	"/get_transaction_pool":        callNoParams((*Chain).getTransactionPool),
+	"/get_transaction_pool_hashes": callNoParams((*Chain).getTransactionPoolHashes),
+	"/get_transaction_pool_stats":  callNoParams((*Chain).getTransactionPoolStats),
+	"/pop_blocks":                  call((*Chain).popBlocks),
+	"/get_outs":                    call((*Chain).getOuts),
+}
+
+// binaryHandlers maps the .bin endpoints to the Chain.
+var binaryHandlers = map[string]handler{
+	"/get_blocks.bin":                  callBinary((*Chain).getBlocksBin),
+	"/get_blocks_by_height.bin":        callBinary((*Chain).getBlocksByHeightBin),
+	"/get_outs.bin":                    callBinary((*Chain).getOutsBin),
+	"/get_o_indexes.bin":               callBinary((*Chain).getOIndexesBin),
+	"/get_hashes.bin":                  callBinary((*Chain).getHashesBin),
+	"/get_transaction_pool_hashes.bin": callBinaryNoParams((*Chain).getTransactionPoolHashesBin),
+	"/get_output_distribution.bin":     callBinary((*Chain).getOutputDistributionBin),
+}
@@ -0,0 +1,175 @@
+package daemon
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/boomhut/go-monero-rpc-client/epee"
+)
+
+// CompressDistribution encodes counts as consecutive varints, the
+// "compressed_data" format of monerod's get_output_distribution.bin.
+// Per-block counts compress much better than cumulative ones.
+func CompressDistribution(counts []uint64) []byte {
+	var buf []byte
+	for _, n := range counts {
+		buf = binary.AppendUvarint(buf, n)
+	}
+	return buf
+}
+
+// DecompressDistribution decodes data encoded by CompressDistribution.
+func DecompressDistribution(data []byte) ([]uint64, error) {
+	var counts []uint64
+	for len(data) > 0 {
+		n, size := binary.Uvarint(data)
+		if size <= 0 {
+			return nil, errors.New("invalid varint in compressed distribution")
+		}
+		counts = append(counts, n)
+		data = data[size:]
+	}
+	return counts, nil
+}
+
+// ToCumulative turns per-block counts into cumulative ones, base being the
+// number of outputs before the first block.
+func ToCumulative(perBlock []uint64, base uint64) []uint64 {
+	cumulative := make([]uint64, len(perBlock))
+	total := base
+	for i, n := range perBlock {
+		total += n
+		cumulative[i] = total
+	}
+	return cumulative
+}
+
+// ToPerBlock turns cumulative counts into per-block ones, base being the
+// number of outputs before the first block.
+func ToPerBlock(cumulative []uint64, base uint64) []uint64 {
+	perBlock := make([]uint64, len(cumulative))
+	prev := base
+	for i, n := range cumulative {
+		perBlock[i] = n - prev
+		prev = n
+	}
+	return perBlock
+}
+
+// CumulativeCounts returns the distribution in cumulative form.
+func (d *OutputDistribution) CumulativeCounts() []uint64 {
+	if d.Cumulative {
+		return append([]uint64(nil), d.Distribution...)
+	}
+	return ToCumulative(d.Distribution, d.Base)
+}
+
+// PerBlockCounts returns the distribution in per-block form.
+func (d *OutputDistribution) PerBlockCounts() []uint64 {
+	if d.Cumulative {
+		return ToPerBlock(d.Distribution, d.Base)
+	}
+	return append([]uint64(nil), d.Distribution...)
+}
+
+// OutputsUpTo returns the number of outputs created up to and including
+// the block at height. It reports false if the distribution does not
+// cover height: height is after the last block, or more than one block
+// before StartHeight while Base is not zero. monerod starts the RingCT
+// distribution at the RingCT fork, with no outputs before.
+func (d *OutputDistribution) OutputsUpTo(height uint64) (uint64, bool) {
+	if height < d.StartHeight {
+		return d.Base, height+1 == d.StartHeight || d.Base == 0
+	}
+	if height-d.StartHeight >= uint64(len(d.Distribution)) {
+		return 0, false
+	}
+	i := height - d.StartHeight
+	if d.Cumulative {
+		return d.Distribution[i], true
+	}
+	total := d.Base
+	for _, n := range d.Distribution[:i+1] {
+		total += n
+	}
+	return total, true
+}
+
+// MarshalEpee writes the distribution as an array, or as a blob if Binary
+// is set, or as compressed data if Compress is set too, like monerod.
+func (d OutputDistribution) MarshalEpee() (interface{}, error) {
+	m := map[string]interface{}{
+		"amount":       d.Amount,
+		"start_height": d.StartHeight,
+		"base":         d.Base,
+		"binary":       d.Binary,
+		"compress":     d.Compress,
+	}
+	switch {
+	case d.Binary && d.Compress:
+		m["compressed_data"] = CompressDistribution(d.Distribution)
+	case d.Binary:
+		blob := make([]byte, 0, 8*len(d.Distribution))
+		for _, n := range d.Distribution {
+			blob = binary.LittleEndian.AppendUint64(blob, n)
+		}
+		m["distribution"] = blob
+	default:
+		m["distribution"] = d.Distribution
+	}
+	return m, nil
+}
+
+// UnmarshalEpee reads the distribution from an array, a blob or
+// compressed data.
+func (d *OutputDistribution) UnmarshalEpee(v interface{}) error {
+	var entry struct {
+		Amount         uint64   `epee:"amount"`
+		StartHeight    uint64   `epee:"start_height"`
+		Base           uint64   `epee:"base"`
+		Binary         bool     `epee:"binary"`
+		Compress       bool     `epee:"compress"`
+		Distribution   []uint64 `epee:"distribution"`
+		CompressedData []byte   `epee:"compressed_data"`
+	}
+	if err := epee.Assign(&entry, v); err != nil {
+		return err
+	}
+	*d = OutputDistribution{
+		Amount:       entry.Amount,
+		StartHeight:  entry.StartHeight,
+		Base:         entry.Base,
+		Binary:       entry.Binary,
+		Compress:     entry.Compress,
+		Distribution: entry.Distribution,
+	}
+	if entry.Binary && entry.Compress {
+		counts, err := DecompressDistribution(entry.CompressedData)
+		if err != nil {
+			return err
+		}
+		d.Distribution = counts
+	}
+	return nil
+}
+
+// RingCTOutputsUpTo returns the number of RingCT outputs (amount 0)
+// created up to and including the block at height, which is also the
+// global index of the first output of the next block.
+func RingCTOutputsUpTo(ctx context.Context, c Client, height uint64) (uint64, error) {
+	res, err := c.GetOutputDistributionContext(ctx, []uint64{0}, true, height, height)
+	if err != nil {
+		return 0, err
+	}
+	for _, d := range res.Distributions {
+		if d.Amount != 0 {
+			continue
+		}
+		if n, ok := d.OutputsUpTo(height); ok {
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("output distribution does not cover height %d", height)
+}
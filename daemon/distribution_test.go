@@ -0,0 +1,62 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/boomhut/go-monero-rpc-client/epee"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressDistribution(t *testing.T) {
+	counts := []uint64{0, 1, 127, 128, 300, 1 << 40}
+	data := CompressDistribution(counts)
+	assert.Equal(t, []byte{0x00, 0x01, 0x7f, 0x80, 0x01, 0xac, 0x02}, data[:7])
+	back, err := DecompressDistribution(data)
+	assert.NoError(t, err)
+	assert.Equal(t, counts, back)
+
+	_, err = DecompressDistribution([]byte{0x80})
+	assert.Error(t, err)
+}
+
+func TestDistributionForms(t *testing.T) {
+	perBlock := []uint64{2, 0, 5}
+	cumulative := ToCumulative(perBlock, 10)
+	assert.Equal(t, []uint64{12, 12, 17}, cumulative)
+	assert.Equal(t, perBlock, ToPerBlock(cumulative, 10))
+
+	d := OutputDistribution{StartHeight: 5, Base: 10, Distribution: perBlock}
+	assert.Equal(t, cumulative, d.CumulativeCounts())
+	assert.Equal(t, perBlock, d.PerBlockCounts())
+	c := OutputDistribution{StartHeight: 5, Base: 10, Distribution: cumulative, Cumulative: true}
+	assert.Equal(t, perBlock, c.PerBlockCounts())
+
+	for _, d := range []OutputDistribution{d, c} {
+		for height, want := range map[uint64]uint64{4: 10, 5: 12, 6: 12, 7: 17} {
+			n, ok := d.OutputsUpTo(height)
+			assert.True(t, ok, "height %d", height)
+			assert.Equal(t, want, n, "height %d", height)
+		}
+		_, ok := d.OutputsUpTo(8)
+		assert.False(t, ok)
+		_, ok = d.OutputsUpTo(3)
+		assert.False(t, ok)
+	}
+	n, ok := (&OutputDistribution{StartHeight: 5, Distribution: perBlock}).OutputsUpTo(1)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(0), n)
+}
+
+func TestOutputDistributionEpee(t *testing.T) {
+	for _, d := range []OutputDistribution{
+		{Amount: 0, StartHeight: 3, Base: 7, Distribution: []uint64{1, 2, 300}},
+		{Amount: 0, StartHeight: 3, Base: 7, Distribution: []uint64{1, 2, 300}, Binary: true},
+		{Amount: 0, StartHeight: 3, Base: 7, Distribution: []uint64{1, 2, 300}, Binary: true, Compress: true},
+	} {
+		data, err := epee.Marshal(&ResponseGetOutputDistribution{Distributions: []OutputDistribution{d}, Status: StatusOK})
+		assert.NoError(t, err)
+		var res ResponseGetOutputDistribution
+		assert.NoError(t, epee.Unmarshal(data, &res))
+		assert.Equal(t, []OutputDistribution{d}, res.Distributions)
+	}
+}
@@ -0,0 +1,202 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// Sentinel errors for use with errors.Is. Every failure to reach monerod or
+// error reported by it matches one of them. Context cancellation and
+// failures to encode a request or decode a response match none.
+var (
+	// ErrTransport matches failures to reach monerod at all.
+	ErrTransport = errors.New("daemon: transport error")
+	// ErrHTTPStatus matches non-200 HTTP responses.
+	ErrHTTPStatus = errors.New("daemon: unexpected http status")
+	// ErrRPC matches JSON-RPC error objects returned by monerod.
+	ErrRPC = errors.New("daemon: rpc error")
+	// ErrStatus matches responses whose "status" field is not "OK".
+	ErrStatus = errors.New("daemon: status not OK")
+	// ErrBusy matches both a "BUSY" status and the CORE_BUSY JSON-RPC
+	// error, which monerod returns while it is syncing.
+	ErrBusy = errors.New("daemon: busy")
+	// ErrUntrusted is returned for responses flagged "untrusted" (served by
+	// a bootstrap daemon) when Config.RejectUntrusted is set.
+	ErrUntrusted = errors.New("daemon: untrusted response from bootstrap daemon")
+)
+
+// Errors about transaction and block data rather than calls.
+var (
+	// ErrTxNotFound is returned by GetDecodedTransactions when the daemon
+	// does not know some of the requested transactions.
+	ErrTxNotFound = errors.New("daemon: transaction not found")
+	// ErrTxHashMismatch is returned by TxInfo.VerifyHash when a blob does
+	// not hash to the reported transaction id.
+	ErrTxHashMismatch = errors.New("daemon: transaction hash mismatch")
+	// ErrBlockHashMismatch is returned by ResponseGetBlock.VerifyHash when
+	// a block blob does not hash to the reported block id.
+	ErrBlockHashMismatch = errors.New("daemon: block hash mismatch")
+)
+
+// ErrorCode is a monerod JSON-RPC error code.
+// Copied from https://github.com/monero-project/monero/blob/master/src/rpc/core_rpc_server_error_codes.h
+type ErrorCode int
+
+const (
+	// ErrWrongParam - CORE_RPC_ERROR_CODE_WRONG_PARAM
+	ErrWrongParam ErrorCode = -1
+	// ErrTooBigHeight - CORE_RPC_ERROR_CODE_TOO_BIG_HEIGHT
+	ErrTooBigHeight ErrorCode = -2
+	// ErrTooBigReserveSize - CORE_RPC_ERROR_CODE_TOO_BIG_RESERVE_SIZE
+	ErrTooBigReserveSize ErrorCode = -3
+	// ErrWrongWalletAddress - CORE_RPC_ERROR_CODE_WRONG_WALLET_ADDRESS
+	ErrWrongWalletAddress ErrorCode = -4
+	// ErrInternalError - CORE_RPC_ERROR_CODE_INTERNAL_ERROR
+	ErrInternalError ErrorCode = -5
+	// ErrWrongBlockblob - CORE_RPC_ERROR_CODE_WRONG_BLOCKBLOB
+	ErrWrongBlockblob ErrorCode = -6
+	// ErrBlockNotAccepted - CORE_RPC_ERROR_CODE_BLOCK_NOT_ACCEPTED
+	ErrBlockNotAccepted ErrorCode = -7
+	// ErrCoreBusy - CORE_RPC_ERROR_CODE_CORE_BUSY
+	ErrCoreBusy ErrorCode = -9
+	// ErrWrongBlockblobSize - CORE_RPC_ERROR_CODE_WRONG_BLOCKBLOB_SIZE
+	ErrWrongBlockblobSize ErrorCode = -10
+	// ErrUnsupportedRPC - CORE_RPC_ERROR_CODE_UNSUPPORTED_RPC
+	ErrUnsupportedRPC ErrorCode = -11
+	// ErrMiningToSubaddress - CORE_RPC_ERROR_CODE_MINING_TO_SUBADDRESS
+	ErrMiningToSubaddress ErrorCode = -12
+	// ErrRegtestRequired - CORE_RPC_ERROR_CODE_REGTEST_REQUIRED
+	ErrRegtestRequired ErrorCode = -13
+	// ErrPaymentRequired - CORE_RPC_ERROR_CODE_PAYMENT_REQUIRED
+	ErrPaymentRequired ErrorCode = -14
+	// ErrInvalidClient - CORE_RPC_ERROR_CODE_INVALID_CLIENT
+	ErrInvalidClient ErrorCode = -15
+	// ErrPaymentTooLow - CORE_RPC_ERROR_CODE_PAYMENT_TOO_LOW
+	ErrPaymentTooLow ErrorCode = -16
+	// ErrDuplicatePayment - CORE_RPC_ERROR_CODE_DUPLICATE_PAYMENT
+	ErrDuplicatePayment ErrorCode = -17
+	// ErrStalePayment - CORE_RPC_ERROR_CODE_STALE_PAYMENT
+	ErrStalePayment ErrorCode = -18
+	// ErrRestricted - CORE_RPC_ERROR_CODE_RESTRICTED
+	ErrRestricted ErrorCode = -19
+	// ErrUnsupportedBootstrap - CORE_RPC_ERROR_CODE_UNSUPPORTED_BOOTSTRAP
+	ErrUnsupportedBootstrap ErrorCode = -20
+	// ErrPaymentNotSupported - CORE_RPC_ERROR_CODE_PAYMENT_NOT_SUPPORTED
+	ErrPaymentNotSupported ErrorCode = -21
+)
+
+// Error makes ErrorCode usable as an errors.Is target:
+// errors.Is(err, daemon.ErrTooBigHeight) is true for an *RPCError
+// carrying that code.
+func (code ErrorCode) Error() string {
+	return fmt.Sprintf("daemon rpc error code %d", int(code))
+}
+
+// Status values found in the "status" field of monerod responses.
+const (
+	StatusOK     = "OK"
+	StatusBusy   = "BUSY"
+	StatusFailed = "Failed"
+)
+
+// TransportError wraps a failure to deliver the request or read the
+// response, such as a refused or reset connection.
+type TransportError struct {
+	Err error
+}
+
+func (e *TransportError) Error() string {
+	return "failed to send request: " + e.Err.Error()
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrTransport, or context.DeadlineExceeded
+// if the request timed out, for example after Config.Timeout.
+func (e *TransportError) Is(target error) bool {
+	return target == ErrTransport || target == context.DeadlineExceeded && e.Timeout()
+}
+
+// Timeout reports whether the request timed out.
+func (e *TransportError) Timeout() bool {
+	var nerr net.Error
+	return errors.As(e.Err, &nerr) && nerr.Timeout()
+}
+
+// HTTPStatusError is returned when monerod answers with a status other than
+// 200 OK, for example 401 when the credentials are wrong.
+type HTTPStatusError struct {
+	StatusCode int
+	// Body holds (up to 64KiB of) the response body.
+	Body []byte
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
+
+// Is reports whether target is ErrHTTPStatus.
+func (e *HTTPStatusError) Is(target error) bool {
+	return target == ErrHTTPStatus
+}
+
+// RPCError is a JSON-RPC error object returned by monerod.
+type RPCError struct {
+	Code    ErrorCode
+	Message string
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", int(e.Code), e.Message)
+}
+
+// Is reports whether target is ErrRPC, the ErrorCode carried by e, or
+// ErrBusy for ErrCoreBusy.
+func (e *RPCError) Is(target error) bool {
+	switch target {
+	case ErrRPC:
+		return true
+	case ErrBusy:
+		return e.Code == ErrCoreBusy
+	}
+	code, ok := target.(ErrorCode)
+	return ok && code == e.Code
+}
+
+// StatusError is returned when a response's "status" field is anything but
+// "OK", for example "BUSY" while the daemon is syncing or "Failed".
+type StatusError struct {
+	// Method is the JSON-RPC method or endpoint path that was called.
+	Method string
+	// Status is the status text returned by monerod.
+	Status string
+	// Reason carries the optional "reason" field (e.g. from /send_raw_transaction).
+	Reason string
+	// Response is the raw JSON result, so that method specific fields
+	// (such as the double_spend or fee_too_low flags of
+	// /send_raw_transaction) can still be decoded.
+	Response json.RawMessage
+}
+
+func (e *StatusError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("%s: status %s: %s", e.Method, e.Status, e.Reason)
+	}
+	return fmt.Sprintf("%s: status %s", e.Method, e.Status)
+}
+
+// Is reports whether target is ErrStatus, or ErrBusy for a "BUSY" status.
+func (e *StatusError) Is(target error) bool {
+	switch target {
+	case ErrStatus:
+		return true
+	case ErrBusy:
+		return e.Status == StatusBusy
+	}
+	return false
+}
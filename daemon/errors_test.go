@@ -0,0 +1,55 @@
+package daemon
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/get_alt_blocks_hashes":
+			http.Error(w, strings.Repeat("x", 100<<10), http.StatusBadGateway)
+		case "/json_rpc":
+			w.Write([]byte(`{"id":0,"jsonrpc":"2.0","error":{"code":-2,"message":"Requested block height: 10 greater than current top block height: 5"}}`))
+		default:
+			http.Error(w, "boom", http.StatusInternalServerError)
+		}
+	}))
+
+	cl := New(Config{Address: srv.URL})
+	_, err := cl.GetBlockHeaderByHeight(10, false)
+	assert.True(t, errors.Is(err, ErrRPC))
+	assert.True(t, errors.Is(err, ErrTooBigHeight))
+	var rerr *RPCError
+	assert.True(t, errors.As(err, &rerr))
+	assert.Equal(t, ErrTooBigHeight, rerr.Code)
+
+	_, err = cl.GetHeight()
+	assert.True(t, errors.Is(err, ErrHTTPStatus))
+	var herr *HTTPStatusError
+	assert.True(t, errors.As(err, &herr))
+	assert.Equal(t, http.StatusInternalServerError, herr.StatusCode)
+	assert.Equal(t, "boom\n", string(herr.Body))
+	assert.NotContains(t, err.Error(), "boom")
+
+	_, err = cl.GetAltBlocksHashes()
+	assert.True(t, errors.As(err, &herr))
+	assert.Len(t, herr.Body, 64<<10)
+
+	srv.Close()
+	_, err = cl.GetHeight()
+	assert.True(t, errors.Is(err, ErrTransport))
+}
+
+func TestBusyErrors(t *testing.T) {
+	assert.True(t, errors.Is(&StatusError{Method: "get_info", Status: StatusBusy}, ErrBusy))
+	assert.True(t, errors.Is(&StatusError{Method: "get_info", Status: StatusFailed}, ErrStatus))
+	assert.False(t, errors.Is(&StatusError{Method: "get_info", Status: StatusFailed}, ErrBusy))
+	assert.True(t, errors.Is(&RPCError{Code: ErrCoreBusy}, ErrBusy))
+}
@@ -0,0 +1,44 @@
+package daemon
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterceptors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json_rpc":
+			w.Write([]byte(`{"id":0,"jsonrpc":"2.0","result":{"count":7,"status":"OK"}}`))
+		case "/is_key_image_spent":
+			w.Write([]byte(`{"spent_status":[1],"status":"OK"}`))
+		}
+	}))
+	defer srv.Close()
+
+	var methods []string
+	var params []interface{}
+	cl := New(Config{
+		Address: srv.URL,
+		Interceptors: []Interceptor{func(ctx context.Context, method string, req, res interface{}, next Invoker) error {
+			methods = append(methods, method)
+			params = append(params, req)
+			return next(ctx, method, req, res)
+		}},
+	})
+
+	count, err := cl.GetBlockCount()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), count.Count)
+
+	spent, err := cl.IsKeyImageSpent([]string{"ki"})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{1}, spent.SpentStatus)
+
+	assert.Equal(t, []string{"get_block_count", "/is_key_image_spent"}, methods)
+	assert.Equal(t, &RequestIsKeyImageSpent{KeyImages: []string{"ki"}}, params[1])
+}
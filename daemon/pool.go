@@ -0,0 +1,373 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/boomhut/go-monero-rpc-client/internal/instrument"
+	"github.com/boomhut/go-monero-rpc-client/telemetry"
+)
+
+// PoolConfig holds the configuration of a multi-node daemon client.
+type PoolConfig struct {
+	// Nodes are the monerod endpoints to balance over. Address, CustomHeaders,
+	// Transport, Username and Password are honoured per node; Retry,
+	// Interceptors, Tracer and Metrics are ignored here, use the PoolConfig
+	// fields instead.
+	Nodes []Config
+	// Retry is applied to calls made through the pool. Every retry is sent
+	// to the best node at that moment, so it doubles as a failover budget.
+	Retry *RetryPolicy
+	// Interceptors wrap every call made through the pool. Health checks
+	// are not intercepted.
+	Interceptors []Interceptor
+	// RejectUntrusted has the same meaning as Config.RejectUntrusted.
+	RejectUntrusted bool
+	// Tracer and Metrics instrument calls made through the pool. Health
+	// checks are not reported.
+	Tracer  telemetry.Tracer
+	Metrics telemetry.Recorder
+	// Timeout limits a single request through the pool, including any
+	// failover to other nodes. Per-node Timeout values are ignored.
+	Timeout time.Duration
+	// HealthCheckInterval is how often nodes are probed with GetInfo.
+	// Defaults to 30s. A negative value disables background checks, in
+	// which case CheckNodes must be called to refresh the ranking.
+	HealthCheckInterval time.Duration
+	// HealthCheckTimeout bounds a single probe. Defaults to 5s.
+	HealthCheckTimeout time.Duration
+	// MaxHeightLag is the number of blocks a node may trail the highest
+	// known node before it is considered behind. Defaults to 2.
+	MaxHeightLag uint64
+}
+
+// NodeStatus is a snapshot of what the pool knows about a node.
+type NodeStatus struct {
+	Address     string
+	Healthy     bool
+	Behind      bool
+	Height      uint64
+	Latency     time.Duration
+	LastError   error
+	LastChecked time.Time
+}
+
+// Pool is a Client that spreads calls over several monerod nodes. It
+// prefers healthy, fully synced nodes with the lowest latency and fails
+// over to the next node when a request cannot be delivered or the node
+// answers with a 5xx status. Only idempotent methods, and those listed in
+// PoolConfig.Retry.UnsafeMethods, fail over once a node may have received
+// them; others only move on when the node cannot be dialed.
+type Pool struct {
+	Client
+
+	nodes    []*poolNode
+	interval time.Duration
+	timeout  time.Duration
+	lag      uint64
+
+	mu      sync.RWMutex
+	closing chan struct{}
+	done    chan struct{}
+}
+
+type poolNode struct {
+	base     *url.URL
+	endpoint string
+	client   *client
+
+	mu     sync.Mutex
+	status NodeStatus
+}
+
+// NewPool returns a Client that fails over between the given nodes. Call
+// Close to stop the background health checks.
+func NewPool(cfg PoolConfig) (*Pool, error) {
+	if len(cfg.Nodes) == 0 {
+		return nil, errors.New("daemon pool: no nodes configured")
+	}
+	p := &Pool{
+		interval: cfg.HealthCheckInterval,
+		timeout:  cfg.HealthCheckTimeout,
+		lag:      cfg.MaxHeightLag,
+		closing:  make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if p.interval == 0 {
+		p.interval = 30 * time.Second
+	}
+	if p.timeout <= 0 {
+		p.timeout = 5 * time.Second
+	}
+	if p.lag == 0 {
+		p.lag = 2
+	}
+	for _, nodeCfg := range cfg.Nodes {
+		base, err := url.Parse(nodeCfg.Address)
+		if err != nil {
+			return nil, fmt.Errorf("daemon pool: invalid node address %q: %w", nodeCfg.Address, err)
+		}
+		nodeCfg.Retry = nil
+		nodeCfg.Interceptors = nil
+		nodeCfg.Tracer = nil
+		nodeCfg.Metrics = nil
+		p.nodes = append(p.nodes, &poolNode{
+			base:     base,
+			endpoint: instrument.Endpoint(nodeCfg.Address),
+			client:   New(nodeCfg).(*client),
+			status:   NodeStatus{Address: nodeCfg.Address, Healthy: true},
+		})
+	}
+	p.Client = newClient(Config{
+		Retry:           cfg.Retry,
+		Interceptors:    cfg.Interceptors,
+		RejectUntrusted: cfg.RejectUntrusted,
+		Timeout:         cfg.Timeout,
+		Tracer:          cfg.Tracer,
+		Metrics:         cfg.Metrics,
+	}, &poolTransport{pool: p})
+
+	if p.interval > 0 {
+		go p.run()
+	} else {
+		close(p.done)
+	}
+	return p, nil
+}
+
+// Close stops the background health checks.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	select {
+	case <-p.closing:
+	default:
+		close(p.closing)
+	}
+	<-p.done
+}
+
+// Nodes returns the current status of every node, best candidate first.
+func (p *Pool) Nodes() []NodeStatus {
+	ranked := p.ranked()
+	out := make([]NodeStatus, len(ranked))
+	for i, n := range ranked {
+		out[i] = n.snapshot()
+	}
+	return out
+}
+
+// CheckNodes probes every node concurrently and updates the ranking.
+func (p *Pool) CheckNodes(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, n := range p.nodes {
+		wg.Add(1)
+		go func(n *poolNode) {
+			defer wg.Done()
+			p.check(ctx, n)
+		}(n)
+	}
+	wg.Wait()
+	p.markBehind()
+}
+
+func (p *Pool) run() {
+	defer close(p.done)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-p.closing
+		cancel()
+	}()
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		p.CheckNodes(ctx)
+		select {
+		case <-p.closing:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Pool) check(ctx context.Context, n *poolNode) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	start := time.Now()
+	var height uint64
+	var synced bool
+	info, err := n.client.GetInfoContext(ctx)
+	if err == nil {
+		height, synced = info.Height, !info.BusySyncing && (info.Synchronized || info.TargetHeight <= info.Height)
+	} else {
+		// Some restricted nodes refuse get_info, fall back to /get_height.
+		var res *ResponseGetHeight
+		if res, err = n.client.GetHeightContext(ctx); err == nil {
+			height, synced = res.Height, true
+		}
+	}
+	latency := time.Since(start)
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.status.LastChecked = time.Now()
+	n.status.LastError = err
+	n.status.Healthy = err == nil && synced
+	if err == nil {
+		n.status.Height = height
+		// Smooth latency so that a single slow probe does not reorder the pool.
+		if n.status.Latency == 0 {
+			n.status.Latency = latency
+		} else {
+			n.status.Latency = (n.status.Latency*3 + latency) / 4
+		}
+	}
+}
+
+// markBehind flags nodes that trail the highest healthy node by more than
+// the configured lag.
+func (p *Pool) markBehind() {
+	var top uint64
+	for _, n := range p.nodes {
+		s := n.snapshot()
+		if s.Healthy && s.Height > top {
+			top = s.Height
+		}
+	}
+	for _, n := range p.nodes {
+		n.mu.Lock()
+		n.status.Behind = n.status.Height+p.lag < top
+		n.mu.Unlock()
+	}
+}
+
+// ranked orders nodes by preference: healthy before unhealthy, synced
+// before behind, then by latency. Configuration order breaks ties.
+func (p *Pool) ranked() []*poolNode {
+	type entry struct {
+		node   *poolNode
+		status NodeStatus
+	}
+	entries := make([]entry, len(p.nodes))
+	for i, n := range p.nodes {
+		entries[i] = entry{n, n.snapshot()}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i].status, entries[j].status
+		if a.Healthy != b.Healthy {
+			return a.Healthy
+		}
+		if a.Behind != b.Behind {
+			return !a.Behind
+		}
+		return a.Latency < b.Latency
+	})
+	out := make([]*poolNode, len(entries))
+	for i, e := range entries {
+		out[i] = e.node
+	}
+	return out
+}
+
+func (n *poolNode) snapshot() NodeStatus {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.status
+}
+
+func (n *poolNode) fail(err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.status.Healthy = false
+	n.status.LastError = err
+}
+
+// poolTransport routes each request to the best node and moves on to the
+// next one when the node is unreachable or answers with a server error.
+// Requests that must not be repeated are only moved on if the node could
+// not be dialed, so it never saw them.
+type poolTransport struct {
+	pool *Pool
+}
+
+type resendKey struct{}
+
+// withResend records in ctx whether the request may be sent to another
+// node after a node failed to answer it.
+func withResend(ctx context.Context, ok bool) context.Context {
+	return context.WithValue(ctx, resendKey{}, ok)
+}
+
+func resendable(ctx context.Context) bool {
+	ok, _ := ctx.Value(resendKey{}).(bool)
+	return ok
+}
+
+// isDialError reports whether err means no connection to the node was
+// made, so no part of the request reached it.
+func isDialError(err error) bool {
+	var operr *net.OpError
+	return errors.As(err, &operr) && operr.Op == "dial"
+}
+
+func (t *poolTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	nodes := t.pool.ranked()
+	resend := resendable(req.Context())
+	var lastErr error
+	for i, n := range nodes {
+		r := req.Clone(req.Context())
+		r.URL.Scheme = n.base.Scheme
+		r.URL.Host = n.base.Host
+		r.URL.Path = n.base.Path + req.URL.Path
+		r.Host = ""
+		for key, value := range n.client.config.CustomHeaders {
+			r.Header.Set(key, value)
+		}
+		if i > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r.Body = body
+		}
+
+		recordServedNode(req.Context(), n.endpoint)
+		resp, err := n.client.transport.RoundTrip(r)
+		if err == nil && resp.StatusCode < http.StatusInternalServerError {
+			return resp, nil
+		}
+		if req.Context().Err() != nil {
+			if err == nil {
+				resp.Body.Close()
+			}
+			return nil, req.Context().Err()
+		}
+		if err == nil {
+			err = fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+			n.fail(err)
+			if !resend || i == len(nodes)-1 {
+				return resp, nil
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		} else {
+			n.fail(err)
+			if !resend && !isDialError(err) {
+				return nil, err
+			}
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("all daemon nodes failed: %w", lastErr)
+}
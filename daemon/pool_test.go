@@ -0,0 +1,143 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeNode answers get_info with a fixed height and counts served calls.
+type fakeNode struct {
+	height uint64
+	calls  int32
+	down   int32
+}
+
+func (f *fakeNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if atomic.LoadInt32(&f.down) == 1 {
+		http.Error(w, "down", http.StatusBadGateway)
+		return
+	}
+	atomic.AddInt32(&f.calls, 1)
+	switch r.URL.Path {
+	case "/json_rpc":
+		fmt.Fprintf(w, `{"id":0,"jsonrpc":"2.0","result":{"height":%d,"target_height":0,"synchronized":true,"status":"OK"}}`, f.height)
+	case "/get_height":
+		fmt.Fprintf(w, `{"height":%d,"status":"OK"}`, f.height)
+	case "/send_raw_transaction":
+		fmt.Fprint(w, `{"status":"OK"}`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestPoolPrefersSyncedNode(t *testing.T) {
+	behind, synced := &fakeNode{height: 100}, &fakeNode{height: 200}
+	srvBehind, srvSynced := httptest.NewServer(behind), httptest.NewServer(synced)
+	defer srvBehind.Close()
+	defer srvSynced.Close()
+
+	pool, err := NewPool(PoolConfig{
+		Nodes:               []Config{{Address: srvBehind.URL}, {Address: srvSynced.URL}},
+		HealthCheckInterval: -1,
+	})
+	assert.NoError(t, err)
+	defer pool.Close()
+	pool.CheckNodes(context.Background())
+
+	nodes := pool.Nodes()
+	assert.Equal(t, srvSynced.URL, nodes[0].Address)
+	assert.True(t, nodes[1].Behind)
+
+	res, err := pool.GetHeight()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(200), res.Height)
+}
+
+func TestPoolFailover(t *testing.T) {
+	first, second := &fakeNode{height: 200}, &fakeNode{height: 200}
+	srvFirst, srvSecond := httptest.NewServer(first), httptest.NewServer(second)
+	defer srvFirst.Close()
+	defer srvSecond.Close()
+
+	pool, err := NewPool(PoolConfig{
+		Nodes:               []Config{{Address: srvFirst.URL}, {Address: srvSecond.URL}},
+		HealthCheckInterval: -1,
+	})
+	assert.NoError(t, err)
+	defer pool.Close()
+
+	atomic.StoreInt32(&first.down, 1)
+	res, err := pool.GetInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(200), res.Height)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&second.calls))
+
+	nodes := pool.Nodes()
+	assert.Equal(t, srvSecond.URL, nodes[0].Address)
+	assert.False(t, nodes[1].Healthy)
+
+	// Once every node is down the error surfaces.
+	atomic.StoreInt32(&second.down, 1)
+	_, err = pool.GetInfo()
+	assert.Error(t, err)
+}
+
+func TestPoolNoFailoverForUnsafeMethods(t *testing.T) {
+	first, second := &fakeNode{height: 200}, &fakeNode{height: 200}
+	srvFirst, srvSecond := httptest.NewServer(first), httptest.NewServer(second)
+	defer srvFirst.Close()
+	defer srvSecond.Close()
+
+	newPool := func(retry *RetryPolicy) *Pool {
+		pool, err := NewPool(PoolConfig{
+			Nodes:               []Config{{Address: srvFirst.URL}, {Address: srvSecond.URL}},
+			Retry:               retry,
+			HealthCheckInterval: -1,
+		})
+		assert.NoError(t, err)
+		return pool
+	}
+
+	// The first node may have relayed the transaction before failing.
+	atomic.StoreInt32(&first.down, 1)
+	pool := newPool(nil)
+	defer pool.Close()
+	_, err := pool.SendRawTransaction("00", false)
+	var herr *HTTPStatusError
+	assert.True(t, errors.As(err, &herr), "got %v", err)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&second.calls))
+
+	// Opting in through UnsafeMethods restores failover.
+	unsafe := newPool(&RetryPolicy{UnsafeMethods: []string{"/send_raw_transaction"}})
+	defer unsafe.Close()
+	_, err = unsafe.SendRawTransaction("00", false)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&second.calls))
+}
+
+func TestPoolFailoverOnDialError(t *testing.T) {
+	node := &fakeNode{height: 200}
+	srv := httptest.NewServer(node)
+	defer srv.Close()
+	closed := httptest.NewServer(node)
+	closed.Close()
+
+	pool, err := NewPool(PoolConfig{
+		Nodes:               []Config{{Address: closed.URL}, {Address: srv.URL}},
+		HealthCheckInterval: -1,
+	})
+	assert.NoError(t, err)
+	defer pool.Close()
+
+	// A node that cannot be dialed never saw the transaction.
+	_, err = pool.SendRawTransaction("00", false)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&node.calls))
+}
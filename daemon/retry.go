@@ -0,0 +1,165 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/boomhut/go-monero-rpc-client/internal/retry"
+)
+
+// RetryPolicy configures how the client retries failed calls.
+//
+// Only methods classified as idempotent by IsIdempotent are retried.
+// Methods with side effects (relay_tx, submit_block, /send_raw_transaction,
+// /pop_blocks, ...) are attempted exactly once unless they are listed in
+// UnsafeMethods.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first one.
+	// Values below 2 disable retrying.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry. Defaults to 100ms.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts. Defaults to 5s.
+	MaxBackoff time.Duration
+	// Multiplier is the exponential growth factor of the delay. Defaults to 2.
+	Multiplier float64
+	// Jitter is the fraction (0 to 1) of each delay that is randomized.
+	Jitter float64
+	// Retryable reports whether a failed attempt should be retried.
+	// DefaultRetryable is used when nil.
+	Retryable func(err error) bool
+	// UnsafeMethods lists non-idempotent methods that the caller explicitly
+	// allows to be retried. JSON-RPC methods are named as sent on the wire
+	// (e.g. "relay_tx"), other endpoints by path (e.g. "/send_raw_transaction").
+	UnsafeMethods []string
+}
+
+// attempts returns how many times method may be attempted under p.
+func (p *RetryPolicy) attempts(method string) int {
+	if p == nil || p.MaxAttempts < 2 || !p.repeatable(method) {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// repeatable reports whether method may be sent more than once under p:
+// it is idempotent or listed in UnsafeMethods. A nil policy only repeats
+// idempotent methods.
+func (p *RetryPolicy) repeatable(method string) bool {
+	if IsIdempotent(method) {
+		return true
+	}
+	if p == nil {
+		return false
+	}
+	for _, m := range p.UnsafeMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RetryPolicy) retryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return DefaultRetryable(err)
+}
+
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	initial, max, multiplier := p.InitialBackoff, p.MaxBackoff, p.Multiplier
+	if initial <= 0 {
+		initial = 100 * time.Millisecond
+	}
+	if max <= 0 {
+		max = 5 * time.Second
+	}
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	return retry.Backoff(attempt, initial, max, multiplier, p.Jitter)
+}
+
+// DefaultRetryable retries transport failures such as connection refused or
+// reset, timeouts and unexpected EOF, 502/503/504 responses and a busy
+// daemon. A request that timed out is retried even though it matches
+// context.DeadlineExceeded; cancellation or expiry of the caller's context
+// is never retried.
+func DefaultRetryable(err error) bool {
+	var terr *TransportError
+	if errors.As(err, &terr) && terr.Timeout() {
+		return true
+	}
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var serr *HTTPStatusError
+	if errors.As(err, &serr) {
+		switch serr.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	return errors.Is(err, ErrTransport) || errors.Is(err, ErrBusy)
+}
+
+// IsIdempotent reports whether the daemon method can be repeated without
+// changing the outcome, and is therefore retried by a RetryPolicy.
+// JSON-RPC methods are named as sent on the wire, other endpoints by path.
+func IsIdempotent(method string) bool {
+	return idempotentMethods[method]
+}
+
+var idempotentMethods = map[string]bool{
+	// JSON-RPC
+	"get_block_count":            true,
+	"on_get_block_hash":          true,
+	"get_block_template":         true,
+	"get_last_block_header":      true,
+	"get_block_header_by_hash":   true,
+	"get_block_header_by_height": true,
+	"get_block_headers_range":    true,
+	"get_block":                  true,
+	"get_connections":            true,
+	"get_info":                   true,
+	"hard_fork_info":             true,
+	"get_bans":                   true,
+	"banned":                     true,
+	"get_output_histogram":       true,
+	"get_coinbase_tx_sum":        true,
+	"get_version":                true,
+	"get_fee_estimate":           true,
+	"get_alternate_chains":       true,
+	"sync_info":                  true,
+	"get_txpool_backlog":         true,
+	"get_output_distribution":    true,
+	"get_miner_data":             true,
+	"calc_pow":                   true,
+	"add_aux_pow":                true,
+
+	// Other endpoints
+	"/get_height":                      true,
+	"/get_blocks.bin":                  true,
+	"/get_blocks_by_height.bin":        true,
+	"/get_outs.bin":                    true,
+	"/get_o_indexes.bin":               true,
+	"/get_hashes.bin":                  true,
+	"/get_transaction_pool_hashes.bin": true,
+	"/get_output_distribution.bin":     true,
+	"/get_transactions":                true,
+	"/get_alt_blocks_hashes":           true,
+	"/is_key_image_spent":              true,
+	"/mining_status":                   true,
+	"/get_peer_list":                   true,
+	"/get_public_nodes":                true,
+	"/get_transaction_pool":            true,
+	"/get_transaction_pool_hashes":     true,
+	"/get_transaction_pool_stats":      true,
+	"/get_limit":                       true,
+	"/get_net_stats":                   true,
+	"/get_outs":                        true,
+}
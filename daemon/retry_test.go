@@ -0,0 +1,63 @@
+package daemon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyServer drops the connection until failures is exhausted.
+func flakyServer(t *testing.T, failures int32, calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(calls, 1) <= failures {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if assert.NoError(t, err) {
+				conn.Close()
+			}
+			return
+		}
+		w.Write([]byte(`{"height":42,"status":"OK"}`))
+	}))
+}
+
+func TestRetryIdempotent(t *testing.T) {
+	var calls int32
+	srv := flakyServer(t, 2, &calls)
+	defer srv.Close()
+
+	cl := New(Config{
+		Address: srv.URL,
+		Retry:   &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	})
+	res, err := cl.GetHeight()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(42), res.Height)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestRetryUnsafe(t *testing.T) {
+	var calls int32
+	srv := flakyServer(t, 1, &calls)
+	defer srv.Close()
+
+	cl := New(Config{
+		Address: srv.URL,
+		Retry:   &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	})
+	_, err := cl.SendRawTransaction("00", false)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	atomic.StoreInt32(&calls, 0)
+	cl = New(Config{
+		Address: srv.URL,
+		Retry:   &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, UnsafeMethods: []string{"/send_raw_transaction"}},
+	})
+	_, err = cl.SendRawTransaction("00", false)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
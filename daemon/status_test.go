@@ -0,0 +1,67 @@
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusNotOK(t *testing.T) {
+	var busy int32 = 1
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json_rpc":
+			if atomic.CompareAndSwapInt32(&busy, 1, 0) {
+				w.Write([]byte(`{"id":0,"jsonrpc":"2.0","result":{"status":"BUSY"}}`))
+				return
+			}
+			w.Write([]byte(`{"id":0,"jsonrpc":"2.0","result":{"height":5,"status":"OK"}}`))
+		case "/send_raw_transaction":
+			w.Write([]byte(`{"double_spend":true,"reason":"double spend","status":"Failed"}`))
+		}
+	}))
+	defer srv.Close()
+
+	_, err := New(Config{Address: srv.URL}).GetInfo()
+	assert.True(t, errors.Is(err, ErrBusy))
+	assert.True(t, errors.Is(err, ErrStatus))
+
+	// BUSY is retried by the default policy.
+	atomic.StoreInt32(&busy, 1)
+	info, err := New(Config{
+		Address: srv.URL,
+		Retry:   &RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+	}).GetInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), info.Height)
+
+	_, err = New(Config{Address: srv.URL}).SendRawTransaction("00", false)
+	var serr *StatusError
+	assert.True(t, errors.As(err, &serr))
+	assert.Equal(t, StatusFailed, serr.Status)
+	assert.Equal(t, "double spend", serr.Reason)
+	assert.Equal(t, "/send_raw_transaction", serr.Method)
+	var res ResponseSendRawTransaction
+	assert.NoError(t, json.Unmarshal(serr.Response, &res))
+	assert.True(t, res.DoubleSpend)
+}
+
+func TestRejectUntrusted(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"height":5,"status":"OK","untrusted":true}`))
+	}))
+	defer srv.Close()
+
+	res, err := New(Config{Address: srv.URL}).GetHeight()
+	assert.NoError(t, err)
+	assert.True(t, res.Untrusted)
+
+	_, err = New(Config{Address: srv.URL, RejectUntrusted: true}).GetHeight()
+	assert.True(t, errors.Is(err, ErrUntrusted))
+}
@@ -464,15 +464,27 @@ type RequestGetOutputDistribution struct {
 	Compress   bool     `json:"compress,omitempty"`
 }
 
+// OutputDistribution is the distribution of the outputs of one amount.
+// Distribution has one count per block from StartHeight on: the number of
+// outputs created up to and including the block if Cumulative is set, and
+// in the block otherwise. Base is the number of outputs created before
+// StartHeight.
+type OutputDistribution struct {
+	Amount       uint64   `json:"amount"`
+	Base         uint64   `json:"base"`
+	Distribution []uint64 `json:"distribution"`
+	StartHeight  uint64   `json:"start_height"`
+	Binary       bool     `json:"binary"`
+	Compress     bool     `json:"compress"`
+	// Cumulative is not sent by the daemon. The client sets it from the
+	// request.
+	Cumulative bool `json:"-"`
+}
+
 type ResponseGetOutputDistribution struct {
-	Distributions []struct {
-		Amount       uint64   `json:"amount"`
-		Base         uint64   `json:"base"`
-		Distribution []uint64 `json:"distribution"`
-		StartHeight  uint64   `json:"start_height"`
-	} `json:"distributions"`
-	Status    string `json:"status"`
-	Untrusted bool   `json:"untrusted"`
+	Distributions []OutputDistribution `json:"distributions"`
+	Status        string               `json:"status"`
+	Untrusted     bool                 `json:"untrusted"`
 }
 
 // GetMinerData
@@ -885,3 +897,156 @@ type ResponsePopBlocks struct {
 	Status    string `json:"status"`
 	Untrusted bool   `json:"untrusted"`
 }
+
+// Binary endpoints. These are encoded with epee portable storage, so hashes
+// are binary and blobs are raw bytes instead of hex strings.
+
+// RequestedInfo values of RequestGetBlocksBin.
+const (
+	RequestedBlocks        uint8 = 0
+	RequestedBlocksAndPool uint8 = 1
+	RequestedPool          uint8 = 2
+)
+
+// PoolInfoExtent values of ResponseGetBlocksBin.
+const (
+	PoolInfoNone        uint8 = 0
+	PoolInfoIncremental uint8 = 1
+	PoolInfoFull        uint8 = 2
+)
+
+// TxBlobEntry is a transaction of a BlockCompleteEntry. PrunableHash is
+// only set for pruned transactions.
+type TxBlobEntry struct {
+	Blob         []byte `epee:"blob"`
+	PrunableHash Hash   `epee:"prunable_hash"`
+}
+
+// BlockCompleteEntry is a block blob with the blobs of its transactions,
+// not including the miner transaction, which is part of the block.
+type BlockCompleteEntry struct {
+	Pruned      bool          `epee:"pruned,omitempty"`
+	Block       []byte        `epee:"block"`
+	BlockWeight uint64        `epee:"block_weight,omitempty"`
+	Txs         []TxBlobEntry `epee:"txs"`
+}
+
+// TxOutputIndices are the global output indices of a transaction.
+type TxOutputIndices struct {
+	Indices []uint64 `epee:"indices"`
+}
+
+// BlockOutputIndices are the output indices of the transactions of a
+// block, the miner transaction first.
+type BlockOutputIndices struct {
+	Indices []TxOutputIndices `epee:"indices"`
+}
+
+// PoolTxInfo is a pool transaction returned by get_blocks.bin.
+type PoolTxInfo struct {
+	TxHash          Hash   `epee:"tx_hash"`
+	TxBlob          []byte `epee:"tx_blob"`
+	DoubleSpendSeen bool   `epee:"double_spend_seen"`
+}
+
+// GetBlocksBin
+//
+// BlockIDs is required: a sparse chain history with the most recent block
+// ids first and the genesis block id last, which monerod uses to find
+// where its main chain and the caller's split. It fails the request if
+// BlockIDs is empty or does not end with the genesis block. The blocks
+// start at that split height, or at StartHeight if it is higher; a
+// StartHeight alone, with empty BlockIDs, is rejected. Pass just the
+// genesis block id to start from StartHeight.
+type RequestGetBlocksBin struct {
+	RequestedInfo uint8  `epee:"requested_info,omitempty"`
+	BlockIDs      []Hash `epee:"block_ids,blob"`
+	StartHeight   uint64 `epee:"start_height"`
+	Prune         bool   `epee:"prune"`
+	NoMinerTx     bool   `epee:"no_miner_tx,omitempty"`
+	PoolInfoSince uint64 `epee:"pool_info_since,omitempty"`
+	MaxBlockCount uint64 `epee:"max_block_count,omitempty"`
+}
+
+type ResponseGetBlocksBin struct {
+	Blocks                  []BlockCompleteEntry `epee:"blocks"`
+	StartHeight             uint64               `epee:"start_height"`
+	CurrentHeight           uint64               `epee:"current_height"`
+	OutputIndices           []BlockOutputIndices `epee:"output_indices"`
+	DaemonTime              uint64               `epee:"daemon_time"`
+	PoolInfoExtent          uint8                `epee:"pool_info_extent"`
+	AddedPoolTxs            []PoolTxInfo         `epee:"added_pool_txs"`
+	RemainingAddedPoolTxids []Hash               `epee:"remaining_added_pool_txids,blob"`
+	RemovedPoolTxids        []Hash               `epee:"removed_pool_txids,blob"`
+	TopBlockHash            Hash                 `epee:"top_block_hash"`
+	Status                  string               `epee:"status"`
+	Untrusted               bool                 `epee:"untrusted"`
+}
+
+// GetBlocksByHeightBin
+type RequestGetBlocksByHeightBin struct {
+	Heights []uint64 `epee:"heights"`
+}
+
+type ResponseGetBlocksByHeightBin struct {
+	Blocks    []BlockCompleteEntry `epee:"blocks"`
+	Status    string               `epee:"status"`
+	Untrusted bool                 `epee:"untrusted"`
+}
+
+// GetOutsBin
+type RequestGetOutsBin struct {
+	Outputs []OutputIndex `epee:"outputs"`
+	// GetTxID is always sent: monerod defaults it to true.
+	GetTxID bool `epee:"get_txid"`
+}
+
+// OutKeyBin is an output returned by get_outs.bin. Txid is zero unless it
+// was requested.
+type OutKeyBin struct {
+	Key      Hash   `epee:"key"`
+	Mask     Hash   `epee:"mask"`
+	Unlocked bool   `epee:"unlocked"`
+	Height   uint64 `epee:"height"`
+	Txid     Hash   `epee:"txid"`
+}
+
+type ResponseGetOutsBin struct {
+	Outs      []OutKeyBin `epee:"outs"`
+	Status    string      `epee:"status"`
+	Untrusted bool        `epee:"untrusted"`
+}
+
+// GetOIndexesBin
+type RequestGetOIndexesBin struct {
+	TxID Hash `epee:"txid"`
+}
+
+type ResponseGetOIndexesBin struct {
+	OIndexes  []uint64 `epee:"o_indexes"`
+	Status    string   `epee:"status"`
+	Untrusted bool     `epee:"untrusted"`
+}
+
+// GetHashesBin
+//
+// BlockIDs is a short chain history, as in RequestGetBlocksBin.
+type RequestGetHashesBin struct {
+	BlockIDs    []Hash `epee:"block_ids,blob"`
+	StartHeight uint64 `epee:"start_height"`
+}
+
+type ResponseGetHashesBin struct {
+	BlockIDs      []Hash `epee:"m_block_ids,blob"`
+	StartHeight   uint64 `epee:"start_height"`
+	CurrentHeight uint64 `epee:"current_height"`
+	Status        string `epee:"status"`
+	Untrusted     bool   `epee:"untrusted"`
+}
+
+// GetTransactionPoolHashesBin
+type ResponseGetTransactionPoolHashesBin struct {
+	TxHashes  []Hash `epee:"tx_hashes,blob"`
+	Status    string `epee:"status"`
+	Untrusted bool   `epee:"untrusted"`
+}
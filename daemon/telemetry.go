@@ -0,0 +1,83 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/boomhut/go-monero-rpc-client/internal/instrument"
+	"github.com/boomhut/go-monero-rpc-client/telemetry"
+)
+
+// instrumentInterceptor reports every call to config.Tracer and
+// config.Metrics. It runs inside the user interceptors, so it describes the
+// RPC as actually sent, retries included. Calls through a Pool report the
+// node that served them as their endpoint.
+func instrumentInterceptor(config Config) Interceptor {
+	address := instrument.Endpoint(config.Address)
+	return func(ctx context.Context, method string, req, res interface{}, next Invoker) error {
+		path := "/json_rpc"
+		if strings.HasPrefix(method, "/") {
+			path = method
+		}
+		info := telemetry.CallInfo{
+			System:   telemetry.SystemDaemon,
+			Method:   method,
+			Endpoint: address + path,
+		}
+		served := &servedNode{}
+		classify := func(info *telemetry.CallInfo) {
+			if served.endpoint != "" {
+				info.Endpoint = served.endpoint + path
+			}
+			classifyError(info)
+		}
+		ctx = context.WithValue(ctx, servedNodeKey{}, served)
+		return instrument.Call(ctx, config.Tracer, config.Metrics, info, classify, func(ctx context.Context) error {
+			return next(ctx, method, req, res)
+		})
+	}
+}
+
+type servedNodeKey struct{}
+
+// servedNode is filled in by a Pool with the node that last handled a
+// request, for instrumentInterceptor.
+type servedNode struct {
+	endpoint string
+}
+
+// recordServedNode notes in ctx, if it is instrumented, that endpoint
+// handled the request.
+func recordServedNode(ctx context.Context, endpoint string) {
+	if served, ok := ctx.Value(servedNodeKey{}).(*servedNode); ok {
+		served.endpoint = endpoint
+	}
+}
+
+func classifyError(info *telemetry.CallInfo) {
+	var (
+		herr *HTTPStatusError
+		rerr *RPCError
+		serr *StatusError
+	)
+	switch {
+	case info.Err == nil:
+		info.HTTPStatusCode = http.StatusOK
+		info.Status = StatusOK
+	case errors.As(info.Err, &rerr):
+		info.HTTPStatusCode = http.StatusOK
+		info.ErrorCode = int(rerr.Code)
+		info.ErrorType = telemetry.ErrorRPC
+	case errors.As(info.Err, &serr):
+		info.HTTPStatusCode = http.StatusOK
+		info.Status = serr.Status
+		info.ErrorType = telemetry.ErrorStatus
+	case errors.As(info.Err, &herr):
+		info.HTTPStatusCode = herr.StatusCode
+		info.ErrorType = telemetry.ErrorHTTP
+	case errors.Is(info.Err, ErrTransport) && info.ErrorType == "":
+		info.ErrorType = telemetry.ErrorTransport
+	}
+}
@@ -0,0 +1,107 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/boomhut/go-monero-rpc-client/telemetry"
+	"github.com/stretchr/testify/assert"
+)
+
+type testRecorder struct{ calls []telemetry.CallInfo }
+
+func (r *testRecorder) RecordCall(ctx context.Context, info telemetry.CallInfo) {
+	r.calls = append(r.calls, info)
+}
+
+type testSpan struct{ err error }
+
+func (s *testSpan) SetAttributes(attrs ...telemetry.Attribute) {}
+func (s *testSpan) RecordError(err error)                      { s.err = err }
+func (s *testSpan) End()                                       {}
+
+type testTracer struct{ spans []*testSpan }
+
+func (t *testTracer) Start(ctx context.Context, name string) (context.Context, telemetry.Span) {
+	s := &testSpan{}
+	t.spans = append(t.spans, s)
+	return ctx, s
+}
+
+func TestTelemetry(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json_rpc":
+			w.Write([]byte(`{"id":0,"jsonrpc":"2.0","result":{"status":"BUSY"}}`))
+		case "/get_height":
+			w.Write([]byte(`{"height":5,"status":"OK"}`))
+		default:
+			http.Error(w, "forbidden", http.StatusForbidden)
+		}
+	}))
+	defer srv.Close()
+
+	rec, tracer := &testRecorder{}, &testTracer{}
+	cl := New(Config{Address: srv.URL, Metrics: rec, Tracer: tracer})
+	_, err := cl.GetHeight()
+	assert.NoError(t, err)
+	_, err = cl.GetInfo()
+	assert.Error(t, err)
+	_, err = cl.StopDaemon()
+	assert.Error(t, err)
+
+	assert.Len(t, rec.calls, 3)
+	assert.Equal(t, "/get_height", rec.calls[0].Method)
+	assert.Equal(t, srv.URL+"/get_height", rec.calls[0].Endpoint)
+	assert.Equal(t, StatusOK, rec.calls[0].Status)
+	assert.Equal(t, "", rec.calls[0].ErrorType)
+
+	assert.Equal(t, "get_info", rec.calls[1].Method)
+	assert.Equal(t, srv.URL+"/json_rpc", rec.calls[1].Endpoint)
+	assert.Equal(t, StatusBusy, rec.calls[1].Status)
+	assert.Equal(t, telemetry.ErrorStatus, rec.calls[1].ErrorType)
+
+	assert.Equal(t, http.StatusForbidden, rec.calls[2].HTTPStatusCode)
+	assert.Equal(t, telemetry.ErrorHTTP, rec.calls[2].ErrorType)
+
+	// Spans get the error type and status, not the response body.
+	assert.Len(t, tracer.spans, 3)
+	assert.NoError(t, tracer.spans[0].err)
+	assert.Equal(t, "monerod status error, status BUSY", tracer.spans[1].err.Error())
+	assert.Equal(t, "monerod http error, HTTP status 403", tracer.spans[2].err.Error())
+	var herr *HTTPStatusError
+	assert.True(t, errors.As(err, &herr))
+	assert.Contains(t, string(herr.Body), "forbidden")
+	assert.Equal(t, tracer.spans[2].err, rec.calls[2].Err)
+	assert.NotContains(t, rec.calls[2].Err.Error(), "forbidden")
+}
+
+func TestTelemetryPool(t *testing.T) {
+	first, second := &fakeNode{height: 200}, &fakeNode{height: 200}
+	srvFirst, srvSecond := httptest.NewServer(first), httptest.NewServer(second)
+	defer srvFirst.Close()
+	defer srvSecond.Close()
+
+	rec := &testRecorder{}
+	pool, err := NewPool(PoolConfig{
+		Nodes:               []Config{{Address: srvFirst.URL}, {Address: srvSecond.URL}},
+		Metrics:             rec,
+		HealthCheckInterval: -1,
+	})
+	assert.NoError(t, err)
+	defer pool.Close()
+
+	_, err = pool.GetInfo()
+	assert.NoError(t, err)
+	atomic.StoreInt32(&first.down, 1)
+	_, err = pool.GetHeight()
+	assert.NoError(t, err)
+
+	assert.Len(t, rec.calls, 2)
+	assert.Equal(t, srvFirst.URL+"/json_rpc", rec.calls[0].Endpoint)
+	assert.Equal(t, srvSecond.URL+"/get_height", rec.calls[1].Endpoint)
+}
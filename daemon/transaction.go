@@ -0,0 +1,155 @@
+package daemon
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/boomhut/go-monero-rpc-client/cryptonote"
+)
+
+// Transaction decodes AsJSON, which is only set when the transaction was
+// requested with decodeAsJSON.
+func (t *TxInfo) Transaction() (*cryptonote.Transaction, error) {
+	if t.AsJSON == "" {
+		return nil, fmt.Errorf("transaction %s has no JSON, request it with decodeAsJSON", t.TxHash)
+	}
+	var tx cryptonote.Transaction
+	if err := json.Unmarshal([]byte(t.AsJSON), &tx); err != nil {
+		return nil, fmt.Errorf("failed to decode transaction %s: %w", t.TxHash, err)
+	}
+	return &tx, nil
+}
+
+// ParseBlob decodes the transaction from its blob: AsHex, or PrunedAsHex
+// followed by PrunableAsHex when it was requested with split. With
+// PrunedAsHex alone the transaction has no RctPrunable.
+func (t *TxInfo) ParseBlob() (*cryptonote.Transaction, error) {
+	full, pruned, err := t.blobs()
+	if err != nil {
+		return nil, err
+	}
+	var tx *cryptonote.Transaction
+	if full != nil {
+		tx, err = cryptonote.ParseTransaction(full)
+	} else {
+		tx, err = cryptonote.ParsePrunedTransaction(pruned)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse transaction %s: %w", t.TxHash, err)
+	}
+	return tx, nil
+}
+
+// VerifyHash recomputes the id of the transaction from its blob, using
+// PrunableHash for pruned blobs, and checks it against TxHash.
+func (t *TxInfo) VerifyHash() error {
+	full, pruned, err := t.blobs()
+	if err != nil {
+		return err
+	}
+	var id cryptonote.Hash
+	if full != nil {
+		id, err = cryptonote.TransactionHash(full)
+	} else {
+		var prunableHash cryptonote.Hash
+		if prunableHash, err = cryptonote.ParseHash(t.PrunableHash); err == nil {
+			id, err = cryptonote.PrunedTransactionHash(pruned, prunableHash)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to hash transaction %s: %w", t.TxHash, err)
+	}
+	if id.String() != t.TxHash {
+		return fmt.Errorf("%w: transaction %s hashes to %s", ErrTxHashMismatch, t.TxHash, id)
+	}
+	return nil
+}
+
+// blobs returns the full blob of the transaction if t has one, otherwise
+// the pruned blob.
+func (t *TxInfo) blobs() (full, pruned []byte, err error) {
+	switch {
+	case t.AsHex != "":
+		full, err = hex.DecodeString(t.AsHex)
+	case t.PrunedAsHex != "" && t.PrunableAsHex != "":
+		var prunable []byte
+		if pruned, err = hex.DecodeString(t.PrunedAsHex); err == nil {
+			prunable, err = hex.DecodeString(t.PrunableAsHex)
+		}
+		full, pruned = append(pruned, prunable...), nil
+	case t.PrunedAsHex != "":
+		pruned, err = hex.DecodeString(t.PrunedAsHex)
+	default:
+		return nil, nil, fmt.Errorf("transaction %s has no blob", t.TxHash)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid blob of transaction %s: %w", t.TxHash, err)
+	}
+	return full, pruned, nil
+}
+
+// Transactions decodes the JSON of all transactions in r, in the order of
+// Txs. Responses of old daemons without Txs are decoded from TxsAsJSON.
+func (r *ResponseGetTransactions) Transactions() ([]*cryptonote.Transaction, error) {
+	if len(r.Txs) == 0 && len(r.TxsAsJSON) > 0 {
+		txs := make([]*cryptonote.Transaction, len(r.TxsAsJSON))
+		for i, s := range r.TxsAsJSON {
+			info := TxInfo{AsJSON: s}
+			tx, err := info.Transaction()
+			if err != nil {
+				return nil, err
+			}
+			txs[i] = tx
+		}
+		return txs, nil
+	}
+	txs := make([]*cryptonote.Transaction, len(r.Txs))
+	for i := range r.Txs {
+		tx, err := r.Txs[i].Transaction()
+		if err != nil {
+			return nil, err
+		}
+		txs[i] = tx
+	}
+	return txs, nil
+}
+
+// GetDecodedTransactions fetches transactions by hash with get_transactions
+// and returns them decoded, in the order of txHashes. Pruned transactions
+// have no RctPrunable.
+func GetDecodedTransactions(ctx context.Context, c Client, txHashes []string, prune bool) ([]*cryptonote.Transaction, error) {
+	res, err := c.GetTransactionsContext(ctx, txHashes, true, prune, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.MissedTx) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrTxNotFound, strings.Join(res.MissedTx, ", "))
+	}
+	txs, err := res.Transactions()
+	if err != nil {
+		return nil, err
+	}
+	if len(txs) != len(txHashes) {
+		return nil, fmt.Errorf("got %d transactions for %d hashes", len(txs), len(txHashes))
+	}
+	if len(res.Txs) != len(txs) {
+		// Old daemons only fill TxsAsJSON, in request order.
+		return txs, nil
+	}
+	byHash := make(map[string]*cryptonote.Transaction, len(txs))
+	for i, tx := range txs {
+		byHash[res.Txs[i].TxHash] = tx
+	}
+	ordered := make([]*cryptonote.Transaction, len(txHashes))
+	for i, h := range txHashes {
+		tx, ok := byHash[h]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrTxNotFound, h)
+		}
+		ordered[i] = tx
+	}
+	return ordered, nil
+}
@@ -0,0 +1,90 @@
+package daemon
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/boomhut/go-monero-rpc-client/cryptonote"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxInfoBlobs(t *testing.T) {
+	tx := &cryptonote.Transaction{
+		Version: 2,
+		Inputs:  []cryptonote.TxIn{{Key: &cryptonote.TxInKey{KeyOffsets: []uint64{10, 3}, KeyImage: cryptonote.Key{1}}}},
+		Outputs: []cryptonote.TxOut{{Target: cryptonote.TxOutTarget{TaggedKey: &cryptonote.TaggedKey{Key: cryptonote.Key{2}, ViewTag: 3}}}},
+		Extra:   cryptonote.Extra{1},
+		RctSignatures: &cryptonote.RctSignatures{
+			Type:     cryptonote.RCTTypeBulletproofPlus,
+			TxnFee:   1000,
+			EcdhInfo: []cryptonote.EcdhTuple{{Amount: make(cryptonote.HexBytes, 8)}},
+			OutPk:    []cryptonote.Key{{4}},
+		},
+		RctPrunable: &cryptonote.RctPrunable{
+			NBP:              1,
+			BulletproofsPlus: []cryptonote.BulletproofPlus{{L: []cryptonote.Key{{5}}, R: []cryptonote.Key{{6}}}},
+			CLSAGs:           []cryptonote.Clsag{{S: []cryptonote.Key{{7}, {8}}}},
+			PseudoOuts:       []cryptonote.Key{{9}},
+		},
+	}
+	full, err := tx.MarshalBinary()
+	assert.NoError(t, err)
+	pruned, err := tx.MarshalPrunedBinary()
+	assert.NoError(t, err)
+	id, err := tx.Hash()
+	assert.NoError(t, err)
+	prunableHash, err := cryptonote.TransactionPrunableHash(full)
+	assert.NoError(t, err)
+
+	for name, info := range map[string]TxInfo{
+		"full":  {AsHex: hex.EncodeToString(full)},
+		"split": {PrunedAsHex: hex.EncodeToString(pruned), PrunableAsHex: hex.EncodeToString(full[len(pruned):])},
+	} {
+		info.TxHash = id.String()
+		assert.NoError(t, info.VerifyHash(), name)
+		parsed, err := info.ParseBlob()
+		assert.NoError(t, err, name)
+		assert.Equal(t, tx, parsed, name)
+	}
+
+	info := TxInfo{TxHash: id.String(), PrunedAsHex: hex.EncodeToString(pruned), PrunableHash: prunableHash.String()}
+	assert.NoError(t, info.VerifyHash())
+	parsed, err := info.ParseBlob()
+	assert.NoError(t, err)
+	assert.Nil(t, parsed.RctPrunable)
+	assert.Equal(t, uint64(1000), parsed.Fee())
+
+	info.PrunableHash = cryptonote.Hash{}.String()
+	assert.True(t, errors.Is(info.VerifyHash(), ErrTxHashMismatch))
+	info.PrunableHash = ""
+	assert.Error(t, info.VerifyHash())
+
+	_, err = (&TxInfo{TxHash: id.String()}).ParseBlob()
+	assert.Error(t, err)
+	_, err = (&TxInfo{AsHex: "zz"}).ParseBlob()
+	assert.Error(t, err)
+}
+
+// A real mainnet transaction, see TestMainnetTransaction in the cryptonote
+// package.
+func TestTxInfoMainnet(t *testing.T) {
+	data, err := os.ReadFile("../cryptonote/testdata/mainnet_rct_simple.json")
+	assert.NoError(t, err)
+	var info TxInfo
+	assert.NoError(t, json.Unmarshal(data, &info))
+
+	full := TxInfo{TxHash: info.TxHash, AsHex: info.AsHex}
+	assert.NoError(t, full.VerifyHash())
+	tx, err := full.ParseBlob()
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(cryptonote.RCTTypeSimple), tx.RctSignatures.Type)
+
+	pruned := TxInfo{TxHash: info.TxHash, PrunedAsHex: info.PrunedAsHex, PrunableHash: info.PrunableHash}
+	assert.NoError(t, pruned.VerifyHash())
+	parsed, err := pruned.ParseBlob()
+	assert.NoError(t, err)
+	assert.Equal(t, tx.Fee(), parsed.Fee())
+}
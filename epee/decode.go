@@ -0,0 +1,411 @@
+package epee
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// Unmarshal decodes the epee document data into v, which must be a
+// non-nil pointer to a struct, a map with string keys or an interface{}.
+// Entries without a matching struct field are ignored.
+func Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("epee: Unmarshal needs a non-nil pointer, not %T", v)
+	}
+	if !bytes.HasPrefix(data, Header) {
+		return ErrHeader
+	}
+	d := &decoder{data: data[len(Header):]}
+	root, err := d.section()
+	if err != nil {
+		return err
+	}
+	if len(d.data) > 0 {
+		return formatError("%d bytes after the root section", len(d.data))
+	}
+	return assign(rv.Elem(), root, "")
+}
+
+// Assign stores x, a value decoded by Unmarshal into an interface{}, in
+// the value v points to, as Unmarshal would. It lets an Unmarshaler decode
+// parts of its value with the usual rules.
+func Assign(v interface{}, x interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("epee: Assign needs a non-nil pointer, not %T", v)
+	}
+	return assign(rv.Elem(), x, "")
+}
+
+// ReadVarint decodes an epee varint from the start of data and returns it
+// with the number of bytes read.
+func ReadVarint(data []byte) (uint64, int, error) {
+	if len(data) == 0 {
+		return 0, 0, formatError("truncated varint")
+	}
+	size := 1 << (data[0] & 3)
+	if len(data) < size {
+		return 0, 0, formatError("truncated varint")
+	}
+	var v uint64
+	for i := size - 1; i >= 0; i-- {
+		v = v<<8 | uint64(data[i])
+	}
+	return v >> 2, size, nil
+}
+
+type decoder struct {
+	data  []byte
+	depth int
+}
+
+func (d *decoder) next(n int) ([]byte, error) {
+	if n > len(d.data) {
+		return nil, formatError("unexpected end of data")
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b, nil
+}
+
+func (d *decoder) varint() (uint64, error) {
+	v, n, err := ReadVarint(d.data)
+	if err != nil {
+		return 0, err
+	}
+	d.data = d.data[n:]
+	return v, nil
+}
+
+// count reads the number of entries or elements that follow. Each takes
+// at least one byte, so larger counts are rejected before allocating.
+func (d *decoder) count() (int, error) {
+	n, err := d.varint()
+	if err != nil {
+		return 0, err
+	}
+	if n > uint64(len(d.data)) {
+		return 0, formatError("count %d exceeds the %d bytes left", n, len(d.data))
+	}
+	return int(n), nil
+}
+
+func (d *decoder) enter() error {
+	d.depth++
+	if d.depth > MaxDepth {
+		return formatError("nested deeper than %d", MaxDepth)
+	}
+	return nil
+}
+
+func (d *decoder) section() (map[string]interface{}, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { d.depth-- }()
+	n, err := d.count()
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		size, err := d.next(1)
+		if err != nil {
+			return nil, err
+		}
+		name, err := d.next(int(size[0]))
+		if err != nil {
+			return nil, err
+		}
+		t, err := d.next(1)
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.value(Type(t[0]))
+		if err != nil {
+			return nil, fmt.Errorf("%w (in %s)", err, name)
+		}
+		m[string(name)] = v
+	}
+	return m, nil
+}
+
+// sizes are the sizes of the fixed-size types.
+var sizes = map[Type]int{
+	TypeInt64: 8, TypeInt32: 4, TypeInt16: 2, TypeInt8: 1,
+	TypeUint64: 8, TypeUint32: 4, TypeUint16: 2, TypeUint8: 1,
+	TypeDouble: 8, TypeBool: 1,
+}
+
+func (d *decoder) value(t Type) (interface{}, error) {
+	if t&FlagArray != 0 {
+		return d.array(t &^ FlagArray)
+	}
+	if t == TypeObject {
+		return d.section()
+	}
+	if t == TypeString {
+		n, err := d.varint()
+		if err != nil {
+			return nil, err
+		}
+		if n > uint64(len(d.data)) {
+			return nil, formatError("string of %d bytes exceeds the %d bytes left", n, len(d.data))
+		}
+		b, _ := d.next(int(n))
+		return string(b), nil
+	}
+	if t == TypeArray {
+		// An element of an array of arrays carries its own type.
+		et, err := d.next(1)
+		if err != nil {
+			return nil, err
+		}
+		if Type(et[0])&FlagArray == 0 {
+			return nil, formatError("nested array has type %s", Type(et[0]))
+		}
+		return d.value(Type(et[0]))
+	}
+	size := sizes[t]
+	if size == 0 {
+		return nil, formatError("unknown type %d", byte(t))
+	}
+	b, err := d.next(size)
+	if err != nil {
+		return nil, err
+	}
+	var u uint64
+	for i := size - 1; i >= 0; i-- {
+		u = u<<8 | uint64(b[i])
+	}
+	switch t {
+	case TypeInt64:
+		return int64(u), nil
+	case TypeInt32:
+		return int32(u), nil
+	case TypeInt16:
+		return int16(u), nil
+	case TypeInt8:
+		return int8(u), nil
+	case TypeUint64:
+		return u, nil
+	case TypeUint32:
+		return uint32(u), nil
+	case TypeUint16:
+		return uint16(u), nil
+	case TypeUint8:
+		return uint8(u), nil
+	case TypeDouble:
+		return math.Float64frombits(u), nil
+	}
+	return u != 0, nil
+}
+
+func (d *decoder) array(t Type) ([]interface{}, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { d.depth-- }()
+	n, err := d.count()
+	if err != nil {
+		return nil, err
+	}
+	a := make([]interface{}, n)
+	for i := range a {
+		if a[i], err = d.value(t); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// assign stores x, a decoded value, in v. path names the entry in errors.
+func assign(v reflect.Value, x interface{}, path string) error {
+	if v.CanAddr() && v.Addr().Type().Implements(unmarshalerType) {
+		if err := v.Addr().Interface().(Unmarshaler).UnmarshalEpee(x); err != nil {
+			return fmt.Errorf("epee: %s: %w", path, err)
+		}
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.NumMethod() == 0 {
+			v.Set(reflect.ValueOf(x))
+			return nil
+		}
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return assign(v.Elem(), x, path)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i, ok := toInt(x); ok && !v.OverflowInt(i) {
+			v.SetInt(i)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u, ok := toUint(x); ok && !v.OverflowUint(u) {
+			v.SetUint(u)
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, ok := x.(float64); ok {
+			v.SetFloat(f)
+			return nil
+		}
+	case reflect.Bool:
+		if b, ok := x.(bool); ok {
+			v.SetBool(b)
+			return nil
+		}
+	case reflect.String:
+		if s, ok := x.(string); ok {
+			v.SetString(s)
+			return nil
+		}
+	case reflect.Struct:
+		if m, ok := x.(map[string]interface{}); ok {
+			for _, f := range fieldsOf(v.Type()) {
+				if fx, ok := m[f.name]; ok {
+					if err := assign(v.FieldByIndex(f.index), fx, join(path, f.name)); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		}
+	case reflect.Map:
+		if m, ok := x.(map[string]interface{}); ok && isSection(v.Type()) {
+			if v.IsNil() {
+				v.Set(reflect.MakeMapWithSize(v.Type(), len(m)))
+			}
+			for k, ex := range m {
+				ev := reflect.New(v.Type().Elem()).Elem()
+				if err := assign(ev, ex, join(path, k)); err != nil {
+					return err
+				}
+				v.SetMapIndex(reflect.ValueOf(k).Convert(v.Type().Key()), ev)
+			}
+			return nil
+		}
+	case reflect.Slice:
+		switch x := x.(type) {
+		case string:
+			return assignBlob(v, x, path)
+		case []interface{}:
+			s := reflect.MakeSlice(v.Type(), len(x), len(x))
+			for i, ex := range x {
+				if err := assign(s.Index(i), ex, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+			v.Set(s)
+			return nil
+		}
+	case reflect.Array:
+		switch x := x.(type) {
+		case string:
+			if v.Type().Elem().Kind() == reflect.Uint8 && len(x) == v.Len() {
+				reflect.Copy(v, reflect.ValueOf([]byte(x)))
+				return nil
+			}
+		case []interface{}:
+			if len(x) == v.Len() {
+				for i, ex := range x {
+					if err := assign(v.Index(i), ex, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+		}
+	}
+	return &UnmarshalTypeError{Path: path, Value: describe(x), Type: v.Type()}
+}
+
+var unmarshalerType = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+
+// assignBlob stores a string in a []byte, or in a slice of fixed-size
+// values written as a blob.
+func assignBlob(v reflect.Value, s string, path string) error {
+	et := v.Type().Elem()
+	if et.Kind() == reflect.Uint8 {
+		v.SetBytes([]byte(s))
+		return nil
+	}
+	size := binary.Size(reflect.Zero(et).Interface())
+	if size <= 0 || len(s)%size != 0 {
+		return &UnmarshalTypeError{Path: path, Value: fmt.Sprintf("string of %d bytes", len(s)), Type: v.Type()}
+	}
+	sv := reflect.MakeSlice(v.Type(), len(s)/size, len(s)/size)
+	if err := binary.Read(bytes.NewReader([]byte(s)), binary.LittleEndian, sv.Interface()); err != nil {
+		return &UnmarshalTypeError{Path: path, Value: "blob", Type: v.Type()}
+	}
+	v.Set(sv)
+	return nil
+}
+
+func toInt(x interface{}) (int64, bool) {
+	switch x := x.(type) {
+	case int64:
+		return x, true
+	case int32:
+		return int64(x), true
+	case int16:
+		return int64(x), true
+	case int8:
+		return int64(x), true
+	}
+	u, ok := toUint(x)
+	return int64(u), ok && u <= math.MaxInt64
+}
+
+func toUint(x interface{}) (uint64, bool) {
+	switch x := x.(type) {
+	case uint64:
+		return x, true
+	case uint32:
+		return uint64(x), true
+	case uint16:
+		return uint64(x), true
+	case uint8:
+		return uint64(x), true
+	case int64, int32, int16, int8:
+		i, _ := toInt(x)
+		return uint64(i), i >= 0
+	}
+	return 0, false
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func describe(x interface{}) string {
+	switch x.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	return fmt.Sprintf("%T", x)
+}
+
+// UnmarshalTypeError is returned for an entry that cannot be stored in the
+// Go value for it.
+type UnmarshalTypeError struct {
+	Path  string       // dotted path of the entry
+	Value string       // description of the epee value
+	Type  reflect.Type // Go type it could not be stored in
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	return fmt.Sprintf("epee: cannot unmarshal %s into %s (%s)", e.Value, e.Type, e.Path)
+}
@@ -0,0 +1,339 @@
+package epee
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// Marshal returns the epee document for v, which must be a struct, a map
+// with string keys or a pointer to one of them.
+func Marshal(v interface{}) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("epee: cannot marshal nil %s", rv.Type())
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct && !isSection(rv.Type()) {
+		return nil, fmt.Errorf("epee: cannot marshal %s as a section", rv.Type())
+	}
+	e := &encoder{buf: append([]byte(nil), Header...)}
+	if err := e.section(rv); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+// AppendVarint appends v in the epee varint encoding, in which the two
+// low bits of the first byte give the size of the integer: 1, 2, 4 or 8
+// bytes. Values of 1<<62 and above cannot be encoded.
+func AppendVarint(buf []byte, v uint64) ([]byte, error) {
+	switch {
+	case v < 1<<6:
+		return append(buf, byte(v<<2)), nil
+	case v < 1<<14:
+		return binary.LittleEndian.AppendUint16(buf, uint16(v<<2|1)), nil
+	case v < 1<<30:
+		return binary.LittleEndian.AppendUint32(buf, uint32(v<<2|2)), nil
+	case v < 1<<62:
+		return binary.LittleEndian.AppendUint64(buf, v<<2|3), nil
+	}
+	return buf, fmt.Errorf("epee: varint %d too large", v)
+}
+
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) varint(v uint64) error {
+	var err error
+	e.buf, err = AppendVarint(e.buf, v)
+	return err
+}
+
+func isSection(t reflect.Type) bool {
+	return t.Kind() == reflect.Map && t.Key().Kind() == reflect.String
+}
+
+func isBytes(t reflect.Type) bool {
+	return (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() == reflect.Uint8
+}
+
+// entry is a value to write in a section.
+type entry struct {
+	name string
+	v    reflect.Value
+	blob bool
+}
+
+// section writes v, a struct or a map, without type byte.
+func (e *encoder) section(v reflect.Value) error {
+	var entries []entry
+	if v.Kind() == reflect.Struct {
+		for _, f := range fieldsOf(v.Type()) {
+			fv := v.FieldByIndex(f.index)
+			if f.omitEmpty && fv.IsZero() {
+				continue
+			}
+			entries = append(entries, entry{name: f.name, v: fv, blob: f.blob})
+		}
+	} else {
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, k := range keys {
+			entries = append(entries, entry{name: k.String(), v: v.MapIndex(k)})
+		}
+	}
+
+	// Nil pointers and empty slices are not written.
+	n := 0
+	for _, en := range entries {
+		v := indirect(en.v)
+		if !v.IsValid() || (v.Kind() == reflect.Slice && v.Len() == 0 && !isBytes(v.Type())) {
+			continue
+		}
+		entries[n] = entry{name: en.name, v: v, blob: en.blob}
+		n++
+	}
+	entries = entries[:n]
+
+	if err := e.varint(uint64(len(entries))); err != nil {
+		return err
+	}
+	for _, en := range entries {
+		if len(en.name) > 255 {
+			return fmt.Errorf("epee: entry name %.20q... longer than 255 bytes", en.name)
+		}
+		e.buf = append(e.buf, byte(len(en.name)))
+		e.buf = append(e.buf, en.name...)
+		if en.blob {
+			if err := e.blob(en.v); err != nil {
+				return fmt.Errorf("epee: %s: %w", en.name, err)
+			}
+			continue
+		}
+		t, err := typeOf(en.v)
+		if err != nil {
+			return fmt.Errorf("epee: %s: %w", en.name, err)
+		}
+		e.buf = append(e.buf, byte(t))
+		if err := e.value(t, en.v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indirect follows pointers and interfaces, returning the zero Value for
+// nil, and replaces a Marshaler by the value it marshals to.
+func indirect(v reflect.Value) reflect.Value {
+	for {
+		if v.IsValid() && v.Type().Implements(marshalerType) {
+			if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+				return reflect.Value{}
+			}
+			mv, err := v.Interface().(Marshaler).MarshalEpee()
+			if err != nil {
+				return reflect.ValueOf(marshalError{err})
+			}
+			v = reflect.ValueOf(mv)
+			continue
+		}
+		if v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface {
+			return v
+		}
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+}
+
+var marshalerType = reflect.TypeOf((*Marshaler)(nil)).Elem()
+
+// marshalError carries the error of a Marshaler to typeOf, which reports
+// it.
+type marshalError struct{ err error }
+
+// blob writes a slice or array of fixed-size values as a string.
+func (e *encoder) blob(v reflect.Value) error {
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return fmt.Errorf("blob option on %s", v.Type())
+	}
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, v.Interface()); err != nil {
+		return fmt.Errorf("cannot write %s as blob: %w", v.Type(), err)
+	}
+	e.buf = append(e.buf, byte(TypeString))
+	if err := e.varint(uint64(buf.Len())); err != nil {
+		return err
+	}
+	e.buf = append(e.buf, buf.Bytes()...)
+	return nil
+}
+
+// typeOf returns the epee type of v, which is not a pointer or interface.
+func typeOf(v reflect.Value) (Type, error) {
+	if m, ok := v.Interface().(marshalError); ok {
+		return 0, m.err
+	}
+	switch v.Kind() {
+	case reflect.Int64, reflect.Int:
+		return TypeInt64, nil
+	case reflect.Int32:
+		return TypeInt32, nil
+	case reflect.Int16:
+		return TypeInt16, nil
+	case reflect.Int8:
+		return TypeInt8, nil
+	case reflect.Uint64, reflect.Uint, reflect.Uintptr:
+		return TypeUint64, nil
+	case reflect.Uint32:
+		return TypeUint32, nil
+	case reflect.Uint16:
+		return TypeUint16, nil
+	case reflect.Uint8:
+		return TypeUint8, nil
+	case reflect.Float64, reflect.Float32:
+		return TypeDouble, nil
+	case reflect.Bool:
+		return TypeBool, nil
+	case reflect.String:
+		return TypeString, nil
+	case reflect.Struct:
+		return TypeObject, nil
+	case reflect.Map:
+		if isSection(v.Type()) {
+			return TypeObject, nil
+		}
+	case reflect.Slice, reflect.Array:
+		if isBytes(v.Type()) {
+			return TypeString, nil
+		}
+		elem, err := elemType(v)
+		if err != nil {
+			return 0, err
+		}
+		return elem | FlagArray, nil
+	}
+	return 0, fmt.Errorf("unsupported type %s", v.Type())
+}
+
+// elemType returns the type of the elements of the array v. The elements
+// of an array must all have the same type.
+func elemType(v reflect.Value) (Type, error) {
+	et := v.Type().Elem()
+	if et.Kind() != reflect.Interface && et.Kind() != reflect.Ptr && !et.Implements(marshalerType) {
+		t, err := typeOf(reflect.Zero(et))
+		if t&FlagArray != 0 {
+			t = TypeArray
+		}
+		return t, err
+	}
+	var t Type
+	for i := 0; i < v.Len(); i++ {
+		ev := indirect(v.Index(i))
+		if !ev.IsValid() {
+			return 0, fmt.Errorf("nil element in %s", v.Type())
+		}
+		it, err := typeOf(ev)
+		if err != nil {
+			return 0, err
+		}
+		if it&FlagArray != 0 {
+			it = TypeArray
+		}
+		if i > 0 && it != t {
+			return 0, fmt.Errorf("array mixes %s and %s", t, it)
+		}
+		t = it
+	}
+	if v.Len() == 0 {
+		return 0, fmt.Errorf("cannot tell the element type of empty %s", v.Type())
+	}
+	return t, nil
+}
+
+// value writes v of type t without type byte.
+func (e *encoder) value(t Type, v reflect.Value) error {
+	if t&FlagArray != 0 {
+		return e.array(t&^FlagArray, v)
+	}
+	switch t {
+	case TypeInt64:
+		e.buf = binary.LittleEndian.AppendUint64(e.buf, uint64(v.Int()))
+	case TypeInt32:
+		e.buf = binary.LittleEndian.AppendUint32(e.buf, uint32(v.Int()))
+	case TypeInt16:
+		e.buf = binary.LittleEndian.AppendUint16(e.buf, uint16(v.Int()))
+	case TypeInt8:
+		e.buf = append(e.buf, byte(v.Int()))
+	case TypeUint64:
+		e.buf = binary.LittleEndian.AppendUint64(e.buf, v.Uint())
+	case TypeUint32:
+		e.buf = binary.LittleEndian.AppendUint32(e.buf, uint32(v.Uint()))
+	case TypeUint16:
+		e.buf = binary.LittleEndian.AppendUint16(e.buf, uint16(v.Uint()))
+	case TypeUint8:
+		e.buf = append(e.buf, byte(v.Uint()))
+	case TypeDouble:
+		e.buf = binary.LittleEndian.AppendUint64(e.buf, math.Float64bits(v.Float()))
+	case TypeBool:
+		b := byte(0)
+		if v.Bool() {
+			b = 1
+		}
+		e.buf = append(e.buf, b)
+	case TypeString:
+		var s []byte
+		switch {
+		case v.Kind() == reflect.String:
+			s = []byte(v.String())
+		case v.Kind() == reflect.Array:
+			s = make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(s), v)
+		default:
+			s = v.Bytes()
+		}
+		if err := e.varint(uint64(len(s))); err != nil {
+			return err
+		}
+		e.buf = append(e.buf, s...)
+	case TypeObject:
+		return e.section(v)
+	default:
+		return fmt.Errorf("epee: cannot write %s", t)
+	}
+	return nil
+}
+
+// array writes the elements of v, which have type t.
+func (e *encoder) array(t Type, v reflect.Value) error {
+	if err := e.varint(uint64(v.Len())); err != nil {
+		return err
+	}
+	for i := 0; i < v.Len(); i++ {
+		ev := indirect(v.Index(i))
+		if t == TypeArray {
+			// Nested arrays carry their own type.
+			it, err := typeOf(ev)
+			if err != nil {
+				return err
+			}
+			e.buf = append(e.buf, byte(it))
+			if err := e.value(it, ev); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := e.value(t, ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+// Package epee implements the epee portable storage format, the binary
+// serialisation monerod uses on its .bin endpoints.
+//
+// A document is a header followed by the root section, a list of named
+// entries. Entries hold integers of every size, doubles, booleans, strings
+// (which are byte strings and often carry binary hashes), nested sections
+// and arrays of any of those.
+//
+// Marshal and Unmarshal map Go values to sections like encoding/json:
+//
+//	type Request struct {
+//		Heights []uint64 `epee:"heights"`
+//		Prune   bool     `epee:"prune,omitempty"`
+//	}
+//	data, err := epee.Marshal(&Request{Heights: []uint64{1, 2}})
+//
+// The name of a struct field is taken from its epee tag, or its json tag
+// if it has no epee tag, or the field name. The "omitempty" option skips
+// zero values and the "blob" option writes a slice of fixed-size values
+// (e.g. []uint64 or [][32]byte) as one string of little-endian values, as
+// monerod does for its "POD as blob" containers. Empty slices are never
+// written, like in epee.
+//
+// Go types map to epee types as follows: intN and uintN to the integer
+// type of the same size (int and uint to 64 bits), float32 and float64 to
+// double, bool to bool, string, []byte and [N]byte to string, structs and
+// maps with string keys to sections and other slices and arrays to arrays.
+//
+// Types implementing Marshaler or Unmarshaler handle their own mapping.
+//
+// Unmarshal accepts any integer type for any Go integer field that can
+// hold the value, and a string for a slice of fixed-size values, so blob
+// fields need no tag to be decoded. Unmarshal into an interface{} gives
+// map[string]interface{} for sections, []interface{} for arrays, string
+// for strings and the Go type of the same size for numbers.
+package epee
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Header is the signature and version every document starts with.
+var Header = []byte{0x01, 0x11, 0x01, 0x01, 0x01, 0x01, 0x02, 0x01, 0x01}
+
+// Type is the type of an entry.
+type Type byte
+
+// Entry types. An array has the type of its elements with FlagArray set.
+const (
+	TypeInt64  Type = 1
+	TypeInt32  Type = 2
+	TypeInt16  Type = 3
+	TypeInt8   Type = 4
+	TypeUint64 Type = 5
+	TypeUint32 Type = 6
+	TypeUint16 Type = 7
+	TypeUint8  Type = 8
+	TypeDouble Type = 9
+	TypeString Type = 10
+	TypeBool   Type = 11
+	TypeObject Type = 12
+	TypeArray  Type = 13
+
+	FlagArray Type = 0x80
+)
+
+// Marshaler is implemented by types that write themselves as a different
+// Go value, typically a map[string]interface{}, when the same Go type does
+// not always have the same layout.
+type Marshaler interface {
+	MarshalEpee() (interface{}, error)
+}
+
+// Unmarshaler is implemented by types that decode themselves from the
+// generic value Unmarshal produces for their entry.
+type Unmarshaler interface {
+	UnmarshalEpee(v interface{}) error
+}
+
+// MaxDepth is the maximum nesting of sections and arrays Unmarshal accepts.
+const MaxDepth = 100
+
+var (
+	// ErrHeader is returned for data that does not start with Header.
+	ErrHeader = errors.New("epee: bad header")
+	// ErrFormat matches all other errors about malformed data.
+	ErrFormat = errors.New("epee: malformed data")
+)
+
+// formatError returns an error matching ErrFormat.
+func formatError(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: "+format, append([]interface{}{ErrFormat}, args...)...)
+}
+
+var typeNames = map[Type]string{
+	TypeInt64: "int64", TypeInt32: "int32", TypeInt16: "int16", TypeInt8: "int8",
+	TypeUint64: "uint64", TypeUint32: "uint32", TypeUint16: "uint16", TypeUint8: "uint8",
+	TypeDouble: "double", TypeString: "string", TypeBool: "bool", TypeObject: "object",
+	TypeArray: "array",
+}
+
+func (t Type) String() string {
+	if t&FlagArray != 0 {
+		return "array of " + (t &^ FlagArray).String()
+	}
+	if name, ok := typeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("type %d", byte(t))
+}
+
+// field is a struct field mapped to a section entry.
+type field struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	blob      bool
+}
+
+var fieldCache sync.Map // reflect.Type -> []field
+
+// fieldsOf returns the entries of struct type t.
+func fieldsOf(t reflect.Type) []field {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.([]field)
+	}
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag, ok := sf.Tag.Lookup("epee")
+		if !ok {
+			tag = sf.Tag.Get("json")
+		}
+		if tag == "-" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		f := field{name: parts[0], index: sf.Index}
+		if f.name == "" {
+			f.name = sf.Name
+		}
+		for _, opt := range parts[1:] {
+			switch opt {
+			case "omitempty":
+				f.omitEmpty = true
+			case "blob":
+				f.blob = true
+			}
+		}
+		fields = append(fields, f)
+	}
+	fieldCache.Store(t, fields)
+	return fields
+}
@@ -0,0 +1,222 @@
+package epee
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type inner struct {
+	Name string `epee:"name"`
+	N    int32  `epee:"n"`
+}
+
+type all struct {
+	I64    int64             `epee:"i64"`
+	I32    int32             `epee:"i32"`
+	I16    int16             `epee:"i16"`
+	I8     int8              `epee:"i8"`
+	U64    uint64            `epee:"u64"`
+	U32    uint32            `epee:"u32"`
+	U16    uint16            `epee:"u16"`
+	U8     uint8             `epee:"u8"`
+	F      float64           `epee:"f"`
+	B      bool              `epee:"b"`
+	S      string            `epee:"s"`
+	Bytes  []byte            `epee:"bytes"`
+	Hash   [32]byte          `epee:"hash"`
+	Obj    inner             `epee:"obj"`
+	Ptr    *inner            `epee:"ptr"`
+	Objs   []inner           `epee:"objs"`
+	Ints   []uint64          `epee:"ints"`
+	Nested [][]uint32        `epee:"nested"`
+	Blob   []uint64          `epee:"blob,blob"`
+	Hashes [][32]byte        `epee:"hashes,blob"`
+	Map    map[string]uint16 `epee:"map"`
+	JSON   string            `json:"json_name"`
+	Skip   string            `epee:"-"`
+	Empty  uint64            `epee:"empty,omitempty"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	in := all{
+		I64: -1 << 40, I32: -5, I16: -300, I8: -7,
+		U64: 1 << 63, U32: 1 << 31, U16: 65535, U8: 255,
+		F: 1.5, B: true, S: "OK", Bytes: []byte{0, 1, 2},
+		Obj:    inner{Name: "a", N: 1},
+		Ptr:    &inner{Name: "b"},
+		Objs:   []inner{{Name: "c"}, {Name: "d", N: -2}},
+		Ints:   []uint64{1, 1 << 50},
+		Nested: [][]uint32{{1, 2}, {3}},
+		Blob:   []uint64{7, 1 << 40},
+		Hashes: [][32]byte{{1}, {2}},
+		Map:    map[string]uint16{"x": 1},
+		JSON:   "j",
+		Skip:   "skipped",
+	}
+	in.Hash[31] = 9
+	data, err := Marshal(&in)
+	assert.NoError(t, err)
+
+	var out all
+	assert.NoError(t, Unmarshal(data, &out))
+	in.Skip = ""
+	assert.Equal(t, in, out)
+
+	var generic map[string]interface{}
+	assert.NoError(t, Unmarshal(data, &generic))
+	assert.Equal(t, int16(-300), generic["i16"])
+	assert.Equal(t, "j", generic["json_name"])
+	assert.Equal(t, string([]byte{7, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0}), generic["blob"])
+	assert.NotContains(t, generic, "empty")
+	assert.NotContains(t, generic, "Skip")
+}
+
+func TestKnownEncoding(t *testing.T) {
+	// {"status": "OK", "heights": [1, 2]} as monerod writes it.
+	data := append(append([]byte{}, Header...),
+		0x08,
+		0x06, 's', 't', 'a', 't', 'u', 's', 0x0a, 0x08, 'O', 'K',
+		0x07, 'h', 'e', 'i', 'g', 'h', 't', 's', 0x85, 0x08,
+		1, 0, 0, 0, 0, 0, 0, 0,
+		2, 0, 0, 0, 0, 0, 0, 0,
+	)
+	var v struct {
+		Status  string   `json:"status"`
+		Heights []uint64 `json:"heights"`
+	}
+	assert.NoError(t, Unmarshal(data, &v))
+	assert.Equal(t, "OK", v.Status)
+	assert.Equal(t, []uint64{1, 2}, v.Heights)
+
+	out, err := Marshal(v)
+	assert.NoError(t, err)
+	assert.Equal(t, data, out)
+}
+
+func TestVarint(t *testing.T) {
+	for _, v := range []uint64{0, 63, 64, 1<<14 - 1, 1 << 14, 1<<30 - 1, 1 << 30, 1<<62 - 1} {
+		buf, err := AppendVarint(nil, v)
+		assert.NoError(t, err)
+		got, n, err := ReadVarint(buf)
+		assert.NoError(t, err)
+		assert.Equal(t, v, got)
+		assert.Equal(t, len(buf), n)
+	}
+	buf, _ := AppendVarint(nil, 64)
+	assert.Equal(t, []byte{0x01, 0x01}, buf)
+	_, err := AppendVarint(nil, 1<<62)
+	assert.Error(t, err)
+}
+
+func TestConversions(t *testing.T) {
+	data, err := Marshal(map[string]interface{}{"a": uint8(200), "b": int64(-1), "c": "xyz"})
+	assert.NoError(t, err)
+
+	var ok struct {
+		A int64  `epee:"a"`
+		B int8   `epee:"b"`
+		C []byte `epee:"c"`
+	}
+	assert.NoError(t, Unmarshal(data, &ok))
+	assert.Equal(t, int64(200), ok.A)
+	assert.Equal(t, int8(-1), ok.B)
+
+	var overflow struct {
+		A int8 `epee:"a"`
+	}
+	var terr *UnmarshalTypeError
+	assert.True(t, errors.As(Unmarshal(data, &overflow), &terr))
+	assert.Equal(t, "a", terr.Path)
+
+	var negative struct {
+		B uint64 `epee:"b"`
+	}
+	assert.True(t, errors.As(Unmarshal(data, &negative), &terr))
+
+	var blob struct {
+		C []uint64 `epee:"c"`
+	}
+	assert.True(t, errors.As(Unmarshal(data, &blob), &terr))
+}
+
+func TestMalformed(t *testing.T) {
+	var v map[string]interface{}
+	assert.True(t, errors.Is(Unmarshal([]byte{1, 2, 3}, &v), ErrHeader))
+
+	good, err := Marshal(map[string]interface{}{"s": "hello", "a": []uint32{1, 2, 3}})
+	assert.NoError(t, err)
+	for n := len(Header); n < len(good); n++ {
+		err := Unmarshal(good[:n], &v)
+		assert.True(t, errors.Is(err, ErrFormat), "truncated at %d: %v", n, err)
+	}
+	assert.True(t, errors.Is(Unmarshal(append(good, 0), &v), ErrFormat))
+
+	// A count far larger than the data is rejected without allocating.
+	huge := append(append([]byte{}, Header...), 0x03, 0xff, 0xff, 0xff, 0x3f)
+	assert.True(t, errors.Is(Unmarshal(huge, &v), ErrFormat))
+
+	// So is nesting deeper than MaxDepth.
+	deep := append([]byte{}, Header...)
+	for i := 0; i < MaxDepth+1; i++ {
+		deep = append(deep, 0x04, 0x01, 'x', byte(TypeObject))
+	}
+	deep = append(deep, 0x00)
+	assert.True(t, errors.Is(Unmarshal(deep, &v), ErrFormat))
+
+	_, err = Marshal([]int{1})
+	assert.Error(t, err)
+	_, err = Marshal(map[string]interface{}{"mixed": []interface{}{1, "a"}})
+	assert.Error(t, err)
+}
+
+// either is a string or a section, depending on its own flag, like the
+// transactions of a block in get_blocks.bin.
+type either struct {
+	Full bool
+	Blob string
+}
+
+func (e either) MarshalEpee() (interface{}, error) {
+	if e.Full {
+		return map[string]interface{}{"blob": e.Blob}, nil
+	}
+	return e.Blob, nil
+}
+
+func (e *either) UnmarshalEpee(v interface{}) error {
+	switch v := v.(type) {
+	case string:
+		*e = either{Blob: v}
+	case map[string]interface{}:
+		s, _ := v["blob"].(string)
+		*e = either{Full: true, Blob: s}
+	default:
+		return errors.New("neither")
+	}
+	return nil
+}
+
+func TestMarshaler(t *testing.T) {
+	type doc struct {
+		One  either   `epee:"one"`
+		Many []either `epee:"many"`
+	}
+	in := doc{One: either{Full: true, Blob: "a"}, Many: []either{{Blob: "b"}, {Blob: "c"}}}
+	data, err := Marshal(in)
+	assert.NoError(t, err)
+
+	var generic map[string]interface{}
+	assert.NoError(t, Unmarshal(data, &generic))
+	assert.Equal(t, map[string]interface{}{"blob": "a"}, generic["one"])
+	assert.Equal(t, []interface{}{"b", "c"}, generic["many"])
+
+	var out doc
+	assert.NoError(t, Unmarshal(data, &out))
+	assert.Equal(t, in, out)
+
+	data, err = Marshal(map[string]interface{}{"one": true})
+	assert.NoError(t, err)
+	assert.Error(t, Unmarshal(data, &out))
+}
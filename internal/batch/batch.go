@@ -0,0 +1,99 @@
+// Package batch holds the JSON-RPC 2.0 batch envelope shared by the wallet
+// and daemon clients: encoding the calls, telling a batch response from a
+// server that does not support batches, and matching replies to calls.
+package batch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+)
+
+// ErrUnsupported is returned when the server does not accept batches, so
+// the calls must be sent one by one.
+var ErrUnsupported = errors.New("batch requests not supported")
+
+// Call is the method and params of one call in a batch.
+type Call struct {
+	Method string
+	Params interface{}
+}
+
+type request struct {
+	Version string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+	ID      uint64      `json:"id"`
+}
+
+// Reply is the reply to one call of a batch: a result or an error object.
+type Reply struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+	ID *uint64 `json:"id"`
+}
+
+// Encode returns the body of a batch of calls. Each call gets its index as
+// JSON-RPC id.
+func Encode(calls []Call) ([]byte, error) {
+	reqs := make([]request, len(calls))
+	for i, call := range calls {
+		reqs[i] = request{Version: "2.0", Method: call.Method, Params: call.Params, ID: uint64(i)}
+	}
+	body, err := json.Marshal(reqs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	return body, nil
+}
+
+// UnsupportedStatus reports whether an HTTP status answering a batch means
+// the server does not support batches.
+func UnsupportedStatus(code int) bool {
+	return code == http.StatusBadRequest || code == http.StatusNotImplemented
+}
+
+// Decode matches the replies in a batch response body to n calls, in the
+// order of the calls; the server may answer in any order. Calls without a
+// reply get nil. A body that is not an array, the single error object of a
+// server without batch support, returns ErrUnsupported.
+func Decode(body []byte, n int) ([]*Reply, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 || body[0] != '[' {
+		return nil, ErrUnsupported
+	}
+	var replies []Reply
+	if err := json.Unmarshal(body, &replies); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	out := make([]*Reply, n)
+	for i := range replies {
+		if id := replies[i].ID; id != nil && *id < uint64(n) {
+			out[*id] = &replies[i]
+		}
+	}
+	return out, nil
+}
+
+// NoReply is the error of a call the batch response has no reply for.
+func NoReply(method string) error {
+	return fmt.Errorf("%s: no reply in batch response", method)
+}
+
+// State remembers that the server rejected a batch so later batches go
+// straight to sequential calls.
+type State struct {
+	unsupported int32
+}
+
+// Supported reports whether batches may still be sent.
+func (s *State) Supported() bool { return atomic.LoadInt32(&s.unsupported) == 0 }
+
+// Disable records that the server does not support batches.
+func (s *State) Disable() { atomic.StoreInt32(&s.unsupported, 1) }
@@ -0,0 +1,58 @@
+package batch
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncode(t *testing.T) {
+	body, err := Encode([]Call{{Method: "get_height"}, {Method: "get_balance", Params: map[string]int{"account_index": 1}}})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"jsonrpc":"2.0","method":"get_height","id":0},
+		{"jsonrpc":"2.0","method":"get_balance","params":{"account_index":1},"id":1}
+	]`, string(body))
+
+	_, err = Encode([]Call{{Method: "get_height", Params: math.NaN()}})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to encode request")
+}
+
+func TestDecode(t *testing.T) {
+	replies, err := Decode([]byte(` [
+		{"jsonrpc":"2.0","id":2,"error":{"code":-8,"message":"Transaction not found."}},
+		{"jsonrpc":"2.0","id":0,"result":{"height":100}},
+		{"jsonrpc":"2.0","id":7,"result":{}},
+		{"jsonrpc":"2.0","result":{}}
+	]`), 3)
+	assert.NoError(t, err)
+	assert.Len(t, replies, 3)
+	assert.JSONEq(t, `{"height":100}`, string(replies[0].Result))
+	assert.Nil(t, replies[1])
+	assert.Equal(t, -8, replies[2].Error.Code)
+	assert.Equal(t, "Transaction not found.", replies[2].Error.Message)
+	assert.Equal(t, "get_info: no reply in batch response", NoReply("get_info").Error())
+
+	_, err = Decode([]byte(`{"id":0,"jsonrpc":"2.0","error":{"code":-32700,"message":"Parse error"}}`), 1)
+	assert.True(t, errors.Is(err, ErrUnsupported))
+	_, err = Decode(nil, 1)
+	assert.True(t, errors.Is(err, ErrUnsupported))
+	_, err = Decode([]byte(`[{"id":"x"}]`), 1)
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrUnsupported))
+}
+
+func TestUnsupported(t *testing.T) {
+	assert.True(t, UnsupportedStatus(http.StatusBadRequest))
+	assert.True(t, UnsupportedStatus(http.StatusNotImplemented))
+	assert.False(t, UnsupportedStatus(http.StatusServiceUnavailable))
+
+	var s State
+	assert.True(t, s.Supported())
+	s.Disable()
+	assert.False(t, s.Supported())
+}
@@ -0,0 +1,300 @@
+// Package digest implements the client side of HTTP Digest access
+// authentication (RFC 7616) as used by monerod and monero-wallet-rpc when
+// they are started with --rpc-login.
+package digest
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Transport is an http.RoundTripper that answers Digest challenges.
+//
+// The first request is sent without credentials. When the server replies
+// with 401 and a Digest challenge, the request is replayed with an
+// Authorization header. The challenge is then cached and reused (with an
+// incrementing nonce count) for subsequent requests, so that only the first
+// request pays for the extra round trip. If the server rejects a cached
+// nonce (for example because it is stale), the new challenge is adopted and
+// the request is replayed once more. A 401 that is neither the first
+// challenge nor flagged stale is returned to the caller unchanged.
+type Transport struct {
+	Username string
+	Password string
+	// Transport is the underlying round tripper. http.DefaultTransport is
+	// used when nil.
+	Transport http.RoundTripper
+
+	mu   sync.Mutex
+	chal *challenge
+	nc   uint32
+}
+
+// NewTransport returns a Transport wrapping next.
+func NewTransport(username, password string, next http.RoundTripper) *Transport {
+	return &Transport{
+		Username:  username,
+		Password:  password,
+		Transport: next,
+	}
+}
+
+type challenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	qop       string
+	userhash  bool
+	stale     bool
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	// The body may have to be sent up to three times, buffer it once.
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	send := func(authorize bool) (*http.Response, error) {
+		r := req.Clone(req.Context())
+		if body != nil {
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			r.GetBody = func() (io.ReadCloser, error) {
+				return io.NopCloser(bytes.NewReader(body)), nil
+			}
+			r.ContentLength = int64(len(body))
+		}
+		if authorize {
+			auth, err := t.authorization(r)
+			if err != nil {
+				return nil, err
+			}
+			if auth != "" {
+				r.Header.Set("Authorization", auth)
+			}
+		}
+		return next.RoundTrip(r)
+	}
+
+	t.mu.Lock()
+	cached := t.chal != nil
+	t.mu.Unlock()
+
+	resp, err := send(cached)
+	if err != nil {
+		return nil, err
+	}
+	for attempt := 0; resp.StatusCode == http.StatusUnauthorized; attempt++ {
+		chal, err := parseChallenges(resp.Header.Values("WWW-Authenticate"))
+		if err != nil {
+			// Not a digest challenge we understand, let the caller see the 401.
+			return resp, nil
+		}
+		// A second rejection is only worth answering when the server tells
+		// us the nonce went stale, otherwise the credentials are wrong.
+		if attempt > 1 || (attempt == 1 && !chal.stale) {
+			return resp, nil
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		t.mu.Lock()
+		t.chal = chal
+		t.nc = 0
+		t.mu.Unlock()
+
+		resp, err = send(true)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// authorization builds the Authorization header for r from the cached challenge.
+func (t *Transport) authorization(r *http.Request) (string, error) {
+	t.mu.Lock()
+	chal := t.chal
+	t.nc++
+	nc := t.nc
+	t.mu.Unlock()
+	if chal == nil {
+		return "", nil
+	}
+
+	h, sess, err := hashFor(chal.algorithm)
+	if err != nil {
+		return "", err
+	}
+	cnonce, err := newCnonce()
+	if err != nil {
+		return "", err
+	}
+	ncs := fmt.Sprintf("%08x", nc)
+	uri := r.URL.RequestURI()
+
+	ha1 := hexHash(h, t.Username+":"+chal.realm+":"+t.Password)
+	if sess {
+		ha1 = hexHash(h, ha1+":"+chal.nonce+":"+cnonce)
+	}
+	ha2 := hexHash(h, r.Method+":"+uri)
+
+	var response string
+	if chal.qop != "" {
+		response = hexHash(h, strings.Join([]string{ha1, chal.nonce, ncs, cnonce, chal.qop, ha2}, ":"))
+	} else {
+		response = hexHash(h, ha1+":"+chal.nonce+":"+ha2)
+	}
+
+	username := t.Username
+	if chal.userhash {
+		username = hexHash(h, t.Username+":"+chal.realm)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `Digest username=%q, realm=%q, nonce=%q, uri=%q, response=%q`,
+		username, chal.realm, chal.nonce, uri, response)
+	if chal.algorithm != "" {
+		fmt.Fprintf(&b, `, algorithm=%s`, chal.algorithm)
+	}
+	if chal.qop != "" {
+		fmt.Fprintf(&b, `, qop=%s, nc=%s, cnonce=%q`, chal.qop, ncs, cnonce)
+	}
+	if chal.opaque != "" {
+		fmt.Fprintf(&b, `, opaque=%q`, chal.opaque)
+	}
+	if chal.userhash {
+		b.WriteString(`, userhash=true`)
+	}
+	return b.String(), nil
+}
+
+var errNoChallenge = errors.New("digest: no supported challenge")
+
+// parseChallenges picks the first Digest challenge with a supported
+// algorithm. monerod sends one challenge per algorithm (MD5 and MD5-sess).
+func parseChallenges(headers []string) (*challenge, error) {
+	for _, header := range headers {
+		scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+		if !strings.EqualFold(scheme, "Digest") {
+			continue
+		}
+		params := parseParams(rest)
+		chal := &challenge{
+			realm:     params["realm"],
+			nonce:     params["nonce"],
+			opaque:    params["opaque"],
+			algorithm: params["algorithm"],
+			userhash:  strings.EqualFold(params["userhash"], "true"),
+			stale:     strings.EqualFold(params["stale"], "true"),
+		}
+		if chal.nonce == "" {
+			continue
+		}
+		if _, _, err := hashFor(chal.algorithm); err != nil {
+			continue
+		}
+		if qop, ok := params["qop"]; ok {
+			for _, q := range strings.Split(qop, ",") {
+				if strings.TrimSpace(q) == "auth" {
+					chal.qop = "auth"
+				}
+			}
+			if chal.qop == "" {
+				// Only auth-int is offered, which we do not implement.
+				continue
+			}
+		}
+		return chal, nil
+	}
+	return nil, errNoChallenge
+}
+
+// parseParams parses a comma separated list of key=value or key="value" pairs.
+func parseParams(s string) map[string]string {
+	params := make(map[string]string)
+	for len(s) > 0 {
+		s = strings.TrimLeft(s, " \t,")
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = strings.TrimLeft(s[eq+1:], " \t")
+		var val string
+		if strings.HasPrefix(s, `"`) {
+			var b strings.Builder
+			i := 1
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				b.WriteByte(s[i])
+			}
+			val = b.String()
+			if i < len(s) {
+				i++
+			}
+			s = s[i:]
+		} else {
+			end := strings.IndexByte(s, ',')
+			if end < 0 {
+				end = len(s)
+			}
+			val = strings.TrimSpace(s[:end])
+			s = s[end:]
+		}
+		params[key] = val
+	}
+	return params
+}
+
+func hashFor(algorithm string) (func() hash.Hash, bool, error) {
+	switch strings.ToUpper(algorithm) {
+	case "", "MD5":
+		return md5.New, false, nil
+	case "MD5-SESS":
+		return md5.New, true, nil
+	case "SHA-256":
+		return sha256.New, false, nil
+	case "SHA-256-SESS":
+		return sha256.New, true, nil
+	}
+	return nil, false, fmt.Errorf("digest: unsupported algorithm %q", algorithm)
+}
+
+func hexHash(h func() hash.Hash, s string) string {
+	d := h()
+	io.WriteString(d, s)
+	return hex.EncodeToString(d.Sum(nil))
+}
+
+func newCnonce() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
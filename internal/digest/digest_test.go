@@ -0,0 +1,135 @@
+package digest
+
+import (
+	"crypto/md5"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// digestServer is a minimal Digest server modelled on monerod's: it offers
+// MD5 and MD5-sess challenges and can be told to expire its nonce.
+type digestServer struct {
+	user, pass string
+
+	mu         sync.Mutex
+	nonce      int
+	challenges int
+	lastNC     string
+}
+
+func (s *digestServer) currentNonce() string {
+	return fmt.Sprintf("nonce-%d", s.nonce)
+}
+
+func (s *digestServer) expire() {
+	s.mu.Lock()
+	s.nonce++
+	s.mu.Unlock()
+}
+
+func (s *digestServer) challenge(w http.ResponseWriter, stale bool) {
+	s.challenges++
+	w.Header().Add("WWW-Authenticate", fmt.Sprintf(`Digest qop="auth",algorithm=MD5,realm="monero-rpc",nonce=%q,stale=%v`, s.currentNonce(), stale))
+	w.Header().Add("WWW-Authenticate", fmt.Sprintf(`Digest qop="auth",algorithm=MD5-sess,realm="monero-rpc",nonce=%q,stale=%v`, s.currentNonce(), stale))
+	w.WriteHeader(http.StatusUnauthorized)
+}
+
+func (s *digestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Digest ") {
+		s.challenge(w, false)
+		return
+	}
+	p := parseParams(strings.TrimPrefix(auth, "Digest "))
+	if p["nonce"] != s.currentNonce() {
+		s.challenge(w, true)
+		return
+	}
+	h := func(v string) string { return fmt.Sprintf("%x", md5.Sum([]byte(v))) }
+	ha1 := h(p["username"] + ":" + p["realm"] + ":" + s.pass)
+	ha2 := h(r.Method + ":" + p["uri"])
+	want := h(strings.Join([]string{ha1, p["nonce"], p["nc"], p["cnonce"], p["qop"], ha2}, ":"))
+	if p["username"] != s.user || p["response"] != want || p["uri"] != r.URL.RequestURI() {
+		s.challenge(w, false)
+		return
+	}
+	s.lastNC = p["nc"]
+	w.Write(body)
+}
+
+func TestTransport(t *testing.T) {
+	ds := &digestServer{user: "user", pass: "secret"}
+	srv := httptest.NewServer(ds)
+	defer srv.Close()
+
+	cl := &http.Client{Transport: NewTransport("user", "secret", nil)}
+	post := func(payload string) (int, string) {
+		resp, err := cl.Post(srv.URL+"/json_rpc", "application/json", strings.NewReader(payload))
+		if !assert.NoError(t, err) {
+			return 0, ""
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(b)
+	}
+
+	code, body := post(`{"id":1}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, `{"id":1}`, body)
+	assert.Equal(t, 1, ds.challenges)
+
+	// The nonce is reused without another challenge.
+	code, body = post(`{"id":2}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, `{"id":2}`, body)
+	assert.Equal(t, 1, ds.challenges)
+	assert.Equal(t, "00000002", ds.lastNC)
+
+	// A stale nonce triggers a fresh challenge and a replay.
+	ds.expire()
+	code, body = post(`{"id":3}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, `{"id":3}`, body)
+	assert.Equal(t, 2, ds.challenges)
+	assert.Equal(t, "00000001", ds.lastNC)
+}
+
+func TestTransportWrongPassword(t *testing.T) {
+	ds := &digestServer{user: "user", pass: "secret"}
+	srv := httptest.NewServer(ds)
+	defer srv.Close()
+
+	cl := &http.Client{Transport: NewTransport("user", "wrong", nil)}
+	resp, err := cl.Post(srv.URL+"/json_rpc", "application/json", strings.NewReader(`{}`))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, 2, ds.challenges)
+}
+
+func TestParseChallenges(t *testing.T) {
+	chal, err := parseChallenges([]string{
+		`Basic realm="x"`,
+		`Digest qop="auth-int",algorithm=MD5,realm="a",nonce="n1"`,
+		`Digest qop="auth",algorithm=SHA-256,realm="b",nonce="n2",opaque="o"`,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "b", chal.realm)
+	assert.Equal(t, "n2", chal.nonce)
+	assert.Equal(t, "o", chal.opaque)
+	assert.Equal(t, "auth", chal.qop)
+
+	_, err = parseChallenges([]string{`Digest algorithm=SHA-512,nonce="n"`})
+	assert.Error(t, err)
+}
@@ -0,0 +1,90 @@
+// Package instrument wraps RPC calls with the hooks from the telemetry
+// package. It is shared by the wallet and daemon clients.
+package instrument
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/boomhut/go-monero-rpc-client/telemetry"
+)
+
+// Call runs call inside a span started by tracer and reports the outcome
+// to rec; either may be nil. classify fills in the package specific
+// fields of info (HTTP status, error code, ...) once the call returned.
+func Call(ctx context.Context, tracer telemetry.Tracer, rec telemetry.Recorder, info telemetry.CallInfo,
+	classify func(*telemetry.CallInfo), call func(context.Context) error) error {
+	var span telemetry.Span
+	if tracer != nil {
+		ctx, span = tracer.Start(ctx, info.System+"/"+info.Method)
+	}
+	start := time.Now()
+	err := call(ctx)
+	info.Duration = time.Since(start)
+	info.Err = err
+
+	switch {
+	case err == nil:
+	case errors.Is(err, context.Canceled):
+		info.ErrorType = telemetry.ErrorCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		info.ErrorType = telemetry.ErrorTimeout
+	}
+	classify(&info)
+	if err != nil && info.ErrorType == "" {
+		info.ErrorType = telemetry.ErrorOther
+	}
+	// Spans and recorders only see a summary; err may carry the body.
+	if err != nil {
+		summary := info
+		summary.Err = nil
+		info.Err = &callError{info: summary}
+	}
+
+	if span != nil {
+		span.SetAttributes(info.Attributes()...)
+		if info.Err != nil {
+			span.RecordError(info.Err)
+		}
+		span.End()
+	}
+	if rec != nil {
+		rec.RecordCall(ctx, info)
+	}
+	return err
+}
+
+// callError is what spans and recorders get for a failed call: the error
+// type and status codes of info. The error itself may carry the response
+// body.
+type callError struct {
+	info telemetry.CallInfo
+}
+
+func (e *callError) Error() string {
+	msg := e.info.System + " " + e.info.ErrorType + " error"
+	if code := e.info.HTTPStatusCode; code != 0 && code != http.StatusOK {
+		msg += fmt.Sprintf(", HTTP status %d", code)
+	}
+	if e.info.ErrorCode != 0 {
+		msg += fmt.Sprintf(", error code %d", e.info.ErrorCode)
+	}
+	if e.info.Status != "" {
+		msg += ", status " + e.info.Status
+	}
+	return msg
+}
+
+// Endpoint returns address with any user info removed.
+func Endpoint(address string) string {
+	u, err := url.Parse(address)
+	if err != nil {
+		return ""
+	}
+	u.User = nil
+	return u.String()
+}
@@ -0,0 +1,29 @@
+package keccak
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSum256(t *testing.T) {
+	for in, want := range map[string]string{
+		"":    "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470",
+		"abc": "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45",
+	} {
+		sum := Sum256([]byte(in))
+		assert.Equal(t, want, hex.EncodeToString(sum[:]), in)
+	}
+}
+
+func TestSum256Blocks(t *testing.T) {
+	// Inputs around the rate, split differently, must hash alike.
+	for _, n := range []int{rate - 1, rate, rate + 1, 3*rate + 7} {
+		data := bytes.Repeat([]byte{0xa5}, n)
+		whole := Sum256(data)
+		assert.Equal(t, whole, Sum256(data[:n/3], data[n/3:n/2], nil, data[n/2:]), n)
+		assert.NotEqual(t, whole, Sum256(data[:n-1]), n)
+	}
+}
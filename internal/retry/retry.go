@@ -0,0 +1,47 @@
+// Package retry holds the backoff arithmetic shared by the wallet and
+// daemon retry policies.
+package retry
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Backoff returns the delay to wait after the given failed attempt
+// (starting at 1). The delay grows exponentially from initial by multiplier,
+// is capped at max, and then has up to jitter (0..1) of its length randomly
+// removed so that many clients do not retry in lockstep.
+func Backoff(attempt int, initial, max time.Duration, multiplier, jitter float64) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	d := float64(initial) * math.Pow(multiplier, float64(attempt-1))
+	if max > 0 && d > float64(max) {
+		d = float64(max)
+	}
+	if jitter > 0 {
+		if jitter > 1 {
+			jitter = 1
+		}
+		d -= d * jitter * rand.Float64()
+	}
+	return time.Duration(d)
+}
+
+// Sleep waits for d or until ctx is done, whichever comes first. It returns
+// ctx.Err() if the context ended the wait.
+func Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,26 @@
+package retry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 100*time.Millisecond, Backoff(1, 100*time.Millisecond, time.Second, 2, 0))
+	assert.Equal(t, 400*time.Millisecond, Backoff(3, 100*time.Millisecond, time.Second, 2, 0))
+	assert.Equal(t, time.Second, Backoff(10, 100*time.Millisecond, time.Second, 2, 0))
+
+	for i := 0; i < 100; i++ {
+		d := Backoff(2, 100*time.Millisecond, time.Second, 2, 0.5)
+		assert.True(t, d >= 100*time.Millisecond && d <= 200*time.Millisecond, "got %v", d)
+	}
+}
+
+func TestSleepCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, Sleep(ctx, time.Hour))
+}
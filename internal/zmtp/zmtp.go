@@ -0,0 +1,239 @@
+// Package zmtp implements the parts of ZMTP 3.0, the ZeroMQ wire protocol,
+// that PUB and SUB sockets need with the NULL security mechanism: the
+// greeting, the READY handshake and message framing.
+//
+// A Conn announces version 3.0, so a libzmq peer uses the 3.0 framing in
+// which a SUB socket subscribes by sending a message whose first byte is 1
+// followed by the topic prefix (0 unsubscribes).
+package zmtp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+)
+
+// MaxFrameSize is the largest frame ReadMessage accepts.
+const MaxFrameSize = 64 << 20
+
+// Frame flags.
+const (
+	flagMore    = 0x01
+	flagLong    = 0x02
+	flagCommand = 0x04
+)
+
+// Socket types.
+const (
+	PUB = "PUB"
+	SUB = "SUB"
+)
+
+// peers are the socket types each socket type may talk to.
+var peers = map[string][]string{
+	PUB: {SUB, "XSUB"},
+	SUB: {PUB, "XPUB"},
+}
+
+// ErrProtocol matches errors about a peer not following the protocol.
+var ErrProtocol = errors.New("zmtp: protocol error")
+
+func protocolError(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: "+format, append([]interface{}{ErrProtocol}, args...)...)
+}
+
+// Conn is a ZMTP connection after the handshake. Reads and writes may run
+// concurrently with each other, but not with themselves.
+type Conn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	// PeerType is the socket type the peer announced.
+	PeerType string
+}
+
+// Handshake performs the greeting and READY exchange on c for a socket
+// of the given type (PUB or SUB). c is not closed on failure.
+func Handshake(c net.Conn, socketType string) (*Conn, error) {
+	if _, err := c.Write(greeting()); err != nil {
+		return nil, err
+	}
+	zc := &Conn{conn: c, r: bufio.NewReader(c)}
+	var peer [64]byte
+	if _, err := io.ReadFull(zc.r, peer[:]); err != nil {
+		return nil, err
+	}
+	if peer[0] != 0xff || peer[9] != 0x7f {
+		return nil, protocolError("bad greeting signature")
+	}
+	if peer[10] < 3 {
+		return nil, protocolError("peer speaks ZMTP %d.%d", peer[10], peer[11])
+	}
+	if mechanism := string(bytes.TrimRight(peer[12:32], "\x00")); mechanism != "NULL" {
+		return nil, protocolError("unsupported mechanism %q", mechanism)
+	}
+
+	if err := zc.writeFrame(flagCommand, ready(socketType)); err != nil {
+		return nil, err
+	}
+	flags, body, err := zc.readFrame()
+	if err != nil {
+		return nil, err
+	}
+	if flags&flagCommand == 0 {
+		return nil, protocolError("expected READY, got a message")
+	}
+	name, props, err := parseCommand(body)
+	if err != nil {
+		return nil, err
+	}
+	if name != "READY" {
+		return nil, protocolError("expected READY, got %s", name)
+	}
+	zc.PeerType = props["Socket-Type"]
+	for _, t := range peers[socketType] {
+		if t == zc.PeerType {
+			return zc, nil
+		}
+	}
+	return nil, protocolError("%s socket cannot talk to %q", socketType, zc.PeerType)
+}
+
+func greeting() []byte {
+	g := make([]byte, 64)
+	g[0] = 0xff
+	g[9] = 0x7f
+	g[10] = 3 // version 3.0
+	copy(g[12:32], "NULL")
+	return g
+}
+
+// ready returns the body of a READY command.
+func ready(socketType string) []byte {
+	b := []byte{5}
+	b = append(b, "READY"...)
+	b = appendProperty(b, "Socket-Type", socketType)
+	return b
+}
+
+func appendProperty(b []byte, name, value string) []byte {
+	b = append(b, byte(len(name)))
+	b = append(b, name...)
+	b = binary.BigEndian.AppendUint32(b, uint32(len(value)))
+	return append(b, value...)
+}
+
+// parseCommand splits a command into its name and properties.
+func parseCommand(body []byte) (string, map[string]string, error) {
+	if len(body) == 0 || int(body[0]) >= len(body) {
+		return "", nil, protocolError("malformed command")
+	}
+	name := string(body[1 : 1+body[0]])
+	props := make(map[string]string)
+	rest := body[1+body[0]:]
+	if name != "READY" {
+		return name, props, nil
+	}
+	for len(rest) > 0 {
+		n := int(rest[0])
+		if len(rest) < 1+n+4 {
+			return "", nil, protocolError("malformed READY property")
+		}
+		key := string(rest[1 : 1+n])
+		size := binary.BigEndian.Uint32(rest[1+n:])
+		rest = rest[1+n+4:]
+		if uint64(size) > uint64(len(rest)) {
+			return "", nil, protocolError("malformed READY property %s", key)
+		}
+		props[key] = string(rest[:size])
+		rest = rest[size:]
+	}
+	return name, props, nil
+}
+
+func (c *Conn) writeFrame(flags byte, body []byte) error {
+	var header []byte
+	if len(body) > 255 {
+		header = binary.BigEndian.AppendUint64([]byte{flags | flagLong}, uint64(len(body)))
+	} else {
+		header = []byte{flags, byte(len(body))}
+	}
+	_, err := c.conn.Write(append(header, body...))
+	return err
+}
+
+func (c *Conn) readFrame() (byte, []byte, error) {
+	flags, err := c.r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	var size uint64
+	if flags&flagLong != 0 {
+		var b [8]byte
+		if _, err := io.ReadFull(c.r, b[:]); err != nil {
+			return 0, nil, err
+		}
+		size = binary.BigEndian.Uint64(b[:])
+	} else {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		size = uint64(b)
+	}
+	if size > MaxFrameSize {
+		return 0, nil, protocolError("frame of %d bytes exceeds %d", size, MaxFrameSize)
+	}
+	body := make([]byte, size)
+	if _, err := io.ReadFull(c.r, body); err != nil {
+		return 0, nil, err
+	}
+	return flags, body, nil
+}
+
+// ReadMessage returns the next message, one slice per frame. Commands,
+// such as the PINGs of newer peers, are skipped.
+func (c *Conn) ReadMessage() ([][]byte, error) {
+	var parts [][]byte
+	for {
+		flags, body, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+		if flags&flagCommand != 0 {
+			continue
+		}
+		parts = append(parts, body)
+		if flags&flagMore == 0 {
+			return parts, nil
+		}
+	}
+}
+
+// WriteMessage sends a message with one frame per part.
+func (c *Conn) WriteMessage(parts ...[]byte) error {
+	for i, part := range parts {
+		var flags byte
+		if i < len(parts)-1 {
+			flags = flagMore
+		}
+		if err := c.writeFrame(flags, part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Subscribe sends a subscription to topic, a prefix of the messages to
+// receive. The empty topic subscribes to everything.
+func (c *Conn) Subscribe(topic string) error {
+	return c.WriteMessage(append([]byte{1}, topic...))
+}
+
+// Close closes the underlying connection.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,99 @@
+package zmtp
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pair returns two connected TCP connections.
+func pair(t *testing.T) (net.Conn, net.Conn) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		c, _ := ln.Accept()
+		accepted <- c
+	}()
+	client, err := net.Dial("tcp", ln.Addr().String())
+	assert.NoError(t, err)
+	server := <-accepted
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+	return client, server
+}
+
+func handshake(t *testing.T, serverType, clientType string) (*Conn, *Conn, error, error) {
+	client, server := pair(t)
+	type result struct {
+		c   *Conn
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		c, err := Handshake(server, serverType)
+		done <- result{c, err}
+	}()
+	c, err := Handshake(client, clientType)
+	r := <-done
+	return r.c, c, r.err, err
+}
+
+func TestPubSub(t *testing.T) {
+	pub, sub, perr, serr := handshake(t, PUB, SUB)
+	assert.NoError(t, perr)
+	assert.NoError(t, serr)
+	assert.Equal(t, SUB, pub.PeerType)
+	assert.Equal(t, PUB, sub.PeerType)
+
+	assert.NoError(t, sub.Subscribe("json-minimal-chain_main"))
+	msg, err := pub.ReadMessage()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("\x01json-minimal-chain_main")}, msg)
+
+	long := bytes.Repeat([]byte("x"), 1000)
+	assert.NoError(t, pub.WriteMessage([]byte("topic"), long))
+	assert.NoError(t, pub.writeFrame(flagCommand, []byte("\x04PING")))
+	assert.NoError(t, pub.WriteMessage([]byte("next")))
+	msg, err = sub.ReadMessage()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("topic"), long}, msg)
+	msg, err = sub.ReadMessage()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("next")}, msg)
+}
+
+func TestHandshakeErrors(t *testing.T) {
+	_, _, perr, serr := handshake(t, SUB, SUB)
+	assert.True(t, errors.Is(perr, ErrProtocol), "got %v", perr)
+	assert.True(t, errors.Is(serr, ErrProtocol), "got %v", serr)
+
+	client, server := pair(t)
+	go server.Write(make([]byte, 64))
+	_, err := Handshake(client, SUB)
+	assert.True(t, errors.Is(err, ErrProtocol), "got %v", err)
+
+	client, server = pair(t)
+	g := greeting()
+	copy(g[12:32], "PLAIN")
+	go server.Write(g)
+	_, err = Handshake(client, SUB)
+	assert.True(t, errors.Is(err, ErrProtocol), "got %v", err)
+}
+
+func TestFrameTooLarge(t *testing.T) {
+	pub, sub, perr, serr := handshake(t, PUB, SUB)
+	assert.NoError(t, perr)
+	assert.NoError(t, serr)
+	header := []byte{flagLong, 0xff, 0, 0, 0, 0, 0, 0, 0}
+	_, err := pub.conn.Write(header)
+	assert.NoError(t, err)
+	_, err = sub.ReadMessage()
+	assert.True(t, errors.Is(err, ErrProtocol), "got %v", err)
+}
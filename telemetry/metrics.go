@@ -0,0 +1,159 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are the latency histogram bounds in seconds. They extend
+// the usual Prometheus defaults because wallet calls such as refresh can
+// take minutes.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 300}
+
+// Metrics is an in-memory Recorder keeping per-method call and error
+// counters and a latency histogram. WritePrometheus exposes them in the
+// Prometheus text format, Snapshot as plain values.
+type Metrics struct {
+	buckets []float64
+
+	mu      sync.Mutex
+	methods map[methodKey]*MethodStats
+}
+
+type methodKey struct {
+	system, method string
+}
+
+// MethodStats holds the metrics of one RPC method.
+type MethodStats struct {
+	System string
+	Method string
+	Calls  uint64
+	// Errors counts failed calls by CallInfo.ErrorType.
+	Errors map[string]uint64
+	// Buckets are the histogram upper bounds in seconds and Counts the
+	// number of calls that took at most that long (cumulative).
+	Buckets []float64
+	Counts  []uint64
+	// Sum is the total duration of all calls.
+	Sum time.Duration
+}
+
+// NewMetrics returns a Metrics with the given histogram bounds in seconds,
+// or DefaultBuckets if none are given.
+func NewMetrics(buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Metrics{
+		buckets: buckets,
+		methods: make(map[methodKey]*MethodStats),
+	}
+}
+
+// RecordCall implements Recorder.
+func (m *Metrics) RecordCall(ctx context.Context, info CallInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := methodKey{info.System, info.Method}
+	s := m.methods[key]
+	if s == nil {
+		s = &MethodStats{
+			System:  info.System,
+			Method:  info.Method,
+			Errors:  make(map[string]uint64),
+			Buckets: m.buckets,
+			Counts:  make([]uint64, len(m.buckets)),
+		}
+		m.methods[key] = s
+	}
+	s.Calls++
+	if info.Err != nil {
+		s.Errors[info.ErrorType]++
+	}
+	s.Sum += info.Duration
+	seconds := info.Duration.Seconds()
+	for i, bound := range m.buckets {
+		if seconds <= bound {
+			s.Counts[i]++
+		}
+	}
+}
+
+// Snapshot returns a copy of the current metrics ordered by system and
+// method.
+func (m *Metrics) Snapshot() []MethodStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := make([]MethodStats, 0, len(m.methods))
+	for _, s := range m.methods {
+		c := *s
+		c.Errors = make(map[string]uint64, len(s.Errors))
+		for k, v := range s.Errors {
+			c.Errors[k] = v
+		}
+		c.Counts = append([]uint64(nil), s.Counts...)
+		stats = append(stats, c)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].System != stats[j].System {
+			return stats[i].System < stats[j].System
+		}
+		return stats[i].Method < stats[j].Method
+	})
+	return stats
+}
+
+// WritePrometheus writes the metrics in the Prometheus text exposition
+// format as monero_rpc_calls_total, monero_rpc_errors_total and
+// monero_rpc_duration_seconds.
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	stats := m.Snapshot()
+	var err error
+	printf := func(format string, args ...interface{}) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, format, args...)
+		}
+	}
+
+	printf("# HELP monero_rpc_calls_total Number of RPC calls.\n")
+	printf("# TYPE monero_rpc_calls_total counter\n")
+	for _, s := range stats {
+		printf("monero_rpc_calls_total{system=%q,method=%q} %d\n", s.System, s.Method, s.Calls)
+	}
+
+	printf("# HELP monero_rpc_errors_total Number of failed RPC calls.\n")
+	printf("# TYPE monero_rpc_errors_total counter\n")
+	for _, s := range stats {
+		types := make([]string, 0, len(s.Errors))
+		for t := range s.Errors {
+			types = append(types, t)
+		}
+		sort.Strings(types)
+		for _, t := range types {
+			printf("monero_rpc_errors_total{system=%q,method=%q,type=%q} %d\n", s.System, s.Method, t, s.Errors[t])
+		}
+	}
+
+	printf("# HELP monero_rpc_duration_seconds Latency of RPC calls.\n")
+	printf("# TYPE monero_rpc_duration_seconds histogram\n")
+	for _, s := range stats {
+		for i, bound := range s.Buckets {
+			le := strconv.FormatFloat(bound, 'g', -1, 64)
+			printf("monero_rpc_duration_seconds_bucket{system=%q,method=%q,le=%q} %d\n", s.System, s.Method, le, s.Counts[i])
+		}
+		printf("monero_rpc_duration_seconds_bucket{system=%q,method=%q,le=\"+Inf\"} %d\n", s.System, s.Method, s.Calls)
+		printf("monero_rpc_duration_seconds_sum{system=%q,method=%q} %g\n", s.System, s.Method, s.Sum.Seconds())
+		printf("monero_rpc_duration_seconds_count{system=%q,method=%q} %d\n", s.System, s.Method, s.Calls)
+	}
+	return err
+}
@@ -0,0 +1,42 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics(0.1, 1)
+	ctx := context.Background()
+	m.RecordCall(ctx, CallInfo{System: SystemDaemon, Method: "get_info", Duration: 50 * time.Millisecond})
+	m.RecordCall(ctx, CallInfo{System: SystemDaemon, Method: "get_info", Duration: 500 * time.Millisecond,
+		Err: errors.New("busy"), ErrorType: ErrorStatus})
+	m.RecordCall(ctx, CallInfo{System: SystemWallet, Method: "get_balance", Duration: 2 * time.Second})
+
+	stats := m.Snapshot()
+	assert.Len(t, stats, 2)
+	assert.Equal(t, "get_info", stats[1].Method)
+	assert.Equal(t, uint64(2), stats[1].Calls)
+	assert.Equal(t, map[string]uint64{ErrorStatus: 1}, stats[1].Errors)
+	assert.Equal(t, []uint64{1, 2}, stats[1].Counts)
+	assert.Equal(t, 550*time.Millisecond, stats[1].Sum)
+	assert.Equal(t, []uint64{0, 0}, stats[0].Counts)
+
+	var sb strings.Builder
+	assert.NoError(t, m.WritePrometheus(&sb))
+	out := sb.String()
+	for _, line := range []string{
+		`monero_rpc_calls_total{system="monerod",method="get_info"} 2`,
+		`monero_rpc_errors_total{system="monerod",method="get_info",type="status"} 1`,
+		`monero_rpc_duration_seconds_bucket{system="monerod",method="get_info",le="0.1"} 1`,
+		`monero_rpc_duration_seconds_bucket{system="monero-wallet-rpc",method="get_balance",le="+Inf"} 1`,
+		`monero_rpc_duration_seconds_sum{system="monero-wallet-rpc",method="get_balance"} 2`,
+	} {
+		assert.Contains(t, out, line+"\n")
+	}
+}
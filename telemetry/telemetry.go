@@ -0,0 +1,115 @@
+// Package telemetry defines the tracing and metrics hooks of the wallet and
+// daemon clients. It has no dependencies; adapters for OpenTelemetry,
+// Prometheus or any other backend only need to implement Tracer, Span or
+// Recorder.
+//
+// Only the method name, the endpoint and the outcome of a call are
+// reported. Request params and results, which may hold passwords, seeds or
+// keys returned by query_key, are never passed to a Tracer or Recorder.
+package telemetry
+
+import (
+	"context"
+	"time"
+)
+
+// Attribute keys set on spans. They follow the OpenTelemetry semantic
+// conventions for RPC where one exists.
+const (
+	AttrSystem       = "rpc.system"
+	AttrMethod       = "rpc.method"
+	AttrEndpoint     = "server.address"
+	AttrHTTPStatus   = "http.response.status_code"
+	AttrJSONRPCCode  = "rpc.jsonrpc.error_code"
+	AttrMoneroStatus = "monero.status"
+	AttrErrorType    = "error.type"
+)
+
+// Values of CallInfo.System.
+const (
+	SystemWallet = "monero-wallet-rpc"
+	SystemDaemon = "monerod"
+)
+
+// Values of CallInfo.ErrorType.
+const (
+	ErrorTransport = "transport"
+	ErrorHTTP      = "http"
+	ErrorRPC       = "rpc"
+	ErrorStatus    = "status"
+	ErrorCanceled  = "canceled"
+	ErrorTimeout   = "timeout"
+	ErrorOther     = "other"
+)
+
+// Attribute is a key/value pair attached to a span. Value is a string, an
+// int or a bool.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Tracer starts a span for every RPC call.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a single traced RPC call.
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	// RecordError receives a summary of a failed call: its error type and
+	// status codes, never the response body.
+	RecordError(err error)
+	End()
+}
+
+// Recorder receives one CallInfo per completed RPC call.
+type Recorder interface {
+	RecordCall(ctx context.Context, info CallInfo)
+}
+
+// CallInfo describes a completed RPC call.
+type CallInfo struct {
+	// System is SystemWallet or SystemDaemon.
+	System string
+	// Method is the JSON-RPC method name, or the path for monerod
+	// endpoints outside /json_rpc (e.g. "/get_height").
+	Method string
+	// Endpoint is the URL the call was sent to, without credentials.
+	Endpoint string
+	// Duration of the call including retries.
+	Duration time.Duration
+	// Err summarizes the error returned to the caller, nil on success. It
+	// holds the error type and status codes, never the response body.
+	Err error
+	// ErrorType classifies Err, see the Error* constants.
+	ErrorType string
+	// HTTPStatusCode is the HTTP status of the response, if one was received.
+	HTTPStatusCode int
+	// ErrorCode is the JSON-RPC error code, if the server returned one.
+	ErrorCode int
+	// Status is the monerod "status" field, if known.
+	Status string
+}
+
+// Attributes returns the span attributes describing the call.
+func (c CallInfo) Attributes() []Attribute {
+	attrs := []Attribute{
+		{AttrSystem, c.System},
+		{AttrMethod, c.Method},
+		{AttrEndpoint, c.Endpoint},
+	}
+	if c.HTTPStatusCode != 0 {
+		attrs = append(attrs, Attribute{AttrHTTPStatus, c.HTTPStatusCode})
+	}
+	if c.ErrorCode != 0 {
+		attrs = append(attrs, Attribute{AttrJSONRPCCode, c.ErrorCode})
+	}
+	if c.Status != "" {
+		attrs = append(attrs, Attribute{AttrMoneroStatus, c.Status})
+	}
+	if c.ErrorType != "" {
+		attrs = append(attrs, Attribute{AttrErrorType, c.ErrorType})
+	}
+	return attrs
+}
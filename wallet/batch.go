@@ -0,0 +1,140 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/boomhut/go-monero-rpc-client/internal/batch"
+	"github.com/boomhut/go-monero-rpc-client/internal/retry"
+)
+
+// BatchMethod is the method name interceptors see for a batch sent with
+// CallBatch. The params are the []BatchCall being sent.
+const BatchMethod = "batch"
+
+// BatchCall is a single call in a batch. Result must be a pointer to the
+// value the reply is decoded into (for example *ResponseGetTransferByTxID)
+// or nil to discard it. Error is set by CallBatch once the batch completes.
+type BatchCall struct {
+	Method string
+	Params interface{}
+	Result interface{}
+	Error  error
+}
+
+// CallBatch sends calls as a single JSON-RPC 2.0 batch and decodes each reply
+// into its Result. Errors returned by the wallet for individual calls are
+// stored in their Error field; the returned error reports a failure of the
+// batch as a whole. If the server does not accept batches the calls are
+// made one by one instead.
+func (c *client) CallBatch(ctx context.Context, calls []BatchCall) error {
+	if len(calls) == 0 {
+		return nil
+	}
+	if c.batch.Supported() {
+		err := c.invoke(ctx, BatchMethod, calls, nil)
+		if !errors.Is(err, batch.ErrUnsupported) {
+			return err
+		}
+		c.batch.Disable()
+	}
+	for i := range calls {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("request aborted: %w", err)
+		}
+		calls[i].Error = c.invoke(ctx, calls[i].Method, calls[i].Params, calls[i].Result)
+	}
+	return nil
+}
+
+// callBatch is the innermost Invoker for BatchMethod. The batch is retried
+// only if every call in it may be retried.
+func (c *client) callBatch(ctx context.Context, calls []BatchCall) error {
+	reqs := make([]batch.Call, len(calls))
+	attempts := 0
+	for i, call := range calls {
+		reqs[i] = batch.Call{Method: call.Method, Params: call.Params}
+		if n := c.retry.attempts(call.Method); attempts == 0 || n < attempts {
+			attempts = n
+		}
+	}
+	payload, err := batch.Encode(reqs)
+	if err != nil {
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		err = c.doBatchOnce(ctx, payload, calls)
+		if err == nil || attempt >= attempts || !c.retry.retryable(err) {
+			return err
+		}
+		if err := retry.Sleep(ctx, c.retry.backoff(attempt)); err != nil {
+			return fmt.Errorf("request aborted: %w", err)
+		}
+	}
+}
+
+func (c *client) doBatchOnce(ctx context.Context, payload []byte, calls []BatchCall) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.addr, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := c.httpcl.Do(req)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fmt.Errorf("request aborted: %w", ctxErr)
+		}
+		return &TransportError{Err: err}
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fmt.Errorf("request aborted: %w", ctxErr)
+		}
+		return &TransportError{Err: err}
+	}
+	if resp.StatusCode != http.StatusOK {
+		if batch.UnsupportedStatus(resp.StatusCode) {
+			return batch.ErrUnsupported
+		}
+		if len(body) > 64<<10 {
+			body = body[:64<<10]
+		}
+		return &HTTPStatusError{StatusCode: resp.StatusCode, Body: body}
+	}
+
+	replies, err := batch.Decode(body, len(calls))
+	if err != nil {
+		return err
+	}
+	for i, reply := range replies {
+		if reply == nil {
+			calls[i].Error = batch.NoReply(calls[i].Method)
+			continue
+		}
+		calls[i].Error = decodeBatchReply(calls[i], reply)
+	}
+	return nil
+}
+
+func decodeBatchReply(call BatchCall, reply *batch.Reply) error {
+	if reply.Error != nil {
+		return &WalletError{Code: ErrorCode(reply.Error.Code), Message: reply.Error.Message}
+	}
+	if call.Result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(reply.Result, call.Result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
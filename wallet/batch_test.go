@@ -0,0 +1,114 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testRPCRequest struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	ID     uint64          `json:"id"`
+}
+
+func testReply(req testRPCRequest) map[string]interface{} {
+	reply := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+	switch req.Method {
+	case "get_height":
+		reply["result"] = map[string]interface{}{"height": 100}
+	case "get_balance":
+		reply["result"] = map[string]interface{}{"balance": 10}
+	default:
+		reply["error"] = map[string]interface{}{"code": -8, "message": "Transaction not found."}
+	}
+	return reply
+}
+
+func newBatchServer(t *testing.T, batches bool, posts *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(posts, 1)
+		body, _ := io.ReadAll(r.Body)
+		var reqs []testRPCRequest
+		if err := json.Unmarshal(body, &reqs); err == nil {
+			if !batches {
+				w.Write([]byte(`{"id":0,"jsonrpc":"2.0","error":{"code":-32700,"message":"Parse error"}}`))
+				return
+			}
+			// reply in reverse order to check demultiplexing by id
+			var replies []interface{}
+			for i := len(reqs) - 1; i >= 0; i-- {
+				replies = append(replies, testReply(reqs[i]))
+			}
+			json.NewEncoder(w).Encode(replies)
+			return
+		}
+		var req testRPCRequest
+		assert.NoError(t, json.Unmarshal(body, &req))
+		json.NewEncoder(w).Encode(testReply(req))
+	}))
+}
+
+func TestCallBatch(t *testing.T) {
+	for _, batches := range []bool{true, false} {
+		var posts int32
+		srv := newBatchServer(t, batches, &posts)
+
+		var methods []string
+		cl := New(Config{
+			Address: srv.URL + "/json_rpc",
+			Interceptors: []Interceptor{func(ctx context.Context, method string, params, result interface{}, next Invoker) error {
+				methods = append(methods, method)
+				return next(ctx, method, params, result)
+			}},
+		})
+		for round := 0; round < 2; round++ {
+			var height *ResponseGetHeight
+			var balance *ResponseGetBalance
+			var transfer *ResponseGetTransferByTxID
+			calls := []BatchCall{
+				{Method: "get_height", Result: &height},
+				{Method: "get_balance", Params: &RequestGetBalance{}, Result: &balance},
+				{Method: "get_transfer_by_txid", Params: &RequestGetTransferByTxID{TxID: "ab"}, Result: &transfer},
+			}
+			assert.NoError(t, cl.CallBatch(context.Background(), calls))
+			assert.NoError(t, calls[0].Error)
+			assert.Equal(t, uint64(100), height.Height)
+			assert.NoError(t, calls[1].Error)
+			assert.Equal(t, uint64(10), balance.Balance)
+			assert.True(t, errors.Is(calls[2].Error, ErrWrongTxID), "got %v", calls[2].Error)
+			assert.Nil(t, transfer)
+		}
+
+		if batches {
+			assert.Equal(t, int32(2), posts)
+			assert.Equal(t, []string{BatchMethod, BatchMethod}, methods)
+		} else {
+			// the first batch is rejected, after which calls are sent one by one
+			assert.Equal(t, int32(1+3+3), posts)
+			assert.Equal(t, BatchMethod, methods[0])
+			assert.Len(t, methods, 1+3+3)
+		}
+		srv.Close()
+	}
+}
+
+func TestCallBatchDecodeError(t *testing.T) {
+	var posts int32
+	srv := newBatchServer(t, true, &posts)
+	defer srv.Close()
+
+	var height string
+	calls := []BatchCall{{Method: "get_height", Result: &height}}
+	assert.NoError(t, New(Config{Address: srv.URL + "/json_rpc"}).CallBatch(context.Background(), calls))
+	var jerr *json.UnmarshalTypeError
+	assert.True(t, errors.As(calls[0].Error, &jerr), "got %v", calls[0].Error)
+	assert.Contains(t, calls[0].Error.Error(), "failed to decode response")
+}
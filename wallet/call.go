@@ -0,0 +1,34 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// CallRaw calls method with params and returns the undecoded "result" member
+// of the reply. It goes through the same interceptors, retry policy,
+// authentication and error handling as the typed methods, so it can be used
+// for RPC methods (or fields) this package does not support yet.
+func (c *client) CallRaw(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	var result json.RawMessage
+	if err := c.do(ctx, method, params, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Call calls method on c with params and decodes the result into a T.
+//
+//	res, err := wallet.Call[wallet.ResponseGetHeight](ctx, client, "get_height", nil)
+func Call[T any](ctx context.Context, c Client, method string, params interface{}) (T, error) {
+	var res T
+	raw, err := c.CallRaw(ctx, method, params)
+	if err != nil {
+		return res, err
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return res, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return res, nil
+}
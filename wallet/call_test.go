@@ -0,0 +1,60 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCall(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req struct {
+			Method string                 `json:"method"`
+			Params map[string]interface{} `json:"params"`
+		}
+		assert.NoError(t, json.Unmarshal(body, &req))
+		switch req.Method {
+		case "get_height":
+			w.Write([]byte(`{"id":0,"jsonrpc":"2.0","result":{"height":42,"new_field":"x"}}`))
+		case "estimate_tx_size_and_weight":
+			assert.Equal(t, float64(2), req.Params["n_inputs"])
+			w.Write([]byte(`{"id":0,"jsonrpc":"2.0","result":{"size":1000,"weight":1500}}`))
+		default:
+			w.Write([]byte(`{"id":0,"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found"}}`))
+		}
+	}))
+	defer srv.Close()
+	cl := New(Config{Address: srv.URL + "/json_rpc"})
+	ctx := context.Background()
+
+	raw, err := cl.CallRaw(ctx, "get_height", nil)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"height":42,"new_field":"x"}`, string(raw))
+
+	height, err := Call[ResponseGetHeight](ctx, cl, "get_height", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(42), height.Height)
+
+	type sizeAndWeight struct {
+		Size   uint64 `json:"size"`
+		Weight uint64 `json:"weight"`
+	}
+	sw, err := Call[sizeAndWeight](ctx, cl, "estimate_tx_size_and_weight", map[string]interface{}{"n_inputs": 2})
+	assert.NoError(t, err)
+	assert.Equal(t, sizeAndWeight{1000, 1500}, sw)
+
+	_, err = Call[string](ctx, cl, "get_height", nil)
+	var jerr *json.UnmarshalTypeError
+	assert.True(t, errors.As(err, &jerr), "got %v", err)
+	assert.Contains(t, err.Error(), "failed to decode response")
+
+	_, err = Call[json.RawMessage](ctx, cl, "no_such_method", nil)
+	assert.True(t, errors.Is(err, ErrRPC), "got %v", err)
+}
@@ -2,9 +2,16 @@ package wallet
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 
+	"github.com/boomhut/go-monero-rpc-client/internal/batch"
+	"github.com/boomhut/go-monero-rpc-client/internal/digest"
+	"github.com/boomhut/go-monero-rpc-client/internal/retry"
 	"github.com/gorilla/rpc/v2/json2"
 )
 
@@ -12,185 +19,278 @@ import (
 type Client interface {
 	// Return the wallet's balance.
 	GetBalance(*RequestGetBalance) (*ResponseGetBalance, error)
+	GetBalanceContext(context.Context, *RequestGetBalance) (*ResponseGetBalance, error)
 	// Return the wallet's addresses for an account. Optionally filter for specific set of subaddresses.
 	GetAddress(*RequestGetAddress) (*ResponseGetAddress, error)
+	GetAddressContext(context.Context, *RequestGetAddress) (*ResponseGetAddress, error)
 	// Get account and address indexes from a specific (sub)address
 	GetAddressIndex(*RequestGetAddressIndex) (*ResponseGetAddressIndex, error)
+	GetAddressIndexContext(context.Context, *RequestGetAddressIndex) (*ResponseGetAddressIndex, error)
 	// Create a new address for an account. Optionally, label the new address.
 	CreateAddress(*RequestCreateAddress) (*ResponseCreateAddress, error)
+	CreateAddressContext(context.Context, *RequestCreateAddress) (*ResponseCreateAddress, error)
 	// Label an address.
 	LabelAddress(*RequestLabelAddress) error
+	LabelAddressContext(context.Context, *RequestLabelAddress) error
 	// Validate an address.
 	ValidateAddress(*RequestValidateAddress) (*ResponseValidateAddress, error)
+	ValidateAddressContext(context.Context, *RequestValidateAddress) (*ResponseValidateAddress, error)
 	// Get all accounts for a wallet. Optionally filter accounts by tag.
 	GetAccounts(*RequestGetAccounts) (*ResponseGetAccounts, error)
+	GetAccountsContext(context.Context, *RequestGetAccounts) (*ResponseGetAccounts, error)
 	// Create a new account with an optional label.
 	CreateAccount(*RequestCreateAccount) (*ResponseCreateAccount, error)
+	CreateAccountContext(context.Context, *RequestCreateAccount) (*ResponseCreateAccount, error)
 	// Label an account.
 	LabelAccount(*RequestLabelAccount) error
+	LabelAccountContext(context.Context, *RequestLabelAccount) error
 	// Get a list of user-defined account tags.
 	GetAccountTags() (*ResponseGetAccountTags, error)
+	GetAccountTagsContext(ctx context.Context) (*ResponseGetAccountTags, error)
 	// Apply a filtering tag to a list of accounts.
 	TagAccounts(*RequestTagAccounts) error
+	TagAccountsContext(context.Context, *RequestTagAccounts) error
 	// Remove filtering tag from a list of accounts.
 	UntagAccounts(*RequestUntagAccounts) error
+	UntagAccountsContext(context.Context, *RequestUntagAccounts) error
 	// Set description for an account tag.
 	SetAccountTagDescription(*RequestSetAccountTagDescription) error
+	SetAccountTagDescriptionContext(context.Context, *RequestSetAccountTagDescription) error
 	// Returns the wallet's current block height.
 	GetHeight() (*ResponseGetHeight, error)
+	GetHeightContext(ctx context.Context) (*ResponseGetHeight, error)
 	// Send monero to a number of recipients.
 	Transfer(*RequestTransfer) (*ResponseTransfer, error)
+	TransferContext(context.Context, *RequestTransfer) (*ResponseTransfer, error)
 	// Same as transfer, but can split into more than one tx if necessary.
 	TransferSplit(*RequestTransferSplit) (*ResponseTransferSplit, error)
+	TransferSplitContext(context.Context, *RequestTransferSplit) (*ResponseTransferSplit, error)
 	// Sign a transaction created on a read-only wallet (in cold-signing process)
 	SignTransfer(*RequestSignTransfer) (*ResponseSignTransfer, error)
+	SignTransferContext(context.Context, *RequestSignTransfer) (*ResponseSignTransfer, error)
 	// Submit a previously signed transaction on a read-only wallet (in cold-signing process).
 	SubmitTransfer(*RequestSubmitTransfer) (*ResponseSubmitTransfer, error)
+	SubmitTransferContext(context.Context, *RequestSubmitTransfer) (*ResponseSubmitTransfer, error)
 	// Send all dust outputs back to the wallet's, to make them easier to spend (and mix).
 	SweepDust(*RequestSweepDust) (*ResponseSweepDust, error)
+	SweepDustContext(context.Context, *RequestSweepDust) (*ResponseSweepDust, error)
 	// Send all unlocked balance to an address.
 	SweepAll(*RequestSweepAll) (*ResponseSweepAll, error)
+	SweepAllContext(context.Context, *RequestSweepAll) (*ResponseSweepAll, error)
 	// Send all of a specific unlocked output to an address.
 	SweepSingle(*RequestSweepSingle) (*ResponseSweepSingle, error)
+	SweepSingleContext(context.Context, *RequestSweepSingle) (*ResponseSweepSingle, error)
 	// Relay a transaction previously created with "do_not_relay":true.
 	RelayTx(*RequestRelayTx) (*ResponseRelayTx, error)
+	RelayTxContext(context.Context, *RequestRelayTx) (*ResponseRelayTx, error)
 	// Save the wallet file.
 	Store() error
+	StoreContext(ctx context.Context) error
 	// Get a list of incoming payments using a given payment id.
 	GetPayments(*RequestGetPayments) (*ResponseGetPayments, error)
+	GetPaymentsContext(context.Context, *RequestGetPayments) (*ResponseGetPayments, error)
 	// Get a list of incoming payments using a given payment id, or a list of payments ids, from a given height.
 	// This method is the preferred method over get_payments because it has the same functionality but is more extendable.
 	// Either is fine for looking up transactions by a single payment ID.
 	GetBulkPayments(*RequestGetBulkPayments) (*ResponseGetBulkPayments, error)
+	GetBulkPaymentsContext(context.Context, *RequestGetBulkPayments) (*ResponseGetBulkPayments, error)
 	// Return a list of incoming transfers to the wallet.
 	IncomingTransfers(*RequestIncomingTransfers) (*ResponseIncomingTransfers, error)
+	IncomingTransfersContext(context.Context, *RequestIncomingTransfers) (*ResponseIncomingTransfers, error)
 	// Return the spend or view private key.
 	QueryKey(*RequestQueryKey) (*ResponseQueryKey, error)
+	QueryKeyContext(context.Context, *RequestQueryKey) (*ResponseQueryKey, error)
 	// Make an integrated address from the wallet address and a payment id.
 	MakeIntegratedAddress(*RequestMakeIntegratedAddress) (*ResponseMakeIntegratedAddress, error)
+	MakeIntegratedAddressContext(context.Context, *RequestMakeIntegratedAddress) (*ResponseMakeIntegratedAddress, error)
 	// Retrieve the standard address and payment id corresponding to an integrated address.
 	SplitIntegratedAddress(*RequestSplitIntegratedAddress) (*ResponseSplitIntegratedAddress, error)
+	SplitIntegratedAddressContext(context.Context, *RequestSplitIntegratedAddress) (*ResponseSplitIntegratedAddress, error)
 	// Stops the wallet, storing the current state.
 	StopWallet() error
+	StopWalletContext(ctx context.Context) error
 	// Rescan the blockchain from scratch, losing any information which can not be recovered from the blockchain itself.
 	// This includes destination addresses, tx secret keys, tx notes, etc.
 	RescanBlockchain() error
+	RescanBlockchainContext(ctx context.Context) error
 	// Set arbitrary string notes for transactions.
 	SetTxNotes(*RequestSetTxNotes) error
+	SetTxNotesContext(context.Context, *RequestSetTxNotes) error
 	// Get string notes for transactions.
 	GetTxNotes(*RequestGetTxNotes) (*ResponseGetTxNotes, error)
+	GetTxNotesContext(context.Context, *RequestGetTxNotes) (*ResponseGetTxNotes, error)
 	// Set arbitrary attribute.
 	SetAttribute(*RequestSetAttribute) error
+	SetAttributeContext(context.Context, *RequestSetAttribute) error
 	// Get attribute value by name.
 	GetAttribute(*RequestGetAttribute) (*ResponseGetAttribute, error)
+	GetAttributeContext(context.Context, *RequestGetAttribute) (*ResponseGetAttribute, error)
 	// Get transaction secret key from transaction id.
 	GetTxKey(*RequestGetTxKey) (*ResponseGetTxKey, error)
+	GetTxKeyContext(context.Context, *RequestGetTxKey) (*ResponseGetTxKey, error)
 	// Check a transaction in the blockchain with its secret key.
 	CheckTxKey(*RequestCheckTxKey) (*ResponseCheckTxKey, error)
+	CheckTxKeyContext(context.Context, *RequestCheckTxKey) (*ResponseCheckTxKey, error)
 	// Get transaction signature to prove it.
 	GetTxProof(*RequestGetTxProof) (*ResponseGetTxProof, error)
+	GetTxProofContext(context.Context, *RequestGetTxProof) (*ResponseGetTxProof, error)
 	// Prove a transaction by checking its signature.
 	CheckTxProof(*RequestCheckTxProof) (*ResponseCheckTxProof, error)
+	CheckTxProofContext(context.Context, *RequestCheckTxProof) (*ResponseCheckTxProof, error)
 	// Generate a signature to prove a spend. Unlike proving a transaction, it does not requires the destination public address.
 	GetSpendProof(*RequestGetSpendProof) (*ResponseGetSpendProof, error)
+	GetSpendProofContext(context.Context, *RequestGetSpendProof) (*ResponseGetSpendProof, error)
 	// Prove a spend using a signature. Unlike proving a transaction, it does not requires the destination public address.
 	CheckSpendProof(*RequestCheckSpendProof) (*ResponseCheckSpendProof, error)
+	CheckSpendProofContext(context.Context, *RequestCheckSpendProof) (*ResponseCheckSpendProof, error)
 	// Generate a signature to prove of an available amount in a wallet.
 	GetReserveProof(*RequestGetReserveProof) (*ResponseGetReserveProof, error)
+	GetReserveProofContext(context.Context, *RequestGetReserveProof) (*ResponseGetReserveProof, error)
 	// Proves a wallet has a disposable reserve using a signature.
 	CheckReserveProof(*RequestCheckReserveProof) (*ResponseCheckReserveProof, error)
+	CheckReserveProofContext(context.Context, *RequestCheckReserveProof) (*ResponseCheckReserveProof, error)
 	// Returns a list of transfers.
 	GetTransfers(*RequestGetTransfers) (*ResponseGetTransfers, error)
+	GetTransfersContext(context.Context, *RequestGetTransfers) (*ResponseGetTransfers, error)
 	// Show information about a transfer to/from this address.
 	GetTransferByTxID(*RequestGetTransferByTxID) (*ResponseGetTransferByTxID, error)
+	GetTransferByTxIDContext(context.Context, *RequestGetTransferByTxID) (*ResponseGetTransferByTxID, error)
 	// Sign a string.
 	Sign(*RequestSign) (*ResponseSign, error)
+	SignContext(context.Context, *RequestSign) (*ResponseSign, error)
 	// Verify a signature on a string.
 	Verify(*RequestVerify) (*ResponseVerify, error)
+	VerifyContext(context.Context, *RequestVerify) (*ResponseVerify, error)
 	// Export all outputs in hex format.
 	ExportOutputs() (*ResponseExportOutputs, error)
+	ExportOutputsContext(ctx context.Context) (*ResponseExportOutputs, error)
 	// Import outputs in hex format.
 	ImportOutputs(*RequestImportOutputs) (*ResponseImportOutputs, error)
+	ImportOutputsContext(context.Context, *RequestImportOutputs) (*ResponseImportOutputs, error)
 	// Export a signed set of key images.
 	ExportKeyImages() (*ResponseExportKeyImages, error)
+	ExportKeyImagesContext(ctx context.Context) (*ResponseExportKeyImages, error)
 	// Import signed key images list and verify their spent status.
 	ImportKeyImages(*RequestImportKeyImages) (*ResponseImportKeyImages, error)
+	ImportKeyImagesContext(context.Context, *RequestImportKeyImages) (*ResponseImportKeyImages, error)
 	// Create a payment URI using the official URI spec.
 	MakeURI(*RequestMakeURI) (*ResponseMakeURI, error)
+	MakeURIContext(context.Context, *RequestMakeURI) (*ResponseMakeURI, error)
 	// Parse a payment URI to get payment information.
 	ParseURI(*RequestParseURI) (*ResponseParseURI, error)
+	ParseURIContext(context.Context, *RequestParseURI) (*ResponseParseURI, error)
 	// Retrieves entries from the address book.
 	GetAddressBook(*RequestGetAddressBook) (*ResponseGetAddressBook, error)
+	GetAddressBookContext(context.Context, *RequestGetAddressBook) (*ResponseGetAddressBook, error)
 	// Add an entry to the address book.
 	AddAddressBook(*RequestAddAddressBook) (*ResponseAddAddressBook, error)
+	AddAddressBookContext(context.Context, *RequestAddAddressBook) (*ResponseAddAddressBook, error)
 	// Delete an entry from the address book.
 	DeleteAddressBook(*RequestDeleteAddressBook) error
+	DeleteAddressBookContext(context.Context, *RequestDeleteAddressBook) error
 	// Refresh a wallet after openning.
 	Refresh(*RequestRefresh) (*ResponseRefresh, error)
+	RefreshContext(context.Context, *RequestRefresh) (*ResponseRefresh, error)
 	// Rescan the blockchain for spent outputs.
 	RescanSpent() error
+	RescanSpentContext(ctx context.Context) error
 	// Start mining in the Monero daemon.
 	StartMining(*RequestStartMining) error
+	StartMiningContext(context.Context, *RequestStartMining) error
 	// Stop mining in the Monero daemon.
 	StopMining() error
+	StopMiningContext(ctx context.Context) error
 	// Get a list of available languages for your wallet's seed.
 	GetLanguages() (*ResponseGetLanguages, error)
+	GetLanguagesContext(ctx context.Context) (*ResponseGetLanguages, error)
 	// Create a new wallet. You need to have set the argument "–wallet-dir" when launching monero-wallet-rpc to make this work.
 	CreateWallet(*RequestCreateWallet) error
+	CreateWalletContext(context.Context, *RequestCreateWallet) error
 	// Restores a wallet from a given wallet address, view key, and optional spend key. You need to have set the argument "–wallet-dir" when launching monero-wallet-rpc to make this work.
 	GenerateFromKeys(*RequestGenerateFromKeys) (*ResponseGenerateFromKeys, error)
+	GenerateFromKeysContext(context.Context, *RequestGenerateFromKeys) (*ResponseGenerateFromKeys, error)
 	// Open a wallet. You need to have set the argument "–wallet-dir" when launching monero-wallet-rpc to make this work.
 	OpenWallet(*RequestOpenWallet) error
+	OpenWalletContext(context.Context, *RequestOpenWallet) error
 	// Close the currently opened wallet, after trying to save it.
 	CloseWallet() error
+	CloseWalletContext(ctx context.Context) error
 	// Change a wallet password.
 	ChangeWalletPassword(*RequestChangeWalletPassword) error
+	ChangeWalletPasswordContext(context.Context, *RequestChangeWalletPassword) error
 	// Check if a wallet is a multisig one.
 	IsMultisig() (*ResponseIsMultisig, error)
+	IsMultisigContext(ctx context.Context) (*ResponseIsMultisig, error)
 	// Prepare a wallet for multisig by generating a multisig string to share with peers.
 	PrepareMultisig() (*ResponsePrepareMultisig, error)
+	PrepareMultisigContext(ctx context.Context) (*ResponsePrepareMultisig, error)
 	// Make a wallet multisig by importing peers multisig string.
 	MakeMultisig(*RequestMakeMultisig) (*ResponseMakeMultisig, error)
+	MakeMultisigContext(context.Context, *RequestMakeMultisig) (*ResponseMakeMultisig, error)
 	// Export multisig info for other participants.
 	ExportMultisigInfo() (*ResponseExportMultisigInfo, error)
+	ExportMultisigInfoContext(ctx context.Context) (*ResponseExportMultisigInfo, error)
 	// Import multisig info from other participants.
 	ImportMultisigInfo(*RequestImportMultisigInfo) (*ResponseImportMultisigInfo, error)
+	ImportMultisigInfoContext(context.Context, *RequestImportMultisigInfo) (*ResponseImportMultisigInfo, error)
 	// Turn this wallet into a multisig wallet, extra step for N-1/N wallets.
 	FinalizeMultisig(*RequestFinalizeMultisig) (*ResponseFinalizeMultisig, error)
+	FinalizeMultisigContext(context.Context, *RequestFinalizeMultisig) (*ResponseFinalizeMultisig, error)
 	// Sign a transaction in multisig.
 	SignMultisig(*RequestSignMultisig) (*ResponseSignMultisig, error)
+	SignMultisigContext(context.Context, *RequestSignMultisig) (*ResponseSignMultisig, error)
 	// Submit a signed multisig transaction.
 	SubmitMultisig(*RequestSubmitMultisig) (*ResponseSubmitMultisig, error)
+	SubmitMultisigContext(context.Context, *RequestSubmitMultisig) (*ResponseSubmitMultisig, error)
 	// Get RPC version Major & Minor integer-format, where Major is the first 16 bits and Minor the last 16 bits.
 	GetVersion() (*ResponseGetVersion, error)
+	GetVersionContext(ctx context.Context) (*ResponseGetVersion, error)
 	// Set daemon that the wallet connects to.
 	SetDaemon(*RequestSetDaemon) error
+	SetDaemonContext(context.Context, *RequestSetDaemon) error
 	// Set auto-refresh mode.
 	AutoRefresh(*RequestAutoRefresh) error
+	AutoRefreshContext(context.Context, *RequestAutoRefresh) error
 	// Describe a transaction from unsigned_txset or multisig_txset.
 	DescribeTransfer(*RequestDescribeTransfer) (*ResponseDescribeTransfer, error)
+	DescribeTransferContext(context.Context, *RequestDescribeTransfer) (*ResponseDescribeTransfer, error)
 	// Edit an existing address book entry.
 	EditAddressBook(*RequestEditAddressBook) error
+	EditAddressBookContext(context.Context, *RequestEditAddressBook) error
 	// Estimate size and weight of a transaction.
 	EstimateTxSizeAndWeight(*RequestEstimateTxSizeAndWeight) (*ResponseEstimateTxSizeAndWeight, error)
+	EstimateTxSizeAndWeightContext(context.Context, *RequestEstimateTxSizeAndWeight) (*ResponseEstimateTxSizeAndWeight, error)
 	// Exchanges multisig keys with other participants (after make_multisig).
 	ExchangeMultisigKeys(*RequestExchangeMultisigKeys) (*ResponseExchangeMultisigKeys, error)
+	ExchangeMultisigKeysContext(context.Context, *RequestExchangeMultisigKeys) (*ResponseExchangeMultisigKeys, error)
 	// Freeze a single output by key image so it will not be used.
 	Freeze(*RequestFreeze) error
+	FreezeContext(context.Context, *RequestFreeze) error
 	// Checks whether a given output is currently frozen by key image.
 	Frozen(*RequestFrozen) (*ResponseFrozen, error)
+	FrozenContext(context.Context, *RequestFrozen) (*ResponseFrozen, error)
 	// Thaw a single output by key image so it may be used again.
 	Thaw(*RequestThaw) error
+	ThawContext(context.Context, *RequestThaw) error
 	// Scan blockchain for transactions for this wallet.
 	ScanTx(*RequestScanTx) error
+	ScanTxContext(context.Context, *RequestScanTx) error
 	// Set up background sync mode.
 	SetupBackgroundSync(*RequestSetupBackgroundSync) (*ResponseSetupBackgroundSync, error)
+	SetupBackgroundSyncContext(context.Context, *RequestSetupBackgroundSync) (*ResponseSetupBackgroundSync, error)
 	// Start background sync mode.
 	StartBackgroundSync() error
+	StartBackgroundSyncContext(ctx context.Context) error
 	// Stop background sync mode.
 	StopBackgroundSync() error
+	StopBackgroundSyncContext(ctx context.Context) error
 	// Get information on all accounts.
 	GetDefaultFeePriority() (*ResponseGetDefaultFeePriority, error)
+	GetDefaultFeePriorityContext(ctx context.Context) (*ResponseGetDefaultFeePriority, error)
+	// Send several calls in a single JSON-RPC batch request.
+	CallBatch(ctx context.Context, calls []BatchCall) error
+	// Call any RPC method and return the undecoded result. See also Call.
+	CallRaw(ctx context.Context, method string, params interface{}) (json.RawMessage, error)
 }
 
 // New returns a new monero-wallet-rpc client.
@@ -198,12 +298,23 @@ func New(cfg Config) Client {
 	cl := &client{
 		addr:    cfg.Address,
 		headers: cfg.CustomHeaders,
+		retry:   cfg.Retry,
 	}
-	if cfg.Transport == nil {
+	interceptors := cfg.Interceptors
+	if cfg.Tracer != nil || cfg.Metrics != nil {
+		interceptors = append(interceptors[:len(interceptors):len(interceptors)], instrumentInterceptor(cfg))
+	}
+	cl.invoke = chainInterceptors(interceptors, cl.call)
+	transport := cfg.Transport
+	if cfg.Username != "" {
+		transport = digest.NewTransport(cfg.Username, cfg.Password, cfg.Transport)
+	}
+	if transport == nil && cfg.Timeout == 0 {
 		cl.httpcl = http.DefaultClient
 	} else {
 		cl.httpcl = &http.Client{
-			Transport: cfg.Transport,
+			Transport: transport,
+			Timeout:   cfg.Timeout,
 		}
 	}
 	return cl
@@ -213,16 +324,45 @@ type client struct {
 	httpcl  *http.Client
 	addr    string
 	headers map[string]string
+	retry   *RetryPolicy
+	invoke  Invoker
+	batch   batch.State
 }
 
 // Helper function
-func (c *client) do(method string, in, out interface{}) error {
+//
+// If ctx is canceled or its deadline expires before the call completes,
+// the returned error wraps ctx.Err() so callers can test for it with
+// errors.Is(err, context.Canceled) or errors.Is(err, context.DeadlineExceeded).
+func (c *client) do(ctx context.Context, method string, in, out interface{}) error {
+	return c.invoke(ctx, method, in, out)
+}
+
+// call is the innermost Invoker: it encodes the request and sends it,
+// retrying according to the client's RetryPolicy.
+func (c *client) call(ctx context.Context, method string, in, out interface{}) error {
+	if calls, ok := in.([]BatchCall); ok && method == BatchMethod {
+		return c.callBatch(ctx, calls)
+	}
 	payload, err := json2.EncodeClientRequest(method, in)
 	if err != nil {
 		return err
 	}
 
-	req, err := http.NewRequest(http.MethodPost, c.addr, bytes.NewBuffer(payload))
+	attempts := c.retry.attempts(method)
+	for attempt := 1; ; attempt++ {
+		err = c.doOnce(ctx, payload, out)
+		if err == nil || attempt >= attempts || !c.retry.retryable(err) {
+			return err
+		}
+		if err := retry.Sleep(ctx, c.retry.backoff(attempt)); err != nil {
+			return fmt.Errorf("request aborted: %w", err)
+		}
+	}
+}
+
+func (c *client) doOnce(ctx context.Context, payload []byte, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.addr, bytes.NewReader(payload))
 	if err != nil {
 		return err
 	}
@@ -233,26 +373,43 @@ func (c *client) do(method string, in, out interface{}) error {
 	}
 	resp, err := c.httpcl.Do(req)
 	if err != nil {
-		return err
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fmt.Errorf("request aborted: %w", ctxErr)
+		}
+		return &TransportError{Err: err}
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("http status %v", resp.StatusCode)
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		return &HTTPStatusError{StatusCode: resp.StatusCode, Body: body}
 	}
-	defer resp.Body.Close()
 
 	// in theory this is only done to catch
 	// any monero related errors if
 	// we are not expecting any data back
 	if out == nil {
-		v := &json2.EmptyResponse{}
-		return json2.DecodeClientResponse(resp.Body, v)
+		out = &json2.EmptyResponse{}
+	}
+	if err := json2.DecodeClientResponse(resp.Body, out); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fmt.Errorf("request aborted: %w", ctxErr)
+		}
+		var jerr *json2.Error
+		if errors.As(err, &jerr) {
+			return &WalletError{Code: ErrorCode(jerr.Code), Message: jerr.Message}
+		}
+		return err
 	}
-	return json2.DecodeClientResponse(resp.Body, out)
+	return nil
 }
 
 // Methods
 func (c *client) GetBalance(req *RequestGetBalance) (resp *ResponseGetBalance, err error) {
-	err = c.do("get_balance", &req, &resp)
+	return c.GetBalanceContext(context.Background(), req)
+}
+
+func (c *client) GetBalanceContext(ctx context.Context, req *RequestGetBalance) (resp *ResponseGetBalance, err error) {
+	err = c.do(ctx, "get_balance", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -260,7 +417,11 @@ func (c *client) GetBalance(req *RequestGetBalance) (resp *ResponseGetBalance, e
 }
 
 func (c *client) GetAddress(req *RequestGetAddress) (resp *ResponseGetAddress, err error) {
-	err = c.do("get_address", &req, &resp)
+	return c.GetAddressContext(context.Background(), req)
+}
+
+func (c *client) GetAddressContext(ctx context.Context, req *RequestGetAddress) (resp *ResponseGetAddress, err error) {
+	err = c.do(ctx, "get_address", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -268,7 +429,11 @@ func (c *client) GetAddress(req *RequestGetAddress) (resp *ResponseGetAddress, e
 }
 
 func (c *client) GetAddressIndex(req *RequestGetAddressIndex) (resp *ResponseGetAddressIndex, err error) {
-	err = c.do("get_address_index", &req, &resp)
+	return c.GetAddressIndexContext(context.Background(), req)
+}
+
+func (c *client) GetAddressIndexContext(ctx context.Context, req *RequestGetAddressIndex) (resp *ResponseGetAddressIndex, err error) {
+	err = c.do(ctx, "get_address_index", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -276,7 +441,11 @@ func (c *client) GetAddressIndex(req *RequestGetAddressIndex) (resp *ResponseGet
 }
 
 func (c *client) CreateAddress(req *RequestCreateAddress) (resp *ResponseCreateAddress, err error) {
-	err = c.do("create_address", &req, &resp)
+	return c.CreateAddressContext(context.Background(), req)
+}
+
+func (c *client) CreateAddressContext(ctx context.Context, req *RequestCreateAddress) (resp *ResponseCreateAddress, err error) {
+	err = c.do(ctx, "create_address", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -284,7 +453,11 @@ func (c *client) CreateAddress(req *RequestCreateAddress) (resp *ResponseCreateA
 }
 
 func (c *client) LabelAddress(req *RequestLabelAddress) (err error) {
-	err = c.do("label_address", req, nil)
+	return c.LabelAddressContext(context.Background(), req)
+}
+
+func (c *client) LabelAddressContext(ctx context.Context, req *RequestLabelAddress) (err error) {
+	err = c.do(ctx, "label_address", req, nil)
 	if err != nil {
 		return err
 	}
@@ -292,7 +465,11 @@ func (c *client) LabelAddress(req *RequestLabelAddress) (err error) {
 }
 
 func (c *client) ValidateAddress(req *RequestValidateAddress) (resp *ResponseValidateAddress, err error) {
-	err = c.do("validate_address", &req, &resp)
+	return c.ValidateAddressContext(context.Background(), req)
+}
+
+func (c *client) ValidateAddressContext(ctx context.Context, req *RequestValidateAddress) (resp *ResponseValidateAddress, err error) {
+	err = c.do(ctx, "validate_address", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -300,7 +477,11 @@ func (c *client) ValidateAddress(req *RequestValidateAddress) (resp *ResponseVal
 }
 
 func (c *client) GetAccounts(req *RequestGetAccounts) (resp *ResponseGetAccounts, err error) {
-	err = c.do("get_accounts", &req, &resp)
+	return c.GetAccountsContext(context.Background(), req)
+}
+
+func (c *client) GetAccountsContext(ctx context.Context, req *RequestGetAccounts) (resp *ResponseGetAccounts, err error) {
+	err = c.do(ctx, "get_accounts", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -308,7 +489,11 @@ func (c *client) GetAccounts(req *RequestGetAccounts) (resp *ResponseGetAccounts
 }
 
 func (c *client) CreateAccount(req *RequestCreateAccount) (resp *ResponseCreateAccount, err error) {
-	err = c.do("create_account", &req, &resp)
+	return c.CreateAccountContext(context.Background(), req)
+}
+
+func (c *client) CreateAccountContext(ctx context.Context, req *RequestCreateAccount) (resp *ResponseCreateAccount, err error) {
+	err = c.do(ctx, "create_account", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -316,14 +501,22 @@ func (c *client) CreateAccount(req *RequestCreateAccount) (resp *ResponseCreateA
 }
 
 func (c *client) LabelAccount(req *RequestLabelAccount) (err error) {
-	err = c.do("label_account", &req, nil)
+	return c.LabelAccountContext(context.Background(), req)
+}
+
+func (c *client) LabelAccountContext(ctx context.Context, req *RequestLabelAccount) (err error) {
+	err = c.do(ctx, "label_account", req, nil)
 	if err != nil {
 		return err
 	}
 	return
 }
 func (c *client) GetAccountTags() (resp *ResponseGetAccountTags, err error) {
-	err = c.do("get_account_tags", nil, &resp)
+	return c.GetAccountTagsContext(context.Background())
+}
+
+func (c *client) GetAccountTagsContext(ctx context.Context) (resp *ResponseGetAccountTags, err error) {
+	err = c.do(ctx, "get_account_tags", nil, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -331,28 +524,44 @@ func (c *client) GetAccountTags() (resp *ResponseGetAccountTags, err error) {
 }
 
 func (c *client) TagAccounts(req *RequestTagAccounts) (err error) {
-	err = c.do("tag_accounts", &req, nil)
+	return c.TagAccountsContext(context.Background(), req)
+}
+
+func (c *client) TagAccountsContext(ctx context.Context, req *RequestTagAccounts) (err error) {
+	err = c.do(ctx, "tag_accounts", req, nil)
 	if err != nil {
 		return err
 	}
 	return
 }
 func (c *client) UntagAccounts(req *RequestUntagAccounts) (err error) {
-	err = c.do("untag_accounts", &req, nil)
+	return c.UntagAccountsContext(context.Background(), req)
+}
+
+func (c *client) UntagAccountsContext(ctx context.Context, req *RequestUntagAccounts) (err error) {
+	err = c.do(ctx, "untag_accounts", req, nil)
 	if err != nil {
 		return err
 	}
 	return
 }
 func (c *client) SetAccountTagDescription(req *RequestSetAccountTagDescription) (err error) {
-	err = c.do("set_account_tag_description", &req, nil)
+	return c.SetAccountTagDescriptionContext(context.Background(), req)
+}
+
+func (c *client) SetAccountTagDescriptionContext(ctx context.Context, req *RequestSetAccountTagDescription) (err error) {
+	err = c.do(ctx, "set_account_tag_description", req, nil)
 	if err != nil {
 		return err
 	}
 	return
 }
 func (c *client) GetHeight() (resp *ResponseGetHeight, err error) {
-	err = c.do("get_height", nil, &resp)
+	return c.GetHeightContext(context.Background())
+}
+
+func (c *client) GetHeightContext(ctx context.Context) (resp *ResponseGetHeight, err error) {
+	err = c.do(ctx, "get_height", nil, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -360,7 +569,11 @@ func (c *client) GetHeight() (resp *ResponseGetHeight, err error) {
 }
 
 func (c *client) Transfer(req *RequestTransfer) (resp *ResponseTransfer, err error) {
-	err = c.do("transfer", &req, &resp)
+	return c.TransferContext(context.Background(), req)
+}
+
+func (c *client) TransferContext(ctx context.Context, req *RequestTransfer) (resp *ResponseTransfer, err error) {
+	err = c.do(ctx, "transfer", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -368,7 +581,11 @@ func (c *client) Transfer(req *RequestTransfer) (resp *ResponseTransfer, err err
 }
 
 func (c *client) TransferSplit(req *RequestTransferSplit) (resp *ResponseTransferSplit, err error) {
-	err = c.do("transfer_split", &req, &resp)
+	return c.TransferSplitContext(context.Background(), req)
+}
+
+func (c *client) TransferSplitContext(ctx context.Context, req *RequestTransferSplit) (resp *ResponseTransferSplit, err error) {
+	err = c.do(ctx, "transfer_split", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -376,7 +593,11 @@ func (c *client) TransferSplit(req *RequestTransferSplit) (resp *ResponseTransfe
 }
 
 func (c *client) SignTransfer(req *RequestSignTransfer) (resp *ResponseSignTransfer, err error) {
-	err = c.do("sign_transfer", &req, &resp)
+	return c.SignTransferContext(context.Background(), req)
+}
+
+func (c *client) SignTransferContext(ctx context.Context, req *RequestSignTransfer) (resp *ResponseSignTransfer, err error) {
+	err = c.do(ctx, "sign_transfer", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -384,7 +605,11 @@ func (c *client) SignTransfer(req *RequestSignTransfer) (resp *ResponseSignTrans
 }
 
 func (c *client) SubmitTransfer(req *RequestSubmitTransfer) (resp *ResponseSubmitTransfer, err error) {
-	err = c.do("submit_transfer", &req, &resp)
+	return c.SubmitTransferContext(context.Background(), req)
+}
+
+func (c *client) SubmitTransferContext(ctx context.Context, req *RequestSubmitTransfer) (resp *ResponseSubmitTransfer, err error) {
+	err = c.do(ctx, "submit_transfer", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -392,7 +617,11 @@ func (c *client) SubmitTransfer(req *RequestSubmitTransfer) (resp *ResponseSubmi
 }
 
 func (c *client) SweepDust(req *RequestSweepDust) (resp *ResponseSweepDust, err error) {
-	err = c.do("sweep_dust", &req, &resp)
+	return c.SweepDustContext(context.Background(), req)
+}
+
+func (c *client) SweepDustContext(ctx context.Context, req *RequestSweepDust) (resp *ResponseSweepDust, err error) {
+	err = c.do(ctx, "sweep_dust", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -400,7 +629,11 @@ func (c *client) SweepDust(req *RequestSweepDust) (resp *ResponseSweepDust, err
 }
 
 func (c *client) SweepAll(req *RequestSweepAll) (resp *ResponseSweepAll, err error) {
-	err = c.do("sweep_all", &req, &resp)
+	return c.SweepAllContext(context.Background(), req)
+}
+
+func (c *client) SweepAllContext(ctx context.Context, req *RequestSweepAll) (resp *ResponseSweepAll, err error) {
+	err = c.do(ctx, "sweep_all", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -408,7 +641,11 @@ func (c *client) SweepAll(req *RequestSweepAll) (resp *ResponseSweepAll, err err
 }
 
 func (c *client) SweepSingle(req *RequestSweepSingle) (resp *ResponseSweepSingle, err error) {
-	err = c.do("sweep_single", &req, &resp)
+	return c.SweepSingleContext(context.Background(), req)
+}
+
+func (c *client) SweepSingleContext(ctx context.Context, req *RequestSweepSingle) (resp *ResponseSweepSingle, err error) {
+	err = c.do(ctx, "sweep_single", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -416,7 +653,11 @@ func (c *client) SweepSingle(req *RequestSweepSingle) (resp *ResponseSweepSingle
 }
 
 func (c *client) RelayTx(req *RequestRelayTx) (resp *ResponseRelayTx, err error) {
-	err = c.do("relay_tx", &req, &resp)
+	return c.RelayTxContext(context.Background(), req)
+}
+
+func (c *client) RelayTxContext(ctx context.Context, req *RequestRelayTx) (resp *ResponseRelayTx, err error) {
+	err = c.do(ctx, "relay_tx", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -424,14 +665,22 @@ func (c *client) RelayTx(req *RequestRelayTx) (resp *ResponseRelayTx, err error)
 }
 
 func (c *client) Store() (err error) {
-	err = c.do("store", nil, nil)
+	return c.StoreContext(context.Background())
+}
+
+func (c *client) StoreContext(ctx context.Context) (err error) {
+	err = c.do(ctx, "store", nil, nil)
 	if err != nil {
 		return err
 	}
 	return
 }
 func (c *client) GetPayments(req *RequestGetPayments) (resp *ResponseGetPayments, err error) {
-	err = c.do("get_payments", &req, &resp)
+	return c.GetPaymentsContext(context.Background(), req)
+}
+
+func (c *client) GetPaymentsContext(ctx context.Context, req *RequestGetPayments) (resp *ResponseGetPayments, err error) {
+	err = c.do(ctx, "get_payments", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -439,7 +688,11 @@ func (c *client) GetPayments(req *RequestGetPayments) (resp *ResponseGetPayments
 }
 
 func (c *client) GetBulkPayments(req *RequestGetBulkPayments) (resp *ResponseGetBulkPayments, err error) {
-	err = c.do("get_bulk_payments", &req, &resp)
+	return c.GetBulkPaymentsContext(context.Background(), req)
+}
+
+func (c *client) GetBulkPaymentsContext(ctx context.Context, req *RequestGetBulkPayments) (resp *ResponseGetBulkPayments, err error) {
+	err = c.do(ctx, "get_bulk_payments", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -447,7 +700,11 @@ func (c *client) GetBulkPayments(req *RequestGetBulkPayments) (resp *ResponseGet
 }
 
 func (c *client) IncomingTransfers(req *RequestIncomingTransfers) (resp *ResponseIncomingTransfers, err error) {
-	err = c.do("incoming_transfers", &req, &resp)
+	return c.IncomingTransfersContext(context.Background(), req)
+}
+
+func (c *client) IncomingTransfersContext(ctx context.Context, req *RequestIncomingTransfers) (resp *ResponseIncomingTransfers, err error) {
+	err = c.do(ctx, "incoming_transfers", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -455,7 +712,11 @@ func (c *client) IncomingTransfers(req *RequestIncomingTransfers) (resp *Respons
 }
 
 func (c *client) QueryKey(req *RequestQueryKey) (resp *ResponseQueryKey, err error) {
-	err = c.do("query_key", &req, &resp)
+	return c.QueryKeyContext(context.Background(), req)
+}
+
+func (c *client) QueryKeyContext(ctx context.Context, req *RequestQueryKey) (resp *ResponseQueryKey, err error) {
+	err = c.do(ctx, "query_key", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -463,7 +724,11 @@ func (c *client) QueryKey(req *RequestQueryKey) (resp *ResponseQueryKey, err err
 }
 
 func (c *client) MakeIntegratedAddress(req *RequestMakeIntegratedAddress) (resp *ResponseMakeIntegratedAddress, err error) {
-	err = c.do("make_integrated_address", &req, &resp)
+	return c.MakeIntegratedAddressContext(context.Background(), req)
+}
+
+func (c *client) MakeIntegratedAddressContext(ctx context.Context, req *RequestMakeIntegratedAddress) (resp *ResponseMakeIntegratedAddress, err error) {
+	err = c.do(ctx, "make_integrated_address", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -471,7 +736,11 @@ func (c *client) MakeIntegratedAddress(req *RequestMakeIntegratedAddress) (resp
 }
 
 func (c *client) SplitIntegratedAddress(req *RequestSplitIntegratedAddress) (resp *ResponseSplitIntegratedAddress, err error) {
-	err = c.do("split_integrated_address", &req, &resp)
+	return c.SplitIntegratedAddressContext(context.Background(), req)
+}
+
+func (c *client) SplitIntegratedAddressContext(ctx context.Context, req *RequestSplitIntegratedAddress) (resp *ResponseSplitIntegratedAddress, err error) {
+	err = c.do(ctx, "split_integrated_address", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -479,28 +748,44 @@ func (c *client) SplitIntegratedAddress(req *RequestSplitIntegratedAddress) (res
 }
 
 func (c *client) StopWallet() (err error) {
-	err = c.do("stop_wallet", nil, nil)
+	return c.StopWalletContext(context.Background())
+}
+
+func (c *client) StopWalletContext(ctx context.Context) (err error) {
+	err = c.do(ctx, "stop_wallet", nil, nil)
 	if err != nil {
 		return err
 	}
 	return
 }
 func (c *client) RescanBlockchain() (err error) {
-	err = c.do("rescan_blockchain", nil, nil)
+	return c.RescanBlockchainContext(context.Background())
+}
+
+func (c *client) RescanBlockchainContext(ctx context.Context) (err error) {
+	err = c.do(ctx, "rescan_blockchain", nil, nil)
 	if err != nil {
 		return err
 	}
 	return
 }
 func (c *client) SetTxNotes(req *RequestSetTxNotes) (err error) {
-	err = c.do("set_tx_notes", &req, nil)
+	return c.SetTxNotesContext(context.Background(), req)
+}
+
+func (c *client) SetTxNotesContext(ctx context.Context, req *RequestSetTxNotes) (err error) {
+	err = c.do(ctx, "set_tx_notes", req, nil)
 	if err != nil {
 		return err
 	}
 	return
 }
 func (c *client) GetTxNotes(req *RequestGetTxNotes) (resp *ResponseGetTxNotes, err error) {
-	err = c.do("get_tx_notes", &req, &resp)
+	return c.GetTxNotesContext(context.Background(), req)
+}
+
+func (c *client) GetTxNotesContext(ctx context.Context, req *RequestGetTxNotes) (resp *ResponseGetTxNotes, err error) {
+	err = c.do(ctx, "get_tx_notes", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -508,14 +793,22 @@ func (c *client) GetTxNotes(req *RequestGetTxNotes) (resp *ResponseGetTxNotes, e
 }
 
 func (c *client) SetAttribute(req *RequestSetAttribute) (err error) {
-	err = c.do("set_attribute", &req, nil)
+	return c.SetAttributeContext(context.Background(), req)
+}
+
+func (c *client) SetAttributeContext(ctx context.Context, req *RequestSetAttribute) (err error) {
+	err = c.do(ctx, "set_attribute", req, nil)
 	if err != nil {
 		return err
 	}
 	return
 }
 func (c *client) GetAttribute(req *RequestGetAttribute) (resp *ResponseGetAttribute, err error) {
-	err = c.do("get_attribute", &req, &resp)
+	return c.GetAttributeContext(context.Background(), req)
+}
+
+func (c *client) GetAttributeContext(ctx context.Context, req *RequestGetAttribute) (resp *ResponseGetAttribute, err error) {
+	err = c.do(ctx, "get_attribute", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -523,7 +816,11 @@ func (c *client) GetAttribute(req *RequestGetAttribute) (resp *ResponseGetAttrib
 }
 
 func (c *client) GetTxKey(req *RequestGetTxKey) (resp *ResponseGetTxKey, err error) {
-	err = c.do("get_tx_key", &req, &resp)
+	return c.GetTxKeyContext(context.Background(), req)
+}
+
+func (c *client) GetTxKeyContext(ctx context.Context, req *RequestGetTxKey) (resp *ResponseGetTxKey, err error) {
+	err = c.do(ctx, "get_tx_key", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -531,7 +828,11 @@ func (c *client) GetTxKey(req *RequestGetTxKey) (resp *ResponseGetTxKey, err err
 }
 
 func (c *client) CheckTxKey(req *RequestCheckTxKey) (resp *ResponseCheckTxKey, err error) {
-	err = c.do("check_tx_key", &req, &resp)
+	return c.CheckTxKeyContext(context.Background(), req)
+}
+
+func (c *client) CheckTxKeyContext(ctx context.Context, req *RequestCheckTxKey) (resp *ResponseCheckTxKey, err error) {
+	err = c.do(ctx, "check_tx_key", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -539,7 +840,11 @@ func (c *client) CheckTxKey(req *RequestCheckTxKey) (resp *ResponseCheckTxKey, e
 }
 
 func (c *client) GetTxProof(req *RequestGetTxProof) (resp *ResponseGetTxProof, err error) {
-	err = c.do("get_tx_proof", &req, &resp)
+	return c.GetTxProofContext(context.Background(), req)
+}
+
+func (c *client) GetTxProofContext(ctx context.Context, req *RequestGetTxProof) (resp *ResponseGetTxProof, err error) {
+	err = c.do(ctx, "get_tx_proof", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -547,7 +852,11 @@ func (c *client) GetTxProof(req *RequestGetTxProof) (resp *ResponseGetTxProof, e
 }
 
 func (c *client) CheckTxProof(req *RequestCheckTxProof) (resp *ResponseCheckTxProof, err error) {
-	err = c.do("check_tx_proof", &req, &resp)
+	return c.CheckTxProofContext(context.Background(), req)
+}
+
+func (c *client) CheckTxProofContext(ctx context.Context, req *RequestCheckTxProof) (resp *ResponseCheckTxProof, err error) {
+	err = c.do(ctx, "check_tx_proof", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -555,7 +864,11 @@ func (c *client) CheckTxProof(req *RequestCheckTxProof) (resp *ResponseCheckTxPr
 }
 
 func (c *client) GetSpendProof(req *RequestGetSpendProof) (resp *ResponseGetSpendProof, err error) {
-	err = c.do("get_spend_proof", &req, &resp)
+	return c.GetSpendProofContext(context.Background(), req)
+}
+
+func (c *client) GetSpendProofContext(ctx context.Context, req *RequestGetSpendProof) (resp *ResponseGetSpendProof, err error) {
+	err = c.do(ctx, "get_spend_proof", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -563,7 +876,11 @@ func (c *client) GetSpendProof(req *RequestGetSpendProof) (resp *ResponseGetSpen
 }
 
 func (c *client) CheckSpendProof(req *RequestCheckSpendProof) (resp *ResponseCheckSpendProof, err error) {
-	err = c.do("check_spend_proof", &req, &resp)
+	return c.CheckSpendProofContext(context.Background(), req)
+}
+
+func (c *client) CheckSpendProofContext(ctx context.Context, req *RequestCheckSpendProof) (resp *ResponseCheckSpendProof, err error) {
+	err = c.do(ctx, "check_spend_proof", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -571,7 +888,11 @@ func (c *client) CheckSpendProof(req *RequestCheckSpendProof) (resp *ResponseChe
 }
 
 func (c *client) GetReserveProof(req *RequestGetReserveProof) (resp *ResponseGetReserveProof, err error) {
-	err = c.do("get_reserve_proof", &req, &resp)
+	return c.GetReserveProofContext(context.Background(), req)
+}
+
+func (c *client) GetReserveProofContext(ctx context.Context, req *RequestGetReserveProof) (resp *ResponseGetReserveProof, err error) {
+	err = c.do(ctx, "get_reserve_proof", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -579,7 +900,11 @@ func (c *client) GetReserveProof(req *RequestGetReserveProof) (resp *ResponseGet
 }
 
 func (c *client) CheckReserveProof(req *RequestCheckReserveProof) (resp *ResponseCheckReserveProof, err error) {
-	err = c.do("check_reserve_proof", &req, &resp)
+	return c.CheckReserveProofContext(context.Background(), req)
+}
+
+func (c *client) CheckReserveProofContext(ctx context.Context, req *RequestCheckReserveProof) (resp *ResponseCheckReserveProof, err error) {
+	err = c.do(ctx, "check_reserve_proof", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -587,7 +912,11 @@ func (c *client) CheckReserveProof(req *RequestCheckReserveProof) (resp *Respons
 }
 
 func (c *client) GetTransfers(req *RequestGetTransfers) (resp *ResponseGetTransfers, err error) {
-	err = c.do("get_transfers", &req, &resp)
+	return c.GetTransfersContext(context.Background(), req)
+}
+
+func (c *client) GetTransfersContext(ctx context.Context, req *RequestGetTransfers) (resp *ResponseGetTransfers, err error) {
+	err = c.do(ctx, "get_transfers", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -595,7 +924,11 @@ func (c *client) GetTransfers(req *RequestGetTransfers) (resp *ResponseGetTransf
 }
 
 func (c *client) GetTransferByTxID(req *RequestGetTransferByTxID) (resp *ResponseGetTransferByTxID, err error) {
-	err = c.do("get_transfer_by_txid", &req, &resp)
+	return c.GetTransferByTxIDContext(context.Background(), req)
+}
+
+func (c *client) GetTransferByTxIDContext(ctx context.Context, req *RequestGetTransferByTxID) (resp *ResponseGetTransferByTxID, err error) {
+	err = c.do(ctx, "get_transfer_by_txid", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -603,7 +936,11 @@ func (c *client) GetTransferByTxID(req *RequestGetTransferByTxID) (resp *Respons
 }
 
 func (c *client) Sign(req *RequestSign) (resp *ResponseSign, err error) {
-	err = c.do("sign", &req, &resp)
+	return c.SignContext(context.Background(), req)
+}
+
+func (c *client) SignContext(ctx context.Context, req *RequestSign) (resp *ResponseSign, err error) {
+	err = c.do(ctx, "sign", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -611,7 +948,11 @@ func (c *client) Sign(req *RequestSign) (resp *ResponseSign, err error) {
 }
 
 func (c *client) Verify(req *RequestVerify) (resp *ResponseVerify, err error) {
-	err = c.do("verify", &req, &resp)
+	return c.VerifyContext(context.Background(), req)
+}
+
+func (c *client) VerifyContext(ctx context.Context, req *RequestVerify) (resp *ResponseVerify, err error) {
+	err = c.do(ctx, "verify", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -619,7 +960,11 @@ func (c *client) Verify(req *RequestVerify) (resp *ResponseVerify, err error) {
 }
 
 func (c *client) ExportOutputs() (resp *ResponseExportOutputs, err error) {
-	err = c.do("export_outputs", nil, &resp)
+	return c.ExportOutputsContext(context.Background())
+}
+
+func (c *client) ExportOutputsContext(ctx context.Context) (resp *ResponseExportOutputs, err error) {
+	err = c.do(ctx, "export_outputs", nil, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -627,7 +972,11 @@ func (c *client) ExportOutputs() (resp *ResponseExportOutputs, err error) {
 }
 
 func (c *client) ImportOutputs(req *RequestImportOutputs) (resp *ResponseImportOutputs, err error) {
-	err = c.do("import_outputs", &req, &resp)
+	return c.ImportOutputsContext(context.Background(), req)
+}
+
+func (c *client) ImportOutputsContext(ctx context.Context, req *RequestImportOutputs) (resp *ResponseImportOutputs, err error) {
+	err = c.do(ctx, "import_outputs", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -635,7 +984,11 @@ func (c *client) ImportOutputs(req *RequestImportOutputs) (resp *ResponseImportO
 }
 
 func (c *client) ExportKeyImages() (resp *ResponseExportKeyImages, err error) {
-	err = c.do("export_key_images", nil, &resp)
+	return c.ExportKeyImagesContext(context.Background())
+}
+
+func (c *client) ExportKeyImagesContext(ctx context.Context) (resp *ResponseExportKeyImages, err error) {
+	err = c.do(ctx, "export_key_images", nil, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -643,7 +996,11 @@ func (c *client) ExportKeyImages() (resp *ResponseExportKeyImages, err error) {
 }
 
 func (c *client) ImportKeyImages(req *RequestImportKeyImages) (resp *ResponseImportKeyImages, err error) {
-	err = c.do("import_key_images", &req, &resp)
+	return c.ImportKeyImagesContext(context.Background(), req)
+}
+
+func (c *client) ImportKeyImagesContext(ctx context.Context, req *RequestImportKeyImages) (resp *ResponseImportKeyImages, err error) {
+	err = c.do(ctx, "import_key_images", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -651,7 +1008,11 @@ func (c *client) ImportKeyImages(req *RequestImportKeyImages) (resp *ResponseImp
 }
 
 func (c *client) MakeURI(req *RequestMakeURI) (resp *ResponseMakeURI, err error) {
-	err = c.do("make_uri", &req, &resp)
+	return c.MakeURIContext(context.Background(), req)
+}
+
+func (c *client) MakeURIContext(ctx context.Context, req *RequestMakeURI) (resp *ResponseMakeURI, err error) {
+	err = c.do(ctx, "make_uri", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -659,7 +1020,11 @@ func (c *client) MakeURI(req *RequestMakeURI) (resp *ResponseMakeURI, err error)
 }
 
 func (c *client) ParseURI(req *RequestParseURI) (resp *ResponseParseURI, err error) {
-	err = c.do("parse_uri", &req, &resp)
+	return c.ParseURIContext(context.Background(), req)
+}
+
+func (c *client) ParseURIContext(ctx context.Context, req *RequestParseURI) (resp *ResponseParseURI, err error) {
+	err = c.do(ctx, "parse_uri", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -667,7 +1032,11 @@ func (c *client) ParseURI(req *RequestParseURI) (resp *ResponseParseURI, err err
 }
 
 func (c *client) GetAddressBook(req *RequestGetAddressBook) (resp *ResponseGetAddressBook, err error) {
-	err = c.do("get_address_book", &req, &resp)
+	return c.GetAddressBookContext(context.Background(), req)
+}
+
+func (c *client) GetAddressBookContext(ctx context.Context, req *RequestGetAddressBook) (resp *ResponseGetAddressBook, err error) {
+	err = c.do(ctx, "get_address_book", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -675,7 +1044,11 @@ func (c *client) GetAddressBook(req *RequestGetAddressBook) (resp *ResponseGetAd
 }
 
 func (c *client) AddAddressBook(req *RequestAddAddressBook) (resp *ResponseAddAddressBook, err error) {
-	err = c.do("add_address_book", &req, &resp)
+	return c.AddAddressBookContext(context.Background(), req)
+}
+
+func (c *client) AddAddressBookContext(ctx context.Context, req *RequestAddAddressBook) (resp *ResponseAddAddressBook, err error) {
+	err = c.do(ctx, "add_address_book", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -683,14 +1056,22 @@ func (c *client) AddAddressBook(req *RequestAddAddressBook) (resp *ResponseAddAd
 }
 
 func (c *client) DeleteAddressBook(req *RequestDeleteAddressBook) (err error) {
-	err = c.do("delete_address_book", &req, nil)
+	return c.DeleteAddressBookContext(context.Background(), req)
+}
+
+func (c *client) DeleteAddressBookContext(ctx context.Context, req *RequestDeleteAddressBook) (err error) {
+	err = c.do(ctx, "delete_address_book", req, nil)
 	if err != nil {
 		return err
 	}
 	return
 }
 func (c *client) Refresh(req *RequestRefresh) (resp *ResponseRefresh, err error) {
-	err = c.do("refresh", &req, &resp)
+	return c.RefreshContext(context.Background(), req)
+}
+
+func (c *client) RefreshContext(ctx context.Context, req *RequestRefresh) (resp *ResponseRefresh, err error) {
+	err = c.do(ctx, "refresh", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -698,28 +1079,44 @@ func (c *client) Refresh(req *RequestRefresh) (resp *ResponseRefresh, err error)
 }
 
 func (c *client) RescanSpent() (err error) {
-	err = c.do("rescan_spent", nil, nil)
+	return c.RescanSpentContext(context.Background())
+}
+
+func (c *client) RescanSpentContext(ctx context.Context) (err error) {
+	err = c.do(ctx, "rescan_spent", nil, nil)
 	if err != nil {
 		return err
 	}
 	return
 }
 func (c *client) StartMining(req *RequestStartMining) (err error) {
-	err = c.do("start_mining", &req, nil)
+	return c.StartMiningContext(context.Background(), req)
+}
+
+func (c *client) StartMiningContext(ctx context.Context, req *RequestStartMining) (err error) {
+	err = c.do(ctx, "start_mining", req, nil)
 	if err != nil {
 		return err
 	}
 	return
 }
 func (c *client) StopMining() (err error) {
-	err = c.do("stop_mining", nil, nil)
+	return c.StopMiningContext(context.Background())
+}
+
+func (c *client) StopMiningContext(ctx context.Context) (err error) {
+	err = c.do(ctx, "stop_mining", nil, nil)
 	if err != nil {
 		return err
 	}
 	return
 }
 func (c *client) GetLanguages() (resp *ResponseGetLanguages, err error) {
-	err = c.do("get_languages", nil, &resp)
+	return c.GetLanguagesContext(context.Background())
+}
+
+func (c *client) GetLanguagesContext(ctx context.Context) (resp *ResponseGetLanguages, err error) {
+	err = c.do(ctx, "get_languages", nil, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -727,42 +1124,66 @@ func (c *client) GetLanguages() (resp *ResponseGetLanguages, err error) {
 }
 
 func (c *client) CreateWallet(req *RequestCreateWallet) (err error) {
-	err = c.do("create_wallet", &req, nil)
+	return c.CreateWalletContext(context.Background(), req)
+}
+
+func (c *client) CreateWalletContext(ctx context.Context, req *RequestCreateWallet) (err error) {
+	err = c.do(ctx, "create_wallet", req, nil)
 	if err != nil {
 		return err
 	}
 	return
 }
 func (c *client) GenerateFromKeys(req *RequestGenerateFromKeys) (resp *ResponseGenerateFromKeys, err error) {
-	err = c.do("generate_from_keys", &req, &resp)
+	return c.GenerateFromKeysContext(context.Background(), req)
+}
+
+func (c *client) GenerateFromKeysContext(ctx context.Context, req *RequestGenerateFromKeys) (resp *ResponseGenerateFromKeys, err error) {
+	err = c.do(ctx, "generate_from_keys", req, &resp)
 	if err != nil {
 		return nil, err
 	}
 	return
 }
 func (c *client) OpenWallet(req *RequestOpenWallet) (err error) {
-	err = c.do("open_wallet", &req, nil)
+	return c.OpenWalletContext(context.Background(), req)
+}
+
+func (c *client) OpenWalletContext(ctx context.Context, req *RequestOpenWallet) (err error) {
+	err = c.do(ctx, "open_wallet", req, nil)
 	if err != nil {
 		return err
 	}
 	return
 }
 func (c *client) CloseWallet() (err error) {
-	err = c.do("close_wallet", nil, nil)
+	return c.CloseWalletContext(context.Background())
+}
+
+func (c *client) CloseWalletContext(ctx context.Context) (err error) {
+	err = c.do(ctx, "close_wallet", nil, nil)
 	if err != nil {
 		return err
 	}
 	return
 }
 func (c *client) ChangeWalletPassword(req *RequestChangeWalletPassword) (err error) {
-	err = c.do("change_wallet_password", &req, nil)
+	return c.ChangeWalletPasswordContext(context.Background(), req)
+}
+
+func (c *client) ChangeWalletPasswordContext(ctx context.Context, req *RequestChangeWalletPassword) (err error) {
+	err = c.do(ctx, "change_wallet_password", req, nil)
 	if err != nil {
 		return err
 	}
 	return
 }
 func (c *client) IsMultisig() (resp *ResponseIsMultisig, err error) {
-	err = c.do("is_multisig", nil, &resp)
+	return c.IsMultisigContext(context.Background())
+}
+
+func (c *client) IsMultisigContext(ctx context.Context) (resp *ResponseIsMultisig, err error) {
+	err = c.do(ctx, "is_multisig", nil, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -770,7 +1191,11 @@ func (c *client) IsMultisig() (resp *ResponseIsMultisig, err error) {
 }
 
 func (c *client) PrepareMultisig() (resp *ResponsePrepareMultisig, err error) {
-	err = c.do("prepare_multisig", nil, &resp)
+	return c.PrepareMultisigContext(context.Background())
+}
+
+func (c *client) PrepareMultisigContext(ctx context.Context) (resp *ResponsePrepareMultisig, err error) {
+	err = c.do(ctx, "prepare_multisig", nil, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -778,7 +1203,11 @@ func (c *client) PrepareMultisig() (resp *ResponsePrepareMultisig, err error) {
 }
 
 func (c *client) MakeMultisig(req *RequestMakeMultisig) (resp *ResponseMakeMultisig, err error) {
-	err = c.do("make_multisig", &req, &resp)
+	return c.MakeMultisigContext(context.Background(), req)
+}
+
+func (c *client) MakeMultisigContext(ctx context.Context, req *RequestMakeMultisig) (resp *ResponseMakeMultisig, err error) {
+	err = c.do(ctx, "make_multisig", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -786,7 +1215,11 @@ func (c *client) MakeMultisig(req *RequestMakeMultisig) (resp *ResponseMakeMulti
 }
 
 func (c *client) ExportMultisigInfo() (resp *ResponseExportMultisigInfo, err error) {
-	err = c.do("export_multisig_info", nil, &resp)
+	return c.ExportMultisigInfoContext(context.Background())
+}
+
+func (c *client) ExportMultisigInfoContext(ctx context.Context) (resp *ResponseExportMultisigInfo, err error) {
+	err = c.do(ctx, "export_multisig_info", nil, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -794,7 +1227,11 @@ func (c *client) ExportMultisigInfo() (resp *ResponseExportMultisigInfo, err err
 }
 
 func (c *client) ImportMultisigInfo(req *RequestImportMultisigInfo) (resp *ResponseImportMultisigInfo, err error) {
-	err = c.do("import_multisig_info", &req, &resp)
+	return c.ImportMultisigInfoContext(context.Background(), req)
+}
+
+func (c *client) ImportMultisigInfoContext(ctx context.Context, req *RequestImportMultisigInfo) (resp *ResponseImportMultisigInfo, err error) {
+	err = c.do(ctx, "import_multisig_info", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -802,7 +1239,11 @@ func (c *client) ImportMultisigInfo(req *RequestImportMultisigInfo) (resp *Respo
 }
 
 func (c *client) FinalizeMultisig(req *RequestFinalizeMultisig) (resp *ResponseFinalizeMultisig, err error) {
-	err = c.do("finalize_multisig", &req, &resp)
+	return c.FinalizeMultisigContext(context.Background(), req)
+}
+
+func (c *client) FinalizeMultisigContext(ctx context.Context, req *RequestFinalizeMultisig) (resp *ResponseFinalizeMultisig, err error) {
+	err = c.do(ctx, "finalize_multisig", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -810,7 +1251,11 @@ func (c *client) FinalizeMultisig(req *RequestFinalizeMultisig) (resp *ResponseF
 }
 
 func (c *client) SignMultisig(req *RequestSignMultisig) (resp *ResponseSignMultisig, err error) {
-	err = c.do("sign_multisig", &req, &resp)
+	return c.SignMultisigContext(context.Background(), req)
+}
+
+func (c *client) SignMultisigContext(ctx context.Context, req *RequestSignMultisig) (resp *ResponseSignMultisig, err error) {
+	err = c.do(ctx, "sign_multisig", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -818,7 +1263,11 @@ func (c *client) SignMultisig(req *RequestSignMultisig) (resp *ResponseSignMulti
 }
 
 func (c *client) SubmitMultisig(req *RequestSubmitMultisig) (resp *ResponseSubmitMultisig, err error) {
-	err = c.do("submit_multisig", &req, &resp)
+	return c.SubmitMultisigContext(context.Background(), req)
+}
+
+func (c *client) SubmitMultisigContext(ctx context.Context, req *RequestSubmitMultisig) (resp *ResponseSubmitMultisig, err error) {
+	err = c.do(ctx, "submit_multisig", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -826,7 +1275,11 @@ func (c *client) SubmitMultisig(req *RequestSubmitMultisig) (resp *ResponseSubmi
 }
 
 func (c *client) GetVersion() (resp *ResponseGetVersion, err error) {
-	err = c.do("get_version", nil, &resp)
+	return c.GetVersionContext(context.Background())
+}
+
+func (c *client) GetVersionContext(ctx context.Context) (resp *ResponseGetVersion, err error) {
+	err = c.do(ctx, "get_version", nil, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -834,7 +1287,11 @@ func (c *client) GetVersion() (resp *ResponseGetVersion, err error) {
 }
 
 func (c *client) SetDaemon(req *RequestSetDaemon) (err error) {
-	err = c.do("set_daemon", &req, nil)
+	return c.SetDaemonContext(context.Background(), req)
+}
+
+func (c *client) SetDaemonContext(ctx context.Context, req *RequestSetDaemon) (err error) {
+	err = c.do(ctx, "set_daemon", req, nil)
 	if err != nil {
 		return err
 	}
@@ -842,7 +1299,11 @@ func (c *client) SetDaemon(req *RequestSetDaemon) (err error) {
 }
 
 func (c *client) AutoRefresh(req *RequestAutoRefresh) (err error) {
-	err = c.do("auto_refresh", &req, nil)
+	return c.AutoRefreshContext(context.Background(), req)
+}
+
+func (c *client) AutoRefreshContext(ctx context.Context, req *RequestAutoRefresh) (err error) {
+	err = c.do(ctx, "auto_refresh", req, nil)
 	if err != nil {
 		return err
 	}
@@ -850,7 +1311,11 @@ func (c *client) AutoRefresh(req *RequestAutoRefresh) (err error) {
 }
 
 func (c *client) DescribeTransfer(req *RequestDescribeTransfer) (resp *ResponseDescribeTransfer, err error) {
-	err = c.do("describe_transfer", &req, &resp)
+	return c.DescribeTransferContext(context.Background(), req)
+}
+
+func (c *client) DescribeTransferContext(ctx context.Context, req *RequestDescribeTransfer) (resp *ResponseDescribeTransfer, err error) {
+	err = c.do(ctx, "describe_transfer", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -858,7 +1323,11 @@ func (c *client) DescribeTransfer(req *RequestDescribeTransfer) (resp *ResponseD
 }
 
 func (c *client) EditAddressBook(req *RequestEditAddressBook) (err error) {
-	err = c.do("edit_address_book", &req, nil)
+	return c.EditAddressBookContext(context.Background(), req)
+}
+
+func (c *client) EditAddressBookContext(ctx context.Context, req *RequestEditAddressBook) (err error) {
+	err = c.do(ctx, "edit_address_book", req, nil)
 	if err != nil {
 		return err
 	}
@@ -866,7 +1335,11 @@ func (c *client) EditAddressBook(req *RequestEditAddressBook) (err error) {
 }
 
 func (c *client) EstimateTxSizeAndWeight(req *RequestEstimateTxSizeAndWeight) (resp *ResponseEstimateTxSizeAndWeight, err error) {
-	err = c.do("estimate_tx_size_and_weight", &req, &resp)
+	return c.EstimateTxSizeAndWeightContext(context.Background(), req)
+}
+
+func (c *client) EstimateTxSizeAndWeightContext(ctx context.Context, req *RequestEstimateTxSizeAndWeight) (resp *ResponseEstimateTxSizeAndWeight, err error) {
+	err = c.do(ctx, "estimate_tx_size_and_weight", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -874,7 +1347,11 @@ func (c *client) EstimateTxSizeAndWeight(req *RequestEstimateTxSizeAndWeight) (r
 }
 
 func (c *client) ExchangeMultisigKeys(req *RequestExchangeMultisigKeys) (resp *ResponseExchangeMultisigKeys, err error) {
-	err = c.do("exchange_multisig_keys", &req, &resp)
+	return c.ExchangeMultisigKeysContext(context.Background(), req)
+}
+
+func (c *client) ExchangeMultisigKeysContext(ctx context.Context, req *RequestExchangeMultisigKeys) (resp *ResponseExchangeMultisigKeys, err error) {
+	err = c.do(ctx, "exchange_multisig_keys", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -882,7 +1359,11 @@ func (c *client) ExchangeMultisigKeys(req *RequestExchangeMultisigKeys) (resp *R
 }
 
 func (c *client) Freeze(req *RequestFreeze) (err error) {
-	err = c.do("freeze", &req, nil)
+	return c.FreezeContext(context.Background(), req)
+}
+
+func (c *client) FreezeContext(ctx context.Context, req *RequestFreeze) (err error) {
+	err = c.do(ctx, "freeze", req, nil)
 	if err != nil {
 		return err
 	}
@@ -890,7 +1371,11 @@ func (c *client) Freeze(req *RequestFreeze) (err error) {
 }
 
 func (c *client) Frozen(req *RequestFrozen) (resp *ResponseFrozen, err error) {
-	err = c.do("frozen", &req, &resp)
+	return c.FrozenContext(context.Background(), req)
+}
+
+func (c *client) FrozenContext(ctx context.Context, req *RequestFrozen) (resp *ResponseFrozen, err error) {
+	err = c.do(ctx, "frozen", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -898,7 +1383,11 @@ func (c *client) Frozen(req *RequestFrozen) (resp *ResponseFrozen, err error) {
 }
 
 func (c *client) Thaw(req *RequestThaw) (err error) {
-	err = c.do("thaw", &req, nil)
+	return c.ThawContext(context.Background(), req)
+}
+
+func (c *client) ThawContext(ctx context.Context, req *RequestThaw) (err error) {
+	err = c.do(ctx, "thaw", req, nil)
 	if err != nil {
 		return err
 	}
@@ -906,7 +1395,11 @@ func (c *client) Thaw(req *RequestThaw) (err error) {
 }
 
 func (c *client) ScanTx(req *RequestScanTx) (err error) {
-	err = c.do("scan_tx", &req, nil)
+	return c.ScanTxContext(context.Background(), req)
+}
+
+func (c *client) ScanTxContext(ctx context.Context, req *RequestScanTx) (err error) {
+	err = c.do(ctx, "scan_tx", req, nil)
 	if err != nil {
 		return err
 	}
@@ -914,7 +1407,11 @@ func (c *client) ScanTx(req *RequestScanTx) (err error) {
 }
 
 func (c *client) SetupBackgroundSync(req *RequestSetupBackgroundSync) (resp *ResponseSetupBackgroundSync, err error) {
-	err = c.do("setup_background_sync", &req, &resp)
+	return c.SetupBackgroundSyncContext(context.Background(), req)
+}
+
+func (c *client) SetupBackgroundSyncContext(ctx context.Context, req *RequestSetupBackgroundSync) (resp *ResponseSetupBackgroundSync, err error) {
+	err = c.do(ctx, "setup_background_sync", req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -922,7 +1419,11 @@ func (c *client) SetupBackgroundSync(req *RequestSetupBackgroundSync) (resp *Res
 }
 
 func (c *client) StartBackgroundSync() (err error) {
-	err = c.do("start_background_sync", nil, nil)
+	return c.StartBackgroundSyncContext(context.Background())
+}
+
+func (c *client) StartBackgroundSyncContext(ctx context.Context) (err error) {
+	err = c.do(ctx, "start_background_sync", nil, nil)
 	if err != nil {
 		return err
 	}
@@ -930,7 +1431,11 @@ func (c *client) StartBackgroundSync() (err error) {
 }
 
 func (c *client) StopBackgroundSync() (err error) {
-	err = c.do("stop_background_sync", nil, nil)
+	return c.StopBackgroundSyncContext(context.Background())
+}
+
+func (c *client) StopBackgroundSyncContext(ctx context.Context) (err error) {
+	err = c.do(ctx, "stop_background_sync", nil, nil)
 	if err != nil {
 		return err
 	}
@@ -938,7 +1443,11 @@ func (c *client) StopBackgroundSync() (err error) {
 }
 
 func (c *client) GetDefaultFeePriority() (resp *ResponseGetDefaultFeePriority, err error) {
-	err = c.do("get_default_fee_priority", nil, &resp)
+	return c.GetDefaultFeePriorityContext(context.Background())
+}
+
+func (c *client) GetDefaultFeePriorityContext(ctx context.Context) (resp *ResponseGetDefaultFeePriority, err error) {
+	err = c.do(ctx, "get_default_fee_priority", nil, &resp)
 	if err != nil {
 		return nil, err
 	}
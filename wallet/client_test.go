@@ -0,0 +1,70 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/boomhut/go-monero-rpc-client/telemetry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientContextCanceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	cl := New(Config{Address: srv.URL + "/json_rpc"})
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	_, err := cl.RefreshContext(ctx, &RequestRefresh{})
+	assert.True(t, errors.Is(err, context.Canceled), "got %v", err)
+	assert.Contains(t, err.Error(), "request aborted")
+}
+
+func TestClientContextDeadline(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	cl := New(Config{Address: srv.URL + "/json_rpc"})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := cl.RescanBlockchainContext(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "got %v", err)
+}
+
+func TestClientTimeout(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	metrics := telemetry.NewMetrics()
+	cl := New(Config{
+		Address: srv.URL + "/json_rpc",
+		Timeout: 20 * time.Millisecond,
+		Retry:   &RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+		Metrics: metrics,
+	})
+	_, err := cl.GetHeight()
+	assert.True(t, errors.Is(err, ErrTransport), "got %v", err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "got %v", err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Equal(t, uint64(1), metrics.Snapshot()[0].Errors[telemetry.ErrorTimeout])
+}
@@ -2,6 +2,9 @@ package wallet
 
 import (
 	"net/http"
+	"time"
+
+	"github.com/boomhut/go-monero-rpc-client/telemetry"
 )
 
 // Config holds the configuration of a monero rpc client.
@@ -9,4 +12,22 @@ type Config struct {
 	Address       string
 	CustomHeaders map[string]string
 	Transport     http.RoundTripper
+	// Timeout limits a single HTTP request, including reading the response
+	// body. Zero means no limit besides the context passed to the call;
+	// keep in mind that refresh, rescan_blockchain and similar methods can
+	// legitimately take minutes.
+	Timeout time.Duration
+	// Username and Password for monero-wallet-rpc --rpc-login.
+	// When Username is set the client answers HTTP Digest challenges itself.
+	Username string
+	Password string
+	// Retry enables retrying of idempotent methods. Nil means a single attempt.
+	Retry *RetryPolicy
+	// Interceptors wrap every call, the first one being the outermost.
+	Interceptors []Interceptor
+	// Tracer, if set, starts a span for every RPC call and Metrics, if set,
+	// records its outcome and latency. Params and results are never
+	// recorded.
+	Tracer  telemetry.Tracer
+	Metrics telemetry.Recorder
 }
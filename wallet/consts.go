@@ -1,16 +1,12 @@
 package wallet
 
-import (
-	"fmt"
-
-	"github.com/gorilla/rpc/v2/json2"
-)
+import "fmt"
 
 // H is a helper map shortcut.
 type H map[string]interface{}
 
 // ErrorCode is a monero-wallet-rpc error code.
-// Copied from https://github.com/monero-project/monero/blob/release-v0.11.0.0/src/wallet/wallet_rpc_server_error_codes.h
+// Copied from https://github.com/monero-project/monero/blob/master/src/wallet/wallet_rpc_server_error_codes.h
 type ErrorCode int
 
 const (
@@ -40,33 +36,154 @@ const (
 	ErrWrongIndex ErrorCode = -12
 	// ErrNotOpen - E_NOT_OPEN
 	ErrNotOpen ErrorCode = -13
+	// ErrAccountIndexOutOfBounds - E_ACCOUNT_INDEX_OUT_OF_BOUNDS
+	ErrAccountIndexOutOfBounds ErrorCode = -14
+	// ErrAddressIndexOutOfBounds - E_ADDRESS_INDEX_OUT_OF_BOUNDS
+	ErrAddressIndexOutOfBounds ErrorCode = -15
+	// ErrTxNotPossible - E_TX_NOT_POSSIBLE
+	ErrTxNotPossible ErrorCode = -16
+	// ErrNotEnoughMoney - E_NOT_ENOUGH_MONEY
+	ErrNotEnoughMoney ErrorCode = -17
+	// ErrTxTooLarge - E_TX_TOO_LARGE
+	ErrTxTooLarge ErrorCode = -18
+	// ErrNotEnoughOutsToMix - E_NOT_ENOUGH_OUTS_TO_MIX
+	ErrNotEnoughOutsToMix ErrorCode = -19
+	// ErrZeroDestination - E_ZERO_DESTINATION
+	ErrZeroDestination ErrorCode = -20
+	// ErrWalletAlreadyExists - E_WALLET_ALREADY_EXISTS
+	ErrWalletAlreadyExists ErrorCode = -21
+	// ErrInvalidPassword - E_INVALID_PASSWORD
+	ErrInvalidPassword ErrorCode = -22
+	// ErrNoWalletDir - E_NO_WALLET_DIR
+	ErrNoWalletDir ErrorCode = -23
+	// ErrNoTxKey - E_NO_TXKEY
+	ErrNoTxKey ErrorCode = -24
+	// ErrWrongKey - E_WRONG_KEY
+	ErrWrongKey ErrorCode = -25
+	// ErrBadHex - E_BAD_HEX
+	ErrBadHex ErrorCode = -26
+	// ErrBadTxMetadata - E_BAD_TX_METADATA
+	ErrBadTxMetadata ErrorCode = -27
+	// ErrAlreadyMultisig - E_ALREADY_MULTISIG
+	ErrAlreadyMultisig ErrorCode = -28
+	// ErrWatchOnly - E_WATCH_ONLY
+	ErrWatchOnly ErrorCode = -29
+	// ErrBadMultisigInfo - E_BAD_MULTISIG_INFO
+	ErrBadMultisigInfo ErrorCode = -30
+	// ErrNotMultisig - E_NOT_MULTISIG
+	ErrNotMultisig ErrorCode = -31
+	// ErrWrongLR - E_WRONG_LR
+	ErrWrongLR ErrorCode = -32
+	// ErrThresholdNotReached - E_THRESHOLD_NOT_REACHED
+	ErrThresholdNotReached ErrorCode = -33
+	// ErrBadMultisigTxData - E_BAD_MULTISIG_TX_DATA
+	ErrBadMultisigTxData ErrorCode = -34
+	// ErrMultisigSignature - E_MULTISIG_SIGNATURE
+	ErrMultisigSignature ErrorCode = -35
+	// ErrMultisigSubmission - E_MULTISIG_SUBMISSION
+	ErrMultisigSubmission ErrorCode = -36
+	// ErrNotEnoughUnlockedMoney - E_NOT_ENOUGH_UNLOCKED_MONEY
+	ErrNotEnoughUnlockedMoney ErrorCode = -37
+	// ErrNoDaemonConnection - E_NO_DAEMON_CONNECTION
+	ErrNoDaemonConnection ErrorCode = -38
+	// ErrBadUnsignedTxData - E_BAD_UNSIGNED_TX_DATA
+	ErrBadUnsignedTxData ErrorCode = -39
+	// ErrBadSignedTxData - E_BAD_SIGNED_TX_DATA
+	ErrBadSignedTxData ErrorCode = -40
+	// ErrSignedSubmission - E_SIGNED_SUBMISSION
+	ErrSignedSubmission ErrorCode = -41
+	// ErrSignUnsigned - E_SIGN_UNSIGNED
+	ErrSignUnsigned ErrorCode = -42
+	// ErrNonDeterministic - E_NON_DETERMINISTIC
+	ErrNonDeterministic ErrorCode = -43
+	// ErrInvalidLogLevel - E_INVALID_LOG_LEVEL
+	ErrInvalidLogLevel ErrorCode = -44
+	// ErrAttributeNotFound - E_ATTRIBUTE_NOT_FOUND
+	ErrAttributeNotFound ErrorCode = -45
+	// ErrZeroAmount - E_ZERO_AMOUNT
+	ErrZeroAmount ErrorCode = -46
+	// ErrInvalidSignatureType - E_INVALID_SIGNATURE_TYPE
+	ErrInvalidSignatureType ErrorCode = -47
+	// ErrDisabled - E_DISABLED
+	ErrDisabled ErrorCode = -48
+	// ErrProxyAlreadyDefined - E_PROXY_ALREADY_DEFINED
+	ErrProxyAlreadyDefined ErrorCode = -49
+	// ErrNonzeroUnlockTime - E_NONZERO_UNLOCK_TIME
+	ErrNonzeroUnlockTime ErrorCode = -50
+	// ErrIsBackgroundWallet - E_IS_BACKGROUND_WALLET
+	ErrIsBackgroundWallet ErrorCode = -51
+	// ErrIsBackgroundSyncing - E_IS_BACKGROUND_SYNCING
+	ErrIsBackgroundSyncing ErrorCode = -52
 )
 
-// WalletError is the error structured returned by the monero-wallet-rpc
-type WalletError struct {
-	Code    ErrorCode `json:"code"`
-	Message string    `json:"message"`
+var errorCodeNames = map[ErrorCode]string{
+	-1:  "E_UNKNOWN_ERROR",
+	-2:  "E_WRONG_ADDRESS",
+	-3:  "E_DAEMON_IS_BUSY",
+	-4:  "E_GENERIC_TRANSFER_ERROR",
+	-5:  "E_WRONG_PAYMENT_ID",
+	-6:  "E_TRANSFER_TYPE",
+	-7:  "E_DENIED",
+	-8:  "E_WRONG_TXID",
+	-9:  "E_WRONG_SIGNATURE",
+	-10: "E_WRONG_KEY_IMAGE",
+	-11: "E_WRONG_URI",
+	-12: "E_WRONG_INDEX",
+	-13: "E_NOT_OPEN",
+	-14: "E_ACCOUNT_INDEX_OUT_OF_BOUNDS",
+	-15: "E_ADDRESS_INDEX_OUT_OF_BOUNDS",
+	-16: "E_TX_NOT_POSSIBLE",
+	-17: "E_NOT_ENOUGH_MONEY",
+	-18: "E_TX_TOO_LARGE",
+	-19: "E_NOT_ENOUGH_OUTS_TO_MIX",
+	-20: "E_ZERO_DESTINATION",
+	-21: "E_WALLET_ALREADY_EXISTS",
+	-22: "E_INVALID_PASSWORD",
+	-23: "E_NO_WALLET_DIR",
+	-24: "E_NO_TXKEY",
+	-25: "E_WRONG_KEY",
+	-26: "E_BAD_HEX",
+	-27: "E_BAD_TX_METADATA",
+	-28: "E_ALREADY_MULTISIG",
+	-29: "E_WATCH_ONLY",
+	-30: "E_BAD_MULTISIG_INFO",
+	-31: "E_NOT_MULTISIG",
+	-32: "E_WRONG_LR",
+	-33: "E_THRESHOLD_NOT_REACHED",
+	-34: "E_BAD_MULTISIG_TX_DATA",
+	-35: "E_MULTISIG_SIGNATURE",
+	-36: "E_MULTISIG_SUBMISSION",
+	-37: "E_NOT_ENOUGH_UNLOCKED_MONEY",
+	-38: "E_NO_DAEMON_CONNECTION",
+	-39: "E_BAD_UNSIGNED_TX_DATA",
+	-40: "E_BAD_SIGNED_TX_DATA",
+	-41: "E_SIGNED_SUBMISSION",
+	-42: "E_SIGN_UNSIGNED",
+	-43: "E_NON_DETERMINISTIC",
+	-44: "E_INVALID_LOG_LEVEL",
+	-45: "E_ATTRIBUTE_NOT_FOUND",
+	-46: "E_ZERO_AMOUNT",
+	-47: "E_INVALID_SIGNATURE_TYPE",
+	-48: "E_DISABLED",
+	-49: "E_PROXY_ALREADY_DEFINED",
+	-50: "E_NONZERO_UNLOCK_TIME",
+	-51: "E_IS_BACKGROUND_WALLET",
+	-52: "E_IS_BACKGROUND_SYNCING",
 }
 
-func (we *WalletError) Error() string {
-	return fmt.Sprintf("%v: %v", we.Code, we.Message)
+// String returns the wallet_rpc_server_error_codes.h name of the code.
+func (code ErrorCode) String() string {
+	if name, ok := errorCodeNames[code]; ok {
+		return name
+	}
+	return fmt.Sprintf("ErrorCode(%d)", int(code))
 }
 
-// GetWalletError checks if an erro interface is a wallet-rpc error.
-func GetWalletError(err error) (isWalletError bool, werr *WalletError) {
-	if err == nil {
-		return false, nil
-	}
-	gerr, ok := err.(*json2.Error)
-	if !ok {
-		return false, nil
-	}
-	werr = &WalletError{
-		Code:    ErrorCode(gerr.Code),
-		Message: gerr.Message,
-	}
-	isWalletError = true
-	return
+// Error makes ErrorCode usable as an errors.Is target:
+// errors.Is(err, wallet.ErrNotEnoughMoney) is true for a *WalletError
+// carrying that code.
+func (code ErrorCode) Error() string {
+	return code.String()
 }
 
 // Priority represents a transaction priority
@@ -0,0 +1,106 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/gorilla/rpc/v2/json2"
+)
+
+// Sentinel errors for use with errors.Is. Every failure to reach
+// monero-wallet-rpc or error reported by it matches exactly one of them.
+// Context cancellation and failures to encode a request or decode a
+// response match none.
+var (
+	// ErrTransport matches failures to reach monero-wallet-rpc at all.
+	ErrTransport = errors.New("wallet: transport error")
+	// ErrHTTPStatus matches non-200 HTTP responses.
+	ErrHTTPStatus = errors.New("wallet: unexpected http status")
+	// ErrRPC matches JSON-RPC error objects returned by monero-wallet-rpc.
+	ErrRPC = errors.New("wallet: rpc error")
+)
+
+// TransportError wraps a failure to deliver the request or read the
+// response, such as a refused or reset connection.
+type TransportError struct {
+	Err error
+}
+
+func (e *TransportError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrTransport, or context.DeadlineExceeded
+// if the request timed out, for example after Config.Timeout.
+func (e *TransportError) Is(target error) bool {
+	return target == ErrTransport || target == context.DeadlineExceeded && e.Timeout()
+}
+
+// Timeout reports whether the request timed out.
+func (e *TransportError) Timeout() bool {
+	var nerr net.Error
+	return errors.As(e.Err, &nerr) && nerr.Timeout()
+}
+
+// HTTPStatusError is returned when monero-wallet-rpc answers with a status
+// other than 200 OK, for example 401 when the credentials are wrong.
+type HTTPStatusError struct {
+	StatusCode int
+	// Body holds (up to 64KiB of) the response body.
+	Body []byte
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("http status %v", e.StatusCode)
+}
+
+// Is reports whether target is ErrHTTPStatus.
+func (e *HTTPStatusError) Is(target error) bool {
+	return target == ErrHTTPStatus
+}
+
+// WalletError is the error structured returned by the monero-wallet-rpc
+type WalletError struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+}
+
+func (we *WalletError) Error() string {
+	return fmt.Sprintf("%d: %v", int(we.Code), we.Message)
+}
+
+// Is reports whether target is ErrRPC or the ErrorCode carried by we, so
+// that errors.Is(err, wallet.ErrNotEnoughMoney) works.
+func (we *WalletError) Is(target error) bool {
+	if target == ErrRPC {
+		return true
+	}
+	code, ok := target.(ErrorCode)
+	return ok && code == we.Code
+}
+
+// GetWalletError checks if an erro interface is a wallet-rpc error.
+func GetWalletError(err error) (isWalletError bool, werr *WalletError) {
+	if err == nil {
+		return false, nil
+	}
+	if errors.As(err, &werr) {
+		return true, werr
+	}
+	var gerr *json2.Error
+	if !errors.As(err, &gerr) {
+		return false, nil
+	}
+	werr = &WalletError{
+		Code:    ErrorCode(gerr.Code),
+		Message: gerr.Message,
+	}
+	isWalletError = true
+	return
+}
@@ -0,0 +1,52 @@
+package wallet
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/rpc/v2/json2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/unauthorized":
+			http.Error(w, "denied", http.StatusUnauthorized)
+		default:
+			w.Write([]byte(`{"id":0,"jsonrpc":"2.0","error":{"code":-37,"message":"not enough unlocked money"}}`))
+		}
+	}))
+
+	_, err := New(Config{Address: srv.URL + "/json_rpc"}).Transfer(&RequestTransfer{})
+	assert.True(t, errors.Is(err, ErrRPC))
+	assert.True(t, errors.Is(err, ErrNotEnoughUnlockedMoney))
+	assert.False(t, errors.Is(err, ErrNotEnoughMoney))
+	var werr *WalletError
+	assert.True(t, errors.As(err, &werr))
+	assert.Equal(t, "not enough unlocked money", werr.Message)
+	assert.Equal(t, "E_NOT_ENOUGH_UNLOCKED_MONEY", werr.Code.String())
+
+	_, err = New(Config{Address: srv.URL + "/unauthorized"}).GetHeight()
+	assert.True(t, errors.Is(err, ErrHTTPStatus))
+	var serr *HTTPStatusError
+	assert.True(t, errors.As(err, &serr))
+	assert.Equal(t, http.StatusUnauthorized, serr.StatusCode)
+	assert.Equal(t, "denied\n", string(serr.Body))
+
+	srv.Close()
+	_, err = New(Config{Address: srv.URL + "/json_rpc"}).GetHeight()
+	assert.True(t, errors.Is(err, ErrTransport))
+	assert.False(t, errors.Is(err, ErrRPC))
+}
+
+func TestGetWalletError(t *testing.T) {
+	ok, werr := GetWalletError(&json2.Error{Code: -13, Message: "No wallet file"})
+	assert.True(t, ok)
+	assert.Equal(t, ErrNotOpen, werr.Code)
+
+	ok, _ = GetWalletError(errors.New("boom"))
+	assert.False(t, ok)
+}
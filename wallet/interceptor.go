@@ -0,0 +1,26 @@
+package wallet
+
+import "context"
+
+// Invoker performs a single RPC call: it sends params to method and decodes
+// the reply into result. result is a pointer to the method's response (for
+// example **ResponseGetBalance) or nil for methods without a reply.
+type Invoker func(ctx context.Context, method string, params, result interface{}) error
+
+// Interceptor wraps every RPC call made by the client. It sees the RPC
+// method name and the request params before the call and the decoded
+// result after next returns. An interceptor may modify ctx, short-circuit
+// the call by not calling next, or replace the returned error.
+type Interceptor func(ctx context.Context, method string, params, result interface{}, next Invoker) error
+
+// chainInterceptors composes interceptors around final. The first
+// interceptor is the outermost one.
+func chainInterceptors(interceptors []Interceptor, final Invoker) Invoker {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], final
+		final = func(ctx context.Context, method string, params, result interface{}) error {
+			return interceptor(ctx, method, params, result, next)
+		}
+	}
+	return final
+}
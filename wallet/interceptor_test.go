@@ -0,0 +1,62 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterceptors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":0,"jsonrpc":"2.0","result":{"balance":10,"unlocked_balance":5}}`))
+	}))
+	defer srv.Close()
+
+	var order []string
+	trace := func(name string) Interceptor {
+		return func(ctx context.Context, method string, params, result interface{}, next Invoker) error {
+			order = append(order, name+">"+method)
+			err := next(ctx, method, params, result)
+			order = append(order, name+"<"+method)
+			return err
+		}
+	}
+	var seen *ResponseGetBalance
+	inspect := func(ctx context.Context, method string, params, result interface{}, next Invoker) error {
+		req, ok := params.(*RequestGetBalance)
+		assert.True(t, ok)
+		assert.Equal(t, uint64(3), req.AccountIndex)
+		err := next(ctx, method, params, result)
+		seen = *result.(**ResponseGetBalance)
+		return err
+	}
+
+	cl := New(Config{
+		Address:      srv.URL + "/json_rpc",
+		Interceptors: []Interceptor{trace("a"), trace("b"), inspect},
+	})
+	res, err := cl.GetBalance(&RequestGetBalance{AccountIndex: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), res.Balance)
+	assert.Equal(t, res, seen)
+	assert.Equal(t, []string{"a>get_balance", "b>get_balance", "b<get_balance", "a<get_balance"}, order)
+}
+
+func TestInterceptorShortCircuit(t *testing.T) {
+	denied := errors.New("denied by policy")
+	cl := New(Config{
+		Address: "http://127.0.0.1:0/json_rpc",
+		Interceptors: []Interceptor{func(ctx context.Context, method string, params, result interface{}, next Invoker) error {
+			if method == "transfer" {
+				return denied
+			}
+			return next(ctx, method, params, result)
+		}},
+	})
+	_, err := cl.Transfer(&RequestTransfer{})
+	assert.Equal(t, denied, err)
+}
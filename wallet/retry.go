@@ -0,0 +1,162 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/boomhut/go-monero-rpc-client/internal/retry"
+)
+
+// RetryPolicy configures how the client retries failed calls.
+//
+// Only methods classified as idempotent by IsIdempotent are retried.
+// Methods that move funds or change wallet state in a way that cannot be
+// safely repeated (transfer, sweep_all, relay_tx, submit_transfer, ...)
+// are attempted exactly once unless they are listed in UnsafeMethods.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first one.
+	// Values below 2 disable retrying.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry. Defaults to 100ms.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts. Defaults to 5s.
+	MaxBackoff time.Duration
+	// Multiplier is the exponential growth factor of the delay. Defaults to 2.
+	Multiplier float64
+	// Jitter is the fraction (0 to 1) of each delay that is randomized.
+	Jitter float64
+	// Retryable reports whether a failed attempt should be retried.
+	// DefaultRetryable is used when nil.
+	Retryable func(err error) bool
+	// UnsafeMethods lists non-idempotent RPC methods (e.g. "transfer") that
+	// the caller explicitly allows to be retried.
+	UnsafeMethods []string
+}
+
+// attempts returns how many times method may be attempted under p.
+func (p *RetryPolicy) attempts(method string) int {
+	if p == nil || p.MaxAttempts < 2 {
+		return 1
+	}
+	if IsIdempotent(method) {
+		return p.MaxAttempts
+	}
+	for _, m := range p.UnsafeMethods {
+		if m == method {
+			return p.MaxAttempts
+		}
+	}
+	return 1
+}
+
+func (p *RetryPolicy) retryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return DefaultRetryable(err)
+}
+
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	initial, max, multiplier := p.InitialBackoff, p.MaxBackoff, p.Multiplier
+	if initial <= 0 {
+		initial = 100 * time.Millisecond
+	}
+	if max <= 0 {
+		max = 5 * time.Second
+	}
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	return retry.Backoff(attempt, initial, max, multiplier, p.Jitter)
+}
+
+// DefaultRetryable retries transport failures (connection refused or reset,
+// timeouts, unexpected EOF), 502/503/504 responses and ErrDaemonIsBusy.
+// A request that timed out is retried even though it matches
+// context.DeadlineExceeded; cancellation or expiry of the caller's context
+// is never retried.
+func DefaultRetryable(err error) bool {
+	var terr *TransportError
+	if errors.As(err, &terr) && terr.Timeout() {
+		return true
+	}
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var serr *HTTPStatusError
+	if errors.As(err, &serr) {
+		switch serr.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	return errors.Is(err, ErrTransport) || errors.Is(err, ErrDaemonIsBusy)
+}
+
+// IsIdempotent reports whether the monero-wallet-rpc method can be repeated
+// without changing the outcome, and is therefore retried by a RetryPolicy.
+func IsIdempotent(method string) bool {
+	return idempotentMethods[method]
+}
+
+var idempotentMethods = map[string]bool{
+	"get_balance":                 true,
+	"get_address":                 true,
+	"get_address_index":           true,
+	"label_address":               true,
+	"validate_address":            true,
+	"get_accounts":                true,
+	"label_account":               true,
+	"get_account_tags":            true,
+	"tag_accounts":                true,
+	"untag_accounts":              true,
+	"set_account_tag_description": true,
+	"get_height":                  true,
+	"store":                       true,
+	"get_payments":                true,
+	"get_bulk_payments":           true,
+	"incoming_transfers":          true,
+	"query_key":                   true,
+	"make_integrated_address":     true,
+	"split_integrated_address":    true,
+	"set_tx_notes":                true,
+	"get_tx_notes":                true,
+	"set_attribute":               true,
+	"get_attribute":               true,
+	"get_tx_key":                  true,
+	"check_tx_key":                true,
+	"get_tx_proof":                true,
+	"check_tx_proof":              true,
+	"get_spend_proof":             true,
+	"check_spend_proof":           true,
+	"get_reserve_proof":           true,
+	"check_reserve_proof":         true,
+	"get_transfers":               true,
+	"get_transfer_by_txid":        true,
+	"sign":                        true,
+	"verify":                      true,
+	"export_outputs":              true,
+	"export_key_images":           true,
+	"make_uri":                    true,
+	"parse_uri":                   true,
+	"get_address_book":            true,
+	"edit_address_book":           true,
+	"refresh":                     true,
+	"rescan_spent":                true,
+	"get_languages":               true,
+	"is_multisig":                 true,
+	"export_multisig_info":        true,
+	"get_version":                 true,
+	"set_daemon":                  true,
+	"auto_refresh":                true,
+	"describe_transfer":           true,
+	"estimate_tx_size_and_weight": true,
+	"freeze":                      true,
+	"frozen":                      true,
+	"thaw":                        true,
+	"scan_tx":                     true,
+	"get_default_fee_priority":    true,
+}
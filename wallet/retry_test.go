@@ -0,0 +1,70 @@
+package wallet
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// busyServer answers with E_DAEMON_IS_BUSY until failures is exhausted.
+func busyServer(failures int32, calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(calls, 1) <= failures {
+			w.Write([]byte(`{"id":0,"jsonrpc":"2.0","error":{"code":-3,"message":"daemon is busy"}}`))
+			return
+		}
+		w.Write([]byte(`{"id":0,"jsonrpc":"2.0","result":{"height":42}}`))
+	}))
+}
+
+func TestRetryIdempotent(t *testing.T) {
+	var calls int32
+	srv := busyServer(2, &calls)
+	defer srv.Close()
+
+	cl := New(Config{
+		Address: srv.URL + "/json_rpc",
+		Retry:   &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	})
+	res, err := cl.GetHeight()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(42), res.Height)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestRetryUnsafe(t *testing.T) {
+	var calls int32
+	srv := busyServer(1, &calls)
+	defer srv.Close()
+
+	cl := New(Config{
+		Address: srv.URL + "/json_rpc",
+		Retry:   &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	})
+	_, err := cl.Transfer(&RequestTransfer{})
+	isWalletError, werr := GetWalletError(err)
+	assert.True(t, isWalletError)
+	assert.Equal(t, ErrDaemonIsBusy, werr.Code)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// Explicit opt-in.
+	atomic.StoreInt32(&calls, 0)
+	cl = New(Config{
+		Address: srv.URL + "/json_rpc",
+		Retry:   &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, UnsafeMethods: []string{"transfer"}},
+	})
+	_, err = cl.Transfer(&RequestTransfer{})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestIsIdempotent(t *testing.T) {
+	for _, m := range []string{"transfer", "transfer_split", "sweep_all", "sweep_single", "sweep_dust", "relay_tx", "submit_transfer", "submit_multisig", "create_address"} {
+		assert.False(t, IsIdempotent(m), m)
+	}
+	assert.True(t, IsIdempotent("get_balance"))
+}
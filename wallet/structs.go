@@ -571,22 +571,34 @@ type RequestIncomingTransfers struct {
 }
 type ResponseIncomingTransfers struct {
 	// list of transfers:
-	Transfers struct {
-		// Amount of this transfer.
-		Amount uint64 `json:"amount"`
-		// Mostly internal use, can be ignored by most users.
-		GlobalIndex uint64 `json:"global_index"`
-		// Key image for the incoming transfer's unspent output (empty unless verbose is true).
-		KeyImage string `json:"key_image"`
-		// Indicates if this transfer has been spent.
-		Spent bool `json:"spent"`
-		// Subaddress index for incoming transfer.
-		SubaddrIndex uint64 `json:"subaddr_index"`
-		// Several incoming transfers may share the same hash if they were in the same transaction.
-		TxHash string `json:"tx_hash"`
-		// Size of transaction in bytes.
-		TxSize uint64 `json:"tx_size"`
-	} `json:"transfers"`
+	Transfers []IncomingTransfer `json:"transfers"`
+}
+type IncomingTransfer struct {
+	// Amount of this transfer.
+	Amount uint64 `json:"amount"`
+	// Height of the block containing the transfer.
+	BlockHeight uint64 `json:"block_height"`
+	// Indicates if the output is frozen.
+	Frozen bool `json:"frozen"`
+	// Mostly internal use, can be ignored by most users.
+	GlobalIndex uint64 `json:"global_index"`
+	// Key image for the incoming transfer's unspent output (empty unless verbose is true).
+	KeyImage string `json:"key_image"`
+	// Public key of the output.
+	PubKey string `json:"pubkey"`
+	// Indicates if this transfer has been spent.
+	Spent bool `json:"spent"`
+	// Subaddress index for incoming transfer.
+	SubaddrIndex struct {
+		// Account index for the subaddress.
+		Major uint64 `json:"major"`
+		// Index of the subaddress in the account.
+		Minor uint64 `json:"minor"`
+	} `json:"subaddr_index"`
+	// Several incoming transfers may share the same hash if they were in the same transaction.
+	TxHash string `json:"tx_hash"`
+	// Indicates if the output can be spent.
+	Unlocked bool `json:"unlocked"`
 }
 
 // QueryKey()
@@ -0,0 +1,47 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/boomhut/go-monero-rpc-client/internal/instrument"
+	"github.com/boomhut/go-monero-rpc-client/telemetry"
+)
+
+// instrumentInterceptor reports every call to cfg.Tracer and cfg.Metrics.
+// It runs inside the user interceptors, so it describes the RPC as actually
+// sent, retries included.
+func instrumentInterceptor(cfg Config) Interceptor {
+	endpoint := instrument.Endpoint(cfg.Address)
+	return func(ctx context.Context, method string, params, result interface{}, next Invoker) error {
+		info := telemetry.CallInfo{
+			System:   telemetry.SystemWallet,
+			Method:   method,
+			Endpoint: endpoint,
+		}
+		return instrument.Call(ctx, cfg.Tracer, cfg.Metrics, info, classifyError, func(ctx context.Context) error {
+			return next(ctx, method, params, result)
+		})
+	}
+}
+
+func classifyError(info *telemetry.CallInfo) {
+	var (
+		herr *HTTPStatusError
+		werr *WalletError
+	)
+	switch {
+	case info.Err == nil:
+		info.HTTPStatusCode = http.StatusOK
+	case errors.As(info.Err, &werr):
+		info.HTTPStatusCode = http.StatusOK
+		info.ErrorCode = int(werr.Code)
+		info.ErrorType = telemetry.ErrorRPC
+	case errors.As(info.Err, &herr):
+		info.HTTPStatusCode = herr.StatusCode
+		info.ErrorType = telemetry.ErrorHTTP
+	case errors.Is(info.Err, ErrTransport) && info.ErrorType == "":
+		info.ErrorType = telemetry.ErrorTransport
+	}
+}
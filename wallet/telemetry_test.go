@@ -0,0 +1,81 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/boomhut/go-monero-rpc-client/telemetry"
+	"github.com/stretchr/testify/assert"
+)
+
+type testSpan struct {
+	name  string
+	attrs map[string]interface{}
+	err   error
+	ended bool
+}
+
+func (s *testSpan) SetAttributes(attrs ...telemetry.Attribute) {
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+}
+func (s *testSpan) RecordError(err error) { s.err = err }
+func (s *testSpan) End()                  { s.ended = true }
+
+type testTracer struct{ spans []*testSpan }
+
+func (t *testTracer) Start(ctx context.Context, name string) (context.Context, telemetry.Span) {
+	s := &testSpan{name: name, attrs: map[string]interface{}{}}
+	t.spans = append(t.spans, s)
+	return ctx, s
+}
+
+func TestTelemetry(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":0,"jsonrpc":"2.0","result":{"key":"secret seed words"}}`))
+	}))
+	defer srv.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":0,"jsonrpc":"2.0","error":{"code":-13,"message":"No wallet file"}}`))
+	}))
+	defer failing.Close()
+
+	tracer := &testTracer{}
+	metrics := telemetry.NewMetrics()
+	address := strings.Replace(srv.URL, "http://", "http://user:pass@", 1) + "/json_rpc"
+	cl := New(Config{Address: address, Tracer: tracer, Metrics: metrics})
+	res, err := cl.QueryKey(&RequestQueryKey{KeyType: "mnemonic"})
+	assert.NoError(t, err)
+	assert.Equal(t, "secret seed words", res.Key)
+
+	cl = New(Config{Address: failing.URL + "/json_rpc", Tracer: tracer, Metrics: metrics})
+	_, err = cl.GetBalance(&RequestGetBalance{})
+	assert.Error(t, err)
+
+	assert.Len(t, tracer.spans, 2)
+	span := tracer.spans[0]
+	assert.Equal(t, "monero-wallet-rpc/query_key", span.name)
+	assert.True(t, span.ended)
+	assert.Equal(t, "query_key", span.attrs[telemetry.AttrMethod])
+	assert.Equal(t, srv.URL+"/json_rpc", span.attrs[telemetry.AttrEndpoint])
+	assert.Equal(t, 200, span.attrs[telemetry.AttrHTTPStatus])
+	assert.NotContains(t, fmt.Sprint(span.attrs), "secret")
+	assert.NotContains(t, fmt.Sprint(span.attrs), "pass")
+
+	span = tracer.spans[1]
+	assert.Equal(t, int(ErrNotOpen), span.attrs[telemetry.AttrJSONRPCCode])
+	assert.Equal(t, telemetry.ErrorRPC, span.attrs[telemetry.AttrErrorType])
+	assert.Equal(t, "monero-wallet-rpc rpc error, error code -13", span.err.Error())
+
+	stats := metrics.Snapshot()
+	assert.Len(t, stats, 2)
+	assert.Equal(t, "get_balance", stats[0].Method)
+	assert.Equal(t, map[string]uint64{telemetry.ErrorRPC: 1}, stats[0].Errors)
+	assert.Equal(t, "query_key", stats[1].Method)
+	assert.Equal(t, uint64(1), stats[1].Calls)
+}
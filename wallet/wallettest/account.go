@@ -0,0 +1,585 @@
+package wallettest
+
+import (
+	"context"
+	"encoding/hex"
+	"sort"
+
+	"github.com/boomhut/go-monero-rpc-client/wallet"
+)
+
+// inBalance reports whether o counts towards the balance: unspent, not
+// frozen and either confirmed or change of one of our own transactions.
+func (w *Wallet) inBalance(o *output) bool {
+	return !o.spent && !o.frozen && (o.confirmed || o.change)
+}
+
+type balance struct {
+	total, unlocked, outputs, blocksToUnlock uint64
+}
+
+func (w *Wallet) balanceOf(f *file, major uint64, minor func(uint64) bool) balance {
+	var b balance
+	for _, o := range f.outputs {
+		if o.major != major || (minor != nil && !minor(o.minor)) || !w.inBalance(o) {
+			continue
+		}
+		b.total += o.amount
+		b.outputs++
+		if w.unlocked(o) {
+			b.unlocked += o.amount
+		} else if n := w.blocksToUnlock(o); n > b.blocksToUnlock {
+			b.blocksToUnlock = n
+		}
+	}
+	return b
+}
+
+func only(index uint64) func(uint64) bool {
+	return func(i uint64) bool { return i == index }
+}
+
+func (w *Wallet) GetBalanceContext(ctx context.Context, req *wallet.RequestGetBalance) (*wallet.ResponseGetBalance, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "get_balance")
+	if err != nil {
+		return nil, err
+	}
+	a, err := f.account(req.AccountIndex)
+	if err != nil {
+		return nil, err
+	}
+	indices := req.AddressIndices
+	if len(indices) == 0 {
+		for i := range a.subaddresses {
+			if w.balanceOf(f, req.AccountIndex, only(uint64(i))).outputs > 0 {
+				indices = append(indices, uint64(i))
+			}
+		}
+	}
+
+	b := w.balanceOf(f, req.AccountIndex, nil)
+	resp := &wallet.ResponseGetBalance{
+		Balance:         b.total,
+		UnlockedBalance: b.unlocked,
+		BlocksToUnlock:  b.blocksToUnlock,
+	}
+	resp.PerSubaddress = grow(resp.PerSubaddress, len(indices))
+	for n, i := range indices {
+		if i >= uint64(len(a.subaddresses)) {
+			return nil, rpcError(wallet.ErrAddressIndexOutOfBounds, "")
+		}
+		sb := w.balanceOf(f, req.AccountIndex, only(i))
+		ps := &resp.PerSubaddress[n]
+		ps.AddressIndex = i
+		ps.Address = a.subaddresses[i].address
+		ps.Label = a.subaddresses[i].label
+		ps.Balance = sb.total
+		ps.UnlockedBalance = sb.unlocked
+		ps.NumUnspentOutputs = sb.outputs
+		ps.BlocksToUnlock = int64(sb.blocksToUnlock)
+	}
+	return resp, nil
+}
+
+func (w *Wallet) GetAddressContext(ctx context.Context, req *wallet.RequestGetAddress) (*wallet.ResponseGetAddress, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "get_address")
+	if err != nil {
+		return nil, err
+	}
+	a, err := f.account(req.AccountIndex)
+	if err != nil {
+		return nil, err
+	}
+	indices := req.AddressIndex
+	if len(indices) == 0 {
+		for i := range a.subaddresses {
+			indices = append(indices, uint64(i))
+		}
+	}
+	resp := &wallet.ResponseGetAddress{Address: a.subaddresses[0].address}
+	resp.Addresses = grow(resp.Addresses, len(indices))
+	for n, i := range indices {
+		if i >= uint64(len(a.subaddresses)) {
+			return nil, rpcError(wallet.ErrAddressIndexOutOfBounds, "")
+		}
+		s := a.subaddresses[i]
+		resp.Addresses[n].Address = s.address
+		resp.Addresses[n].Label = s.label
+		resp.Addresses[n].AddressIndex = i
+		resp.Addresses[n].Used = f.used(req.AccountIndex, i)
+	}
+	return resp, nil
+}
+
+// used reports whether the subaddress ever received funds.
+func (f *file) used(major, minor uint64) bool {
+	for _, o := range f.outputs {
+		if o.major == major && o.minor == minor && !o.change {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Wallet) GetAddressIndexContext(ctx context.Context, req *wallet.RequestGetAddressIndex) (*wallet.ResponseGetAddressIndex, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "get_address_index")
+	if err != nil {
+		return nil, err
+	}
+	if addressKind(req.Address) == "" {
+		return nil, rpcError(wallet.ErrWrongAddress, "Invalid address")
+	}
+	major, minor, ok := f.find(req.Address)
+	if !ok {
+		return nil, rpcError(wallet.ErrWrongAddress, "Address doesn't belong to the wallet")
+	}
+	resp := &wallet.ResponseGetAddressIndex{}
+	resp.Index.Major = major
+	resp.Index.Minor = minor
+	return resp, nil
+}
+
+func (w *Wallet) CreateAddressContext(ctx context.Context, req *wallet.RequestCreateAddress) (*wallet.ResponseCreateAddress, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "create_address")
+	if err != nil {
+		return nil, err
+	}
+	a, err := f.account(req.AccountIndex)
+	if err != nil {
+		return nil, err
+	}
+	minor := f.addSubaddress(req.AccountIndex, req.Label)
+	return &wallet.ResponseCreateAddress{Address: a.subaddresses[minor].address, AddressIndex: minor}, nil
+}
+
+func (w *Wallet) LabelAddressContext(ctx context.Context, req *wallet.RequestLabelAddress) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "label_address")
+	if err != nil {
+		return err
+	}
+	a, err := f.account(req.Index.Major)
+	if err != nil {
+		return err
+	}
+	if req.Index.Minor >= uint64(len(a.subaddresses)) {
+		return rpcError(wallet.ErrAddressIndexOutOfBounds, "")
+	}
+	a.subaddresses[req.Index.Minor].label = req.Label
+	return nil
+}
+
+func (w *Wallet) ValidateAddressContext(ctx context.Context, req *wallet.RequestValidateAddress) (*wallet.ResponseValidateAddress, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.begin(ctx, "validate_address"); err != nil {
+		return nil, err
+	}
+	kind := addressKind(req.Address)
+	if kind == "" {
+		return &wallet.ResponseValidateAddress{}, nil
+	}
+	return &wallet.ResponseValidateAddress{
+		Valid:      true,
+		Integrated: kind == "integrated",
+		Subaddress: kind == "subaddress",
+		NetType:    "mainnet",
+	}, nil
+}
+
+func (w *Wallet) GetAccountsContext(ctx context.Context, req *wallet.RequestGetAccounts) (*wallet.ResponseGetAccounts, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "get_accounts")
+	if err != nil {
+		return nil, err
+	}
+	if req.Tag != "" && !f.hasTag(req.Tag) {
+		return nil, rpcError(wallet.ErrUnknown, "Tag %s is unregistered.", req.Tag)
+	}
+	resp := &wallet.ResponseGetAccounts{}
+	for i, a := range f.accounts {
+		if req.Tag != "" && a.tag != req.Tag {
+			continue
+		}
+		b := w.balanceOf(f, uint64(i), nil)
+		resp.SubaddressAccounts = grow(resp.SubaddressAccounts, 1)
+		sa := &resp.SubaddressAccounts[len(resp.SubaddressAccounts)-1]
+		sa.AccountIndex = uint64(i)
+		sa.BaseAddress = a.subaddresses[0].address
+		sa.Label = a.subaddresses[0].label
+		sa.Tag = a.tag
+		sa.Balance = b.total
+		sa.UnlockedBalance = b.unlocked
+		resp.TotalBalance += b.total
+		resp.TotalUnlockedBalance += b.unlocked
+	}
+	return resp, nil
+}
+
+func (f *file) hasTag(tag string) bool {
+	for _, a := range f.accounts {
+		if a.tag == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Wallet) CreateAccountContext(ctx context.Context, req *wallet.RequestCreateAccount) (*wallet.ResponseCreateAccount, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "create_account")
+	if err != nil {
+		return nil, err
+	}
+	a := f.addAccount(req.Label)
+	return &wallet.ResponseCreateAccount{
+		AccountIndex: uint64(len(f.accounts) - 1),
+		Address:      a.subaddresses[0].address,
+	}, nil
+}
+
+func (w *Wallet) LabelAccountContext(ctx context.Context, req *wallet.RequestLabelAccount) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "label_account")
+	if err != nil {
+		return err
+	}
+	a, err := f.account(req.AccountIndex)
+	if err != nil {
+		return err
+	}
+	a.label = req.Label
+	a.subaddresses[0].label = req.Label
+	return nil
+}
+
+func (w *Wallet) GetAccountTagsContext(ctx context.Context) (*wallet.ResponseGetAccountTags, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "get_account_tags")
+	if err != nil {
+		return nil, err
+	}
+	accounts := make(map[string][]uint64)
+	for i, a := range f.accounts {
+		if a.tag != "" {
+			accounts[a.tag] = append(accounts[a.tag], uint64(i))
+		}
+	}
+	tags := make([]string, 0, len(accounts))
+	for tag := range accounts {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	resp := &wallet.ResponseGetAccountTags{}
+	resp.AccountTags = grow(resp.AccountTags, len(tags))
+	for i, tag := range tags {
+		resp.AccountTags[i].Tag = tag
+		resp.AccountTags[i].Label = f.tagDesc[tag]
+		resp.AccountTags[i].Accounts = accounts[tag]
+	}
+	return resp, nil
+}
+
+func (w *Wallet) TagAccountsContext(ctx context.Context, req *wallet.RequestTagAccounts) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "tag_accounts")
+	if err != nil {
+		return err
+	}
+	return f.setTag(req.Accounts, req.Tag)
+}
+
+func (w *Wallet) UntagAccountsContext(ctx context.Context, req *wallet.RequestUntagAccounts) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "untag_accounts")
+	if err != nil {
+		return err
+	}
+	return f.setTag(req.Accounts, "")
+}
+
+func (f *file) setTag(accounts []uint64, tag string) error {
+	for _, i := range accounts {
+		if _, err := f.account(i); err != nil {
+			return err
+		}
+	}
+	for _, i := range accounts {
+		f.accounts[i].tag = tag
+	}
+	return nil
+}
+
+func (w *Wallet) SetAccountTagDescriptionContext(ctx context.Context, req *wallet.RequestSetAccountTagDescription) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "set_account_tag_description")
+	if err != nil {
+		return err
+	}
+	if !f.hasTag(req.Tag) {
+		return rpcError(wallet.ErrUnknown, "Tag %s is unregistered.", req.Tag)
+	}
+	f.tagDesc[req.Tag] = req.Description
+	return nil
+}
+
+func (w *Wallet) GetHeightContext(ctx context.Context) (*wallet.ResponseGetHeight, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.begin(ctx, "get_height"); err != nil {
+		return nil, err
+	}
+	return &wallet.ResponseGetHeight{Height: w.height}, nil
+}
+
+// view returns a copy of t as get_transfers reports it.
+func (w *Wallet) view(f *file, t *wallet.Transfer) wallet.Transfer {
+	v := *t
+	v.Confirmations = w.confirmations(t)
+	v.Note = f.notes[t.TxID]
+	if t.Type == "in" || t.Type == "pool" {
+		v.SuggestedConfirmationsThreshold = 1
+	}
+	return v
+}
+
+// matches reports whether t belongs to account major and, for incoming
+// transfers, to one of the subaddresses in minor.
+func matches(t *wallet.Transfer, major uint64, minor []uint64) bool {
+	if t.SubaddrIndex.Major != major {
+		return false
+	}
+	if len(minor) == 0 || t.Type == "out" || t.Type == "pending" {
+		return true
+	}
+	for _, i := range minor {
+		if t.SubaddrIndex.Minor == i {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Wallet) GetTransfersContext(ctx context.Context, req *wallet.RequestGetTransfers) (*wallet.ResponseGetTransfers, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "get_transfers")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.account(req.AccountIndex); err != nil {
+		return nil, err
+	}
+	maxHeight := req.MaxHeight
+	if maxHeight == 0 {
+		maxHeight = ^uint64(0)
+	}
+	resp := &wallet.ResponseGetTransfers{}
+	for _, t := range f.transfers {
+		if !matches(t, req.AccountIndex, req.SubaddrIndices) {
+			continue
+		}
+		confirmed := t.Type == "in" || t.Type == "out"
+		if confirmed && req.FilterByHeight && (t.Height <= req.MinHeight || t.Height > maxHeight) {
+			continue
+		}
+		v := w.view(f, t)
+		switch {
+		case t.Type == "in" && req.In:
+			resp.In = append(resp.In, &v)
+		case t.Type == "out" && req.Out:
+			resp.Out = append(resp.Out, &v)
+		case t.Type == "pending" && req.Pending:
+			resp.Pending = append(resp.Pending, &v)
+		case t.Type == "failed" && req.Failed:
+			resp.Failed = append(resp.Failed, &v)
+		case t.Type == "pool" && req.Pool:
+			resp.Pool = append(resp.Pool, &v)
+		}
+	}
+	return resp, nil
+}
+
+func validTxID(txid string) bool {
+	b, err := hex.DecodeString(txid)
+	return err == nil && len(b) == 32
+}
+
+func (w *Wallet) GetTransferByTxIDContext(ctx context.Context, req *wallet.RequestGetTransferByTxID) (*wallet.ResponseGetTransferByTxID, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "get_transfer_by_txid")
+	if err != nil {
+		return nil, err
+	}
+	if !validTxID(req.TxID) {
+		return nil, rpcError(wallet.ErrWrongTxID, "Transaction ID has invalid format")
+	}
+	if _, err := f.account(req.AccountIndex); err != nil {
+		return nil, err
+	}
+	resp := &wallet.ResponseGetTransferByTxID{}
+	for _, t := range f.transfers {
+		if t.TxID == req.TxID && t.SubaddrIndex.Major == req.AccountIndex {
+			resp.Transfers = append(resp.Transfers, w.view(f, t))
+		}
+	}
+	if len(resp.Transfers) == 0 {
+		return nil, rpcError(wallet.ErrWrongTxID, "")
+	}
+	resp.Transfer = resp.Transfers[0]
+	return resp, nil
+}
+
+func (w *Wallet) IncomingTransfersContext(ctx context.Context, req *wallet.RequestIncomingTransfers) (*wallet.ResponseIncomingTransfers, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "incoming_transfers")
+	if err != nil {
+		return nil, err
+	}
+	var keep func(*output) bool
+	switch req.TransferType {
+	case "all":
+		keep = func(*output) bool { return true }
+	case "available":
+		keep = func(o *output) bool { return !o.spent }
+	case "unavailable":
+		keep = func(o *output) bool { return o.spent }
+	default:
+		return nil, rpcError(wallet.ErrTransferType, "Transfer type must be one of: all, available, or unavailable")
+	}
+	if _, err := f.account(req.AccountIndex); err != nil {
+		return nil, err
+	}
+	in := make(map[uint64]bool, len(req.SubaddrIndices))
+	for _, i := range req.SubaddrIndices {
+		in[i] = true
+	}
+	resp := &wallet.ResponseIncomingTransfers{}
+	for _, o := range f.outputs {
+		if !o.confirmed || o.major != req.AccountIndex || (len(in) > 0 && !in[o.minor]) || !keep(o) {
+			continue
+		}
+		t := wallet.IncomingTransfer{
+			Amount:      o.amount,
+			BlockHeight: o.blockHeight,
+			Frozen:      o.frozen,
+			GlobalIndex: o.globalIndex,
+			KeyImage:    o.keyImage,
+			PubKey:      o.pubKey,
+			Spent:       o.spent,
+			TxHash:      o.txHash,
+			Unlocked:    w.unlocked(o),
+		}
+		t.SubaddrIndex.Major = o.major
+		t.SubaddrIndex.Minor = o.minor
+		resp.Transfers = append(resp.Transfers, t)
+	}
+	return resp, nil
+}
+
+func validPaymentID(pid string) bool {
+	b, err := hex.DecodeString(pid)
+	return err == nil && (len(b) == 8 || len(b) == 32)
+}
+
+// payments returns the confirmed incoming transfers with one of the payment
+// ids (all if none) above minHeight.
+func (w *Wallet) payments(f *file, paymentIDs []string, minHeight uint64) ([]*wallet.Transfer, error) {
+	want := make(map[string]bool, len(paymentIDs))
+	for _, pid := range paymentIDs {
+		if !validPaymentID(pid) {
+			return nil, rpcError(wallet.ErrWrongPaymentID, "Payment ID has invalid format")
+		}
+		want[pid] = true
+	}
+	var out []*wallet.Transfer
+	for _, t := range f.transfers {
+		if t.Type != "in" || t.Height <= minHeight || (len(want) > 0 && !want[t.PaymentID]) {
+			continue
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+func (w *Wallet) locked(t *wallet.Transfer) bool {
+	return w.height < t.Height+SpendableAge || w.height < t.UnlockTime
+}
+
+func (w *Wallet) GetPaymentsContext(ctx context.Context, req *wallet.RequestGetPayments) (*wallet.ResponseGetPayments, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "get_payments")
+	if err != nil {
+		return nil, err
+	}
+	if req.PaymentID == "" {
+		return nil, rpcError(wallet.ErrWrongPaymentID, "Payment ID has invalid format")
+	}
+	ts, err := w.payments(f, []string{req.PaymentID}, 0)
+	if err != nil {
+		return nil, err
+	}
+	resp := &wallet.ResponseGetPayments{}
+	resp.Payments = grow(resp.Payments, len(ts))
+	for i, t := range ts {
+		p := &resp.Payments[i]
+		p.PaymentID = t.PaymentID
+		p.TxHash = t.TxID
+		p.Amount = t.Amount
+		p.BlockHeight = t.Height
+		p.UnlockTime = t.UnlockTime
+		p.Locked = w.locked(t)
+		p.SubaddrIndex.Major = t.SubaddrIndex.Major
+		p.SubaddrIndex.Minor = t.SubaddrIndex.Minor
+		p.Address = t.Address
+	}
+	return resp, nil
+}
+
+func (w *Wallet) GetBulkPaymentsContext(ctx context.Context, req *wallet.RequestGetBulkPayments) (*wallet.ResponseGetBulkPayments, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "get_bulk_payments")
+	if err != nil {
+		return nil, err
+	}
+	ts, err := w.payments(f, req.PaymentIDs, req.MinBlockHeight)
+	if err != nil {
+		return nil, err
+	}
+	resp := &wallet.ResponseGetBulkPayments{}
+	resp.Payments = grow(resp.Payments, len(ts))
+	for i, t := range ts {
+		p := &resp.Payments[i]
+		p.PaymentID = t.PaymentID
+		p.TxHash = t.TxID
+		p.Amount = t.Amount
+		p.BlockHeight = t.Height
+		p.UnlockTime = t.UnlockTime
+		p.Locked = w.locked(t)
+		p.SubaddrIndex.Major = t.SubaddrIndex.Major
+		p.SubaddrIndex.Minor = t.SubaddrIndex.Minor
+		p.Address = t.Address
+	}
+	return resp, nil
+}
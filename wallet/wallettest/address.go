@@ -0,0 +1,111 @@
+package wallettest
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+)
+
+const (
+	alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+	addressLength    = 95
+	integratedLength = addressLength + 11
+)
+
+// fakeAddress returns a 95 character mainnet-looking address starting with
+// prefix ('4' for primary addresses, '8' for subaddresses).
+func fakeAddress(prefix byte, seed string) string {
+	var sb strings.Builder
+	sb.WriteByte(prefix)
+	for block := 0; sb.Len() < addressLength; block++ {
+		sum := sha256.Sum256([]byte(seed + "/" + string(rune('a'+block))))
+		for _, b := range sum {
+			if sb.Len() == addressLength {
+				break
+			}
+			sb.WriteByte(alphabet[int(b)%len(alphabet)])
+		}
+	}
+	return sb.String()
+}
+
+// subaddressFor returns the address at major/minor of the wallet with the
+// given primary address.
+func subaddressFor(primary string, major, minor uint64) string {
+	if major == 0 && minor == 0 {
+		return primary
+	}
+	buf := make([]byte, 16)
+	binary.BigEndian.PutUint64(buf, major)
+	binary.BigEndian.PutUint64(buf[8:], minor)
+	return fakeAddress('8', primary+"/"+hex.EncodeToString(buf))
+}
+
+func isBase58(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(alphabet, s[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// addressKind classifies address as "standard", "subaddress", "integrated"
+// or "" if it is not a valid address.
+func addressKind(address string) string {
+	if !isBase58(address) {
+		return ""
+	}
+	switch {
+	case len(address) == addressLength && address[0] == '4':
+		return "standard"
+	case len(address) == addressLength && address[0] == '8':
+		return "subaddress"
+	case len(address) == integratedLength && address[0] == '4':
+		if _, ok := decodeBlock(address[addressLength:]); ok {
+			return "integrated"
+		}
+	}
+	return ""
+}
+
+// integratedAddress appends the 8 byte payment id to address as one
+// base58 block of 11 characters.
+func integratedAddress(address, paymentID string) (string, bool) {
+	pid, err := hex.DecodeString(paymentID)
+	if err != nil || len(pid) != 8 {
+		return "", false
+	}
+	v := binary.BigEndian.Uint64(pid)
+	block := make([]byte, 11)
+	for i := len(block) - 1; i >= 0; i-- {
+		block[i] = alphabet[v%58]
+		v /= 58
+	}
+	return address + string(block), true
+}
+
+// splitIntegratedAddress is the inverse of integratedAddress.
+func splitIntegratedAddress(address string) (standard, paymentID string, ok bool) {
+	if addressKind(address) != "integrated" {
+		return "", "", false
+	}
+	v, _ := decodeBlock(address[addressLength:])
+	pid := make([]byte, 8)
+	binary.BigEndian.PutUint64(pid, v)
+	return address[:addressLength], hex.EncodeToString(pid), true
+}
+
+func decodeBlock(block string) (uint64, bool) {
+	var v uint64
+	for i := 0; i < len(block); i++ {
+		d := strings.IndexByte(alphabet, block[i])
+		if d < 0 || v > (^uint64(0)-uint64(d))/58 {
+			return 0, false
+		}
+		v = v*58 + uint64(d)
+	}
+	return v, true
+}
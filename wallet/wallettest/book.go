@@ -0,0 +1,214 @@
+package wallettest
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/boomhut/go-monero-rpc-client/wallet"
+)
+
+func (w *Wallet) GetAddressBookContext(ctx context.Context, req *wallet.RequestGetAddressBook) (*wallet.ResponseGetAddressBook, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "get_address_book")
+	if err != nil {
+		return nil, err
+	}
+	indices := req.Entries
+	if len(indices) == 0 {
+		for i := range f.addressBook {
+			indices = append(indices, uint64(i))
+		}
+	}
+	resp := &wallet.ResponseGetAddressBook{}
+	resp.Entries = grow(resp.Entries, len(indices))
+	for n, i := range indices {
+		if i >= uint64(len(f.addressBook)) {
+			return nil, rpcError(wallet.ErrWrongIndex, "Index out of range: %d", i)
+		}
+		e := f.addressBook[i]
+		resp.Entries[n].Address = e.address
+		resp.Entries[n].Description = e.description
+		resp.Entries[n].Index = i
+		resp.Entries[n].PaymentID = e.paymentID
+	}
+	return resp, nil
+}
+
+func checkBookAddress(address string) error {
+	if addressKind(address) == "" {
+		return rpcError(wallet.ErrWrongAddress, "WALLET_RPC_ERROR_CODE_WRONG_ADDRESS: %s", address)
+	}
+	return nil
+}
+
+func (w *Wallet) AddAddressBookContext(ctx context.Context, req *wallet.RequestAddAddressBook) (*wallet.ResponseAddAddressBook, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "add_address_book")
+	if err != nil {
+		return nil, err
+	}
+	if err := checkBookAddress(req.Address); err != nil {
+		return nil, err
+	}
+	if req.PaymentID != "" {
+		return nil, rpcError(wallet.ErrWrongPaymentID, "Standalone payment IDs are obsolete. Use subaddresses or integrated addresses instead")
+	}
+	f.addressBook = append(f.addressBook, &addressBookEntry{address: req.Address, description: req.Description})
+	return &wallet.ResponseAddAddressBook{Index: uint64(len(f.addressBook) - 1)}, nil
+}
+
+func (w *Wallet) EditAddressBookContext(ctx context.Context, req *wallet.RequestEditAddressBook) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "edit_address_book")
+	if err != nil {
+		return err
+	}
+	if req.Index >= uint64(len(f.addressBook)) {
+		return rpcError(wallet.ErrWrongIndex, "Index out of range: %d", req.Index)
+	}
+	e := *f.addressBook[req.Index]
+	if req.SetAddress {
+		if err := checkBookAddress(req.Address); err != nil {
+			return err
+		}
+		e.address = req.Address
+	}
+	if req.SetDescription {
+		e.description = req.Description
+	}
+	if req.SetPaymentID {
+		if req.PaymentID != "" && !validPaymentID(req.PaymentID) {
+			return rpcError(wallet.ErrWrongPaymentID, "Payment ID has invalid format")
+		}
+		e.paymentID = req.PaymentID
+	}
+	*f.addressBook[req.Index] = e
+	return nil
+}
+
+func (w *Wallet) DeleteAddressBookContext(ctx context.Context, req *wallet.RequestDeleteAddressBook) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "delete_address_book")
+	if err != nil {
+		return err
+	}
+	if req.Index >= uint64(len(f.addressBook)) {
+		return rpcError(wallet.ErrWrongIndex, "Index out of range: %d", req.Index)
+	}
+	f.addressBook = append(f.addressBook[:req.Index], f.addressBook[req.Index+1:]...)
+	return nil
+}
+
+// formatAmount prints atomic units like cryptonote::print_money.
+func formatAmount(amount uint64) string {
+	return fmt.Sprintf("%d.%012d", amount/1e12, amount%1e12)
+}
+
+// parseAmount parses a decimal XMR amount into atomic units exactly.
+func parseAmount(s string) (uint64, bool) {
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	if len(frac) > 12 {
+		return 0, false
+	}
+	w, err := strconv.ParseUint(whole, 10, 64)
+	if err != nil || w > (^uint64(0))/1e12 {
+		return 0, false
+	}
+	var f uint64
+	if frac != "" {
+		if f, err = strconv.ParseUint(frac+strings.Repeat("0", 12-len(frac)), 10, 64); err != nil {
+			return 0, false
+		}
+	}
+	return w*1e12 + f, true
+}
+
+func (w *Wallet) MakeURIContext(ctx context.Context, req *wallet.RequestMakeURI) (*wallet.ResponseMakeURI, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.begin(ctx, "make_uri"); err != nil {
+		return nil, err
+	}
+	if addressKind(req.Address) == "" {
+		return nil, rpcError(wallet.ErrWrongURI, "Cannot make URI from supplied parameters: Failed to parse address")
+	}
+	if req.PaymentID != "" && !validPaymentID(req.PaymentID) {
+		return nil, rpcError(wallet.ErrWrongURI, "Cannot make URI from supplied parameters: Invalid payment ID")
+	}
+	var params []string
+	if req.PaymentID != "" {
+		params = append(params, "tx_payment_id="+req.PaymentID)
+	}
+	if req.Amount > 0 {
+		params = append(params, "tx_amount="+formatAmount(req.Amount))
+	}
+	if req.RecipientName != "" {
+		params = append(params, "recipient_name="+url.QueryEscape(req.RecipientName))
+	}
+	if req.TxDescription != "" {
+		params = append(params, "tx_description="+url.QueryEscape(req.TxDescription))
+	}
+	uri := "monero:" + req.Address
+	if len(params) > 0 {
+		uri += "?" + strings.Join(params, "&")
+	}
+	return &wallet.ResponseMakeURI{URI: uri}, nil
+}
+
+func (w *Wallet) ParseURIContext(ctx context.Context, req *wallet.RequestParseURI) (*wallet.ResponseParseURI, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.begin(ctx, "parse_uri"); err != nil {
+		return nil, err
+	}
+	rest := strings.TrimPrefix(req.URI, "monero:")
+	if rest == req.URI {
+		return nil, rpcError(wallet.ErrWrongURI, "URI has wrong scheme (expected \"monero:\"): %s", req.URI)
+	}
+	address, query, _ := strings.Cut(rest, "?")
+	if addressKind(address) == "" {
+		return nil, rpcError(wallet.ErrWrongURI, "URI has wrong address: %s", address)
+	}
+	resp := &wallet.ResponseParseURI{}
+	resp.URI.Address = address
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, rpcError(wallet.ErrWrongURI, "Error parsing URI: %v", err)
+	}
+	for key := range values {
+		value := values.Get(key)
+		switch key {
+		case "tx_amount":
+			amount, ok := parseAmount(value)
+			if !ok {
+				return nil, rpcError(wallet.ErrWrongURI, "Invalid amount: %s", value)
+			}
+			resp.URI.Amount = amount
+		case "tx_payment_id":
+			if !validPaymentID(value) {
+				return nil, rpcError(wallet.ErrWrongURI, "Invalid payment ID: %s", value)
+			}
+			resp.URI.PaymentID = value
+		case "recipient_name":
+			resp.URI.RecipientName = value
+		case "tx_description":
+			resp.URI.TxDescription = value
+		default:
+			return nil, rpcError(wallet.ErrWrongURI, "Unknown parameter: %s", key)
+		}
+	}
+	return resp, nil
+}
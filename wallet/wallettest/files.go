@@ -0,0 +1,390 @@
+package wallettest
+
+import (
+	"context"
+
+	"github.com/boomhut/go-monero-rpc-client/wallet"
+)
+
+// languages are the seed languages of monero-wallet-rpc.
+var languages = []string{
+	"German", "English", "Spanish", "French", "Italian", "Dutch", "Portuguese",
+	"Russian", "Japanese", "Chinese_Simplified", "Esperanto", "Lojban",
+}
+
+func validLanguage(language string) bool {
+	if language == "" {
+		return true
+	}
+	for _, l := range languages {
+		if l == language {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Wallet) CreateWalletContext(ctx context.Context, req *wallet.RequestCreateWallet) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.begin(ctx, "create_wallet"); err != nil {
+		return err
+	}
+	if req.Filename == "" {
+		return rpcError(wallet.ErrUnknown, "Invalid filename")
+	}
+	if !validLanguage(req.Language) {
+		return rpcError(wallet.ErrUnknown, "Unknown language: %s", req.Language)
+	}
+	if _, ok := w.files[req.Filename]; ok {
+		return rpcError(wallet.ErrWalletAlreadyExists, "Wallet already exists.")
+	}
+	w.open = w.newFile(req.Filename, req.Password, fakeAddress('4', w.hash("wallet")))
+	return nil
+}
+
+func (w *Wallet) GenerateFromKeysContext(ctx context.Context, req *wallet.RequestGenerateFromKeys) (*wallet.ResponseGenerateFromKeys, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.begin(ctx, "generate_from_keys"); err != nil {
+		return nil, err
+	}
+	if req.Filename == "" {
+		return nil, rpcError(wallet.ErrUnknown, "Invalid filename")
+	}
+	if _, ok := w.files[req.Filename]; ok {
+		return nil, rpcError(wallet.ErrWalletAlreadyExists, "Wallet already exists.")
+	}
+	if addressKind(req.Address) != "standard" {
+		return nil, rpcError(wallet.ErrWrongAddress, "Failed to parse public address")
+	}
+	if req.ViewKey != viewKey(req.Address) {
+		return nil, rpcError(wallet.ErrUnknown, "view key does not match standard address")
+	}
+	if req.SpendKey != "" && req.SpendKey != spendKey(req.Address) {
+		return nil, rpcError(wallet.ErrUnknown, "spend key does not match standard address")
+	}
+	f := w.newFile(req.Filename, req.Password, req.Address)
+	f.viewOnly = req.SpendKey == ""
+	w.open = f
+	info := "Wallet has been generated successfully."
+	if f.viewOnly {
+		info = "Watch-only wallet has been generated successfully."
+	}
+	return &wallet.ResponseGenerateFromKeys{Address: req.Address, Info: info}, nil
+}
+
+func (w *Wallet) OpenWalletContext(ctx context.Context, req *wallet.RequestOpenWallet) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.begin(ctx, "open_wallet"); err != nil {
+		return err
+	}
+	f, ok := w.files[req.Filename]
+	if !ok {
+		return rpcError(wallet.ErrUnknown, "Failed to open wallet")
+	}
+	if f.password != req.Password {
+		return rpcError(wallet.ErrInvalidPassword, "Invalid password.")
+	}
+	w.open = f
+	return nil
+}
+
+func (w *Wallet) CloseWalletContext(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.begin(ctx, "close_wallet"); err != nil {
+		return err
+	}
+	w.open.backgroundSyncing = false
+	w.open = nil
+	return nil
+}
+
+func (w *Wallet) StopWalletContext(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.begin(ctx, "stop_wallet"); err != nil {
+		return err
+	}
+	w.open = nil
+	return nil
+}
+
+func (w *Wallet) StoreContext(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err := w.begin(ctx, "store")
+	return err
+}
+
+func (w *Wallet) ChangeWalletPasswordContext(ctx context.Context, req *wallet.RequestChangeWalletPassword) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "change_wallet_password")
+	if err != nil {
+		return err
+	}
+	if f.password != req.OldPassword {
+		return rpcError(wallet.ErrInvalidPassword, "Invalid original password.")
+	}
+	f.password = req.NewPassword
+	return nil
+}
+
+func (w *Wallet) RefreshContext(ctx context.Context, req *wallet.RequestRefresh) (*wallet.ResponseRefresh, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "refresh")
+	if err != nil {
+		return nil, err
+	}
+	from := f.lastRefresh
+	if req.StartHeight > 0 && req.StartHeight < from {
+		from = req.StartHeight
+	}
+	resp := &wallet.ResponseRefresh{}
+	if w.height > from {
+		resp.BlocksFetched = w.height - from
+	}
+	for _, t := range f.transfers {
+		if t.Type == "in" && t.Height >= from {
+			resp.ReceivedMoney = true
+		}
+	}
+	f.lastRefresh = w.height
+	return resp, nil
+}
+
+func (w *Wallet) RescanBlockchainContext(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "rescan_blockchain")
+	if err != nil {
+		return err
+	}
+	// Like the real wallet, forget what cannot be recovered from the chain.
+	f.txKeys = make(map[string]string)
+	f.notes = make(map[string]string)
+	for _, t := range f.transfers {
+		if t.Type == "out" || t.Type == "pending" {
+			t.Destinations = nil
+		}
+	}
+	return nil
+}
+
+func (w *Wallet) RescanSpentContext(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err := w.begin(ctx, "rescan_spent")
+	return err
+}
+
+func (w *Wallet) ScanTxContext(ctx context.Context, req *wallet.RequestScanTx) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.begin(ctx, "scan_tx"); err != nil {
+		return err
+	}
+	for _, txid := range req.TxIDs {
+		if !validTxID(txid) {
+			return rpcError(wallet.ErrWrongTxID, "Invalid txid specified")
+		}
+	}
+	return nil
+}
+
+func (w *Wallet) SetTxNotesContext(ctx context.Context, req *wallet.RequestSetTxNotes) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "set_tx_notes")
+	if err != nil {
+		return err
+	}
+	if len(req.TxIDs) != len(req.Notes) {
+		return rpcError(wallet.ErrUnknown, "Different amount of txids and notes")
+	}
+	for _, txid := range req.TxIDs {
+		if !validTxID(txid) {
+			return rpcError(wallet.ErrWrongTxID, "TX ID has invalid format")
+		}
+	}
+	for i, txid := range req.TxIDs {
+		f.notes[txid] = req.Notes[i]
+	}
+	return nil
+}
+
+func (w *Wallet) GetTxNotesContext(ctx context.Context, req *wallet.RequestGetTxNotes) (*wallet.ResponseGetTxNotes, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "get_tx_notes")
+	if err != nil {
+		return nil, err
+	}
+	resp := &wallet.ResponseGetTxNotes{Notes: make([]string, len(req.TxIDs))}
+	for i, txid := range req.TxIDs {
+		if !validTxID(txid) {
+			return nil, rpcError(wallet.ErrWrongTxID, "TX ID has invalid format")
+		}
+		resp.Notes[i] = f.notes[txid]
+	}
+	return resp, nil
+}
+
+func (w *Wallet) SetAttributeContext(ctx context.Context, req *wallet.RequestSetAttribute) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "set_attribute")
+	if err != nil {
+		return err
+	}
+	f.attributes[req.Key] = req.Value
+	return nil
+}
+
+func (w *Wallet) GetAttributeContext(ctx context.Context, req *wallet.RequestGetAttribute) (*wallet.ResponseGetAttribute, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "get_attribute")
+	if err != nil {
+		return nil, err
+	}
+	value, ok := f.attributes[req.Key]
+	if !ok {
+		return nil, rpcError(wallet.ErrAttributeNotFound, "")
+	}
+	return &wallet.ResponseGetAttribute{Value: value}, nil
+}
+
+func (w *Wallet) GetVersionContext(ctx context.Context) (*wallet.ResponseGetVersion, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.begin(ctx, "get_version"); err != nil {
+		return nil, err
+	}
+	return &wallet.ResponseGetVersion{Version: 1<<16 | 27}, nil
+}
+
+func (w *Wallet) GetLanguagesContext(ctx context.Context) (*wallet.ResponseGetLanguages, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.begin(ctx, "get_languages"); err != nil {
+		return nil, err
+	}
+	return &wallet.ResponseGetLanguages{Languages: append([]string(nil), languages...)}, nil
+}
+
+func (w *Wallet) SetDaemonContext(ctx context.Context, req *wallet.RequestSetDaemon) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.begin(ctx, "set_daemon"); err != nil {
+		return err
+	}
+	w.daemon = *req
+	return nil
+}
+
+func (w *Wallet) AutoRefreshContext(ctx context.Context, req *wallet.RequestAutoRefresh) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "auto_refresh")
+	if err != nil {
+		return err
+	}
+	f.autoRefresh = req.Enable
+	return nil
+}
+
+func (w *Wallet) StartMiningContext(ctx context.Context, req *wallet.RequestStartMining) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "start_mining")
+	if err != nil {
+		return err
+	}
+	if f.viewOnly {
+		return rpcError(wallet.ErrWatchOnly, "command not supported by watch-only wallet")
+	}
+	if req.ThreadsCount == 0 {
+		return rpcError(wallet.ErrUnknown, "Invalid number of threads")
+	}
+	w.mining = true
+	return nil
+}
+
+func (w *Wallet) StopMiningContext(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.begin(ctx, "stop_mining"); err != nil {
+		return err
+	}
+	w.mining = false
+	return nil
+}
+
+func (w *Wallet) GetDefaultFeePriorityContext(ctx context.Context) (*wallet.ResponseGetDefaultFeePriority, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.begin(ctx, "get_default_fee_priority"); err != nil {
+		return nil, err
+	}
+	return &wallet.ResponseGetDefaultFeePriority{Priority: wallet.PriorityUnimportant}, nil
+}
+
+func (w *Wallet) SetupBackgroundSyncContext(ctx context.Context, req *wallet.RequestSetupBackgroundSync) (*wallet.ResponseSetupBackgroundSync, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "setup_background_sync")
+	if err != nil {
+		return nil, err
+	}
+	switch req.BackgroundSyncType {
+	case "off", "reuse-wallet-password":
+	case "custom-background-password":
+		if req.BackgroundCachePassword == "" {
+			return nil, rpcError(wallet.ErrUnknown, "Must provide a background cache password")
+		}
+	default:
+		return nil, rpcError(wallet.ErrUnknown, "Unknown background sync type %s", req.BackgroundSyncType)
+	}
+	if f.multisig {
+		return nil, rpcError(wallet.ErrUnknown, "Background sync not implemented for multisig wallets")
+	}
+	if f.password != req.WalletPassword {
+		return nil, rpcError(wallet.ErrInvalidPassword, "Invalid password.")
+	}
+	f.backgroundSync = req.BackgroundSyncType
+	return &wallet.ResponseSetupBackgroundSync{}, nil
+}
+
+func (w *Wallet) StartBackgroundSyncContext(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "start_background_sync")
+	if err != nil {
+		return err
+	}
+	if f.backgroundSync == "" || f.backgroundSync == "off" {
+		return rpcError(wallet.ErrUnknown, "Background sync is not enabled")
+	}
+	f.backgroundSyncing = true
+	return nil
+}
+
+func (w *Wallet) StopBackgroundSyncContext(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "stop_background_sync")
+	if err != nil {
+		return err
+	}
+	if !f.backgroundSyncing {
+		return rpcError(wallet.ErrUnknown, "Wallet is not background syncing")
+	}
+	f.backgroundSyncing = false
+	f.lastRefresh = w.height
+	return nil
+}
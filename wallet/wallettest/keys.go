@@ -0,0 +1,550 @@
+package wallettest
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/boomhut/go-monero-rpc-client/wallet"
+)
+
+var seedWords = []string{
+	"abbey", "acid", "adapt", "aged", "agile", "aisle", "album", "algebra",
+	"amaze", "anchor", "apart", "arena", "argue", "atlas", "awful", "axis",
+	"badge", "bakery", "banjo", "basin", "beer", "bias", "binocular", "bluntly",
+	"bobsled", "boxes", "bubble", "buzzer", "cabin", "cactus", "camp", "cease",
+}
+
+func viewKey(address string) string  { return hashOf("view key", address) }
+func spendKey(address string) string { return hashOf("spend key", address) }
+
+// mnemonic returns the fake 25 word seed of the wallet with address.
+func mnemonic(address string) string {
+	sum, _ := hex.DecodeString(hashOf("mnemonic", address))
+	words := make([]string, 25)
+	for i := 0; i < 24; i++ {
+		words[i] = seedWords[int(sum[i])%len(seedWords)]
+	}
+	words[24] = words[int(sum[24])%24]
+	return strings.Join(words, " ")
+}
+
+func (w *Wallet) QueryKeyContext(ctx context.Context, req *wallet.RequestQueryKey) (*wallet.ResponseQueryKey, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "query_key")
+	if err != nil {
+		return nil, err
+	}
+	switch req.KeyType {
+	case "mnemonic":
+		if f.viewOnly {
+			return nil, rpcError(wallet.ErrWatchOnly, "The wallet is watch-only. Cannot display seed.")
+		}
+		return &wallet.ResponseQueryKey{Key: mnemonic(f.address)}, nil
+	case "view_key":
+		return &wallet.ResponseQueryKey{Key: viewKey(f.address)}, nil
+	case "spend_key":
+		if f.viewOnly {
+			return nil, rpcError(wallet.ErrWatchOnly, "The wallet is watch-only. Cannot retrieve spend key.")
+		}
+		return &wallet.ResponseQueryKey{Key: spendKey(f.address)}, nil
+	}
+	return nil, rpcError(wallet.ErrUnknown, "key_type %s not found", req.KeyType)
+}
+
+func (w *Wallet) MakeIntegratedAddressContext(ctx context.Context, req *wallet.RequestMakeIntegratedAddress) (*wallet.ResponseMakeIntegratedAddress, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "make_integrated_address")
+	if err != nil {
+		return nil, err
+	}
+	address := req.StandardAddress
+	if address == "" {
+		address = f.address
+	}
+	switch addressKind(address) {
+	case "standard":
+	case "subaddress":
+		return nil, rpcError(wallet.ErrWrongAddress, "Subaddress shouldn't be used")
+	case "integrated":
+		return nil, rpcError(wallet.ErrWrongAddress, "Already integrated address")
+	default:
+		return nil, rpcError(wallet.ErrWrongAddress, "Invalid address")
+	}
+	pid := req.PaymentID
+	if pid == "" {
+		pid = w.hash("payment id")[:16]
+	}
+	integrated, ok := integratedAddress(address, pid)
+	if !ok {
+		return nil, rpcError(wallet.ErrWrongPaymentID, "Invalid payment ID")
+	}
+	return &wallet.ResponseMakeIntegratedAddress{IntegratedAddress: integrated, PaymentID: pid}, nil
+}
+
+func (w *Wallet) SplitIntegratedAddressContext(ctx context.Context, req *wallet.RequestSplitIntegratedAddress) (*wallet.ResponseSplitIntegratedAddress, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.begin(ctx, "split_integrated_address"); err != nil {
+		return nil, err
+	}
+	if addressKind(req.IntegratedAddress) == "" {
+		return nil, rpcError(wallet.ErrWrongAddress, "Invalid address")
+	}
+	standard, pid, ok := splitIntegratedAddress(req.IntegratedAddress)
+	if !ok {
+		return nil, rpcError(wallet.ErrWrongAddress, "Address is not an integrated address")
+	}
+	return &wallet.ResponseSplitIntegratedAddress{StandardAddress: standard, PaymentID: pid}, nil
+}
+
+func (w *Wallet) GetTxKeyContext(ctx context.Context, req *wallet.RequestGetTxKey) (*wallet.ResponseGetTxKey, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "get_tx_key")
+	if err != nil {
+		return nil, err
+	}
+	if !validTxID(req.TxID) {
+		return nil, rpcError(wallet.ErrWrongTxID, "TX ID has invalid format")
+	}
+	key, ok := f.txKeys[req.TxID]
+	if !ok {
+		return nil, rpcError(wallet.ErrNoTxKey, "No tx secret key is stored for this tx")
+	}
+	return &wallet.ResponseGetTxKey{TxKey: key}, nil
+}
+
+// received sums what txid paid to address and reports the transaction's
+// confirmations, as a daemon-backed check would find them. found is false
+// if no wallet file knows the transaction.
+func (w *Wallet) received(txid, address string) (amount, confirmations uint64, inPool, found bool) {
+	for _, name := range w.fileNames() {
+		f := w.files[name]
+		major, minor, owner := f.find(address)
+		for _, t := range f.transfers {
+			if t.TxID != txid {
+				continue
+			}
+			found = true
+			inPool = t.Type == "pool" || t.Type == "pending"
+			confirmations = w.confirmations(t)
+			if owner && (t.Type == "in" || t.Type == "pool") && t.SubaddrIndex.Major == major && t.SubaddrIndex.Minor == minor {
+				amount += t.Amount
+			}
+		}
+		if owner && found {
+			return amount, confirmations, inPool, found
+		}
+	}
+	return amount, confirmations, inPool, found
+}
+
+// txKey returns the secret key of txid held by any wallet file.
+func (w *Wallet) txKey(txid string) string {
+	for _, f := range w.files {
+		if key, ok := f.txKeys[txid]; ok {
+			return key
+		}
+	}
+	return ""
+}
+
+func (w *Wallet) CheckTxKeyContext(ctx context.Context, req *wallet.RequestCheckTxKey) (*wallet.ResponseCheckTxKey, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.begin(ctx, "check_tx_key"); err != nil {
+		return nil, err
+	}
+	if !validTxID(req.TxID) {
+		return nil, rpcError(wallet.ErrWrongTxID, "TX ID has invalid format")
+	}
+	if !validKeyImage(req.TxKey) {
+		return nil, rpcError(wallet.ErrWrongKey, "Tx key has invalid format")
+	}
+	if addressKind(req.Address) == "" {
+		return nil, rpcError(wallet.ErrWrongAddress, "Invalid address")
+	}
+	amount, confirmations, inPool, found := w.received(req.TxID, req.Address)
+	if !found {
+		return nil, rpcError(wallet.ErrWrongTxID, "")
+	}
+	if w.txKey(req.TxID) != req.TxKey {
+		amount = 0
+	}
+	return &wallet.ResponseCheckTxKey{Confirmations: confirmations, InPool: inPool, Received: amount}, nil
+}
+
+func txProof(kind, txid, address, message string) string {
+	return kind + hashOf("tx proof", kind, txid, address, message)
+}
+
+func (w *Wallet) GetTxProofContext(ctx context.Context, req *wallet.RequestGetTxProof) (*wallet.ResponseGetTxProof, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "get_tx_proof")
+	if err != nil {
+		return nil, err
+	}
+	if !validTxID(req.TxID) {
+		return nil, rpcError(wallet.ErrWrongTxID, "TX ID has invalid format")
+	}
+	if addressKind(req.Address) == "" {
+		return nil, rpcError(wallet.ErrWrongAddress, "Invalid address")
+	}
+	if _, ok := f.txKeys[req.TxID]; ok {
+		return &wallet.ResponseGetTxProof{Signature: txProof("OutProofV2", req.TxID, req.Address, req.Message)}, nil
+	}
+	for _, t := range f.transfers {
+		if t.TxID == req.TxID {
+			return &wallet.ResponseGetTxProof{Signature: txProof("InProofV2", req.TxID, req.Address, req.Message)}, nil
+		}
+	}
+	return nil, rpcError(wallet.ErrWrongTxID, "")
+}
+
+func (w *Wallet) CheckTxProofContext(ctx context.Context, req *wallet.RequestCheckTxProof) (*wallet.ResponseCheckTxProof, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.begin(ctx, "check_tx_proof"); err != nil {
+		return nil, err
+	}
+	if !validTxID(req.TxID) {
+		return nil, rpcError(wallet.ErrWrongTxID, "TX ID has invalid format")
+	}
+	if addressKind(req.Address) == "" {
+		return nil, rpcError(wallet.ErrWrongAddress, "Invalid address")
+	}
+	good := req.Signature == txProof("OutProofV2", req.TxID, req.Address, req.Message) ||
+		req.Signature == txProof("InProofV2", req.TxID, req.Address, req.Message)
+	if !good {
+		return &wallet.ResponseCheckTxProof{}, nil
+	}
+	amount, confirmations, inPool, _ := w.received(req.TxID, req.Address)
+	return &wallet.ResponseCheckTxProof{Good: true, Confirmations: confirmations, InPool: inPool, Received: amount}, nil
+}
+
+func spendProof(txid, message string) string {
+	return "SpendProofV1" + hashOf("spend proof", txid, message)
+}
+
+func (w *Wallet) GetSpendProofContext(ctx context.Context, req *wallet.RequestGetSpendProof) (*wallet.ResponseGetSpendProof, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "get_spend_proof")
+	if err != nil {
+		return nil, err
+	}
+	if !validTxID(req.TxID) {
+		return nil, rpcError(wallet.ErrWrongTxID, "TX ID has invalid format")
+	}
+	if f.viewOnly {
+		return nil, rpcError(wallet.ErrWatchOnly, "command not supported by watch-only wallet")
+	}
+	if _, ok := f.txKeys[req.TxID]; !ok {
+		return nil, rpcError(wallet.ErrUnknown, "This tx wasn't generated by this wallet!")
+	}
+	return &wallet.ResponseGetSpendProof{Signature: spendProof(req.TxID, req.Message)}, nil
+}
+
+func (w *Wallet) CheckSpendProofContext(ctx context.Context, req *wallet.RequestCheckSpendProof) (*wallet.ResponseCheckSpendProof, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.begin(ctx, "check_spend_proof"); err != nil {
+		return nil, err
+	}
+	if !validTxID(req.TxID) {
+		return nil, rpcError(wallet.ErrWrongTxID, "TX ID has invalid format")
+	}
+	return &wallet.ResponseCheckSpendProof{Good: req.Signature == spendProof(req.TxID, req.Message)}, nil
+}
+
+func (w *Wallet) GetReserveProofContext(ctx context.Context, req *wallet.RequestGetReserveProof) (*wallet.ResponseGetReserveProof, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "get_reserve_proof")
+	if err != nil {
+		return nil, err
+	}
+	if f.viewOnly {
+		return nil, rpcError(wallet.ErrWatchOnly, "command not supported by watch-only wallet")
+	}
+	var available uint64
+	if req.All {
+		for i := range f.accounts {
+			available += w.balanceOf(f, uint64(i), nil).unlocked
+		}
+	} else {
+		if _, err := f.account(req.AccountIndex); err != nil {
+			return nil, err
+		}
+		available = w.balanceOf(f, req.AccountIndex, nil).unlocked
+		if req.Amount > available {
+			return nil, rpcError(wallet.ErrUnknown, "Not enough balance in this account for the requested minimum reserve amount")
+		}
+	}
+	amount := available
+	if !req.All {
+		amount = req.Amount
+	}
+	signature := "ReserveProofV2" + w.hash("reserve proof")
+	w.reserveProofs[signature] = reserveProof{address: f.address, message: req.Message, amount: amount}
+	return &wallet.ResponseGetReserveProof{Signature: signature}, nil
+}
+
+func (w *Wallet) CheckReserveProofContext(ctx context.Context, req *wallet.RequestCheckReserveProof) (*wallet.ResponseCheckReserveProof, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.begin(ctx, "check_reserve_proof"); err != nil {
+		return nil, err
+	}
+	if addressKind(req.Address) == "" {
+		return nil, rpcError(wallet.ErrWrongAddress, "Invalid address")
+	}
+	proof, ok := w.reserveProofs[req.Signature]
+	good := ok && proof.address == req.Address && proof.message == req.Message
+	return &wallet.ResponseCheckReserveProof{Good: good}, nil
+}
+
+func signature(address, data string) string {
+	return "SigV2" + hashOf("signature", address, data)
+}
+
+func (w *Wallet) SignContext(ctx context.Context, req *wallet.RequestSign) (*wallet.ResponseSign, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "sign")
+	if err != nil {
+		return nil, err
+	}
+	if f.viewOnly {
+		return nil, rpcError(wallet.ErrWatchOnly, "command not supported by watch-only wallet")
+	}
+	return &wallet.ResponseSign{Signature: signature(f.address, req.Data)}, nil
+}
+
+func (w *Wallet) VerifyContext(ctx context.Context, req *wallet.RequestVerify) (*wallet.ResponseVerify, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.begin(ctx, "verify"); err != nil {
+		return nil, err
+	}
+	if addressKind(req.Address) == "" {
+		return nil, rpcError(wallet.ErrWrongAddress, "Invalid address")
+	}
+	return &wallet.ResponseVerify{Good: req.Signature == signature(req.Address, req.Data)}, nil
+}
+
+// exportedOutput is the hex encoded JSON export_outputs returns.
+type exportedOutput struct {
+	Amount      uint64 `json:"amount"`
+	TxHash      string `json:"tx_hash"`
+	KeyImage    string `json:"key_image"`
+	PubKey      string `json:"pubkey"`
+	GlobalIndex uint64 `json:"global_index"`
+	Major       uint64 `json:"major"`
+	Minor       uint64 `json:"minor"`
+	BlockHeight uint64 `json:"block_height"`
+	UnlockTime  uint64 `json:"unlock_time"`
+}
+
+func (w *Wallet) ExportOutputsContext(ctx context.Context) (*wallet.ResponseExportOutputs, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "export_outputs")
+	if err != nil {
+		return nil, err
+	}
+	outs := []exportedOutput{}
+	for _, o := range f.outputs {
+		if !o.confirmed {
+			continue
+		}
+		outs = append(outs, exportedOutput{
+			Amount:      o.amount,
+			TxHash:      o.txHash,
+			KeyImage:    o.keyImage,
+			PubKey:      o.pubKey,
+			GlobalIndex: o.globalIndex,
+			Major:       o.major,
+			Minor:       o.minor,
+			BlockHeight: o.blockHeight,
+			UnlockTime:  o.unlockTime,
+		})
+	}
+	data, _ := json.Marshal(outs)
+	return &wallet.ResponseExportOutputs{OutputsDataHex: hex.EncodeToString(data)}, nil
+}
+
+func (w *Wallet) ImportOutputsContext(ctx context.Context, req *wallet.RequestImportOutputs) (*wallet.ResponseImportOutputs, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "import_outputs")
+	if err != nil {
+		return nil, err
+	}
+	data, err := hex.DecodeString(req.OutputsDataHex)
+	if err != nil {
+		return nil, rpcError(wallet.ErrBadHex, "Failed to parse hex.")
+	}
+	var outs []exportedOutput
+	if err := json.Unmarshal(data, &outs); err != nil {
+		return nil, rpcError(wallet.ErrUnknown, "Failed to import outputs")
+	}
+	var n uint64
+	for _, e := range outs {
+		if f.output(e.KeyImage) != nil {
+			continue
+		}
+		for uint64(len(f.accounts)) <= e.Major {
+			f.addAccount("")
+		}
+		for uint64(len(f.accounts[e.Major].subaddresses)) <= e.Minor {
+			f.addSubaddress(e.Major, "")
+		}
+		f.outputs = append(f.outputs, &output{
+			amount:      e.Amount,
+			txHash:      e.TxHash,
+			keyImage:    e.KeyImage,
+			pubKey:      e.PubKey,
+			globalIndex: e.GlobalIndex,
+			major:       e.Major,
+			minor:       e.Minor,
+			blockHeight: e.BlockHeight,
+			confirmed:   true,
+			unlockTime:  e.UnlockTime,
+		})
+		n++
+	}
+	return &wallet.ResponseImportOutputs{NumImported: n}, nil
+}
+
+func keyImageSignature(keyImage string) string {
+	return hashOf("key image signature", keyImage) + hashOf("key image signature", keyImage, "s")
+}
+
+func (w *Wallet) ExportKeyImagesContext(ctx context.Context) (*wallet.ResponseExportKeyImages, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "export_key_images")
+	if err != nil {
+		return nil, err
+	}
+	if f.viewOnly {
+		return nil, rpcError(wallet.ErrWatchOnly, "command not supported by watch-only wallet")
+	}
+	resp := &wallet.ResponseExportKeyImages{}
+	for _, o := range f.outputs {
+		if !o.confirmed {
+			continue
+		}
+		resp.SignedKeyImages = grow(resp.SignedKeyImages, 1)
+		ski := &resp.SignedKeyImages[len(resp.SignedKeyImages)-1]
+		ski.KeyImage = o.keyImage
+		ski.Signature = keyImageSignature(o.keyImage)
+	}
+	return resp, nil
+}
+
+// spentAnywhere reports whether any wallet file spent keyImage, which is
+// what the daemon would report for it.
+func (w *Wallet) spentAnywhere(keyImage string) bool {
+	for _, f := range w.files {
+		if o := f.output(keyImage); o != nil && o.spent {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Wallet) ImportKeyImagesContext(ctx context.Context, req *wallet.RequestImportKeyImages) (*wallet.ResponseImportKeyImages, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "import_key_images")
+	if err != nil {
+		return nil, err
+	}
+	for _, ski := range req.SignedKeyImages {
+		if !validKeyImage(ski.KeyImage) {
+			return nil, rpcError(wallet.ErrWrongKeyImage, "failed to parse key image")
+		}
+		if ski.Signature != keyImageSignature(ski.KeyImage) {
+			return nil, rpcError(wallet.ErrWrongSignature, "failed to parse signature")
+		}
+	}
+	resp := &wallet.ResponseImportKeyImages{Height: w.height}
+	for _, ski := range req.SignedKeyImages {
+		o := f.output(ski.KeyImage)
+		if o == nil {
+			continue
+		}
+		if w.spentAnywhere(ski.KeyImage) {
+			o.spent = true
+			resp.Spent += o.amount
+		} else {
+			resp.Unspent += o.amount
+		}
+	}
+	return resp, nil
+}
+
+func (w *Wallet) outputByKeyImage(f *file, keyImage string) (*output, error) {
+	if keyImage == "" {
+		return nil, rpcError(wallet.ErrWrongKeyImage, "Must specify key image")
+	}
+	if !validKeyImage(keyImage) {
+		return nil, rpcError(wallet.ErrWrongKeyImage, "failed to parse key image")
+	}
+	o := f.output(keyImage)
+	if o == nil {
+		return nil, rpcError(wallet.ErrWrongKeyImage, "Key image not found")
+	}
+	return o, nil
+}
+
+func (w *Wallet) FreezeContext(ctx context.Context, req *wallet.RequestFreeze) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "freeze")
+	if err != nil {
+		return err
+	}
+	o, err := w.outputByKeyImage(f, req.KeyImage)
+	if err != nil {
+		return err
+	}
+	o.frozen = true
+	return nil
+}
+
+func (w *Wallet) ThawContext(ctx context.Context, req *wallet.RequestThaw) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "thaw")
+	if err != nil {
+		return err
+	}
+	o, err := w.outputByKeyImage(f, req.KeyImage)
+	if err != nil {
+		return err
+	}
+	o.frozen = false
+	return nil
+}
+
+func (w *Wallet) FrozenContext(ctx context.Context, req *wallet.RequestFrozen) (*wallet.ResponseFrozen, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "frozen")
+	if err != nil {
+		return nil, err
+	}
+	o, err := w.outputByKeyImage(f, req.KeyImage)
+	if err != nil {
+		return nil, err
+	}
+	return &wallet.ResponseFrozen{Frozen: o.frozen}, nil
+}
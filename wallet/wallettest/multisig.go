@@ -0,0 +1,238 @@
+package wallettest
+
+import (
+	"context"
+	"encoding/hex"
+	"sort"
+	"strings"
+
+	"github.com/boomhut/go-monero-rpc-client/wallet"
+)
+
+const multisigInfoPrefix = "MultisigxV2R1"
+
+func (w *Wallet) IsMultisigContext(ctx context.Context) (*wallet.ResponseIsMultisig, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "is_multisig")
+	if err != nil {
+		return nil, err
+	}
+	return &wallet.ResponseIsMultisig{
+		Multisig:  f.multisig,
+		Ready:     f.multisigReady,
+		Threshold: f.threshold,
+		Total:     f.total,
+	}, nil
+}
+
+func (w *Wallet) PrepareMultisigContext(ctx context.Context) (*wallet.ResponsePrepareMultisig, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "prepare_multisig")
+	if err != nil {
+		return nil, err
+	}
+	if f.multisig {
+		return nil, rpcError(wallet.ErrAlreadyMultisig, "This wallet is already multisig")
+	}
+	if f.viewOnly {
+		return nil, rpcError(wallet.ErrWatchOnly, "wallet is watch-only and cannot be made multisig")
+	}
+	return &wallet.ResponsePrepareMultisig{MultisigInfo: multisigInfoPrefix + hashOf("multisig", f.address)}, nil
+}
+
+// MakeMultisigContext combines the wallet's own info with its peers'. All
+// participants end up with the same seed and so the same address once the
+// key exchange completes.
+func (w *Wallet) MakeMultisigContext(ctx context.Context, req *wallet.RequestMakeMultisig) (*wallet.ResponseMakeMultisig, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "make_multisig")
+	if err != nil {
+		return nil, err
+	}
+	if f.multisig {
+		return nil, rpcError(wallet.ErrAlreadyMultisig, "This wallet is already multisig")
+	}
+	if f.viewOnly {
+		return nil, rpcError(wallet.ErrWatchOnly, "wallet is watch-only and cannot be made multisig")
+	}
+	if len(req.MultisigInfo) == 0 {
+		return nil, rpcError(wallet.ErrBadMultisigInfo, "No multisig info provided")
+	}
+	infos := []string{multisigInfoPrefix + hashOf("multisig", f.address)}
+	for _, info := range req.MultisigInfo {
+		if !strings.HasPrefix(info, multisigInfoPrefix) {
+			return nil, rpcError(wallet.ErrBadMultisigInfo, "Invalid multisig info: %s", info)
+		}
+		infos = append(infos, info)
+	}
+	total := uint64(len(infos))
+	if req.Threshold < 2 || req.Threshold > total {
+		return nil, rpcError(wallet.ErrUnknown, "Invalid threshold")
+	}
+	if f.password != req.Password {
+		return nil, rpcError(wallet.ErrInvalidPassword, "Invalid password.")
+	}
+	sort.Strings(infos)
+
+	f.multisig = true
+	f.threshold = req.Threshold
+	f.total = total
+	f.multisigSeed = hashOf(infos...)
+	return &wallet.ResponseMakeMultisig{MultisigInfo: "MultisigxV2Rn" + hashOf("kex", f.multisigSeed)}, nil
+}
+
+func (w *Wallet) ExchangeMultisigKeysContext(ctx context.Context, req *wallet.RequestExchangeMultisigKeys) (*wallet.ResponseExchangeMultisigKeys, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "exchange_multisig_keys")
+	if err != nil {
+		return nil, err
+	}
+	address, err := w.finishMultisig(f, req.MultisigInfo, req.Password)
+	if err != nil {
+		return nil, err
+	}
+	return &wallet.ResponseExchangeMultisigKeys{Address: address}, nil
+}
+
+func (w *Wallet) FinalizeMultisigContext(ctx context.Context, req *wallet.RequestFinalizeMultisig) (*wallet.ResponseFinalizeMultisig, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "finalize_multisig")
+	if err != nil {
+		return nil, err
+	}
+	address, err := w.finishMultisig(f, req.MultisigInfo, req.Password)
+	if err != nil {
+		return nil, err
+	}
+	return &wallet.ResponseFinalizeMultisig{Address: address}, nil
+}
+
+// finishMultisig completes the key exchange: the wallet file becomes a new,
+// empty wallet with the shared multisig address.
+func (w *Wallet) finishMultisig(f *file, infos []string, password string) (string, error) {
+	if !f.multisig {
+		return "", rpcError(wallet.ErrNotMultisig, "This wallet is not multisig")
+	}
+	if f.multisigReady {
+		return "", rpcError(wallet.ErrAlreadyMultisig, "This wallet is multisig, and already finalized")
+	}
+	if uint64(len(infos)) < f.total-1 {
+		return "", rpcError(wallet.ErrBadMultisigInfo, "Needs multisig info from all other participants")
+	}
+	if f.password != password {
+		return "", rpcError(wallet.ErrInvalidPassword, "Invalid password.")
+	}
+	f.multisigReady = true
+	f.address = fakeAddress('4', f.multisigSeed)
+	f.accounts = nil
+	f.outputs = nil
+	f.transfers = nil
+	f.addAccount("Primary account")
+	return f.address, nil
+}
+
+func (f *file) readyMultisig() error {
+	if !f.multisig {
+		return rpcError(wallet.ErrNotMultisig, "This wallet is not multisig")
+	}
+	if !f.multisigReady {
+		return rpcError(wallet.ErrNotMultisig, "This wallet is multisig, but not yet finalized")
+	}
+	return nil
+}
+
+func (w *Wallet) ExportMultisigInfoContext(ctx context.Context) (*wallet.ResponseExportMultisigInfo, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "export_multisig_info")
+	if err != nil {
+		return nil, err
+	}
+	if err := f.readyMultisig(); err != nil {
+		return nil, err
+	}
+	return &wallet.ResponseExportMultisigInfo{Info: hex.EncodeToString([]byte(w.hash("multisig info")))}, nil
+}
+
+func (w *Wallet) ImportMultisigInfoContext(ctx context.Context, req *wallet.RequestImportMultisigInfo) (*wallet.ResponseImportMultisigInfo, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "import_multisig_info")
+	if err != nil {
+		return nil, err
+	}
+	if err := f.readyMultisig(); err != nil {
+		return nil, err
+	}
+	if len(req.Info) == 0 {
+		return nil, rpcError(wallet.ErrBadMultisigInfo, "No multisig info provided")
+	}
+	for _, info := range req.Info {
+		if _, err := hex.DecodeString(info); err != nil || info == "" {
+			return nil, rpcError(wallet.ErrBadHex, "Failed to parse hex.")
+		}
+	}
+	var n uint64
+	for _, o := range f.outputs {
+		if o.confirmed && !o.spent {
+			n++
+		}
+	}
+	return &wallet.ResponseImportMultisigInfo{NOutputs: n}, nil
+}
+
+func (w *Wallet) SignMultisigContext(ctx context.Context, req *wallet.RequestSignMultisig) (*wallet.ResponseSignMultisig, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "sign_multisig")
+	if err != nil {
+		return nil, err
+	}
+	if err := f.readyMultisig(); err != nil {
+		return nil, err
+	}
+	set, ok := decodeTxSet(req.TxDataHex)
+	if !ok || set.Signatures == 0 {
+		return nil, rpcError(wallet.ErrBadMultisigTxData, "Failed to parse multisig tx data.")
+	}
+	set.Signatures++
+	resp := &wallet.ResponseSignMultisig{TxDataHex: set.encode()}
+	if set.Signatures >= f.threshold {
+		for _, p := range set.Txs {
+			resp.TxHashList = append(resp.TxHashList, p.TxID)
+		}
+	}
+	return resp, nil
+}
+
+func (w *Wallet) SubmitMultisigContext(ctx context.Context, req *wallet.RequestSubmitMultisig) (*wallet.ResponseSubmitMultisig, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "submit_multisig")
+	if err != nil {
+		return nil, err
+	}
+	if err := f.readyMultisig(); err != nil {
+		return nil, err
+	}
+	set, ok := decodeTxSet(req.TxDataHex)
+	if !ok || set.Signatures == 0 {
+		return nil, rpcError(wallet.ErrBadMultisigTxData, "Failed to parse multisig tx data.")
+	}
+	if set.Signatures < f.threshold {
+		return nil, rpcError(wallet.ErrThresholdNotReached, "Not enough signers signed this transaction.")
+	}
+	resp := &wallet.ResponseSubmitMultisig{}
+	for _, p := range set.Txs {
+		if err := w.apply(f, p); err != nil {
+			return nil, rpcError(wallet.ErrMultisigSubmission, "Failed to submit multisig transaction: %v", err)
+		}
+		resp.TxHashList = append(resp.TxHashList, p.TxID)
+	}
+	return resp, nil
+}
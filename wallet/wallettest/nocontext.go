@@ -0,0 +1,365 @@
+package wallettest
+
+import (
+	"context"
+
+	"github.com/boomhut/go-monero-rpc-client/wallet"
+)
+
+// The methods below implement the context-free half of wallet.Client.
+
+func (w *Wallet) GetBalance(req *wallet.RequestGetBalance) (*wallet.ResponseGetBalance, error) {
+	return w.GetBalanceContext(context.Background(), req)
+}
+
+func (w *Wallet) GetAddress(req *wallet.RequestGetAddress) (*wallet.ResponseGetAddress, error) {
+	return w.GetAddressContext(context.Background(), req)
+}
+
+func (w *Wallet) GetAddressIndex(req *wallet.RequestGetAddressIndex) (*wallet.ResponseGetAddressIndex, error) {
+	return w.GetAddressIndexContext(context.Background(), req)
+}
+
+func (w *Wallet) CreateAddress(req *wallet.RequestCreateAddress) (*wallet.ResponseCreateAddress, error) {
+	return w.CreateAddressContext(context.Background(), req)
+}
+
+func (w *Wallet) LabelAddress(req *wallet.RequestLabelAddress) error {
+	return w.LabelAddressContext(context.Background(), req)
+}
+
+func (w *Wallet) ValidateAddress(req *wallet.RequestValidateAddress) (*wallet.ResponseValidateAddress, error) {
+	return w.ValidateAddressContext(context.Background(), req)
+}
+
+func (w *Wallet) GetAccounts(req *wallet.RequestGetAccounts) (*wallet.ResponseGetAccounts, error) {
+	return w.GetAccountsContext(context.Background(), req)
+}
+
+func (w *Wallet) CreateAccount(req *wallet.RequestCreateAccount) (*wallet.ResponseCreateAccount, error) {
+	return w.CreateAccountContext(context.Background(), req)
+}
+
+func (w *Wallet) LabelAccount(req *wallet.RequestLabelAccount) error {
+	return w.LabelAccountContext(context.Background(), req)
+}
+
+func (w *Wallet) GetAccountTags() (*wallet.ResponseGetAccountTags, error) {
+	return w.GetAccountTagsContext(context.Background())
+}
+
+func (w *Wallet) TagAccounts(req *wallet.RequestTagAccounts) error {
+	return w.TagAccountsContext(context.Background(), req)
+}
+
+func (w *Wallet) UntagAccounts(req *wallet.RequestUntagAccounts) error {
+	return w.UntagAccountsContext(context.Background(), req)
+}
+
+func (w *Wallet) SetAccountTagDescription(req *wallet.RequestSetAccountTagDescription) error {
+	return w.SetAccountTagDescriptionContext(context.Background(), req)
+}
+
+func (w *Wallet) GetHeight() (*wallet.ResponseGetHeight, error) {
+	return w.GetHeightContext(context.Background())
+}
+
+func (w *Wallet) Transfer(req *wallet.RequestTransfer) (*wallet.ResponseTransfer, error) {
+	return w.TransferContext(context.Background(), req)
+}
+
+func (w *Wallet) TransferSplit(req *wallet.RequestTransferSplit) (*wallet.ResponseTransferSplit, error) {
+	return w.TransferSplitContext(context.Background(), req)
+}
+
+func (w *Wallet) SignTransfer(req *wallet.RequestSignTransfer) (*wallet.ResponseSignTransfer, error) {
+	return w.SignTransferContext(context.Background(), req)
+}
+
+func (w *Wallet) SubmitTransfer(req *wallet.RequestSubmitTransfer) (*wallet.ResponseSubmitTransfer, error) {
+	return w.SubmitTransferContext(context.Background(), req)
+}
+
+func (w *Wallet) SweepDust(req *wallet.RequestSweepDust) (*wallet.ResponseSweepDust, error) {
+	return w.SweepDustContext(context.Background(), req)
+}
+
+func (w *Wallet) SweepAll(req *wallet.RequestSweepAll) (*wallet.ResponseSweepAll, error) {
+	return w.SweepAllContext(context.Background(), req)
+}
+
+func (w *Wallet) SweepSingle(req *wallet.RequestSweepSingle) (*wallet.ResponseSweepSingle, error) {
+	return w.SweepSingleContext(context.Background(), req)
+}
+
+func (w *Wallet) RelayTx(req *wallet.RequestRelayTx) (*wallet.ResponseRelayTx, error) {
+	return w.RelayTxContext(context.Background(), req)
+}
+
+func (w *Wallet) Store() error {
+	return w.StoreContext(context.Background())
+}
+
+func (w *Wallet) GetPayments(req *wallet.RequestGetPayments) (*wallet.ResponseGetPayments, error) {
+	return w.GetPaymentsContext(context.Background(), req)
+}
+
+func (w *Wallet) GetBulkPayments(req *wallet.RequestGetBulkPayments) (*wallet.ResponseGetBulkPayments, error) {
+	return w.GetBulkPaymentsContext(context.Background(), req)
+}
+
+func (w *Wallet) IncomingTransfers(req *wallet.RequestIncomingTransfers) (*wallet.ResponseIncomingTransfers, error) {
+	return w.IncomingTransfersContext(context.Background(), req)
+}
+
+func (w *Wallet) QueryKey(req *wallet.RequestQueryKey) (*wallet.ResponseQueryKey, error) {
+	return w.QueryKeyContext(context.Background(), req)
+}
+
+func (w *Wallet) MakeIntegratedAddress(req *wallet.RequestMakeIntegratedAddress) (*wallet.ResponseMakeIntegratedAddress, error) {
+	return w.MakeIntegratedAddressContext(context.Background(), req)
+}
+
+func (w *Wallet) SplitIntegratedAddress(req *wallet.RequestSplitIntegratedAddress) (*wallet.ResponseSplitIntegratedAddress, error) {
+	return w.SplitIntegratedAddressContext(context.Background(), req)
+}
+
+func (w *Wallet) StopWallet() error {
+	return w.StopWalletContext(context.Background())
+}
+
+func (w *Wallet) RescanBlockchain() error {
+	return w.RescanBlockchainContext(context.Background())
+}
+
+func (w *Wallet) SetTxNotes(req *wallet.RequestSetTxNotes) error {
+	return w.SetTxNotesContext(context.Background(), req)
+}
+
+func (w *Wallet) GetTxNotes(req *wallet.RequestGetTxNotes) (*wallet.ResponseGetTxNotes, error) {
+	return w.GetTxNotesContext(context.Background(), req)
+}
+
+func (w *Wallet) SetAttribute(req *wallet.RequestSetAttribute) error {
+	return w.SetAttributeContext(context.Background(), req)
+}
+
+func (w *Wallet) GetAttribute(req *wallet.RequestGetAttribute) (*wallet.ResponseGetAttribute, error) {
+	return w.GetAttributeContext(context.Background(), req)
+}
+
+func (w *Wallet) GetTxKey(req *wallet.RequestGetTxKey) (*wallet.ResponseGetTxKey, error) {
+	return w.GetTxKeyContext(context.Background(), req)
+}
+
+func (w *Wallet) CheckTxKey(req *wallet.RequestCheckTxKey) (*wallet.ResponseCheckTxKey, error) {
+	return w.CheckTxKeyContext(context.Background(), req)
+}
+
+func (w *Wallet) GetTxProof(req *wallet.RequestGetTxProof) (*wallet.ResponseGetTxProof, error) {
+	return w.GetTxProofContext(context.Background(), req)
+}
+
+func (w *Wallet) CheckTxProof(req *wallet.RequestCheckTxProof) (*wallet.ResponseCheckTxProof, error) {
+	return w.CheckTxProofContext(context.Background(), req)
+}
+
+func (w *Wallet) GetSpendProof(req *wallet.RequestGetSpendProof) (*wallet.ResponseGetSpendProof, error) {
+	return w.GetSpendProofContext(context.Background(), req)
+}
+
+func (w *Wallet) CheckSpendProof(req *wallet.RequestCheckSpendProof) (*wallet.ResponseCheckSpendProof, error) {
+	return w.CheckSpendProofContext(context.Background(), req)
+}
+
+func (w *Wallet) GetReserveProof(req *wallet.RequestGetReserveProof) (*wallet.ResponseGetReserveProof, error) {
+	return w.GetReserveProofContext(context.Background(), req)
+}
+
+func (w *Wallet) CheckReserveProof(req *wallet.RequestCheckReserveProof) (*wallet.ResponseCheckReserveProof, error) {
+	return w.CheckReserveProofContext(context.Background(), req)
+}
+
+func (w *Wallet) GetTransfers(req *wallet.RequestGetTransfers) (*wallet.ResponseGetTransfers, error) {
+	return w.GetTransfersContext(context.Background(), req)
+}
+
+func (w *Wallet) GetTransferByTxID(req *wallet.RequestGetTransferByTxID) (*wallet.ResponseGetTransferByTxID, error) {
+	return w.GetTransferByTxIDContext(context.Background(), req)
+}
+
+func (w *Wallet) Sign(req *wallet.RequestSign) (*wallet.ResponseSign, error) {
+	return w.SignContext(context.Background(), req)
+}
+
+func (w *Wallet) Verify(req *wallet.RequestVerify) (*wallet.ResponseVerify, error) {
+	return w.VerifyContext(context.Background(), req)
+}
+
+func (w *Wallet) ExportOutputs() (*wallet.ResponseExportOutputs, error) {
+	return w.ExportOutputsContext(context.Background())
+}
+
+func (w *Wallet) ImportOutputs(req *wallet.RequestImportOutputs) (*wallet.ResponseImportOutputs, error) {
+	return w.ImportOutputsContext(context.Background(), req)
+}
+
+func (w *Wallet) ExportKeyImages() (*wallet.ResponseExportKeyImages, error) {
+	return w.ExportKeyImagesContext(context.Background())
+}
+
+func (w *Wallet) ImportKeyImages(req *wallet.RequestImportKeyImages) (*wallet.ResponseImportKeyImages, error) {
+	return w.ImportKeyImagesContext(context.Background(), req)
+}
+
+func (w *Wallet) MakeURI(req *wallet.RequestMakeURI) (*wallet.ResponseMakeURI, error) {
+	return w.MakeURIContext(context.Background(), req)
+}
+
+func (w *Wallet) ParseURI(req *wallet.RequestParseURI) (*wallet.ResponseParseURI, error) {
+	return w.ParseURIContext(context.Background(), req)
+}
+
+func (w *Wallet) GetAddressBook(req *wallet.RequestGetAddressBook) (*wallet.ResponseGetAddressBook, error) {
+	return w.GetAddressBookContext(context.Background(), req)
+}
+
+func (w *Wallet) AddAddressBook(req *wallet.RequestAddAddressBook) (*wallet.ResponseAddAddressBook, error) {
+	return w.AddAddressBookContext(context.Background(), req)
+}
+
+func (w *Wallet) DeleteAddressBook(req *wallet.RequestDeleteAddressBook) error {
+	return w.DeleteAddressBookContext(context.Background(), req)
+}
+
+func (w *Wallet) Refresh(req *wallet.RequestRefresh) (*wallet.ResponseRefresh, error) {
+	return w.RefreshContext(context.Background(), req)
+}
+
+func (w *Wallet) RescanSpent() error {
+	return w.RescanSpentContext(context.Background())
+}
+
+func (w *Wallet) StartMining(req *wallet.RequestStartMining) error {
+	return w.StartMiningContext(context.Background(), req)
+}
+
+func (w *Wallet) StopMining() error {
+	return w.StopMiningContext(context.Background())
+}
+
+func (w *Wallet) GetLanguages() (*wallet.ResponseGetLanguages, error) {
+	return w.GetLanguagesContext(context.Background())
+}
+
+func (w *Wallet) CreateWallet(req *wallet.RequestCreateWallet) error {
+	return w.CreateWalletContext(context.Background(), req)
+}
+
+func (w *Wallet) GenerateFromKeys(req *wallet.RequestGenerateFromKeys) (*wallet.ResponseGenerateFromKeys, error) {
+	return w.GenerateFromKeysContext(context.Background(), req)
+}
+
+func (w *Wallet) OpenWallet(req *wallet.RequestOpenWallet) error {
+	return w.OpenWalletContext(context.Background(), req)
+}
+
+func (w *Wallet) CloseWallet() error {
+	return w.CloseWalletContext(context.Background())
+}
+
+func (w *Wallet) ChangeWalletPassword(req *wallet.RequestChangeWalletPassword) error {
+	return w.ChangeWalletPasswordContext(context.Background(), req)
+}
+
+func (w *Wallet) IsMultisig() (*wallet.ResponseIsMultisig, error) {
+	return w.IsMultisigContext(context.Background())
+}
+
+func (w *Wallet) PrepareMultisig() (*wallet.ResponsePrepareMultisig, error) {
+	return w.PrepareMultisigContext(context.Background())
+}
+
+func (w *Wallet) MakeMultisig(req *wallet.RequestMakeMultisig) (*wallet.ResponseMakeMultisig, error) {
+	return w.MakeMultisigContext(context.Background(), req)
+}
+
+func (w *Wallet) ExportMultisigInfo() (*wallet.ResponseExportMultisigInfo, error) {
+	return w.ExportMultisigInfoContext(context.Background())
+}
+
+func (w *Wallet) ImportMultisigInfo(req *wallet.RequestImportMultisigInfo) (*wallet.ResponseImportMultisigInfo, error) {
+	return w.ImportMultisigInfoContext(context.Background(), req)
+}
+
+func (w *Wallet) FinalizeMultisig(req *wallet.RequestFinalizeMultisig) (*wallet.ResponseFinalizeMultisig, error) {
+	return w.FinalizeMultisigContext(context.Background(), req)
+}
+
+func (w *Wallet) SignMultisig(req *wallet.RequestSignMultisig) (*wallet.ResponseSignMultisig, error) {
+	return w.SignMultisigContext(context.Background(), req)
+}
+
+func (w *Wallet) SubmitMultisig(req *wallet.RequestSubmitMultisig) (*wallet.ResponseSubmitMultisig, error) {
+	return w.SubmitMultisigContext(context.Background(), req)
+}
+
+func (w *Wallet) GetVersion() (*wallet.ResponseGetVersion, error) {
+	return w.GetVersionContext(context.Background())
+}
+
+func (w *Wallet) SetDaemon(req *wallet.RequestSetDaemon) error {
+	return w.SetDaemonContext(context.Background(), req)
+}
+
+func (w *Wallet) AutoRefresh(req *wallet.RequestAutoRefresh) error {
+	return w.AutoRefreshContext(context.Background(), req)
+}
+
+func (w *Wallet) DescribeTransfer(req *wallet.RequestDescribeTransfer) (*wallet.ResponseDescribeTransfer, error) {
+	return w.DescribeTransferContext(context.Background(), req)
+}
+
+func (w *Wallet) EditAddressBook(req *wallet.RequestEditAddressBook) error {
+	return w.EditAddressBookContext(context.Background(), req)
+}
+
+func (w *Wallet) EstimateTxSizeAndWeight(req *wallet.RequestEstimateTxSizeAndWeight) (*wallet.ResponseEstimateTxSizeAndWeight, error) {
+	return w.EstimateTxSizeAndWeightContext(context.Background(), req)
+}
+
+func (w *Wallet) ExchangeMultisigKeys(req *wallet.RequestExchangeMultisigKeys) (*wallet.ResponseExchangeMultisigKeys, error) {
+	return w.ExchangeMultisigKeysContext(context.Background(), req)
+}
+
+func (w *Wallet) Freeze(req *wallet.RequestFreeze) error {
+	return w.FreezeContext(context.Background(), req)
+}
+
+func (w *Wallet) Frozen(req *wallet.RequestFrozen) (*wallet.ResponseFrozen, error) {
+	return w.FrozenContext(context.Background(), req)
+}
+
+func (w *Wallet) Thaw(req *wallet.RequestThaw) error {
+	return w.ThawContext(context.Background(), req)
+}
+
+func (w *Wallet) ScanTx(req *wallet.RequestScanTx) error {
+	return w.ScanTxContext(context.Background(), req)
+}
+
+func (w *Wallet) SetupBackgroundSync(req *wallet.RequestSetupBackgroundSync) (*wallet.ResponseSetupBackgroundSync, error) {
+	return w.SetupBackgroundSyncContext(context.Background(), req)
+}
+
+func (w *Wallet) StartBackgroundSync() error {
+	return w.StartBackgroundSyncContext(context.Background())
+}
+
+func (w *Wallet) StopBackgroundSync() error {
+	return w.StopBackgroundSyncContext(context.Background())
+}
+
+func (w *Wallet) GetDefaultFeePriority() (*wallet.ResponseGetDefaultFeePriority, error) {
+	return w.GetDefaultFeePriorityContext(context.Background())
+}
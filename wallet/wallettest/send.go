@@ -0,0 +1,315 @@
+package wallettest
+
+import (
+	"context"
+	"encoding/hex"
+
+	"github.com/boomhut/go-monero-rpc-client/wallet"
+)
+
+func (w *Wallet) TransferContext(ctx context.Context, req *wallet.RequestTransfer) (*wallet.ResponseTransfer, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "transfer")
+	if err != nil {
+		return nil, err
+	}
+	p, err := w.planTransfer(f, req.AccountIndex, req.SubaddrIndices, req.Destinations, req.SubtractFeeFromOutputs,
+		req.Priority, req.PaymentID, req.UnlockTime, req.RingSize)
+	if err != nil {
+		return nil, err
+	}
+	out, err := w.send(f, []*plannedTx{p}, req.DoNotRelay, req.GetTxKey, req.GetTxHex, req.GetTxMetadata)
+	if err != nil {
+		return nil, err
+	}
+	resp := &wallet.ResponseTransfer{
+		Amount:        p.amount(),
+		Fee:           p.Fee,
+		Weight:        p.weight(),
+		TxBlob:        out[0].txBlob,
+		TxHash:        out[0].txHash,
+		TxKey:         out[0].txKey,
+		TxMetadata:    out[0].txMetadata,
+		UnsignedTxSet: out[0].unsignedTxSet,
+	}
+	for _, d := range p.Destinations {
+		resp.AmountsByDest.Amounts = append(resp.AmountsByDest.Amounts, d.Amount)
+	}
+	resp.SpentKeyImages.KeyImages = p.KeyImages
+	return resp, nil
+}
+
+// txList is the common part of the responses of transfer_split and the
+// sweep methods.
+type txList struct {
+	hashes, keys, blobs, metadata []string
+	amounts, fees                 []uint64
+	multisigTxSet, unsignedTxSet  string
+}
+
+func (w *Wallet) sendList(f *file, txs []*plannedTx, doNotRelay, getTxKeys, getTxHex, getTxMetadata bool) (*txList, error) {
+	out, err := w.send(f, txs, doNotRelay, getTxKeys, getTxHex, getTxMetadata)
+	if err != nil {
+		return nil, err
+	}
+	l := &txList{}
+	for i, p := range txs {
+		l.amounts = append(l.amounts, p.amount())
+		l.fees = append(l.fees, p.Fee)
+		l.multisigTxSet = out[i].multisigTxSet
+		l.unsignedTxSet = out[i].unsignedTxSet
+		if out[i].txHash == "" {
+			continue
+		}
+		l.hashes = append(l.hashes, out[i].txHash)
+		if getTxKeys {
+			l.keys = append(l.keys, out[i].txKey)
+		}
+		if getTxHex {
+			l.blobs = append(l.blobs, out[i].txBlob)
+		}
+		if getTxMetadata {
+			l.metadata = append(l.metadata, out[i].txMetadata)
+		}
+	}
+	return l, nil
+}
+
+func (w *Wallet) TransferSplitContext(ctx context.Context, req *wallet.RequestTransferSplit) (*wallet.ResponseTransferSplit, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "transfer_split")
+	if err != nil {
+		return nil, err
+	}
+	p, err := w.planTransfer(f, req.AccountIndex, req.SubaddrIndices, req.Destinations, nil,
+		req.Priority, req.PaymendID, req.UnlockTime, req.RingSize)
+	if err != nil {
+		return nil, err
+	}
+	l, err := w.sendList(f, []*plannedTx{p}, req.DoNotRelay, req.GetxKeys, req.GetTxHex, req.GetTxMetadata)
+	if err != nil {
+		return nil, err
+	}
+	return &wallet.ResponseTransferSplit{
+		TxHashList:     l.hashes,
+		TxKeyList:      l.keys,
+		AmountList:     l.amounts,
+		FeeList:        l.fees,
+		TxBlobList:     l.blobs,
+		TxMetadataList: l.metadata,
+		MultisigTxSet:  l.multisigTxSet,
+		UnsignedTxSet:  l.unsignedTxSet,
+	}, nil
+}
+
+func (w *Wallet) SweepDustContext(ctx context.Context, req *wallet.RequestSweepDust) (*wallet.ResponseSweepDust, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.begin(ctx, "sweep_dust"); err != nil {
+		return nil, err
+	}
+	// All outputs are RingCT, there is never any unmixable dust.
+	return &wallet.ResponseSweepDust{}, nil
+}
+
+func (w *Wallet) SweepAllContext(ctx context.Context, req *wallet.RequestSweepAll) (*wallet.ResponseSweepAll, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "sweep_all")
+	if err != nil {
+		return nil, err
+	}
+	if req.PaymentID != "" {
+		return nil, rpcError(wallet.ErrWrongPaymentID, "Standalone payment IDs are obsolete. Use subaddresses or integrated addresses instead")
+	}
+	subaddrs := req.SubaddrIndices
+	if req.SubaddrIndicesAll {
+		subaddrs = nil
+	}
+	var filter func(*output) bool
+	if req.BelowAmount > 0 {
+		filter = func(o *output) bool { return o.amount < req.BelowAmount }
+	}
+	p, err := w.planSweep(f, req.AccountIndex, subaddrs, req.Address, req.Priority, req.UnlockTime, req.RingSize, filter)
+	if err != nil {
+		return nil, err
+	}
+	l, err := w.sendList(f, []*plannedTx{p}, req.DoNotRelay, req.GetTxKeys, req.GetTxHex, req.GetTxMetadata)
+	if err != nil {
+		return nil, err
+	}
+	return &wallet.ResponseSweepAll{
+		TxHashList:     l.hashes,
+		TxKeyList:      l.keys,
+		AmountList:     l.amounts,
+		FeeList:        l.fees,
+		TxBlobList:     l.blobs,
+		TxMetadataList: l.metadata,
+		MultisigTxSet:  l.multisigTxSet,
+		UnsignedTxSet:  l.unsignedTxSet,
+	}, nil
+}
+
+func validKeyImage(ki string) bool {
+	b, err := hex.DecodeString(ki)
+	return err == nil && len(b) == 32
+}
+
+func (w *Wallet) SweepSingleContext(ctx context.Context, req *wallet.RequestSweepSingle) (*wallet.ResponseSweepSingle, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "sweep_single")
+	if err != nil {
+		return nil, err
+	}
+	if !validKeyImage(req.KeyImage) {
+		return nil, rpcError(wallet.ErrWrongKeyImage, "failed to parse key image")
+	}
+	if req.PaymentID != "" {
+		return nil, rpcError(wallet.ErrWrongPaymentID, "Standalone payment IDs are obsolete. Use subaddresses or integrated addresses instead")
+	}
+	o := f.output(req.KeyImage)
+	if o == nil || o.spent || o.frozen {
+		return nil, rpcError(wallet.ErrWrongKeyImage, "No outputs found for the given key image")
+	}
+	if !w.unlocked(o) {
+		return nil, rpcError(wallet.ErrNotEnoughUnlockedMoney, "")
+	}
+	p, err := w.planSweep(f, o.major, nil, req.Address, req.Priority, req.UnlockTime, req.RingSize,
+		func(c *output) bool { return c == o })
+	if err != nil {
+		return nil, err
+	}
+	l, err := w.sendList(f, []*plannedTx{p}, req.DoNotRelay, req.GetxKeys, req.GetTxHex, req.GetTxMetadata)
+	if err != nil {
+		return nil, err
+	}
+	return &wallet.ResponseSweepSingle{
+		TxHashList:     l.hashes,
+		TxKeyList:      l.keys,
+		AmountList:     l.amounts,
+		FreeList:       l.fees,
+		TxBlobList:     l.blobs,
+		TxMetadataList: l.metadata,
+		MultisigTxSet:  l.multisigTxSet,
+		UnsignedTxSet:  l.unsignedTxSet,
+	}, nil
+}
+
+func (w *Wallet) RelayTxContext(ctx context.Context, req *wallet.RequestRelayTx) (*wallet.ResponseRelayTx, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "relay_tx")
+	if err != nil {
+		return nil, err
+	}
+	set, ok := decodeTxSet(req.Hex)
+	if !ok || len(set.Txs) != 1 {
+		return nil, rpcError(wallet.ErrBadTxMetadata, "Failed to parse tx metadata.")
+	}
+	if err := w.apply(f, set.Txs[0]); err != nil {
+		return nil, err
+	}
+	return &wallet.ResponseRelayTx{TxHash: set.Txs[0].TxID}, nil
+}
+
+func (w *Wallet) SignTransferContext(ctx context.Context, req *wallet.RequestSignTransfer) (*wallet.ResponseSignTransfer, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "sign_transfer")
+	if err != nil {
+		return nil, err
+	}
+	if f.viewOnly {
+		return nil, rpcError(wallet.ErrWatchOnly, "command not supported by watch-only wallet")
+	}
+	set, ok := decodeTxSet(req.UnsighnedxSet)
+	if !ok || set.Signed || set.Signatures > 0 {
+		return nil, rpcError(wallet.ErrBadUnsignedTxData, "cannot load unsigned_txset")
+	}
+	set.Signed = true
+	resp := &wallet.ResponseSignTransfer{SignedTxSet: set.encode()}
+	for _, p := range set.Txs {
+		resp.TxHashList = append(resp.TxHashList, p.TxID)
+		if req.ExportRaw {
+			resp.TxRawList = append(resp.TxRawList, hex.EncodeToString([]byte("tx:"+p.TxID)))
+		}
+	}
+	return resp, nil
+}
+
+func (w *Wallet) SubmitTransferContext(ctx context.Context, req *wallet.RequestSubmitTransfer) (*wallet.ResponseSubmitTransfer, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, err := w.begin(ctx, "submit_transfer")
+	if err != nil {
+		return nil, err
+	}
+	set, ok := decodeTxSet(req.TxDataHex)
+	if !ok || !set.Signed {
+		return nil, rpcError(wallet.ErrBadSignedTxData, "Failed to load signed transaction")
+	}
+	resp := &wallet.ResponseSubmitTransfer{}
+	for _, p := range set.Txs {
+		if err := w.apply(f, p); err != nil {
+			return nil, rpcError(wallet.ErrSignedSubmission, "Failed to submit signed transaction: %v", err)
+		}
+		resp.TxHashList = append(resp.TxHashList, p.TxID)
+	}
+	return resp, nil
+}
+
+func (w *Wallet) DescribeTransferContext(ctx context.Context, req *wallet.RequestDescribeTransfer) (*wallet.ResponseDescribeTransfer, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.begin(ctx, "describe_transfer"); err != nil {
+		return nil, err
+	}
+	var set *txSet
+	var ok bool
+	switch {
+	case req.UnsignedTxSet != "":
+		if set, ok = decodeTxSet(req.UnsignedTxSet); !ok || set.Signed {
+			return nil, rpcError(wallet.ErrBadUnsignedTxData, "cannot load unsigned_txset")
+		}
+	case req.MultisigTxSet != "":
+		if set, ok = decodeTxSet(req.MultisigTxSet); !ok || set.Signatures == 0 {
+			return nil, rpcError(wallet.ErrBadMultisigTxData, "Failed to parse multisig tx data.")
+		}
+	default:
+		return nil, rpcError(wallet.ErrUnknown, "no txset provided")
+	}
+	resp := &wallet.ResponseDescribeTransfer{}
+	resp.Desc = grow(resp.Desc, len(set.Txs))
+	for i, p := range set.Txs {
+		d := &resp.Desc[i]
+		d.AmountIn = p.AmountIn
+		d.AmountOut = p.AmountIn - p.Fee
+		d.RingSize = p.RingSize
+		if d.RingSize == 0 {
+			d.RingSize = 16
+		}
+		d.UnlockTime = p.UnlockTime
+		d.PaymentID = p.PaymentID
+		d.ChangeAddress = p.ChangeAddress
+		d.ChangeAmount = p.change()
+		d.Fee = p.Fee
+		d.Recipients = p.Destinations
+	}
+	return resp, nil
+}
+
+func (w *Wallet) EstimateTxSizeAndWeightContext(ctx context.Context, req *wallet.RequestEstimateTxSizeAndWeight) (*wallet.ResponseEstimateTxSizeAndWeight, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.begin(ctx, "estimate_tx_size_and_weight"); err != nil {
+		return nil, err
+	}
+	if req.NInputs == 0 || req.NOutputs == 0 {
+		return nil, rpcError(wallet.ErrUnknown, "Invalid number of inputs or outputs")
+	}
+	weight := estimateWeight(req.NInputs, req.NOutputs, req.RingSize)
+	return &wallet.ResponseEstimateTxSizeAndWeight{Size: weight, Weight: weight}, nil
+}
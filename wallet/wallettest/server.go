@@ -0,0 +1,287 @@
+package wallettest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/boomhut/go-monero-rpc-client/wallet"
+)
+
+// JSON-RPC 2.0 error codes used for requests that never reach a method.
+const (
+	codeParseError     = -32700
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// Server serves a wallet.Client over the monero-wallet-rpc JSON-RPC
+// protocol. Like monero-wallet-rpc it only accepts single requests on
+// /json_rpc, JSON-RPC batches are rejected with a parse error.
+type Server struct {
+	*httptest.Server
+	handlers map[string]handler
+}
+
+// NewServer starts a Server for c, or for a new Wallet if c is nil. Call
+// Close when done.
+func NewServer(c wallet.Client) *Server {
+	if c == nil {
+		c = NewWallet()
+	}
+	s := &Server{handlers: handlers(c)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Address returns the URL to use as wallet.Config.Address.
+func (s *Server) Address() string {
+	return s.URL + "/json_rpc"
+}
+
+type rpcRequest struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	ID     json.RawMessage `json:"id"`
+}
+
+type rpcErrorObject struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcResponse struct {
+	ID      json.RawMessage `json:"id"`
+	Version string          `json:"jsonrpc"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcErrorObject `json:"error,omitempty"`
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/json_rpc" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req rpcRequest
+	resp := rpcResponse{ID: json.RawMessage("0"), Version: "2.0"}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		resp.Error = &rpcErrorObject{Code: codeParseError, Message: "Parse error"}
+	} else {
+		if len(req.ID) > 0 {
+			resp.ID = req.ID
+		}
+		result, err := dispatch(r.Context(), s.handlers, req.Method, req.Params)
+		if err != nil {
+			resp.Error = errorObject(err)
+		} else {
+			resp.Result = result
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func errorObject(err error) *rpcErrorObject {
+	var werr *wallet.WalletError
+	if errors.As(err, &werr) {
+		return &rpcErrorObject{Code: int(werr.Code), Message: werr.Message}
+	}
+	return &rpcErrorObject{Code: int(wallet.ErrUnknown), Message: err.Error()}
+}
+
+func dispatch(ctx context.Context, handlers map[string]handler, method string, params json.RawMessage) (interface{}, error) {
+	h, ok := handlers[method]
+	if !ok {
+		return nil, &wallet.WalletError{Code: codeMethodNotFound, Message: "Method not found"}
+	}
+	return h(ctx, params)
+}
+
+// CallRaw calls method on w as if it arrived over JSON-RPC.
+func (w *Wallet) CallRaw(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	result, err := dispatch(ctx, handlers(w), method, raw)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(result)
+}
+
+// CallBatch makes the calls one by one, like a client whose server does
+// not support batches.
+func (w *Wallet) CallBatch(ctx context.Context, calls []wallet.BatchCall) error {
+	for i := range calls {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("request aborted: %w", err)
+		}
+		raw, err := w.CallRaw(ctx, calls[i].Method, calls[i].Params)
+		if err == nil && calls[i].Result != nil {
+			err = json.Unmarshal(raw, calls[i].Result)
+		}
+		calls[i].Error = err
+	}
+	return nil
+}
+
+type handler func(ctx context.Context, params json.RawMessage) (interface{}, error)
+
+func decodeParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return &wallet.WalletError{Code: codeInvalidParams, Message: "Invalid params"}
+	}
+	return nil
+}
+
+func call[Req, Resp any](fn func(context.Context, *Req) (*Resp, error)) handler {
+	return func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		req := new(Req)
+		if err := decodeParams(params, req); err != nil {
+			return nil, err
+		}
+		resp, err := fn(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+}
+
+func callNoResult[Req any](fn func(context.Context, *Req) error) handler {
+	return func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		req := new(Req)
+		if err := decodeParams(params, req); err != nil {
+			return nil, err
+		}
+		if err := fn(ctx, req); err != nil {
+			return nil, err
+		}
+		return struct{}{}, nil
+	}
+}
+
+func callNoParams[Resp any](fn func(context.Context) (*Resp, error)) handler {
+	return func(ctx context.Context, _ json.RawMessage) (interface{}, error) {
+		resp, err := fn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+}
+
+func callBare(fn func(context.Context) error) handler {
+	return func(ctx context.Context, _ json.RawMessage) (interface{}, error) {
+		if err := fn(ctx); err != nil {
+			return nil, err
+		}
+		return struct{}{}, nil
+	}
+}
+
+// handlers maps every RPC method of wallet.Client to c.
+func handlers(c wallet.Client) map[string]handler {
+	return map[string]handler{
+		"get_balance":                 call(c.GetBalanceContext),
+		"get_address":                 call(c.GetAddressContext),
+		"get_address_index":           call(c.GetAddressIndexContext),
+		"create_address":              call(c.CreateAddressContext),
+		"label_address":               callNoResult(c.LabelAddressContext),
+		"validate_address":            call(c.ValidateAddressContext),
+		"get_accounts":                call(c.GetAccountsContext),
+		"create_account":              call(c.CreateAccountContext),
+		"label_account":               callNoResult(c.LabelAccountContext),
+		"get_account_tags":            callNoParams(c.GetAccountTagsContext),
+		"tag_accounts":                callNoResult(c.TagAccountsContext),
+		"untag_accounts":              callNoResult(c.UntagAccountsContext),
+		"set_account_tag_description": callNoResult(c.SetAccountTagDescriptionContext),
+		"get_height":                  callNoParams(c.GetHeightContext),
+		"transfer":                    call(c.TransferContext),
+		"transfer_split":              call(c.TransferSplitContext),
+		"sign_transfer":               call(c.SignTransferContext),
+		"submit_transfer":             call(c.SubmitTransferContext),
+		"sweep_dust":                  call(c.SweepDustContext),
+		"sweep_all":                   call(c.SweepAllContext),
+		"sweep_single":                call(c.SweepSingleContext),
+		"relay_tx":                    call(c.RelayTxContext),
+		"store":                       callBare(c.StoreContext),
+		"get_payments":                call(c.GetPaymentsContext),
+		"get_bulk_payments":           call(c.GetBulkPaymentsContext),
+		"incoming_transfers":          call(c.IncomingTransfersContext),
+		"query_key":                   call(c.QueryKeyContext),
+		"make_integrated_address":     call(c.MakeIntegratedAddressContext),
+		"split_integrated_address":    call(c.SplitIntegratedAddressContext),
+		"stop_wallet":                 callBare(c.StopWalletContext),
+		"rescan_blockchain":           callBare(c.RescanBlockchainContext),
+		"set_tx_notes":                callNoResult(c.SetTxNotesContext),
+		"get_tx_notes":                call(c.GetTxNotesContext),
+		"set_attribute":               callNoResult(c.SetAttributeContext),
+		"get_attribute":               call(c.GetAttributeContext),
+		"get_tx_key":                  call(c.GetTxKeyContext),
+		"check_tx_key":                call(c.CheckTxKeyContext),
+		"get_tx_proof":                call(c.GetTxProofContext),
+		"check_tx_proof":              call(c.CheckTxProofContext),
+		"get_spend_proof":             call(c.GetSpendProofContext),
+		"check_spend_proof":           call(c.CheckSpendProofContext),
+		"get_reserve_proof":           call(c.GetReserveProofContext),
+		"check_reserve_proof":         call(c.CheckReserveProofContext),
+		"get_transfers":               call(c.GetTransfersContext),
+		"get_transfer_by_txid":        call(c.GetTransferByTxIDContext),
+		"sign":                        call(c.SignContext),
+		"verify":                      call(c.VerifyContext),
+		"export_outputs":              callNoParams(c.ExportOutputsContext),
+		"import_outputs":              call(c.ImportOutputsContext),
+		"export_key_images":           callNoParams(c.ExportKeyImagesContext),
+		"import_key_images":           call(c.ImportKeyImagesContext),
+		"make_uri":                    call(c.MakeURIContext),
+		"parse_uri":                   call(c.ParseURIContext),
+		"get_address_book":            call(c.GetAddressBookContext),
+		"add_address_book":            call(c.AddAddressBookContext),
+		"delete_address_book":         callNoResult(c.DeleteAddressBookContext),
+		"refresh":                     call(c.RefreshContext),
+		"rescan_spent":                callBare(c.RescanSpentContext),
+		"start_mining":                callNoResult(c.StartMiningContext),
+		"stop_mining":                 callBare(c.StopMiningContext),
+		"get_languages":               callNoParams(c.GetLanguagesContext),
+		"create_wallet":               callNoResult(c.CreateWalletContext),
+		"generate_from_keys":          call(c.GenerateFromKeysContext),
+		"open_wallet":                 callNoResult(c.OpenWalletContext),
+		"close_wallet":                callBare(c.CloseWalletContext),
+		"change_wallet_password":      callNoResult(c.ChangeWalletPasswordContext),
+		"is_multisig":                 callNoParams(c.IsMultisigContext),
+		"prepare_multisig":            callNoParams(c.PrepareMultisigContext),
+		"make_multisig":               call(c.MakeMultisigContext),
+		"export_multisig_info":        callNoParams(c.ExportMultisigInfoContext),
+		"import_multisig_info":        call(c.ImportMultisigInfoContext),
+		"finalize_multisig":           call(c.FinalizeMultisigContext),
+		"sign_multisig":               call(c.SignMultisigContext),
+		"submit_multisig":             call(c.SubmitMultisigContext),
+		"get_version":                 callNoParams(c.GetVersionContext),
+		"set_daemon":                  callNoResult(c.SetDaemonContext),
+		"auto_refresh":                callNoResult(c.AutoRefreshContext),
+		"describe_transfer":           call(c.DescribeTransferContext),
+		"edit_address_book":           callNoResult(c.EditAddressBookContext),
+		"estimate_tx_size_and_weight": call(c.EstimateTxSizeAndWeightContext),
+		"exchange_multisig_keys":      call(c.ExchangeMultisigKeysContext),
+		"freeze":                      callNoResult(c.FreezeContext),
+		"frozen":                      call(c.FrozenContext),
+		"thaw":                        callNoResult(c.ThawContext),
+		"scan_tx":                     callNoResult(c.ScanTxContext),
+		"setup_background_sync":       call(c.SetupBackgroundSyncContext),
+		"start_background_sync":       callBare(c.StartBackgroundSyncContext),
+		"stop_background_sync":        callBare(c.StopBackgroundSyncContext),
+		"get_default_fee_priority":    callNoParams(c.GetDefaultFeePriorityContext),
+	}
+}
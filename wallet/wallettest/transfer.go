@@ -0,0 +1,448 @@
+package wallettest
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"strconv"
+
+	"github.com/boomhut/go-monero-rpc-client/wallet"
+)
+
+// plannedTx is a transaction built by transfer or sweep_* that has not been
+// applied to any wallet file yet.
+type plannedTx struct {
+	TxID           string                `json:"txid"`
+	TxKey          string                `json:"tx_key"`
+	Account        uint64                `json:"account"`
+	SubaddrIndices []uint64              `json:"subaddr_indices"`
+	KeyImages      []string              `json:"key_images"`
+	AmountIn       uint64                `json:"amount_in"`
+	Destinations   []*wallet.Destination `json:"destinations"`
+	ChangeAddress  string                `json:"change_address"`
+	Fee            uint64                `json:"fee"`
+	PaymentID      string                `json:"payment_id"`
+	UnlockTime     uint64                `json:"unlock_time"`
+	RingSize       uint64                `json:"ring_size"`
+}
+
+func (p *plannedTx) amount() uint64 {
+	var sum uint64
+	for _, d := range p.Destinations {
+		sum += d.Amount
+	}
+	return sum
+}
+
+func (p *plannedTx) change() uint64 {
+	return p.AmountIn - p.amount() - p.Fee
+}
+
+func (p *plannedTx) weight() uint64 {
+	return estimateWeight(uint64(len(p.KeyImages)), uint64(len(p.Destinations))+1, p.RingSize)
+}
+
+// txSet is what unsigned_txset, signed_txset, multisig_txset and
+// tx_metadata carry: the planned transactions, hex encoded JSON.
+type txSet struct {
+	Txs        []*plannedTx `json:"txs"`
+	Signed     bool         `json:"signed,omitempty"`
+	Signatures uint64       `json:"signatures,omitempty"`
+}
+
+func (s *txSet) encode() string {
+	data, _ := json.Marshal(s)
+	return hex.EncodeToString(data)
+}
+
+func decodeTxSet(s string) (*txSet, bool) {
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, false
+	}
+	var set txSet
+	if err := json.Unmarshal(data, &set); err != nil || len(set.Txs) == 0 {
+		return nil, false
+	}
+	return &set, true
+}
+
+// estimateWeight is a rough CLSAG/Bulletproofs+ size estimate.
+func estimateWeight(inputs, outputs, ringSize uint64) uint64 {
+	if ringSize == 0 {
+		ringSize = 16
+	}
+	return 100 + inputs*(32*ringSize+32*2+40) + outputs*(32*3+8) + 32*(6+2*bpRounds(outputs))
+}
+
+func bpRounds(outputs uint64) uint64 {
+	rounds := uint64(6)
+	for n := uint64(1); n < outputs; n *= 2 {
+		rounds++
+	}
+	return rounds
+}
+
+// spendable returns the unspent, unfrozen outputs of account in the given
+// subaddresses (all if empty) that match filter.
+func (w *Wallet) spendable(f *file, major uint64, subaddrs []uint64, filter func(*output) bool) []*output {
+	in := make(map[uint64]bool, len(subaddrs))
+	for _, i := range subaddrs {
+		in[i] = true
+	}
+	var outs []*output
+	for _, o := range f.outputs {
+		if o.spent || o.frozen || o.major != major || (len(in) > 0 && !in[o.minor]) {
+			continue
+		}
+		if filter != nil && !filter(o) {
+			continue
+		}
+		outs = append(outs, o)
+	}
+	return outs
+}
+
+func (w *Wallet) txFee(priority wallet.Priority) uint64 {
+	factor, ok := priorityFactor[priority]
+	if !ok {
+		factor = 1000
+	}
+	return w.fee * factor
+}
+
+// sumOverflow rejects destinations whose amounts, plus the fee, do not fit
+// in a uint64 and would otherwise wrap around the balance check.
+func sumOverflow() error {
+	return rpcError(wallet.ErrNotEnoughMoney, "Transaction sum + fee exceeds %d", uint64(math.MaxUint64))
+}
+
+func (w *Wallet) checkDestinations(dests []*wallet.Destination, paymentID string) (string, error) {
+	if len(dests) == 0 {
+		return "", rpcError(wallet.ErrZeroDestination, "No destinations for this transfer")
+	}
+	if paymentID != "" {
+		return "", rpcError(wallet.ErrWrongPaymentID, "Standalone payment IDs are obsolete. Use subaddresses or integrated addresses instead")
+	}
+	var sum uint64
+	for _, d := range dests {
+		kind := addressKind(d.Address)
+		if kind == "" {
+			return "", rpcError(wallet.ErrWrongAddress, "WALLET_RPC_ERROR_CODE_WRONG_ADDRESS: %s", d.Address)
+		}
+		if d.Amount == 0 {
+			return "", rpcError(wallet.ErrZeroAmount, "Amount cannot be zero")
+		}
+		if sum+d.Amount < sum {
+			return "", sumOverflow()
+		}
+		sum += d.Amount
+		if kind == "integrated" {
+			if paymentID != "" {
+				return "", rpcError(wallet.ErrWrongPaymentID, "A single payment id is allowed per transaction")
+			}
+			_, paymentID, _ = splitIntegratedAddress(d.Address)
+		}
+	}
+	return paymentID, nil
+}
+
+// planTransfer selects unlocked outputs of account to pay dests plus fee.
+// The fee is split over the destinations in subtractFrom, if any.
+func (w *Wallet) planTransfer(f *file, major uint64, subaddrs []uint64, dests []*wallet.Destination, subtractFrom []uint64,
+	priority wallet.Priority, paymentID string, unlockTime, ringSize uint64) (*plannedTx, error) {
+	a, err := f.account(major)
+	if err != nil {
+		return nil, err
+	}
+	paymentID, err = w.checkDestinations(dests, paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &plannedTx{
+		TxID:          w.hash("tx"),
+		TxKey:         w.hash("tx key"),
+		Account:       major,
+		ChangeAddress: a.subaddresses[0].address,
+		Fee:           w.txFee(priority),
+		PaymentID:     paymentID,
+		UnlockTime:    unlockTime,
+		RingSize:      ringSize,
+	}
+	for _, d := range dests {
+		p.Destinations = append(p.Destinations, &wallet.Destination{Amount: d.Amount, Address: d.Address})
+	}
+	if err := p.subtractFee(subtractFrom); err != nil {
+		return nil, err
+	}
+	needed := p.amount() + p.Fee
+	if needed < p.Fee {
+		return nil, sumOverflow()
+	}
+
+	candidates := w.spendable(f, major, subaddrs, nil)
+	var total, unlocked uint64
+	for _, o := range candidates {
+		total += o.amount
+		if w.unlocked(o) {
+			unlocked += o.amount
+		}
+	}
+	switch {
+	case total < needed:
+		return nil, rpcError(wallet.ErrNotEnoughMoney, "")
+	case unlocked < needed:
+		return nil, rpcError(wallet.ErrNotEnoughUnlockedMoney, "")
+	}
+	for _, o := range candidates {
+		if p.AmountIn >= needed {
+			break
+		}
+		if w.unlocked(o) {
+			p.use(o)
+		}
+	}
+	return p, nil
+}
+
+// planSweep spends all unlocked outputs of account matching filter to
+// address.
+func (w *Wallet) planSweep(f *file, major uint64, subaddrs []uint64, address string,
+	priority wallet.Priority, unlockTime, ringSize uint64, filter func(*output) bool) (*plannedTx, error) {
+	a, err := f.account(major)
+	if err != nil {
+		return nil, err
+	}
+	paymentID, err := w.checkDestinations([]*wallet.Destination{{Address: address, Amount: 1}}, "")
+	if err != nil {
+		return nil, err
+	}
+	p := &plannedTx{
+		TxID:          w.hash("tx"),
+		TxKey:         w.hash("tx key"),
+		Account:       major,
+		ChangeAddress: a.subaddresses[0].address,
+		Fee:           w.txFee(priority),
+		PaymentID:     paymentID,
+		UnlockTime:    unlockTime,
+		RingSize:      ringSize,
+	}
+	for _, o := range w.spendable(f, major, subaddrs, filter) {
+		if w.unlocked(o) {
+			p.use(o)
+		}
+	}
+	if len(p.KeyImages) == 0 {
+		return nil, rpcError(wallet.ErrTxNotPossible, "No unlocked balance in the specified subaddress(es)")
+	}
+	if p.AmountIn <= p.Fee {
+		return nil, rpcError(wallet.ErrNotEnoughUnlockedMoney, "")
+	}
+	p.Destinations = []*wallet.Destination{{Address: address, Amount: p.AmountIn - p.Fee}}
+	return p, nil
+}
+
+func (p *plannedTx) subtractFee(indices []uint64) error {
+	if len(indices) == 0 {
+		return nil
+	}
+	share, rest := p.Fee/uint64(len(indices)), p.Fee%uint64(len(indices))
+	for n, i := range indices {
+		if i >= uint64(len(p.Destinations)) {
+			return rpcError(wallet.ErrWrongIndex, "subtract_fee_from_outputs index out of range: %d", i)
+		}
+		cut := share
+		if n == 0 {
+			cut += rest
+		}
+		d := p.Destinations[i]
+		if d.Amount <= cut {
+			return rpcError(wallet.ErrGenericTransferError, "Amount too small to pay the fee")
+		}
+		d.Amount -= cut
+	}
+	return nil
+}
+
+func (p *plannedTx) use(o *output) {
+	p.KeyImages = append(p.KeyImages, o.keyImage)
+	p.AmountIn += o.amount
+	for _, i := range p.SubaddrIndices {
+		if i == o.minor {
+			return
+		}
+	}
+	p.SubaddrIndices = append(p.SubaddrIndices, o.minor)
+}
+
+// apply spends the inputs of p in f, records the outgoing transfer and
+// delivers the outputs to every wallet file owning a destination. The
+// transaction enters the pool and is confirmed by MineBlocks.
+func (w *Wallet) apply(f *file, p *plannedTx) error {
+	spend := make([]*output, 0, len(p.KeyImages))
+	for _, ki := range p.KeyImages {
+		o := f.output(ki)
+		if o == nil || o.spent {
+			return rpcError(wallet.ErrGenericTransferError, "transaction was rejected by daemon: double spend")
+		}
+		spend = append(spend, o)
+	}
+	for _, o := range spend {
+		o.spent = true
+	}
+
+	t := &wallet.Transfer{
+		Address:      p.ChangeAddress,
+		Amount:       p.amount(),
+		Fee:          p.Fee,
+		PaymentID:    p.PaymentID,
+		TxID:         p.TxID,
+		Type:         "pending",
+		UnlockTime:   p.UnlockTime,
+		Timestamp:    timestamp(w.height),
+		Destinations: p.Destinations,
+	}
+	if t.PaymentID == "" {
+		t.PaymentID = "0000000000000000"
+	}
+	t.SubaddrIndex.Major = p.Account
+	for _, d := range p.Destinations {
+		t.Amounts = append(t.Amounts, d.Amount)
+	}
+	t.SubaddrIndices = grow(t.SubaddrIndices, len(p.SubaddrIndices))
+	for i, minor := range p.SubaddrIndices {
+		t.SubaddrIndices[i].Major = p.Account
+		t.SubaddrIndices[i].Minor = minor
+	}
+	f.transfers = append(f.transfers, t)
+	f.txKeys[p.TxID] = p.TxKey
+
+	if change := p.change(); change > 0 {
+		f.outputs = append(f.outputs, &output{
+			amount:      change,
+			txHash:      p.TxID,
+			keyImage:    hashOf("key image", p.TxID, p.ChangeAddress, "change"),
+			pubKey:      hashOf("pubkey", p.TxID, p.ChangeAddress, "change"),
+			globalIndex: w.globalIndex(),
+			major:       p.Account,
+			change:      true,
+		})
+	}
+	for i, d := range p.Destinations {
+		w.deliver(p, i, d)
+	}
+	return nil
+}
+
+// deliver credits output i of p to all wallet files owning its address.
+func (w *Wallet) deliver(p *plannedTx, i int, d *wallet.Destination) {
+	address, paymentID := d.Address, p.PaymentID
+	if standard, pid, ok := splitIntegratedAddress(d.Address); ok {
+		address, paymentID = standard, pid
+	}
+	for _, name := range w.fileNames() {
+		f := w.files[name]
+		major, minor, ok := f.find(address)
+		if !ok {
+			continue
+		}
+		index := strconv.Itoa(i)
+		o := &output{
+			amount:      d.Amount,
+			txHash:      p.TxID,
+			keyImage:    hashOf("key image", p.TxID, address, index),
+			pubKey:      hashOf("pubkey", p.TxID, address, index),
+			globalIndex: w.globalIndex(),
+			major:       major,
+			minor:       minor,
+			unlockTime:  p.UnlockTime,
+		}
+		t := &wallet.Transfer{
+			Address:    address,
+			Amount:     d.Amount,
+			PaymentID:  paymentID,
+			TxID:       p.TxID,
+			Type:       "pool",
+			UnlockTime: p.UnlockTime,
+			Timestamp:  timestamp(w.height),
+		}
+		if t.PaymentID == "" {
+			t.PaymentID = "0000000000000000"
+		}
+		t.SubaddrIndex.Major = major
+		t.SubaddrIndex.Minor = minor
+		f.outputs = append(f.outputs, o)
+		f.transfers = append(f.transfers, t)
+	}
+}
+
+func (f *file) output(keyImage string) *output {
+	for _, o := range f.outputs {
+		if o.keyImage == keyImage {
+			return o
+		}
+	}
+	return nil
+}
+
+func (f *file) find(address string) (major, minor uint64, ok bool) {
+	for i, a := range f.accounts {
+		for j, s := range a.subaddresses {
+			if s.address == address {
+				return uint64(i), uint64(j), true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// outcome is what the transfer methods return for one planned tx.
+type outcome struct {
+	txHash, txKey, txBlob, txMetadata string
+	multisigTxSet, unsignedTxSet      string
+}
+
+// send applies or exports p according to the wallet type and the request
+// flags, like monero-wallet-rpc does: watch-only wallets return an
+// unsigned tx set, multisig wallets a multisig tx set, and do_not_relay
+// keeps the tx out of the pool.
+func (w *Wallet) send(f *file, txs []*plannedTx, doNotRelay, getTxKey, getTxHex, getTxMetadata bool) ([]outcome, error) {
+	out := make([]outcome, len(txs))
+	switch {
+	case f.viewOnly:
+		set := (&txSet{Txs: txs}).encode()
+		for i := range out {
+			out[i].unsignedTxSet = set
+		}
+		return out, nil
+	case f.multisig:
+		if !f.multisigReady {
+			return nil, rpcError(wallet.ErrNotMultisig, "This wallet is multisig, but not yet finalized")
+		}
+		set := (&txSet{Txs: txs, Signatures: 1}).encode()
+		for i := range out {
+			out[i].multisigTxSet = set
+		}
+		return out, nil
+	}
+
+	for i, p := range txs {
+		if !doNotRelay {
+			if err := w.apply(f, p); err != nil {
+				return nil, err
+			}
+		}
+		out[i].txHash = p.TxID
+		if getTxKey {
+			out[i].txKey = p.TxKey
+		}
+		if getTxHex {
+			out[i].txBlob = hex.EncodeToString([]byte("tx:" + p.TxID))
+		}
+		if getTxMetadata {
+			out[i].txMetadata = (&txSet{Txs: []*plannedTx{p}, Signed: true}).encode()
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,502 @@
+// Package wallettest provides an in-memory monero-wallet-rpc for tests.
+//
+// Wallet implements wallet.Client on top of a programmable in-memory state
+// (wallet files, accounts, subaddresses, outputs, transfers, address book,
+// attributes, frozen outputs, ...) and Server exposes any wallet.Client
+// over the monero-wallet-rpc JSON-RPC protocol on an httptest.Server:
+//
+//	w := wallettest.NewWallet()
+//	w.Receive(wallettest.Payment{Amount: 5e12, Height: 1})
+//	srv := wallettest.NewServer(w)
+//	defer srv.Close()
+//
+//	client := wallet.New(wallet.Config{Address: srv.Address()})
+//
+// Tests that do not need the JSON-RPC layer can use a Wallet directly as
+// the wallet.Client, which is much faster. MineBlocks advances the chain:
+// pool transactions confirm and outputs unlock after SpendableAge blocks
+// or at their unlock height, so GetBalance, GetTransfers,
+// IncomingTransfers and Transfer always agree with each other.
+//
+// Addresses, hashes, keys and proofs are fake but deterministic and
+// consistent with each other: an integrated address splits back into its
+// parts, a proof made by one wallet file verifies in another, and funds
+// sent to an address of any wallet file in the same Wallet arrive there.
+package wallettest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/boomhut/go-monero-rpc-client/wallet"
+)
+
+const (
+	// DefaultFileName is the name of the wallet file NewWallet opens.
+	DefaultFileName = "wallet"
+	// DefaultHeight is the chain height of a new Wallet.
+	DefaultHeight = 100
+	// DefaultFee is the base fee per transaction in atomic units. It is
+	// multiplied by the priority factor (1, 1, 5, 25).
+	DefaultFee = 30000000
+	// SpendableAge is the number of confirmations after which an output
+	// is unlocked, as CRYPTONOTE_DEFAULT_TX_SPENDABLE_AGE.
+	SpendableAge = 10
+	// BlockTime is the simulated time between blocks in seconds.
+	BlockTime = 120
+	// GenesisTime is the simulated timestamp of block 0.
+	GenesisTime = 1700000000
+)
+
+var priorityFactor = map[wallet.Priority]uint64{
+	wallet.PriorityDefault:     1,
+	wallet.PriorityUnimportant: 1,
+	wallet.PriorityNormal:      5,
+	wallet.PriorityElevated:    25,
+}
+
+var _ wallet.Client = (*Wallet)(nil)
+
+// Wallet is an in-memory monero-wallet-rpc. It implements wallet.Client and
+// is safe for concurrent use. The zero value is not usable, use NewWallet.
+type Wallet struct {
+	mu sync.Mutex
+
+	height  uint64
+	fee     uint64
+	counter uint64
+	files   map[string]*file
+	open    *file
+	mining  bool
+	daemon  wallet.RequestSetDaemon
+
+	failNext map[string][]wallet.ErrorCode
+	fail     map[string]wallet.ErrorCode
+
+	// proofs made by any wallet file, so that other files can check them
+	reserveProofs map[string]reserveProof
+}
+
+type reserveProof struct {
+	address string
+	message string
+	amount  uint64
+}
+
+// file is a wallet file. Only one is open at a time.
+type file struct {
+	name     string
+	password string
+	address  string
+	viewOnly bool
+
+	accounts    []*account
+	tagDesc     map[string]string
+	outputs     []*output
+	transfers   []*wallet.Transfer
+	txKeys      map[string]string
+	notes       map[string]string
+	attributes  map[string]string
+	addressBook []*addressBookEntry
+
+	multisig      bool
+	multisigReady bool
+	multisigSeed  string
+	threshold     uint64
+	total         uint64
+
+	autoRefresh       bool
+	lastRefresh       uint64
+	backgroundSync    string
+	backgroundSyncing bool
+}
+
+type account struct {
+	label        string
+	tag          string
+	subaddresses []*subaddress
+}
+
+type subaddress struct {
+	address string
+	label   string
+}
+
+type output struct {
+	amount      uint64
+	txHash      string
+	keyImage    string
+	pubKey      string
+	globalIndex uint64
+	major       uint64
+	minor       uint64
+	spent       bool
+	frozen      bool
+	// change outputs count towards the balance while unconfirmed
+	change bool
+	// blockHeight is the block containing the output, valid if confirmed.
+	blockHeight uint64
+	confirmed   bool
+	unlockTime  uint64
+}
+
+type addressBookEntry struct {
+	address     string
+	description string
+	paymentID   string
+}
+
+// NewWallet returns a Wallet at DefaultHeight with an open wallet file
+// named DefaultFileName that has one account and no funds.
+func NewWallet() *Wallet {
+	w := &Wallet{
+		height:        DefaultHeight,
+		fee:           DefaultFee,
+		files:         make(map[string]*file),
+		failNext:      make(map[string][]wallet.ErrorCode),
+		fail:          make(map[string]wallet.ErrorCode),
+		reserveProofs: make(map[string]reserveProof),
+	}
+	w.open = w.newFile(DefaultFileName, "", fakeAddress('4', DefaultFileName))
+	return w
+}
+
+func (w *Wallet) newFile(name, password, address string) *file {
+	f := &file{
+		name:        name,
+		password:    password,
+		address:     address,
+		tagDesc:     make(map[string]string),
+		txKeys:      make(map[string]string),
+		notes:       make(map[string]string),
+		attributes:  make(map[string]string),
+		lastRefresh: w.height,
+	}
+	f.addAccount("Primary account")
+	w.files[name] = f
+	return f
+}
+
+func (f *file) addAccount(label string) *account {
+	a := &account{label: label}
+	f.accounts = append(f.accounts, a)
+	f.addSubaddress(uint64(len(f.accounts)-1), label)
+	return a
+}
+
+func (f *file) addSubaddress(major uint64, label string) uint64 {
+	a := f.accounts[major]
+	minor := uint64(len(a.subaddresses))
+	a.subaddresses = append(a.subaddresses, &subaddress{
+		address: subaddressFor(f.address, major, minor),
+		label:   label,
+	})
+	return minor
+}
+
+// Payment describes funds received by the wallet, see Receive.
+type Payment struct {
+	// File receiving the payment; defaults to the open wallet file.
+	File string
+	// Account and Subaddress receiving the payment.
+	Account    uint64
+	Subaddress uint64
+	Amount     uint64
+	PaymentID  string
+	// UnlockTime is the block height before which the output cannot be
+	// spent, 0 for none.
+	UnlockTime uint64
+	// Height of the block containing the payment. Zero means the top
+	// block, i.e. one confirmation. Use a low height (e.g. 1) for funds
+	// that are already spendable.
+	Height uint64
+	// Pool leaves the payment in the transaction pool until MineBlocks.
+	Pool bool
+}
+
+// Receive credits a payment to the wallet and returns its transaction id.
+// Missing accounts and subaddresses are created.
+func (w *Wallet) Receive(p Payment) string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	f := w.open
+	if p.File != "" {
+		f = w.files[p.File]
+	}
+	if f == nil {
+		panic("wallettest: no wallet file " + strconv.Quote(p.File))
+	}
+	for uint64(len(f.accounts)) <= p.Account {
+		f.addAccount("")
+	}
+	for uint64(len(f.accounts[p.Account].subaddresses)) <= p.Subaddress {
+		f.addSubaddress(p.Account, "")
+	}
+
+	txid := w.hash("tx")
+	height := p.Height
+	if height == 0 || height >= w.height {
+		height = w.height - 1
+	}
+	w.credit(f, txid, p.Account, p.Subaddress, p.Amount, p.PaymentID, p.UnlockTime, !p.Pool, height)
+	return txid
+}
+
+// credit adds an incoming output and transfer to f.
+func (w *Wallet) credit(f *file, txid string, major, minor, amount uint64, paymentID string, unlockTime uint64, confirmed bool, height uint64) {
+	o := &output{
+		amount:      amount,
+		txHash:      txid,
+		keyImage:    w.hash("key image"),
+		pubKey:      w.hash("pubkey"),
+		globalIndex: w.globalIndex(),
+		major:       major,
+		minor:       minor,
+		confirmed:   confirmed,
+		unlockTime:  unlockTime,
+	}
+	t := &wallet.Transfer{
+		Address:    f.accounts[major].subaddresses[minor].address,
+		Amount:     amount,
+		PaymentID:  paymentID,
+		TxID:       txid,
+		Type:       "pool",
+		UnlockTime: unlockTime,
+		Timestamp:  timestamp(w.height),
+	}
+	t.SubaddrIndex.Major = major
+	t.SubaddrIndex.Minor = minor
+	if confirmed {
+		o.blockHeight = height
+		t.Type = "in"
+		t.Height = height
+		t.Timestamp = timestamp(height)
+	}
+	if t.PaymentID == "" {
+		t.PaymentID = "0000000000000000"
+	}
+	f.outputs = append(f.outputs, o)
+	f.transfers = append(f.transfers, t)
+}
+
+// MineBlocks advances the chain by n blocks. Transactions in the pool are
+// included in the first new block.
+func (w *Wallet) MineBlocks(n uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if n == 0 {
+		return
+	}
+	block := w.height
+	for _, f := range w.files {
+		mined := make(map[string]bool)
+		for _, t := range f.transfers {
+			switch t.Type {
+			case "pool":
+				t.Type = "in"
+			case "pending":
+				t.Type = "out"
+			default:
+				continue
+			}
+			t.Height = block
+			t.Timestamp = timestamp(block)
+			mined[t.TxID] = true
+		}
+		for _, o := range f.outputs {
+			if !o.confirmed && mined[o.txHash] {
+				o.confirmed = true
+				o.blockHeight = block
+			}
+		}
+	}
+	w.height += n
+}
+
+// SetHeight sets the chain height without mining the pool.
+func (w *Wallet) SetHeight(height uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.height = height
+}
+
+// Height returns the chain height.
+func (w *Wallet) Height() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.height
+}
+
+// SetFee sets the base fee per transaction in atomic units.
+func (w *Wallet) SetFee(fee uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.fee = fee
+}
+
+// FailNext makes the next call of the RPC method (e.g. "transfer") fail
+// with code. Calls queue up, so FailNext twice fails two calls.
+func (w *Wallet) FailNext(method string, code wallet.ErrorCode) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.failNext[method] = append(w.failNext[method], code)
+}
+
+// Fail makes every call of the RPC method fail with code until
+// ClearErrors is called.
+func (w *Wallet) Fail(method string, code wallet.ErrorCode) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.fail[method] = code
+}
+
+// ClearErrors removes all errors set up by FailNext and Fail.
+func (w *Wallet) ClearErrors() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.failNext = make(map[string][]wallet.ErrorCode)
+	w.fail = make(map[string]wallet.ErrorCode)
+}
+
+// errorMessages are the messages monero-wallet-rpc sends with some codes.
+var errorMessages = map[wallet.ErrorCode]string{
+	wallet.ErrNotOpen:                 "No wallet file",
+	wallet.ErrWrongTxID:               "Transaction not found.",
+	wallet.ErrNotEnoughMoney:          "not enough money",
+	wallet.ErrNotEnoughUnlockedMoney:  "not enough unlocked money",
+	wallet.ErrDaemonIsBusy:            "daemon is busy. Please try again later.",
+	wallet.ErrNoDaemonConnection:      "no connection to daemon. Please make sure daemon is running.",
+	wallet.ErrAccountIndexOutOfBounds: "Account index is out of bound",
+	wallet.ErrAddressIndexOutOfBounds: "Address index is out of bound",
+	wallet.ErrAttributeNotFound:       "Attribute not found.",
+}
+
+func rpcError(code wallet.ErrorCode, format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	if msg == "" {
+		msg = errorMessages[code]
+	}
+	if msg == "" {
+		msg = code.String()
+	}
+	return &wallet.WalletError{Code: code, Message: msg}
+}
+
+// Methods that work without an open wallet file.
+var noWalletMethods = map[string]bool{
+	"get_version": true, "get_languages": true, "create_wallet": true,
+	"open_wallet": true, "generate_from_keys": true,
+}
+
+// Methods that work while background syncing.
+var backgroundSyncMethods = map[string]bool{
+	"get_version": true, "get_height": true, "close_wallet": true, "store": true,
+	"stop_wallet": true, "stop_background_sync": true, "refresh": true,
+	"get_languages": true, "auto_refresh": true,
+}
+
+// begin is called with w.mu held at the start of every RPC method. It
+// returns the open wallet file or the error the call must fail with.
+func (w *Wallet) begin(ctx context.Context, method string) (*file, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("request aborted: %w", err)
+	}
+	if codes := w.failNext[method]; len(codes) > 0 {
+		w.failNext[method] = codes[1:]
+		return nil, rpcError(codes[0], "")
+	}
+	if code, ok := w.fail[method]; ok {
+		return nil, rpcError(code, "")
+	}
+	if w.open == nil && !noWalletMethods[method] {
+		return nil, rpcError(wallet.ErrNotOpen, "")
+	}
+	if w.open != nil && w.open.backgroundSyncing && !backgroundSyncMethods[method] {
+		return nil, rpcError(wallet.ErrIsBackgroundSyncing, "Wallet is background syncing")
+	}
+	return w.open, nil
+}
+
+// hash returns a fresh, deterministic 32-byte hex string.
+func (w *Wallet) hash(kind string) string {
+	w.counter++
+	return hashOf(kind, strconv.FormatUint(w.counter, 10))
+}
+
+// globalIndex returns a fresh global output index.
+func (w *Wallet) globalIndex() uint64 {
+	w.counter++
+	return w.counter
+}
+
+func hashOf(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func timestamp(height uint64) uint64 {
+	return GenesisTime + height*BlockTime
+}
+
+func (w *Wallet) unlocked(o *output) bool {
+	return o.confirmed && w.height-o.blockHeight >= SpendableAge && w.height >= o.unlockTime
+}
+
+func (w *Wallet) blocksToUnlock(o *output) uint64 {
+	if w.unlocked(o) {
+		return 0
+	}
+	at := o.unlockTime
+	if o.confirmed {
+		if h := o.blockHeight + SpendableAge; h > at {
+			at = h
+		}
+	} else if h := w.height + SpendableAge; h > at {
+		at = h
+	}
+	if at <= w.height {
+		return 0
+	}
+	return at - w.height
+}
+
+func (w *Wallet) confirmations(t *wallet.Transfer) uint64 {
+	if t.Height == 0 || t.Height >= w.height {
+		return 0
+	}
+	return w.height - t.Height
+}
+
+// fileNames returns the names of all wallet files in order.
+func (w *Wallet) fileNames() []string {
+	names := make([]string, 0, len(w.files))
+	for name := range w.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// account returns the account at index or an out of bounds error.
+func (f *file) account(index uint64) (*account, error) {
+	if index >= uint64(len(f.accounts)) {
+		return nil, rpcError(wallet.ErrAccountIndexOutOfBounds, "")
+	}
+	return f.accounts[index], nil
+}
+
+// grow appends n zero elements to s. It lets the methods fill response
+// slices of anonymous struct types without repeating the type.
+func grow[S ~[]E, E any](s S, n int) S {
+	return append(s, make(S, n)...)
+}
@@ -0,0 +1,382 @@
+package wallettest
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/boomhut/go-monero-rpc-client/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+func newClient(t *testing.T, w *Wallet) wallet.Client {
+	t.Helper()
+	if w == nil {
+		w = NewWallet()
+	}
+	srv := NewServer(w)
+	t.Cleanup(srv.Close)
+	return wallet.New(wallet.Config{Address: srv.Address()})
+}
+
+func TestReceiveAndBalance(t *testing.T) {
+	w := NewWallet()
+	w.Receive(Payment{Amount: 5e12, Height: 1})
+	w.Receive(Payment{Subaddress: 2, Amount: 1e12})
+	w.Receive(Payment{Amount: 7e12, Pool: true})
+	cl := newClient(t, w)
+
+	res, err := cl.GetBalance(&wallet.RequestGetBalance{})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(6e12), res.Balance)
+	assert.Equal(t, uint64(5e12), res.UnlockedBalance)
+	assert.Equal(t, uint64(SpendableAge-1), res.BlocksToUnlock)
+	assert.Len(t, res.PerSubaddress, 2)
+	assert.Equal(t, uint64(2), res.PerSubaddress[1].AddressIndex)
+
+	transfers, err := cl.GetTransfers(&wallet.RequestGetTransfers{In: true, Pool: true})
+	assert.NoError(t, err)
+	assert.Len(t, transfers.In, 2)
+	assert.Len(t, transfers.Pool, 1)
+
+	w.MineBlocks(SpendableAge - 1)
+	res, err = cl.GetBalance(&wallet.RequestGetBalance{})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(13e12), res.Balance)
+	assert.Equal(t, uint64(6e12), res.UnlockedBalance)
+}
+
+func TestTransferBetweenAccounts(t *testing.T) {
+	w := NewWallet()
+	w.Receive(Payment{Amount: 10e12, Height: 1})
+	cl := newClient(t, w)
+
+	acct, err := cl.CreateAccount(&wallet.RequestCreateAccount{Label: "savings"})
+	assert.NoError(t, err)
+	res, err := cl.Transfer(&wallet.RequestTransfer{
+		Destinations: []*wallet.Destination{{Address: acct.Address, Amount: 4e12}},
+		Priority:     wallet.PriorityNormal,
+		GetTxKey:     true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4e12), res.Amount)
+	assert.Equal(t, uint64(5*DefaultFee), res.Fee)
+	assert.Len(t, res.TxHash, 64)
+	assert.NotEmpty(t, res.TxKey)
+
+	bal, err := cl.GetBalance(&wallet.RequestGetBalance{})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(6e12-5*DefaultFee), bal.Balance)
+	assert.Equal(t, uint64(0), bal.UnlockedBalance)
+
+	pending, err := cl.GetTransfers(&wallet.RequestGetTransfers{Pending: true})
+	assert.NoError(t, err)
+	assert.Len(t, pending.Pending, 1)
+
+	w.MineBlocks(SpendableAge)
+	bal, err = cl.GetBalance(&wallet.RequestGetBalance{AccountIndex: acct.AccountIndex})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4e12), bal.UnlockedBalance)
+
+	tx, err := cl.GetTransferByTxID(&wallet.RequestGetTransferByTxID{TxID: res.TxHash})
+	assert.NoError(t, err)
+	assert.Equal(t, "out", tx.Transfer.Type)
+	assert.Equal(t, uint64(SpendableAge), tx.Transfer.Confirmations)
+
+	key, err := cl.GetTxKey(&wallet.RequestGetTxKey{TxID: res.TxHash})
+	assert.NoError(t, err)
+	check, err := cl.CheckTxKey(&wallet.RequestCheckTxKey{TxID: res.TxHash, TxKey: key.TxKey, Address: acct.Address})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4e12), check.Received)
+}
+
+func TestChangeGlobalIndex(t *testing.T) {
+	w := NewWallet()
+	w.Receive(Payment{Amount: 10e12, Height: 1})
+	cl := newClient(t, w)
+
+	acct, err := cl.CreateAccount(&wallet.RequestCreateAccount{})
+	assert.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err := cl.Transfer(&wallet.RequestTransfer{
+			Destinations: []*wallet.Destination{{Address: acct.Address, Amount: 1e12}},
+		})
+		assert.NoError(t, err)
+		w.MineBlocks(SpendableAge)
+	}
+
+	// Every output, change included, has its own global index.
+	indices := map[uint64]bool{}
+	for _, account := range []uint64{0, acct.AccountIndex} {
+		res, err := cl.IncomingTransfers(&wallet.RequestIncomingTransfers{TransferType: "all", AccountIndex: account})
+		assert.NoError(t, err)
+		for _, o := range res.Transfers {
+			assert.False(t, indices[o.GlobalIndex], "duplicate global index %d", o.GlobalIndex)
+			indices[o.GlobalIndex] = true
+		}
+	}
+	assert.Len(t, indices, 5)
+}
+
+func TestTransferErrors(t *testing.T) {
+	w := NewWallet()
+	w.Receive(Payment{Amount: 1e12, Height: 1})
+	w.Receive(Payment{Amount: 5e12})
+	cl := newClient(t, w)
+	addr, err := cl.GetAddress(&wallet.RequestGetAddress{})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		dests []*wallet.Destination
+		code  wallet.ErrorCode
+	}{
+		{"no destinations", nil, wallet.ErrZeroDestination},
+		{"bad address", []*wallet.Destination{{Address: "nope", Amount: 1}}, wallet.ErrWrongAddress},
+		{"zero amount", []*wallet.Destination{{Address: addr.Address}}, wallet.ErrZeroAmount},
+		{"locked", []*wallet.Destination{{Address: addr.Address, Amount: 2e12}}, wallet.ErrNotEnoughUnlockedMoney},
+		{"too much", []*wallet.Destination{{Address: addr.Address, Amount: 7e12}}, wallet.ErrNotEnoughMoney},
+		{"overflow", []*wallet.Destination{{Address: addr.Address, Amount: 1 << 63}, {Address: addr.Address, Amount: 1<<63 + 1e12}}, wallet.ErrNotEnoughMoney},
+		{"overflow with fee", []*wallet.Destination{{Address: addr.Address, Amount: math.MaxUint64}}, wallet.ErrNotEnoughMoney},
+	}
+	for _, tt := range tests {
+		_, err := cl.Transfer(&wallet.RequestTransfer{Destinations: tt.dests})
+		assert.True(t, errors.Is(err, tt.code), "%s: got %v", tt.name, err)
+	}
+}
+
+func TestFailNext(t *testing.T) {
+	w := NewWallet()
+	cl := newClient(t, w)
+
+	w.FailNext("get_height", wallet.ErrDaemonIsBusy)
+	_, err := cl.GetHeight()
+	assert.True(t, errors.Is(err, wallet.ErrDaemonIsBusy), "got %v", err)
+	res, err := cl.GetHeight()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(DefaultHeight), res.Height)
+
+	w.Fail("get_height", wallet.ErrNoDaemonConnection)
+	_, err = cl.GetHeight()
+	assert.True(t, errors.Is(err, wallet.ErrNoDaemonConnection), "got %v", err)
+	w.ClearErrors()
+	_, err = cl.GetHeight()
+	assert.NoError(t, err)
+
+	assert.NoError(t, cl.CloseWallet())
+	_, err = cl.GetBalance(&wallet.RequestGetBalance{})
+	assert.True(t, errors.Is(err, wallet.ErrNotOpen), "got %v", err)
+}
+
+func TestIntegratedAddressAndURI(t *testing.T) {
+	cl := newClient(t, nil)
+
+	ia, err := cl.MakeIntegratedAddress(&wallet.RequestMakeIntegratedAddress{PaymentID: "0123456789abcdef"})
+	assert.NoError(t, err)
+	split, err := cl.SplitIntegratedAddress(&wallet.RequestSplitIntegratedAddress{IntegratedAddress: ia.IntegratedAddress})
+	assert.NoError(t, err)
+	assert.Equal(t, "0123456789abcdef", split.PaymentID)
+	valid, err := cl.ValidateAddress(&wallet.RequestValidateAddress{Address: ia.IntegratedAddress})
+	assert.NoError(t, err)
+	assert.True(t, valid.Valid)
+	assert.True(t, valid.Integrated)
+
+	uri, err := cl.MakeURI(&wallet.RequestMakeURI{Address: split.StandardAddress, Amount: 1500000000000, RecipientName: "Jane Doe"})
+	assert.NoError(t, err)
+	assert.Equal(t, "monero:"+split.StandardAddress+"?tx_amount=1.500000000000&recipient_name=Jane+Doe", uri.URI)
+	parsed, err := cl.ParseURI(&wallet.RequestParseURI{URI: uri.URI})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1500000000000), parsed.URI.Amount)
+	assert.Equal(t, "Jane Doe", parsed.URI.RecipientName)
+
+	_, err = cl.ParseURI(&wallet.RequestParseURI{URI: "bitcoin:abc"})
+	assert.True(t, errors.Is(err, wallet.ErrWrongURI), "got %v", err)
+}
+
+func TestFreezeOutputs(t *testing.T) {
+	w := NewWallet()
+	w.Receive(Payment{Amount: 2e12, Height: 1})
+	cl := newClient(t, w)
+
+	outs, err := cl.IncomingTransfers(&wallet.RequestIncomingTransfers{TransferType: "available"})
+	assert.NoError(t, err)
+	assert.Len(t, outs.Transfers, 1)
+	ki := outs.Transfers[0].KeyImage
+
+	assert.NoError(t, cl.Freeze(&wallet.RequestFreeze{KeyImage: ki}))
+	frozen, err := cl.Frozen(&wallet.RequestFrozen{KeyImage: ki})
+	assert.NoError(t, err)
+	assert.True(t, frozen.Frozen)
+	addr, _ := cl.GetAddress(&wallet.RequestGetAddress{})
+	_, err = cl.Transfer(&wallet.RequestTransfer{Destinations: []*wallet.Destination{{Address: addr.Address, Amount: 1e12}}})
+	assert.True(t, errors.Is(err, wallet.ErrNotEnoughMoney), "got %v", err)
+
+	assert.NoError(t, cl.Thaw(&wallet.RequestThaw{KeyImage: ki}))
+	_, err = cl.Transfer(&wallet.RequestTransfer{Destinations: []*wallet.Destination{{Address: addr.Address, Amount: 1e12}}})
+	assert.NoError(t, err)
+
+	err = cl.Freeze(&wallet.RequestFreeze{KeyImage: "00"})
+	assert.True(t, errors.Is(err, wallet.ErrWrongKeyImage), "got %v", err)
+}
+
+func TestColdSigning(t *testing.T) {
+	w := NewWallet()
+	cl := newClient(t, w)
+	addr, err := cl.GetAddress(&wallet.RequestGetAddress{})
+	assert.NoError(t, err)
+	view, err := cl.QueryKey(&wallet.RequestQueryKey{KeyType: "view_key"})
+	assert.NoError(t, err)
+
+	_, err = cl.GenerateFromKeys(&wallet.RequestGenerateFromKeys{Filename: "watch", Address: addr.Address, ViewKey: view.Key})
+	assert.NoError(t, err)
+	w.Receive(Payment{File: "watch", Amount: 3e12, Height: 1})
+	unsigned, err := cl.Transfer(&wallet.RequestTransfer{Destinations: []*wallet.Destination{{Address: addr.Address, Amount: 1e12}}})
+	assert.NoError(t, err)
+	assert.Empty(t, unsigned.TxHash)
+	assert.NotEmpty(t, unsigned.UnsignedTxSet)
+
+	assert.NoError(t, cl.OpenWallet(&wallet.RequestOpenWallet{Filename: DefaultFileName}))
+	signed, err := cl.SignTransfer(&wallet.RequestSignTransfer{UnsighnedxSet: unsigned.UnsignedTxSet})
+	assert.NoError(t, err)
+	assert.Len(t, signed.TxHashList, 1)
+
+	assert.NoError(t, cl.OpenWallet(&wallet.RequestOpenWallet{Filename: "watch"}))
+	submitted, err := cl.SubmitTransfer(&wallet.RequestSubmitTransfer{TxDataHex: signed.SignedTxSet})
+	assert.NoError(t, err)
+	assert.Equal(t, signed.TxHashList, submitted.TxHashList)
+	_, err = cl.SubmitTransfer(&wallet.RequestSubmitTransfer{TxDataHex: signed.SignedTxSet})
+	assert.True(t, errors.Is(err, wallet.ErrSignedSubmission), "got %v", err)
+}
+
+func TestServerErrors(t *testing.T) {
+	w := NewWallet()
+	cl := newClient(t, w)
+
+	_, err := cl.CallRaw(context.Background(), "no_such_method", nil)
+	assert.True(t, errors.Is(err, wallet.ErrorCode(codeMethodNotFound)), "got %v", err)
+
+	calls := []wallet.BatchCall{
+		{Method: "get_height", Result: &wallet.ResponseGetHeight{}},
+		{Method: "get_attribute", Params: wallet.RequestGetAttribute{Key: "missing"}},
+	}
+	assert.NoError(t, cl.CallBatch(context.Background(), calls))
+	assert.NoError(t, calls[0].Error)
+	assert.Equal(t, uint64(DefaultHeight), calls[0].Result.(*wallet.ResponseGetHeight).Height)
+	assert.True(t, errors.Is(calls[1].Error, wallet.ErrAttributeNotFound), "got %v", calls[1].Error)
+
+	raw, err := w.CallRaw(context.Background(), "get_height", nil)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"height":100}`, string(raw))
+}
+
+// TestConsistency drives a Wallet directly through wallet.Client with
+// random payments, transfers and blocks, and checks after every step that
+// get_balance, get_transfers and incoming_transfers agree.
+func TestConsistency(t *testing.T) {
+	const accounts, subaddresses = 3, 3
+	for seed := int64(1); seed <= 20; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		w := NewWallet()
+		var cl wallet.Client = w
+		for i := 0; i < accounts; i++ {
+			w.Receive(Payment{Account: uint64(i), Subaddress: subaddresses - 1, Amount: 1e12, Height: 1})
+		}
+		var received, fees uint64 = accounts * 1e12, 0
+		for step := 0; step < 50; step++ {
+			switch rng.Intn(4) {
+			case 0:
+				amount := uint64(rng.Int63n(1e12)) + 1
+				w.Receive(Payment{
+					Account:    uint64(rng.Intn(accounts)),
+					Subaddress: uint64(rng.Intn(subaddresses)),
+					Amount:     amount,
+					UnlockTime: uint64(rng.Intn(2)) * (w.Height() + uint64(rng.Intn(30))),
+					Pool:       rng.Intn(2) == 0,
+				})
+				received += amount
+			case 1:
+				w.MineBlocks(uint64(rng.Intn(SpendableAge)) + 1)
+			default:
+				from, to := uint64(rng.Intn(accounts)), uint64(rng.Intn(accounts))
+				addr, err := cl.GetAddress(&wallet.RequestGetAddress{AccountIndex: to, AddressIndex: []uint64{uint64(rng.Intn(subaddresses))}})
+				assert.NoError(t, err)
+				res, err := cl.Transfer(&wallet.RequestTransfer{
+					AccountIndex: from,
+					Destinations: []*wallet.Destination{{Address: addr.Addresses[0].Address, Amount: uint64(rng.Int63n(5e11)) + 1}},
+					Priority:     wallet.Priority(rng.Intn(4)),
+				})
+				if err == nil {
+					fees += res.Fee
+				} else if !errors.Is(err, wallet.ErrNotEnoughMoney) && !errors.Is(err, wallet.ErrNotEnoughUnlockedMoney) {
+					t.Fatalf("seed %d: transfer: %v", seed, err)
+				}
+			}
+
+			var total uint64
+			for i := uint64(0); i < accounts; i++ {
+				total += checkAccount(t, w, i)
+			}
+			if t.Failed() {
+				t.Fatalf("seed %d: inconsistent after step %d", seed, step)
+			}
+			assert.Equal(t, received-fees, total, "seed %d step %d", seed, step)
+		}
+	}
+}
+
+// checkAccount checks that the views of account agree with each other and
+// returns its balance plus the incoming funds still in the pool.
+func checkAccount(t *testing.T, cl wallet.Client, account uint64) uint64 {
+	t.Helper()
+	bal, err := cl.GetBalance(&wallet.RequestGetBalance{AccountIndex: account})
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, bal.UnlockedBalance, bal.Balance)
+	var perSubaddress uint64
+	for _, s := range bal.PerSubaddress {
+		perSubaddress += s.Balance
+	}
+
+	inc, err := cl.IncomingTransfers(&wallet.RequestIncomingTransfers{TransferType: "available", AccountIndex: account})
+	assert.NoError(t, err)
+	var available, unlocked uint64
+	keyImages := make(map[string]bool)
+	indices := make(map[uint64]bool)
+	for _, o := range inc.Transfers {
+		available += o.Amount
+		if o.Unlocked {
+			unlocked += o.Amount
+		}
+		assert.False(t, keyImages[o.KeyImage], "duplicate key image %s", o.KeyImage)
+		assert.False(t, indices[o.GlobalIndex], "duplicate global index %d", o.GlobalIndex)
+		keyImages[o.KeyImage], indices[o.GlobalIndex] = true, true
+	}
+
+	tr, err := cl.GetTransfers(&wallet.RequestGetTransfers{In: true, Out: true, Pending: true, Pool: true, AccountIndex: account})
+	assert.NoError(t, err)
+	var in, out, pool uint64
+	for _, x := range tr.In {
+		in += x.Amount
+	}
+	for _, x := range tr.Pool {
+		pool += x.Amount
+	}
+	for _, x := range tr.Out {
+		out += x.Amount + x.Fee
+	}
+	for _, x := range tr.Pending {
+		out += x.Amount + x.Fee
+	}
+
+	assert.Equal(t, bal.Balance, perSubaddress)
+	assert.Equal(t, in-out, bal.Balance)
+	// Change of pending transactions counts towards the balance before
+	// incoming_transfers lists it, but it is never unlocked.
+	assert.LessOrEqual(t, available, bal.Balance)
+	assert.Equal(t, unlocked, bal.UnlockedBalance)
+	if len(tr.Pending) == 0 {
+		assert.Equal(t, available, bal.Balance)
+	}
+	return bal.Balance + pool
+}
@@ -0,0 +1,144 @@
+package zmq
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/boomhut/go-monero-rpc-client/daemon"
+)
+
+// Event is a notification. Its concrete type is *ChainMain,
+// *FullChainMain, *TxPoolAdd or *Raw.
+type Event interface {
+	Topic() string
+}
+
+// ChainMain reports blocks added to the main chain, usually one. Each
+// header has Height, Hash and PrevHash set; the other fields are zero.
+type ChainMain struct {
+	Blocks []daemon.BlockHeader
+}
+
+// Topic returns TopicChainMain.
+func (*ChainMain) Topic() string { return TopicChainMain }
+
+// FullChainMain reports blocks added to the main chain with their content.
+// monerod does not send their heights and hashes.
+type FullChainMain struct {
+	Blocks []Block
+}
+
+// Topic returns TopicFullChainMain.
+func (*FullChainMain) Topic() string { return TopicFullChainMain }
+
+// Block is a block of a FullChainMain. The header has MajorVersion,
+// MinorVersion, Timestamp, PrevHash, Nonce and NumTxes set.
+type Block struct {
+	daemon.BlockHeader
+	// MinerTx is the miner transaction in monerod's ZMQ JSON format.
+	MinerTx  json.RawMessage
+	TxHashes []string
+}
+
+// TxPoolAdd reports transactions added to the pool.
+type TxPoolAdd struct {
+	Txs []PoolTx
+}
+
+// Topic returns TopicTxPoolAdd.
+func (*TxPoolAdd) Topic() string { return TopicTxPoolAdd }
+
+// PoolTx is a transaction of a TxPoolAdd.
+type PoolTx struct {
+	TxHash   string `json:"id"`
+	BlobSize uint64 `json:"blob_size"`
+	Weight   uint64 `json:"weight"`
+	Fee      uint64 `json:"fee"`
+}
+
+// Raw is a notification of a topic without typed decoding, such as
+// "json-full-txpool_add" or "json-full-miner_data".
+type Raw struct {
+	Name string
+	Data json.RawMessage
+}
+
+// Topic returns the topic of the notification.
+func (r *Raw) Topic() string { return r.Name }
+
+// decode parses a notification, which monerod sends as a single frame
+// "topic:json".
+func decode(msg [][]byte) (Event, error) {
+	data := bytes.Join(msg, nil)
+	i := bytes.IndexByte(data, ':')
+	if i < 0 {
+		return nil, fmt.Errorf("zmq: message without topic: %.40q", data)
+	}
+	topic, body := string(data[:i]), data[i+1:]
+	ev, err := decodeTopic(topic, body)
+	if err != nil {
+		return nil, fmt.Errorf("zmq: decode %s: %w", topic, err)
+	}
+	return ev, nil
+}
+
+func decodeTopic(topic string, body []byte) (Event, error) {
+	switch topic {
+	case TopicChainMain:
+		var m struct {
+			FirstHeight uint64   `json:"first_height"`
+			FirstPrevID string   `json:"first_prev_id"`
+			IDs         []string `json:"ids"`
+		}
+		if err := json.Unmarshal(body, &m); err != nil {
+			return nil, err
+		}
+		ev := &ChainMain{Blocks: make([]daemon.BlockHeader, len(m.IDs))}
+		prev := m.FirstPrevID
+		for i, id := range m.IDs {
+			ev.Blocks[i] = daemon.BlockHeader{Height: m.FirstHeight + uint64(i), Hash: id, PrevHash: prev}
+			prev = id
+		}
+		return ev, nil
+	case TopicFullChainMain:
+		var blocks []struct {
+			MajorVersion uint64          `json:"major_version"`
+			MinorVersion uint64          `json:"minor_version"`
+			Timestamp    uint64          `json:"timestamp"`
+			PrevID       string          `json:"prev_id"`
+			Nonce        uint64          `json:"nonce"`
+			MinerTx      json.RawMessage `json:"miner_tx"`
+			TxHashes     []string        `json:"tx_hashes"`
+		}
+		if err := json.Unmarshal(body, &blocks); err != nil {
+			return nil, err
+		}
+		ev := &FullChainMain{Blocks: make([]Block, len(blocks))}
+		for i, b := range blocks {
+			ev.Blocks[i] = Block{
+				BlockHeader: daemon.BlockHeader{
+					MajorVersion: b.MajorVersion,
+					MinorVersion: b.MinorVersion,
+					Timestamp:    b.Timestamp,
+					PrevHash:     b.PrevID,
+					Nonce:        b.Nonce,
+					NumTxes:      uint64(len(b.TxHashes)),
+				},
+				MinerTx:  b.MinerTx,
+				TxHashes: b.TxHashes,
+			}
+		}
+		return ev, nil
+	case TopicTxPoolAdd:
+		ev := &TxPoolAdd{}
+		if err := json.Unmarshal(body, &ev.Txs); err != nil {
+			return nil, err
+		}
+		return ev, nil
+	}
+	if !json.Valid(body) {
+		return nil, fmt.Errorf("invalid JSON")
+	}
+	return &Raw{Name: topic, Data: json.RawMessage(body)}, nil
+}
@@ -0,0 +1,223 @@
+// Package zmq subscribes to the notifications monerod publishes over
+// ZeroMQ when started with --zmq-pub, so that services learn about new
+// blocks and pool transactions without polling. It speaks ZMTP, the
+// ZeroMQ wire protocol, in pure Go:
+//
+//	sub, err := zmq.New(zmq.Config{Address: "tcp://127.0.0.1:18083"})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer sub.Close()
+//	for ev := range sub.Events() {
+//		switch ev := ev.(type) {
+//		case *zmq.ChainMain:
+//			fmt.Println("new top block", ev.Blocks[len(ev.Blocks)-1].Hash)
+//		case *zmq.TxPoolAdd:
+//			fmt.Println(len(ev.Txs), "new pool transactions")
+//		}
+//	}
+//
+// The subscriber reconnects with exponential backoff when the connection
+// drops. Notifications published while it is disconnected are lost, so
+// services that must not miss a block should catch up over RPC after
+// Config.OnConnect is called.
+package zmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/boomhut/go-monero-rpc-client/internal/retry"
+	"github.com/boomhut/go-monero-rpc-client/internal/zmtp"
+)
+
+// Topics published by monerod that are decoded into typed events. Other
+// topics are delivered as *Raw.
+const (
+	TopicChainMain     = "json-minimal-chain_main"
+	TopicFullChainMain = "json-full-chain_main"
+	TopicTxPoolAdd     = "json-minimal-txpool_add"
+)
+
+// Config configures a Subscriber.
+type Config struct {
+	// Address of the monerod ZMQ publisher, as given to --zmq-pub, e.g.
+	// "tcp://127.0.0.1:18083".
+	Address string
+	// Topics to subscribe to. Defaults to TopicChainMain, TopicFullChainMain
+	// and TopicTxPoolAdd.
+	Topics []string
+	// ReconnectInterval is the delay before the first reconnection
+	// attempt. It doubles up to MaxReconnectInterval. Defaults to 1s and
+	// 30s.
+	ReconnectInterval    time.Duration
+	MaxReconnectInterval time.Duration
+	// DialTimeout bounds connecting and the ZMTP handshake. Defaults to 10s.
+	DialTimeout time.Duration
+	// Buffer is the capacity of the Events channel. Defaults to 64. When
+	// it is full the subscriber stops reading, and monerod drops messages
+	// once its own queue is full.
+	Buffer int
+	// OnConnect, if set, is called each time a connection is established
+	// and subscribed.
+	OnConnect func()
+	// OnError, if set, is called with connection errors before
+	// reconnecting and with messages that cannot be decoded.
+	OnError func(error)
+}
+
+// Subscriber receives monerod notifications. Create it with New and stop
+// it with Close.
+type Subscriber struct {
+	cfg    Config
+	addr   string
+	events chan Event
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New validates cfg and starts a Subscriber, which connects in the
+// background.
+func New(cfg Config) (*Subscriber, error) {
+	addr, err := parseAddress(cfg.Address)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Topics) == 0 {
+		cfg.Topics = []string{TopicChainMain, TopicFullChainMain, TopicTxPoolAdd}
+	}
+	if cfg.ReconnectInterval <= 0 {
+		cfg.ReconnectInterval = time.Second
+	}
+	if cfg.MaxReconnectInterval <= 0 {
+		cfg.MaxReconnectInterval = 30 * time.Second
+	}
+	if cfg.DialTimeout <= 0 {
+		cfg.DialTimeout = 10 * time.Second
+	}
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = 64
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Subscriber{
+		cfg:    cfg,
+		addr:   addr,
+		events: make(chan Event, cfg.Buffer),
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go s.run(ctx)
+	return s, nil
+}
+
+// parseAddress returns the host:port of a tcp:// address.
+func parseAddress(address string) (string, error) {
+	if address == "" {
+		return "", errors.New("zmq: no address")
+	}
+	scheme, hostport, ok := strings.Cut(address, "://")
+	if !ok {
+		return address, nil
+	}
+	if scheme != "tcp" {
+		return "", fmt.Errorf("zmq: unsupported transport %q, only tcp is supported", scheme)
+	}
+	return hostport, nil
+}
+
+// Events returns the channel notifications are delivered on. It is closed
+// after Close.
+func (s *Subscriber) Events() <-chan Event {
+	return s.events
+}
+
+// Close disconnects and stops the subscriber.
+func (s *Subscriber) Close() error {
+	s.cancel()
+	<-s.done
+	return nil
+}
+
+func (s *Subscriber) run(ctx context.Context) {
+	defer close(s.done)
+	defer close(s.events)
+	attempt := 1
+	for {
+		err := s.session(ctx, func() { attempt = 1 })
+		if ctx.Err() != nil {
+			return
+		}
+		s.report(err)
+		delay := retry.Backoff(attempt, s.cfg.ReconnectInterval, s.cfg.MaxReconnectInterval, 2, 0.2)
+		if retry.Sleep(ctx, delay) != nil {
+			return
+		}
+		attempt++
+	}
+}
+
+func (s *Subscriber) report(err error) {
+	if s.cfg.OnError != nil {
+		s.cfg.OnError(err)
+	}
+}
+
+// session connects, subscribes and delivers events until the connection
+// fails or ctx is done.
+func (s *Subscriber) session(ctx context.Context, connected func()) error {
+	dialer := net.Dialer{Timeout: s.cfg.DialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("zmq: %w", err)
+	}
+	var once sync.Once
+	closeConn := func() { once.Do(func() { conn.Close() }) }
+	defer closeConn()
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			closeConn()
+		case <-stop:
+		}
+	}()
+
+	conn.SetDeadline(time.Now().Add(s.cfg.DialTimeout))
+	zc, err := zmtp.Handshake(conn, zmtp.SUB)
+	if err != nil {
+		return fmt.Errorf("zmq: handshake with %s: %w", s.addr, err)
+	}
+	for _, topic := range s.cfg.Topics {
+		if err := zc.Subscribe(topic); err != nil {
+			return fmt.Errorf("zmq: subscribe to %s: %w", topic, err)
+		}
+	}
+	conn.SetDeadline(time.Time{})
+	connected()
+	if s.cfg.OnConnect != nil {
+		s.cfg.OnConnect()
+	}
+
+	for {
+		msg, err := zc.ReadMessage()
+		if err != nil {
+			return fmt.Errorf("zmq: %w", err)
+		}
+		ev, err := decode(msg)
+		if err != nil {
+			s.report(err)
+			continue
+		}
+		select {
+		case s.events <- ev:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package zmq
+
+import (
+	"testing"
+
+	"github.com/boomhut/go-monero-rpc-client/daemon"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode(t *testing.T) {
+	ev, err := decode([][]byte{[]byte(`json-minimal-chain_main:{"first_height":7,"first_prev_id":"a0","ids":["b1","c2"]}`)})
+	assert.NoError(t, err)
+	assert.Equal(t, &ChainMain{Blocks: []daemon.BlockHeader{
+		{Height: 7, Hash: "b1", PrevHash: "a0"},
+		{Height: 8, Hash: "c2", PrevHash: "b1"},
+	}}, ev)
+
+	ev, err = decode([][]byte{[]byte(`json-full-chain_main:[{"major_version":16,"minor_version":16,"timestamp":1700000000,"prev_id":"a0","nonce":5,"miner_tx":{"version":2},"tx_hashes":["d3"]}]`)})
+	assert.NoError(t, err)
+	full := ev.(*FullChainMain)
+	assert.Equal(t, TopicFullChainMain, full.Topic())
+	assert.Equal(t, "a0", full.Blocks[0].PrevHash)
+	assert.Equal(t, uint64(1), full.Blocks[0].NumTxes)
+	assert.Equal(t, uint64(5), full.Blocks[0].Nonce)
+	assert.JSONEq(t, `{"version":2}`, string(full.Blocks[0].MinerTx))
+	assert.Equal(t, []string{"d3"}, full.Blocks[0].TxHashes)
+
+	ev, err = decode([][]byte{[]byte(`json-minimal-txpool_add:[{"id":"e4","blob_size":1500,"weight":1500,"fee":30000000}]`)})
+	assert.NoError(t, err)
+	assert.Equal(t, &TxPoolAdd{Txs: []PoolTx{{TxHash: "e4", BlobSize: 1500, Weight: 1500, Fee: 30000000}}}, ev)
+
+	ev, err = decode([][]byte{[]byte(`json-full-miner_data:{"height":9}`)})
+	assert.NoError(t, err)
+	assert.Equal(t, "json-full-miner_data", ev.Topic())
+	assert.JSONEq(t, `{"height":9}`, string(ev.(*Raw).Data))
+
+	for _, msg := range []string{`no topic`, `json-minimal-txpool_add:{`, `other:{`} {
+		_, err = decode([][]byte{[]byte(msg)})
+		assert.Error(t, err, msg)
+	}
+}
+
+func TestParseAddress(t *testing.T) {
+	addr, err := parseAddress("tcp://127.0.0.1:18083")
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:18083", addr)
+	addr, err = parseAddress("node:18083")
+	assert.NoError(t, err)
+	assert.Equal(t, "node:18083", addr)
+	_, err = parseAddress("ipc:///tmp/monerod")
+	assert.Error(t, err)
+	_, err = New(Config{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,224 @@
+// Package zmqtest provides an in-process ZeroMQ publisher for tests. It
+// speaks ZMTP like monerod's --zmq-pub endpoint, so a zmq.Subscriber (or
+// any ZeroMQ SUB socket) can connect to it:
+//
+//	pub, err := zmqtest.NewPublisher()
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer pub.Close()
+//	sub, err := zmq.New(zmq.Config{Address: pub.Address()})
+//	...
+//	pub.WaitForSubscriber(ctx, zmq.TopicChainMain)
+//	pub.Publish(zmq.TopicChainMain, map[string]interface{}{
+//		"first_height": 100, "first_prev_id": prev, "ids": []string{id},
+//	})
+package zmqtest
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/boomhut/go-monero-rpc-client/internal/zmtp"
+)
+
+// Publisher is a PUB socket listening on a loopback port. It is safe for
+// concurrent use.
+type Publisher struct {
+	ln net.Listener
+	wg sync.WaitGroup
+
+	mu     sync.Mutex
+	conns  map[net.Conn]struct{}
+	subs   map[*subscriber]struct{}
+	closed bool
+}
+
+type subscriber struct {
+	conn *zmtp.Conn
+	// wmu serialises writes.
+	wmu sync.Mutex
+	// topics are the subscribed prefixes, guarded by Publisher.mu.
+	topics []string
+}
+
+// NewPublisher starts a Publisher on a random loopback port. Call Close
+// when done.
+func NewPublisher() (*Publisher, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	p := &Publisher{
+		ln:    ln,
+		conns: make(map[net.Conn]struct{}),
+		subs:  make(map[*subscriber]struct{}),
+	}
+	p.wg.Add(1)
+	go p.accept()
+	return p, nil
+}
+
+// Address returns the address to use as zmq.Config.Address.
+func (p *Publisher) Address() string {
+	return "tcp://" + p.ln.Addr().String()
+}
+
+func (p *Publisher) accept() {
+	defer p.wg.Done()
+	for {
+		conn, err := p.ln.Accept()
+		if err != nil {
+			return
+		}
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			conn.Close()
+			return
+		}
+		p.conns[conn] = struct{}{}
+		p.mu.Unlock()
+		p.wg.Add(1)
+		go p.serve(conn)
+	}
+}
+
+// serve performs the handshake and records the subscriptions of a
+// connection until it closes.
+func (p *Publisher) serve(conn net.Conn) {
+	defer p.wg.Done()
+	defer func() {
+		conn.Close()
+		p.mu.Lock()
+		delete(p.conns, conn)
+		p.mu.Unlock()
+	}()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	zc, err := zmtp.Handshake(conn, zmtp.PUB)
+	if err != nil {
+		return
+	}
+	conn.SetDeadline(time.Time{})
+	s := &subscriber{conn: zc}
+	p.mu.Lock()
+	p.subs[s] = struct{}{}
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		delete(p.subs, s)
+		p.mu.Unlock()
+	}()
+
+	for {
+		msg, err := zc.ReadMessage()
+		if err != nil {
+			return
+		}
+		if len(msg) != 1 || len(msg[0]) == 0 {
+			continue
+		}
+		topic := string(msg[0][1:])
+		p.mu.Lock()
+		switch msg[0][0] {
+		case 1:
+			s.topics = append(s.topics, topic)
+		case 0:
+			for i, t := range s.topics {
+				if t == topic {
+					s.topics = append(s.topics[:i], s.topics[i+1:]...)
+					break
+				}
+			}
+		}
+		p.mu.Unlock()
+	}
+}
+
+// subscribed returns the subscribers with a subscription matching topic.
+func (p *Publisher) subscribed(topic string) []*subscriber {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var subs []*subscriber
+	for s := range p.subs {
+		for _, prefix := range s.topics {
+			if strings.HasPrefix(topic, prefix) {
+				subs = append(subs, s)
+				break
+			}
+		}
+	}
+	return subs
+}
+
+// Subscribers returns how many connections are subscribed to topic.
+func (p *Publisher) Subscribers(topic string) int {
+	return len(p.subscribed(topic))
+}
+
+// WaitForSubscriber waits until a connection is subscribed to topic or
+// ctx is done. Messages published before that are lost, as with ZeroMQ.
+func (p *Publisher) WaitForSubscriber(ctx context.Context, topic string) error {
+	tick := time.NewTicker(5 * time.Millisecond)
+	defer tick.Stop()
+	for p.Subscribers(topic) == 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tick.C:
+		}
+	}
+	return nil
+}
+
+// Publish sends v as JSON on topic, in monerod's "topic:json" format.
+func (p *Publisher) Publish(topic string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	p.PublishRaw(topic, data)
+	return nil
+}
+
+// PublishRaw sends "topic:data" to the subscribers of topic. Subscribers
+// whose connection fails are dropped.
+func (p *Publisher) PublishRaw(topic string, data []byte) {
+	msg := append([]byte(topic+":"), data...)
+	for _, s := range p.subscribed(topic) {
+		s.wmu.Lock()
+		err := s.conn.WriteMessage(msg)
+		s.wmu.Unlock()
+		if err != nil {
+			s.conn.Close()
+		}
+	}
+}
+
+// Disconnect closes the connections of all subscribers, as a monerod
+// restart would. Subscribers may connect again.
+func (p *Publisher) Disconnect() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for s := range p.subs {
+		s.conn.Close()
+		delete(p.subs, s)
+	}
+}
+
+// Close stops the publisher and disconnects all subscribers.
+func (p *Publisher) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	for conn := range p.conns {
+		conn.Close()
+	}
+	p.mu.Unlock()
+	err := p.ln.Close()
+	p.wg.Wait()
+	return err
+}
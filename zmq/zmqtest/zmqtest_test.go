@@ -0,0 +1,82 @@
+package zmqtest
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/boomhut/go-monero-rpc-client/zmq"
+	"github.com/stretchr/testify/assert"
+)
+
+func next(t *testing.T, sub *zmq.Subscriber) zmq.Event {
+	t.Helper()
+	select {
+	case ev := <-sub.Events():
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event")
+		return nil
+	}
+}
+
+func TestSubscriber(t *testing.T) {
+	pub, err := NewPublisher()
+	assert.NoError(t, err)
+	defer pub.Close()
+
+	var connects, errs int32
+	sub, err := zmq.New(zmq.Config{
+		Address:           pub.Address(),
+		Topics:            []string{zmq.TopicChainMain, zmq.TopicTxPoolAdd},
+		ReconnectInterval: 10 * time.Millisecond,
+		OnConnect:         func() { atomic.AddInt32(&connects, 1) },
+		OnError:           func(error) { atomic.AddInt32(&errs, 1) },
+	})
+	assert.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, pub.WaitForSubscriber(ctx, zmq.TopicTxPoolAdd))
+
+	assert.NoError(t, pub.Publish(zmq.TopicFullChainMain, []interface{}{}))
+	assert.NoError(t, pub.Publish(zmq.TopicChainMain, map[string]interface{}{
+		"first_height": 100, "first_prev_id": "aa", "ids": []string{"bb"},
+	}))
+	pub.PublishRaw(zmq.TopicTxPoolAdd, []byte(`[{"id":"cc","blob_size":10,"weight":10,"fee":3}]`))
+
+	chain, ok := next(t, sub).(*zmq.ChainMain)
+	assert.True(t, ok, "the unsubscribed full chain is not delivered")
+	assert.Equal(t, uint64(100), chain.Blocks[0].Height)
+	assert.Equal(t, "bb", chain.Blocks[0].Hash)
+	pool := next(t, sub).(*zmq.TxPoolAdd)
+	assert.Equal(t, "cc", pool.Txs[0].TxHash)
+
+	pub.Disconnect()
+	assert.NoError(t, pub.WaitForSubscriber(ctx, zmq.TopicTxPoolAdd))
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&connects) == 2 }, 5*time.Second, time.Millisecond)
+	assert.NotZero(t, atomic.LoadInt32(&errs))
+	pub.PublishRaw(zmq.TopicTxPoolAdd, []byte(`[{"id":"dd"}]`))
+	assert.Equal(t, "dd", next(t, sub).(*zmq.TxPoolAdd).Txs[0].TxHash)
+
+	assert.NoError(t, sub.Close())
+	_, open := <-sub.Events()
+	assert.False(t, open)
+}
+
+func TestSubscriberDialErrors(t *testing.T) {
+	pub, err := NewPublisher()
+	assert.NoError(t, err)
+	address := pub.Address()
+	pub.Close()
+
+	var errs int32
+	sub, err := zmq.New(zmq.Config{
+		Address:           address,
+		ReconnectInterval: 5 * time.Millisecond,
+		OnError:           func(error) { atomic.AddInt32(&errs, 1) },
+	})
+	assert.NoError(t, err)
+	defer sub.Close()
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&errs) >= 2 }, 5*time.Second, time.Millisecond, "retried")
+}